@@ -0,0 +1,131 @@
+package logging
+
+// EnglishMessages maps the built-in Chinese log texts to English
+// Shared through each package of this module so one catalog covers each message
+//
+// EnglishMessages 将内置中文日志文本映射为英文
+// 由本模块各个包共享，使一份目录覆盖全部消息
+var EnglishMessages = map[string]string{
+	"申请锁":           "acquire lock",
+	"释放锁":           "release lock",
+	"申请层级锁":         "acquire hierarchy lock",
+	"释放层级锁":         "release hierarchy lock",
+	"锁已成功申请":        "lock acquired",
+	"锁已成功释放":        "lock released",
+	"锁已释放":          "lock released",
+	"锁已自动释放":        "lock expired ahead of release",
+	"锁不存在-或者锁已自动释放": "lock missing or expired ahead of release",
+	"锁已经被占用-申请不到-请等待释放":    "lock held through another session, wait for release",
+	"释放出错-锁被其它线程占用":        "release refused, lock held through another session",
+	"父锁已被占用或等待中-请等待释放":     "parent lock held or pending, wait for release",
+	"子锁仍在使用-已登记意向-请等待释放":   "children still active, intent recorded, wait for release",
+	"守卫条件不满足-拒绝申请":         "guard predicate failed, acquisition rejected",
+	"会话已持有锁-拒绝重复申请":        "session already holds the lock, acquisition rejected",
+	"已达最大持有时长-停止续期":        "max hold duration reached, extension stopped",
+	"探测版本":                 "redis version detected",
+	"探测版本失败-使用兼容脚本":        "redis version probe failed, using fallback scripts",
+	"等待者过多-快速失败":           "too many waiters, failing fast",
+	"请求报错":                 "redis request failed",
+	"其它错误":                 "unexpected blank reply",
+	"回复非预期类型":              "unexpected reply type",
+	"回复非预期格式":              "unexpected reply format",
+	"回复非预期内容":              "unexpected reply content",
+	"消息内容不匹配":              "unexpected reply message",
+	"批量检查报错":               "bulk inspection failed",
+	"检查结果报错":               "inspection reply invalid",
+	"登记注册表报错":              "registry write failed",
+	"注销注册表报错":              "registry removal failed",
+	"读取注册表报错":              "registry read failed",
+	"清理注册表报错":              "registry cleanup failed",
+	"排队报错":                 "enqueue failed",
+	"查询队列报错":               "queue status failed",
+	"离开队列报错":               "leave queue failed",
+	"记录持有时长报错":             "hold duration record failed",
+	"锁事件已关闭-丢弃事件":          "event dispatcher closed, event dropped",
+	"锁事件缓冲已满-丢弃事件":         "event buffer full, event dropped",
+	"锁事件发送失败-丢弃批次":         "event batch send failed, batch dropped",
+	"锁事件发送失败-稍后重试":         "event batch send failed, retrying",
+	"检测到锁误用":               "lock misuse detected",
+	"锁即将过期-未延期":            "lock expiring soon without extension",
+	"等待释放报错":               "await release failed",
+	"保存检查点报错":              "checkpoint write failed",
+	"锁已丢失-不保存检查点":          "lock lost, checkpoint not written",
+	"写入执行记录报错":             "execution record write failed",
+	"读取执行记录报错":             "execution record read failed",
+	"已由先前持有者完成-跳过":         "run completed through a previous holder, skipped",
+	"锁已丢失-未写入执行记录":         "lock lost, execution record not written",
+	"跟随持有者的运行结果":           "following the outcome recorded through the holder",
+	"准入回调否决申请":             "acquisition vetoed by admission",
+	"申请许可报错":               "permit acquisition failed",
+	"归还许可报错":               "permit release failed",
+	"分片运行报错":               "shard run failed",
+	"锁操作延迟异常":              "lock operation latency outlier",
+	"试运行-模拟申请锁成功":          "dry run, lock acquisition simulated",
+	"试运行-模拟释放锁成功":          "dry run, lock release simulated",
+	"试运行模式已开启-锁操作不访问Redis": "dry run enabled, lock operations skip Redis",
+	"清理伴随键报错":              "companion cleanup failed",
+	"集群重定向-重试请求":           "cluster redirection, retrying",
+	"热备轮询报错":               "standby poll failed",
+	"缩短租期报错":               "lease shortening failed",
+	"释放前屏障报错":              "before-release barrier failed",
+	"释放后回调崩溃":              "after-release callback panicked",
+	"运行超时-脱离执行":            "run outlived its deadline, executing detached",
+	"脱离执行-仍在运行":            "detached run still alive",
+	"脱离执行-已结束":             "detached run finished",
+	"脱离执行未结束-拒绝运行":         "detached run still alive, refusing new run",
+	"按预估延期":                "extending by the remaining work estimate",
+	"等待超时-放弃申请":            "lock not obtained within the wait, giving up",
+	"自动续期报错":               "auto renewal failed",
+	"自动续期失败-锁已丢失":          "auto renewal refused, lock lost",
+	"采集抢占信息报错":             "capturing usurper state failed",
+	"锁被抢占-取证记录":            "lock stolen, forensic record captured",
+	"发布释放通知报错":             "publishing release notification failed",
+	"订阅释放通知报错":             "subscribing release notifications failed",
+	"节点探测失败":               "node probe failed",
+	"法定数量不可达":              "quorum not reachable",
+	"预加载脚本报错":              "preloading scripts failed",
+	"写入临时键报错":              "writing temp key failed",
+	"同步临时键报错":              "syncing temp keys failed",
+	"删除临时键报错":              "deleting temp keys failed",
+	"校验剩余租期延期报错":           "extending with remaining check failed",
+	"剩余租期不足-拒绝延期":          "too little lease remained-extension refused",
+	"法定数量锁已申请":             "quorum lock acquired",
+	"法定数量锁未达成-回滚":          "quorum lock missed-rolling back",
+	"法定数量锁节点释放报错":          "quorum lock node release failed",
+	"申请读锁报错":               "acquiring read lock failed",
+	"写锁已占用-申请不到读锁":         "write lock held-read lock unavailable",
+	"释放读锁报错":               "releasing read lock failed",
+	"申请写锁报错":               "acquiring write lock failed",
+	"锁已被占用-申请不到写锁":         "lock held-write lock unavailable",
+	"延迟释放-保持到期":            "deferred release-holding until due",
+	"申请可重入锁报错":             "acquiring reentrant lock failed",
+	"可重入锁已申请":              "reentrant lock acquired",
+	"释放可重入锁报错":             "releasing reentrant lock failed",
+	"可重入锁已释放":              "reentrant lock released",
+	"延期可重入锁报错":             "extending reentrant lock failed",
+	"等待者已被清理-重新排队":         "waiter pruned-queueing again",
+	"Redis连续报错-暂停轮询":       "Redis failing in a row-pausing the polling",
+	"Redis已恢复-继续轮询":        "Redis back-polling goes on",
+	"竞选报错":                 "campaign failed",
+	"当选领导者":                "elected leader",
+	"任期丢失":                 "term lost",
+	"卸任释放报错":               "releasing on resignation failed",
+	"已卸任":                  "resigned",
+	"延期过于频繁-已限流":           "extensions too frequent, throttled",
+	"故障切换-改用下一个客户端":        "connection problems, failing over to the next client",
+	"故障切换后锁已不归属本会话":        "lock no longer owned by the session after failover",
+	"持锁预算已用尽-拒绝申请":         "acquisition refused, hold budget of the process exhausted",
+	"强制释放锁":                "lock force released",
+	"强制释放锁-锁已空闲":           "force release found the lock free",
+	"强制释放锁报错":              "force release failed",
+	"键空间通知未开启-退回轮询":        "keyspace notifications disabled, falling back on polling",
+	"批量申请锁报错":              "acquiring several locks failed",
+	"批量申请锁-部分键被占用":         "acquiring several locks refused, a key is held elsewhere",
+	"批量申请锁-回滚报错":           "rolling back several locks failed",
+	"编码元数据报错":              "encoding metadata failed",
+	"更新元数据报错":              "metadata update failed",
+	"锁已丢失-不更新元数据":          "lock lost, metadata not updated",
+	"试运行-模拟批量申请锁成功":        "dry run, multi lock acquisition simulated",
+	"试运行-跳过Redis操作":        "dry run, redis operation skipped",
+	"维护模式-拒绝申请":            "acquisition refused, lock frozen for maintenance",
+}
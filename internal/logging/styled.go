@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// actionField is the field whose string value is a message translated together with log texts
+// actionField 是其字符串值与日志文本一起翻译的字段
+const actionField = "action"
+
+// styledLogger rewrites field keys and message texts ahead of the wrapped logger
+// Messages and action values are looked up through their built-in Chinese text
+//
+// styledLogger 在被包装的日志记录器之前重写字段键和消息文本
+// 消息和 action 值通过其内置中文文本查找
+type styledLogger struct {
+	logger    Logger            // Wrapped logger // 被包装的日志记录器
+	fieldKeys map[string]string // Built-in field key to rendered key // 内置字段键到输出键
+	messages  map[string]string // Built-in message to rendered message // 内置消息到输出消息
+}
+
+// NewStyledLogger wraps a logger so field keys and messages get renamed through the given maps
+// Entries missing in the maps keep the built-in text, wrapping a styled logger replaces its style
+//
+// NewStyledLogger 包装日志记录器，使字段键和消息按给定映射重命名
+// 映射中缺失的条目保持内置文本，包装已有样式的日志记录器会替换其样式
+func NewStyledLogger(logger Logger, fieldKeys map[string]string, messages map[string]string) Logger {
+	if styled, ok := logger.(*styledLogger); ok {
+		logger = styled.logger
+	}
+	return &styledLogger{
+		logger:    logger,
+		fieldKeys: fieldKeys,
+		messages:  messages,
+	}
+}
+
+// DebugLog logs debug-level messages with renamed fields
+// DebugLog 记录带重命名字段的调试级别消息
+func (l *styledLogger) DebugLog(msg string, fields ...zap.Field) {
+	l.logger.DebugLog(l.message(msg), l.rewrite(fields)...)
+}
+
+// ErrorLog logs error-level messages with renamed fields
+// ErrorLog 记录带重命名字段的错误级别消息
+func (l *styledLogger) ErrorLog(msg string, fields ...zap.Field) {
+	l.logger.ErrorLog(l.message(msg), l.rewrite(fields)...)
+}
+
+// WithMeta creates a new styled logger with renamed context fields
+// WithMeta 创建带重命名上下文字段的新样式日志记录器
+func (l *styledLogger) WithMeta(fields ...zap.Field) Logger {
+	return &styledLogger{
+		logger:    l.logger.WithMeta(l.rewrite(fields)...),
+		fieldKeys: l.fieldKeys,
+		messages:  l.messages,
+	}
+}
+
+// message gets back the rendered text of a built-in message
+// message 返回内置消息的输出文本
+func (l *styledLogger) message(msg string) string {
+	if text, ok := l.messages[msg]; ok {
+		return text
+	}
+	return msg
+}
+
+// rewrite copies the fields with renamed keys and translated action values
+// rewrite 复制字段并重命名键、翻译 action 值
+func (l *styledLogger) rewrite(fields []zap.Field) []zap.Field {
+	res := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		if field.Key == actionField && field.Type == zapcore.StringType {
+			field.String = l.message(field.String)
+		}
+		if key, ok := l.fieldKeys[field.Key]; ok {
+			field.Key = key
+		}
+		res = append(res, field)
+	}
+	return res
+}
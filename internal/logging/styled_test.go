@@ -0,0 +1,50 @@
+package logging_test
+
+import (
+	"testing"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestNewStyledLogger tests renaming of field keys, messages and action values
+// 测试字段键、消息和 action 值的重命名
+func TestNewStyledLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	logger := logging.NewStyledLogger(
+		logging.NewZapLogger(zap.New(core)),
+		map[string]string{"k": "lock_key"},
+		logging.EnglishMessages,
+	)
+	logger.WithMeta(zap.String("action", "申请锁"), zap.String("k", "demo")).DebugLog("锁已成功申请", zap.String("v", "abc"))
+	logger.ErrorLog("custom message")
+
+	entries := logs.All()
+	require.Len(t, entries, 2)
+
+	require.Equal(t, "lock acquired", entries[0].Message)
+	fields := entries[0].ContextMap()
+	require.Equal(t, "acquire lock", fields["action"])
+	require.Equal(t, "demo", fields["lock_key"])
+	require.Equal(t, "abc", fields["v"])
+
+	require.Equal(t, "custom message", entries[1].Message)
+}
+
+// TestNewStyledLogger_Replace tests that wrapping a styled logger replaces its style
+// 测试包装已有样式的日志记录器会替换其样式
+func TestNewStyledLogger_Replace(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	logger := logging.NewStyledLogger(logging.NewZapLogger(zap.New(core)), nil, logging.EnglishMessages)
+	logger = logging.NewStyledLogger(logger, nil, map[string]string{"锁已成功申请": "verrou acquis"})
+	logger.DebugLog("锁已成功申请")
+
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	require.Equal(t, "verrou acquis", entries[0].Message)
+}
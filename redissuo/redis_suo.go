@@ -13,6 +13,7 @@ import (
 	"context"
 	"reflect"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-xlan/redis-go-suo/internal/logging"
@@ -35,10 +36,58 @@ import (
 // 提供基于 Lua 原子操作的核心锁定命令
 // 在多个 goroutine 中使用时是线程安全的
 type Suo struct {
-	redisClient redis.UniversalClient // Redis client connection // Redis 客户端连接
-	key         string                // Unique lock name ID // 唯一锁名标识符
-	ttl         time.Duration         // Lock expiration timeout // 锁过期超时时间
-	logger      logging.Logger        // Logger instance used in operations // 操作中使用的日志记录器实例
+	redisClient    redis.UniversalClient // Redis client connection // Redis 客户端连接
+	key            string                // Unique lock name ID // 唯一锁名标识符
+	ttl            time.Duration         // Lock expiration timeout // 锁过期超时时间
+	logger         logging.Logger        // Logger instance used in operations // 操作中使用的日志记录器实例
+	acquireLOG     logging.Logger        // Logger pre-bound with acquire action and key // 预绑定申请动作和键的日志记录器
+	releaseLOG     logging.Logger        // Logger pre-bound with release action and key // 预绑定释放动作和键的日志记录器
+	serverTime     bool                  // Anchor expiry on Redis TIME // 使用 Redis TIME 锚定过期时间
+	version        *versionProbe         // Lazily detected Redis server version // 延迟探测的 Redis 服务端版本
+	guards         []Guard               // Predicates checked ahead of acquisition // 获取前检查的条件
+	tags           map[string]string     // Tags stored in lock metadata // 存储在锁元数据中的标签
+	registry       string                // Registry hash listing held locks, blank when disabled // 列出已持有锁的注册表哈希，为空时禁用
+	waitQueue      bool                  // Track waiter queue and hold durations // 跟踪等待队列和持有时长
+	fairQueue      bool                  // Grant the lock to queued waiters in arrival sequence // 按到达顺序将锁授予排队的等待者
+	maxHold        time.Duration         // Cap on the whole hold across extensions, 0 means unlimited // 跨延期的总持有时长上限，0 表示不限制
+	extendFraction float64               // Fraction of the TTL below which extension is due, 0 means always // 低于 TTL 该比例时才需延期，0 表示总是延期
+	growFactor     float64               // Lease growth per extension, 1 or below means fixed // 每次延期的租期增长倍数，不大于 1 表示固定
+	safetyFactor   float64               // Lease per unit of the remaining work estimate in ExtendFor // ExtendFor 中每单位剩余工作预估对应的租期倍数
+	growMaxTTL     time.Duration         // Cap of the grown lease // 增长后租期的上限
+	strict         bool                  // Reject same-session acquisition outside extension // 拒绝延期之外的同会话获取
+	fencing        bool                  // Issue a fencing token with each acquisition // 每次获取时签发防护令牌
+	extendLimit    int                   // Extensions allowed per session in each window, 0 means unlimited // 每个窗口内每个会话允许的延期次数，0 表示不限制
+	extendWindow   time.Duration         // Window of the extension rate limit // 延期频率限制的窗口
+	typedErrors    bool                  // Report missed outcomes through sentinel errors // 通过哨兵错误报告未成功的结果
+	identity       bool                  // Store hostname, PID and acquisition time in metadata // 在元数据中存储主机名、PID 和获取时间
+	stackLimit     int                   // Bytes of holder stack kept in metadata, 0 means disabled // 元数据中保留的持有者堆栈字节数，0 表示禁用
+	style          *LogStyle             // Field keys and message language of logs // 日志的字段键和消息语言
+	language       Language              // Language of error messages // 错误消息语言
+	events         *EventDispatcher      // Receives lifecycle events, nil when disabled // 接收生命周期事件，为空时禁用
+	clock          Clock                 // Source of time and sleeps // 时间和休眠的来源
+	misuse         bool                  // Debug mode catching misuse patterns // 捕获误用模式的调试模式
+	onMisuse       func(misuse *Misuse)  // Receives caught misuses, nil when unset // 接收捕获的误用，未设置时为空
+	expiringSoon   time.Duration         // Lease left when the expiring warning fires, 0 means disabled // 触发即将过期警告时的剩余租期，0 表示禁用
+	onExpiringSoon func(xin *Xin)        // Receives the expiring warning, nil when unset // 接收即将过期警告，未设置时为空
+	exitReleaser   *ExitReleaser         // Keeps live sessions released at exit, nil when disabled // 记录退出时释放的存活会话，为空时禁用
+	holds          *holdSet              // Sessions held through locks of the manager, nil outside a manager // 通过管理器的锁持有的会话，不属于管理器时为空
+	maintenance    *MaintenanceGate      // Freezes fresh acquisitions during maintenance, nil when disabled // 维护期间冻结新获取，为空时禁用
+	pause          *pauseSwitch          // Holds fresh acquisitions back while the manager pauses, nil outside a manager // 管理器暂停期间拦住新获取，不属于管理器时为空
+	admission      Admission             // Vetoes fresh acquisitions ahead of Redis traffic, nil when unset // 在 Redis 请求之前否决新获取，未设置时为空
+	hooks          *Hooks                // Side effects at lifecycle points, nil when unset // 生命周期各节点的副作用，未设置时为空
+	tracer         Tracer                // Starts spans around lock operations, nil when disabled // 在锁操作周围开启 span，为空时禁用
+	budget         *holdBudget           // Bounds the locks the process holds at once, nil outside a budgeted manager // 限制进程同时持有的锁数量，不属于设置了预算的管理器时为空
+	latency        *LatencyTracker       // Measures round trips and warns on outliers, nil when disabled // 测量往返延迟并对异常值发出警告，为空时禁用
+	dryRun         bool                  // Simulate lock operations locally without Redis // 在本地模拟锁操作而不访问 Redis
+	keyspaceNotify bool                  // Listen on keyspace notifications of the key in SubscribeRelease // 在 SubscribeRelease 中监听该键的键空间通知
+	releaseNotify  bool                  // Publish on the release channel after each release // 每次释放后在释放频道上发布消息
+	codec          Codec                 // Serializes the metadata companion value // 序列化元数据伴随键的值
+	redirectLimit  int                   // Retries of scripts hitting cluster redirections // 脚本遇到集群重定向时的重试次数
+	redirects      *atomic.Int64         // Redirections met, shared across locks of a manager // 遇到的重定向次数，在同一管理器的锁之间共享
+	failover       *failoverSet          // Ordered clients switched on connection problems, nil when disabled // 遇到连接错误时切换的有序客户端，为空时禁用
+	random         Random                // Source of jitter // 抖动的随机来源
+	ttlJitter      float64               // Fraction of the TTL fresh leases spread across, 0 means disabled // 新租期分布的 TTL 比例，0 表示禁用
+	driftFactor    float64               // Share of the TTL set aside against clock drift, 0 means disabled // 为时钟漂移预留的 TTL 比例，0 表示禁用
 }
 
 // NewSuo creates a new Redis distributed lock instance using specified parameters
@@ -51,12 +100,21 @@ type Suo struct {
 // 设置不能为空否则函数会通过 must.Nice 触发 panic
 // 返回适用于生产环境的准备就绪分布式锁
 func NewSuo(rds redis.UniversalClient, key string, ttl time.Duration) *Suo {
-	return &Suo{
-		redisClient: must.Nice(rds),                            // Validated Redis client // 经过验证的 Redis 客户端
-		key:         must.Nice(key),                            // Validated lock name // 经过验证的锁名
-		ttl:         must.Nice(ttl),                            // Validated TTL duration // 经过验证的 TTL 时长
-		logger:      logging.NewZapLogger(zaplog.LOGS.Skip(1)), // Default logger // 默认日志记录器
+	o := &Suo{
+		redisClient:   must.Nice(rds),       // Validated Redis client // 经过验证的 Redis 客户端
+		key:           must.Nice(key),       // Validated lock name // 经过验证的锁名
+		ttl:           must.Nice(ttl),       // Validated TTL duration // 经过验证的 TTL 时长
+		version:       &versionProbe{},      // Probed on first use // 首次使用时探测
+		language:      LanguageChinese,      // Default error language // 默认错误语言
+		random:        SystemRandom(),       // Global math/rand source // math/rand 全局源
+		clock:         SystemClock(),        // Wall clock // 系统时钟
+		codec:         JSONCodec{},          // JSON metadata // JSON 元数据
+		redirectLimit: defaultRedirectLimit, // Cluster client default // 集群客户端默认值
+		redirects:     &atomic.Int64{},      // Own counter // 独立计数器
+		safetyFactor:  defaultSafetyFactor,  // Half again the estimate // 预估的 1.5 倍
 	}
+	o.setLogger(logging.NewZapLogger(zaplog.LOGS.Skip(1))) // Default logger // 默认日志记录器
+	return o
 }
 
 // WithLogger sets custom logger used in lock operations
@@ -67,7 +125,38 @@ func NewSuo(rds redis.UniversalClient, key string, ttl time.Duration) *Suo {
 // 修改当前 Suo 实例并返回以支持方法链式调用
 // 允许注入自定义日志实现以实现灵活策略
 func (o *Suo) WithLogger(logger logging.Logger) *Suo {
+	o.setLogger(o.style.Wrap(logger))
+	return o
+}
+
+// setLogger sets the logger and pre-binds the key-level loggers of the hot paths
+// Spin-waiters poll acquire many times, binding once spares the per-poll field copies
+//
+// setLogger 设置日志记录器并预绑定热点路径上的键级日志记录器
+// 自旋等待者会多次轮询申请，一次性绑定可节省每次轮询的字段复制
+func (o *Suo) setLogger(logger logging.Logger) {
 	o.logger = logger
+	o.acquireLOG = logger.WithMeta(zap.String("action", "申请锁"), zap.String("k", o.key))
+	o.releaseLOG = logger.WithMeta(zap.String("action", "释放锁"), zap.String("k", o.key))
+}
+
+// Key gets back the lock name ID used in Redis
+// 返回 Redis 中使用的锁名标识符
+func (o *Suo) Key() string {
+	return o.key
+}
+
+// WithServerTime enables anchoring expiry calculations on the Redis server clock
+// The acquire script calls TIME and the session records the expiry in server time
+// Expire is the server expiry read back on the client clock through the offset measured over the round trip
+// Removes client/server clock skew from the expiry safety margin, so the drift margin does not apply
+//
+// WithServerTime 启用基于 Redis 服务端时钟的过期时间计算
+// 获取脚本调用 TIME，会话记录服务端时间下的过期时间
+// Expire 是通过往返过程中测得的偏移换算到客户端时钟上的服务端过期时间
+// 消除客户端与服务端时钟偏差对过期安全余量的影响，因此不再扣除漂移余量
+func (o *Suo) WithServerTime(enable bool) *Suo {
+	o.serverTime = enable
 	return o
 }
 
@@ -78,51 +167,105 @@ const (
 else
     return redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2])
 end`
+
+	// TIME is called past the write so the script stays replication safe on legacy Redis versions
+	// TIME 在写入之后调用，确保在旧版本 Redis 上脚本复制安全
+	commandAcquireServerTime = `local res
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+    res = "OK"
+else
+    res = redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2])
+end
+if not res then
+    return false
+end
+local now = redis.call("TIME")
+return {"OK", now[1], now[2]}`
 )
 
 // acquire attempts to acquire the distributed lock using given session value
 // Uses atomic Lua script preventing race conditions in lock acquisition
 // Returns true when lock is acquired, false when held through different session
 // Handles Redis problems and provides detailed logging assisting debugging
+// Also returns the Redis server time of acquisition when server time is enabled, zero otherwise
+// Also returns the fencing token when fencing is enabled, zero otherwise
 //
 // acquire 尝试使用给定会话值获取分布式锁
 // 使用原子 Lua 脚本防止锁获取过程中的竞态条件
 // 如果成功获取锁返回 true，如果被其他会话持有返回 false
 // 处理 Redis 错误并提供详细日志来辅助调试
-func (o *Suo) acquire(ctx context.Context, value string) (bool, error) {
+// 启用服务端时间时同时返回 Redis 服务端的获取时间，否则返回零值
+// 启用防护时同时返回防护令牌，否则返回零值
+func (o *Suo) acquire(ctx context.Context, value string, request *acquireRequest) (bool, time.Time, int64, error) {
 	must.OK(value) // Validate session value is non-blank // 验证会话值非空
 
 	// Create structured log coordination with operation context // 创建带操作上下文的结构化日志记录器
-	LOG := o.logger.WithMeta(
-		zap.String("action", "申请锁"),
-		zap.String("k", o.key),
-		zap.String("v", value),
-	)
+	LOG := o.acquireLOG.WithMeta(zap.String("v", value))
+	// Dry-run locks always succeed without touching Redis
+	// 试运行的锁总是成功且不访问 Redis
+	if o.dryRun {
+		LOG.DebugLog("试运行-模拟申请锁成功", zap.Bool("dry_run", true))
+		return true, time.Time{}, 0, nil
+	}
 
 	// Convert TTL into milliseconds as Redis PX argument
 	// Redis PX expects milliseconds setting expiration time
 	// 将 TTL 转换为毫秒用于 Redis PX 参数
 	// Redis PX 期望用毫秒数设置过期时间
-	milliseconds := o.ttl.Milliseconds()
+	milliseconds := request.ttl.Milliseconds()
 
 	// Execute atomic Lua script using lock name and session parameters
+	// The script variant matches the options and the Redis server version
 	// 执行带锁名和会话参数的原子 Lua 脚本
-	result, err := o.redisClient.Eval(ctx, commandAcquire, []string{o.key}, []string{value, strconv.FormatInt(milliseconds, 10)}).Result()
+	// 脚本变体与选项和 Redis 服务端版本相匹配
+	command, keys, args, err := o.acquireScript(ctx, value, milliseconds, request)
+	if err != nil {
+		LOG.ErrorLog("编码元数据报错", zap.Error(err))
+		return false, time.Time{}, 0, erero.Wro(err)
+	}
+	evalStart := o.clock.Now()
+	// Extensions skip the transparent failover, verifyFailover checks them on the next attempt instead
+	// 延期不做透明故障切换，改由下一次尝试时的 verifyFailover 检查
+	result, err := o.evalWith(ctx, !request.extend, command, keys, args)
+	o.observeLatency(OperationAcquire, value, evalStart)
 	if errors.Is(err, redis.Nil) {
 		// Lock held by different session, acquisition failed
 		// 锁被其他会话持有，获取失败
 		LOG.DebugLog("锁已经被占用-申请不到-请等待释放")
-		return false, nil
+		return false, time.Time{}, 0, nil
 	} else if err != nil {
 		// Redis operation problem occurred in acquisition
 		// Redis 操作在获取过程中发生错误
 		LOG.ErrorLog("请求报错", zap.Error(err))
-		return false, erero.Wro(err)
+		return false, time.Time{}, 0, erero.Wro(err)
 	} else if result == nil {
 		// Unexpected blank response came back from Redis
 		// Redis 返回意外的空响应
 		LOG.ErrorLog("其它错误")
-		return false, nil
+		return false, time.Time{}, 0, nil
+	}
+
+	// Take the fencing token off the reply ahead of the server time part
+	// 先从回复中取出防护令牌，再处理服务端时间部分
+	var token int64
+	if o.fencing {
+		var ok bool
+		if token, result, ok = splitFencingToken(result); !ok {
+			LOG.ErrorLog("回复非预期格式", zap.Any("result", result))
+			return false, time.Time{}, 0, nil
+		}
+	}
+
+	// Split the server time part away from the status message
+	// 从状态消息中拆分出服务端时间部分
+	var serverTime time.Time
+	if items, ok := result.([]interface{}); ok {
+		if serverTime, ok = parseServerTime(items); !ok {
+			LOG.ErrorLog("回复非预期格式", zap.Any("result", result))
+			return false, time.Time{}, 0, nil
+		}
+		result = items[0]
 	}
 
 	// Parse response given back from Lua script execution
@@ -132,27 +275,75 @@ func (o *Suo) acquire(ctx context.Context, value string) (bool, error) {
 		// Response kind validation check did not pass, unexpected format came back
 		// 响应类型验证失败，收到意外格式
 		LOG.ErrorLog("回复非预期类型", zap.Any("result", result), zap.String("result_type", reflect.TypeOf(result).String()))
-		return false, nil
+		return false, time.Time{}, 0, nil
+	}
+	if message == alreadyHeldMessage {
+		// Strict mode caught a second acquisition through the holding session
+		// 严格模式发现持有会话的二次获取
+		LOG.ErrorLog("会话已持有锁-拒绝重复申请")
+		return false, time.Time{}, 0, o.newError(CodeAlreadyHeld)
+	}
+	if message == guardRejectedMessage {
+		// Guard predicate blocked the acquisition
+		// 守卫条件阻止了锁获取
+		LOG.DebugLog("守卫条件不满足-拒绝申请", zap.Int("guards", len(o.guards)))
+		return false, time.Time{}, 0, o.newError(CodeGuardRejected)
 	}
 	if message != "OK" {
 		// Lock acquisition did not complete, message content mismatch was detected
 		// 锁获取失败，检测到消息内容不匹配
 		LOG.ErrorLog("消息内容不匹配", zap.String("message", message))
-		return false, nil
+		return false, time.Time{}, 0, nil
 	}
 	// Lock was obtained through the session
 	// 当前会话成功获取锁
 	LOG.DebugLog("锁已成功申请")
-	return true, nil
+	return true, serverTime, token, nil
+}
+
+// parseServerTime converts the {status, seconds, microseconds} reply into a time value
+// Returns false when the reply does not match the expected TIME layout
+//
+// parseServerTime 将 {状态, 秒, 微秒} 回复转换为时间值
+// 当回复不符合预期的 TIME 格式时返回 false
+func parseServerTime(items []interface{}) (time.Time, bool) {
+	if len(items) != 3 {
+		return time.Time{}, false
+	}
+	secText, ok := items[1].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	usecText, ok := items[2].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(secText, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	usec, err := strconv.ParseInt(usecText, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, usec*int64(time.Microsecond)), true
 }
 
 const (
 	// 通过官方文档，在 Lua 脚本里判定 redis.call("GET", KEYS[1]) 返回是否为空值，该直接判断结果 true/false，直接不是使用空值判定不存在
 	// redis.call("DEL", KEYS[1]) 只会返回 0 或 1，不会有其他返回值
+	// KEYS[2] is the optional metadata companion, removed together with the lock
+	// KEYS[2] 是可选的元数据伴随键，与锁一同删除
 	commandRelease = `local ch = redis.call("GET", KEYS[1])
 if (ch == false) then
+	if KEYS[2] then
+		redis.call("DEL", KEYS[2])
+	end
 	return 2
 elseif ch == ARGV[1] then
+	if KEYS[2] then
+		redis.call("DEL", KEYS[2])
+	end
     return redis.call("DEL", KEYS[1])
 else
     return 3
@@ -168,64 +359,80 @@ end`
 // 使用原子 Lua 脚本在删除前安全检查所有权
 // 如果成功释放锁返回 true，如果被不同会话拥有返回 false
 // 提供详细状态码以区分各种释放场景
-func (o *Suo) release(ctx context.Context, value string) (bool, error) {
+func (o *Suo) release(ctx context.Context, value string, withMeta bool) (bool, error) {
+	_, success, err := o.releaseStatus(ctx, value, withMeta)
+	return success, err
+}
+
+// releaseStatus runs the release script and gives back its status next to the outcome
+// releaseStatus 执行释放脚本，并在结果之外返回其状态
+func (o *Suo) releaseStatus(ctx context.Context, value string, withMeta bool) (ReleaseStatus, bool, error) {
 	must.OK(value) // Validate session value is non-blank // 验证会话值非空
 
 	// Create structured log coordination handling release operation // 为释放操作创建结构化日志记录器
-	LOG := o.logger.WithMeta(
-		zap.String("action", "释放锁"),
-		zap.String("k", o.key),
-		zap.String("v", value),
-	)
+	LOG := o.releaseLOG.WithMeta(zap.String("v", value))
+	// Dry-run locks always succeed without touching Redis
+	// 试运行的锁总是成功且不访问 Redis
+	if o.dryRun {
+		LOG.DebugLog("试运行-模拟释放锁成功", zap.Bool("dry_run", true))
+		return ReleaseDeleted, true, nil
+	}
 
 	// Execute atomic Lua script ensuring safe lock release
 	// 执行原子 Lua 脚本进行安全锁释放
-	result, err := o.redisClient.Eval(ctx, commandRelease, []string{o.key}, []string{value}).Result()
+	// The metadata companion travels as KEYS[2] so it goes away together with the lock
+	// 元数据伴随键作为 KEYS[2] 传递，使其与锁一同删除
+	keys := []string{o.key}
+	if withMeta {
+		keys = append(keys, o.metaKey())
+	}
+	evalStart := o.clock.Now()
+	result, err := o.eval(ctx, commandRelease, keys, []string{value})
+	o.observeLatency(OperationRelease, value, evalStart)
 	if err != nil {
 		// Redis operation problem happened in release attempt
 		// 释放尝试过程中的 Redis 操作错误
 		LOG.ErrorLog("请求报错", zap.Error(err))
-		return false, erero.Wro(err)
+		return 0, false, erero.Wro(err)
 	} else if result == nil {
 		// Unexpected blank response came back from Redis
 		// Redis 返回意外的空响应
 		LOG.ErrorLog("其它错误")
-		return false, nil
+		return 0, false, nil
 	}
 
 	// Parse numeric response code given back from Lua script
 	// 解析 Lua 脚本返回的数字响应代码
-	statusCode, ok := result.(int64)
-	if !ok {
+	if _, ok := result.(int64); !ok {
 		// Response kind validation check did not pass in release operation
 		// 释放操作的响应类型验证失败
 		LOG.DebugLog("回复非预期类型", zap.Any("result", result), zap.String("result_type", reflect.TypeOf(result).String()))
-		return false, nil
+		return 0, false, nil
+	}
+	status, err := ParseReleaseStatus(result)
+	if err != nil {
+		// Unexpected response code came back from Lua script
+		// Lua 脚本返回意外的响应码
+		LOG.DebugLog("其它错误", zap.Any("statusCode", result))
+		return 0, false, nil
 	}
 	// Handle different release status codes given back from Lua script
 	// 处理 Lua 脚本返回的不同释放状态码
-	switch statusCode {
-	case 0: // Lock found in GET but failed DELETE (rare edge case)
+	switch status {
+	case ReleaseVanished: // Lock found in GET but failed DELETE (rare edge case)
 		// 在 GET 时找到锁但 DELETE 失败（罕见边缘情况）
 		LOG.DebugLog("锁已自动释放")
-		return true, nil
-	case 1: // Standard deletion of lock that completed
+	case ReleaseDeleted: // Standard deletion of lock that completed
 		// 正常成功删除锁
 		LOG.DebugLog("锁已成功释放")
-		return true, nil
-	case 2: // Key went past its expiration, lock was kept too long ahead of release
+	case ReleaseMissing: // Key went past its expiration, lock was kept too long ahead of release
 		// 键自动过期，释放前锁持有时间过长
 		LOG.DebugLog("锁不存在-或者锁已自动释放")
-		return true, nil
-	case 3: // Release did not complete, lock is owned through different session
+	case ReleaseNotOwner: // Release did not complete, lock is owned through different session
 		// 释放失败，锁被不同会话拥有
 		LOG.DebugLog("释放出错-锁被其它线程占用")
-		return false, nil
-	default: // Unexpected response code came back from Lua script
-		// Lua 脚本返回意外的响应码
-		LOG.DebugLog("其它错误", zap.Int64("statusCode", statusCode))
-		return false, nil
 	}
+	return status, status.Released(), nil
 }
 
 // Xin represents an acquired distributed lock session including expiration tracking
@@ -238,9 +445,29 @@ func (o *Suo) release(ctx context.Context, value string) (bool, error) {
 // 提供会话管理来确保安全锁操作和延期
 // 创建后不可变，确保使用过程中锁状态的一致性
 type Xin struct {
-	key         string    // Lock name ID // 锁名标识符
-	sessionUUID string    // Current lock session UUID // 当前锁会话 UUID
-	expire      time.Time // Conservative expiration estimate // 保守的过期时间估算
+	key              string        // Lock name ID // 锁名标识符
+	sessionUUID      string        // Current lock session UUID // 当前锁会话 UUID
+	expire           time.Time     // Conservative expiration estimate // 保守的过期时间估算
+	optimisticExpire time.Time     // Latest expiration estimate, zero when not computed // 最晚的过期时间估算，未计算时为零值
+	serverExpire     time.Time     // Expiration in Redis server time, zero when not enabled // Redis 服务端时间下的过期时间，未启用时为零值
+	clockOffset      time.Duration // Redis clock minus client clock measured at acquisition, zero when not enabled // 获取时测得的 Redis 时钟减客户端时钟，未启用时为零值
+	acquiredAt       time.Time     // First acquisition time, kept across extensions // 首次获取时间，延期时保持不变
+	extensions       int           // Count of extensions past the first acquisition // 首次获取之后的延期次数
+	continues        *Continuation // Earlier session the hold resumes, nil when fresh // 持有所延续的先前会话，全新持有时为 nil
+	tracker          *holdTracker  // Debug mode hold tracking, nil when disabled // 调试模式下的持有跟踪，未启用时为空
+	expiry           *expiryWatch  // Expiring warning of the hold, nil when disabled // 持有的即将过期警告，未启用时为空
+	lease            *leaseWatch   // Cancels hold contexts once exclusivity is gone, nil when none derived // 失去独占后取消持有上下文，未派生时为空
+	temps            bool          // Lock-scoped temp keys written through the session // 会话写入过锁作用域临时键
+	fencingToken     int64         // Fencing token of the hold, 0 when fencing is disabled // 持有的防护令牌，未启用防护时为 0
+	rate             *extendRate   // Extension count of the hold, nil until rate limited extensions // 持有的延期计数，限流延期之前为空
+	failovers        int64         // Client switches seen at the last contact, see verifyFailover // 最后一次访问时已发生的客户端切换次数，参见 verifyFailover
+	closer           *xinCloser    // Releases the hold once on Close, nil outside a lock // 在 Close 时只释放一次持有，不属于锁时为空
+}
+
+// Key gets back the lock name ID of the session
+// Key 返回会话的锁名标识符
+func (s *Xin) Key() string {
+	return s.key
 }
 
 // SessionUUID gets back the unique session ID belonging to this lock instance
@@ -255,16 +482,53 @@ func (s *Xin) SessionUUID() string {
 }
 
 // Expire gets back the conservative expiration time estimate belonging to this lock
-// Estimated through subtracting acquisition time away from the TTL duration
+// Estimated through subtracting acquisition time and the drift margin of WithDriftFactor away from the TTL duration
 // Provides safe timing reference making lock extension decisions
 //
 // Expire 返回此锁的保守过期时间估算
-// 通过从 TTL 时长中减去获取时间来计算
+// 通过从 TTL 时长中减去获取时间以及 WithDriftFactor 的漂移余量来计算
 // 在做出锁延期决策时提供安全的时间参考
 func (s *Xin) Expire() time.Time {
 	return s.expire
 }
 
+// AcquiredAt gets back the time when the session first acquired the lock
+// Extensions keep this value, so it marks the start of the whole hold
+//
+// AcquiredAt 返回会话首次获取锁的时间
+// 延期时保持该值不变，因此它标志着整个持有过程的开始
+func (s *Xin) AcquiredAt() time.Time {
+	return s.acquiredAt
+}
+
+// Extensions gets back the count of extensions applied since the first acquisition
+// Extensions 返回首次获取以来已执行的延期次数
+func (s *Xin) Extensions() int {
+	return s.extensions
+}
+
+// ServerExpire gets back the expiration time measured on the Redis server clock
+// Computed as the TIME seen inside the acquire script plus the TTL
+// Returns zero time when the Suo was not configured using WithServerTime
+//
+// ServerExpire 返回以 Redis 服务端时钟衡量的过期时间
+// 通过获取脚本内的 TIME 加上 TTL 计算得出
+// 当 Suo 未通过 WithServerTime 配置时返回零值
+func (s *Xin) ServerExpire() time.Time {
+	return s.serverExpire
+}
+
+// ClockOffset gets back the Redis clock minus the client clock, measured over the acquisition round trip
+// Expire equals ServerExpire minus the offset, less half the round trip as the measuring uncertainty
+// Returns zero when the Suo was not configured using WithServerTime
+//
+// ClockOffset 返回 Redis 时钟减去客户端时钟的差值，在获取往返过程中测得
+// Expire 等于 ServerExpire 减去该偏移，再扣除作为测量误差的半个往返时间
+// 当 Suo 未通过 WithServerTime 配置时返回零值
+func (s *Xin) ClockOffset() time.Duration {
+	return s.clockOffset
+}
+
 // AcquireLockWithSession attempts acquiring lock using specified session UUID
 // Computes conservative expiration time accounting acquisition duration
 // Gives back lock session object when it succeeds, nil when lock is unavailable, problem on doing it wrong
@@ -275,26 +539,134 @@ func (s *Xin) Expire() time.Time {
 // 成功时返回锁会话对象，锁不可用时返回 nil，失败时返回错误
 // 在管理高性能分布式系统时提供精确的时间协调
 func (o *Suo) AcquireLockWithSession(ctx context.Context, sessionUUID string) (*Xin, error) {
+	return o.typedAcquire(o.acquireSession(ctx, sessionUUID))
+}
+
+// acquireSession is AcquireLockWithSession without typed errors, nil when the lock is unavailable
+// acquireSession 是不带类型化错误的 AcquireLockWithSession，锁不可用时返回 nil
+func (o *Suo) acquireSession(ctx context.Context, sessionUUID string) (*Xin, error) {
+	return o.acquireLockWith(ctx, sessionUUID, &acquireRequest{ttl: o.freshTTL()})
+}
+
+// acquireRequest carries the per-call settings of one acquisition attempt
+// acquireRequest 携带单次获取尝试的调用设置
+type acquireRequest struct {
+	ttl       time.Duration // Lease duration // 租期
+	extend    bool          // Explicit extension of a held session // 对已持有会话的显式延期
+	continues *Continuation // Earlier session the hold resumes, nil when fresh // 持有所延续的先前会话，全新持有时为 nil
+	estimate  time.Duration // Remaining work estimate recorded in the metadata, 0 when none // 记录在元数据中的剩余工作预估，为 0 时不记录
+	reclaim   bool          // Retry of a session whose earlier reply got lost, strict mode lets it through // 先前回复丢失的会话的重试，严格模式放行
+}
+
+// acquireLockWith attempts acquiring lock using specified session UUID and per-call settings
+// Shared by acquisition and extension paths that pick their own TTL
+// Runs inside a span noting the outcome: "acquired", "busy" or "error"
+//
+// acquireLockWith 使用指定会话 UUID 和调用设置尝试获取锁
+// 由自行选择 TTL 的获取和延期路径共用
+// 在记录结果（"acquired"、"busy" 或 "error"）的 span 中执行
+func (o *Suo) acquireLockWith(ctx context.Context, sessionUUID string, request *acquireRequest) (*Xin, error) {
+	name := SpanAcquire
+	if request.extend {
+		name = SpanExtend
+	}
+	ctx, span := o.StartSpan(ctx, name, sessionUUID)
+	span.SetAttribute("lock.ttl_ms", request.ttl.Milliseconds())
+	xin, err := o.tryAcquireLock(ctx, sessionUUID, request)
+	switch {
+	case err != nil:
+		span.SetAttribute("lock.outcome", "error")
+	case xin == nil:
+		span.SetAttribute("lock.outcome", "busy")
+	default:
+		span.SetAttribute("lock.outcome", "acquired")
+		if !request.extend {
+			o.hookAcquired(ctx, xin)
+		}
+	}
+	span.End(err)
+	return xin, err
+}
+
+// tryAcquireLock performs the acquisition of acquireLockWith
+// tryAcquireLock 执行 acquireLockWith 的获取
+func (o *Suo) tryAcquireLock(ctx context.Context, sessionUUID string, request *acquireRequest) (*Xin, error) {
+	var ttl = request.ttl
 	// Note down lock acquisition start time when computing duration
 	// 记录锁获取开始时间用于计算耗时
-	var startTime = time.Now()
+	var startTime = o.clock.Now()
+	// Fresh acquisitions pass the process-side policy first, extensions skip it
+	// 新的获取先通过进程侧的策略检查，延期时跳过
+	// A slot of the hold budget gets reserved last, so refused attempts never wait on one
+	// 持锁预算的名额最后预留，使被拒绝的尝试不会等待名额
+	if !request.extend {
+		if err := o.admitFresh(ctx); err != nil {
+			return nil, erero.Wro(err)
+		}
+		if err := o.takeSlot(ctx); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
 	// Attempt acquiring lock using provided session ID
 	// 使用提供的会话标识符尝试获取锁
-	if ok, err := o.acquire(ctx, sessionUUID); err != nil {
-		return nil, erero.Wro(err)
-	} else if !ok {
+	if ok, serverTime, token, err := o.acquire(ctx, sessionUUID, request); err != nil || !ok {
+		if !request.extend {
+			o.dropSlot()
+		}
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
 		return nil, nil
 	} else {
 		// Compute conservative expiration time accounting acquisition time cost
 		// 在获取开销过程中计算保守过期时间
-		nowTime := time.Now()                  // Time at present in conservative computation // 保守计算中的当前时间
-		timeSpent := time.Since(startTime)     // Time taken in acquisition // 获取过程消耗的时间
-		leftoverTTL := o.ttl - timeSpent       // Leftover TTL past acquisition time cost // 减去获取开销后的剩余 TTL
-		expireTime := nowTime.Add(leftoverTTL) // Conservative expiration estimate // 保守的过期时间估算
-		return &Xin{key: o.key, sessionUUID: sessionUUID, expire: expireTime}, nil
+		// The drift margin comes off too when a drift factor is set
+		// 设置了漂移比例时还会扣除漂移余量
+		nowTime := o.clock.Now()
+		expireTime, optimisticExpire := o.expiryOf(startTime, nowTime, ttl)
+		// Server side expiry is anchored on Redis clock, free of client skew
+		// Expire then follows it through the measured offset instead of the client side estimate
+		// 服务端过期时间锚定在 Redis 时钟上，不受客户端时钟偏差影响
+		// 此时 Expire 通过测得的偏移跟随它，而不是使用客户端的估算
+		var serverExpire time.Time
+		var clockOffset time.Duration
+		if !serverTime.IsZero() {
+			serverExpire = serverTime.Add(ttl)
+			clockOffset, expireTime, optimisticExpire = anchoredExpiry(startTime, nowTime, serverTime, ttl)
+		}
+		// Record the lock in the registry when the manager enables listing
+		// 当管理器启用列举时在注册表中登记锁
+		o.register(ctx, sessionUUID)
+		xin := &Xin{key: o.key, sessionUUID: sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, serverExpire: serverExpire, clockOffset: clockOffset, acquiredAt: startTime, continues: request.continues, fencingToken: token, failovers: o.Failovers()}
+		if !request.extend {
+			o.emit(EventAcquired, sessionUUID, 0)
+			o.trackHold(xin)
+			o.trackLive(xin)
+			o.keepSlot(xin)
+			o.watchExpiry(xin)
+			attachCloser(xin, o.language, o.releaseSession)
+		}
+		return xin, nil
 	}
 }
 
+// admitFresh waits out a paused manager, passes the admission callback,
+// and fails fast while a maintenance gate freezes the lock name
+//
+// admitFresh 等待管理器恢复，通过准入回调，并在维护开关冻结该锁名期间立即失败
+func (o *Suo) admitFresh(ctx context.Context) error {
+	if err := o.awaitResume(ctx); err != nil {
+		return erero.Wro(err)
+	}
+	if err := o.admit(ctx); err != nil {
+		return erero.Wro(err)
+	}
+	if err := o.checkMaintenance(ctx); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
 // Acquire attempts acquiring the distributed lock using auto-generated session UUID
 // Makes a random session ID enabling lock ownership verification
 // Convenient method achieving basic lock acquisition without session management
@@ -323,12 +695,63 @@ func (o *Suo) Acquire(ctx context.Context) (*Xin, error) {
 // 成功释放时返回 true，被不同会话拥有时返回 false
 // 对确保安全清理和防止意外锁干扰至关重要
 func (o *Suo) Release(ctx context.Context, xin *Xin) (bool, error) {
+	return o.typedRelease(o.releaseHold(ctx, xin))
+}
+
+// releaseHold is Release without typed errors, giving back the release status next to the outcome
+// Runs inside a span noting the release status as the outcome
+//
+// releaseHold 是不带类型化错误的 Release，并在结果之外返回释放状态
+// 在以释放状态作为结果的 span 中执行
+func (o *Suo) releaseHold(ctx context.Context, xin *Xin) (ReleaseStatus, bool, error) {
+	ctx, span := o.StartSpan(ctx, SpanRelease, xin.sessionUUID)
+	status, success, err := o.tryReleaseHold(ctx, xin)
+	if err != nil {
+		span.SetAttribute("lock.outcome", "error")
+	} else {
+		span.SetAttribute("lock.outcome", status.String())
+		o.hookReleased(ctx, xin, success)
+	}
+	span.End(err)
+	return status, success, err
+}
+
+// tryReleaseHold performs the release of releaseHold
+// tryReleaseHold 执行 releaseHold 的释放
+func (o *Suo) tryReleaseHold(ctx context.Context, xin *Xin) (ReleaseStatus, bool, error) {
 	// Validate lock name matches what we expect, ensuring safe operation
 	// 验证锁名一致性来确保安全
+	o.checkOwner(xin)
 	must.Equals(xin.key, o.key)
+	o.trackRelease(xin)
+	o.stopExpiry(xin)
+	o.stopLease(xin)
 	// Release lock using session UUID when verifying ownership
 	// 使用会话 UUID 检查所有权来释放锁
-	return o.release(ctx, xin.sessionUUID)
+	status, success, err := o.releaseStatus(ctx, xin.sessionUUID, o.hasMetadata() || xin.continues != nil)
+	if err != nil {
+		return 0, false, erero.Wro(err)
+	}
+	o.dropTemps(ctx, xin)
+	// The session is no longer ours to release at exit or on Close, whether released or lost
+	// 无论已释放还是已丢失，该会话都不再需要在退出时或 Close 时释放
+	o.forgetLive(xin)
+	o.freeSlot(xin)
+	xin.closer.markDone()
+	if success {
+		// Drop the registry entry once the lock is gone
+		// 锁释放后删除注册表条目
+		o.unregister(ctx, xin.sessionUUID)
+		// Feed the hold duration into the waiter queue estimates
+		// 将持有时长记录到等待队列的估算数据中
+		heldFor := o.clock.Now().Sub(xin.acquiredAt)
+		o.recordHold(ctx, heldFor)
+		o.emitStatus(EventReleased, xin.sessionUUID, heldFor, status.String())
+		o.publishRelease(ctx, xin.sessionUUID)
+	} else {
+		o.captureStolen(ctx, xin, "release", status.String())
+	}
+	return status, success, nil
 }
 
 // AcquireAgainExtendLock extends the lock via re-acquiring using the same session UUID
@@ -341,10 +764,61 @@ func (o *Suo) Release(ctx context.Context, xin *Xin) (bool, error) {
 // 延期成功时返回具有更新过期时间的新锁会话
 // 在管理需要延长锁持有时间的长期运行操作时至关重要
 func (o *Suo) AcquireAgainExtendLock(ctx context.Context, xin *Xin) (*Xin, error) {
+	return o.typedExtend(o.extendHold(ctx, xin))
+}
+
+// extendHold is AcquireAgainExtendLock without typed errors, nil once the lock was lost
+// extendHold 是不带类型化错误的 AcquireAgainExtendLock，锁已丢失时返回 nil
+func (o *Suo) extendHold(ctx context.Context, xin *Xin) (*Xin, error) {
 	// Validate lock name matches what we expect, ensuring safe extension
 	// 验证锁名一致性来确保延期安全
+	o.checkOwner(xin)
 	must.Equals(xin.key, o.key)
+	// Clamp the lease so the whole hold stays within the max hold duration
+	// 限制租期使整个持有过程不超过最大持有时长
+	ttl, err := o.extendTTL(xin)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := o.verifyFailover(ctx, xin); err != nil {
+		return nil, erero.Wro(err)
+	}
 	// Re-acquire lock using same session UUID that extends expiration
 	// 使用相同会话 UUID 重新获取锁以延长过期时间
-	return o.AcquireLockWithSession(ctx, xin.sessionUUID)
+	res, err := o.acquireLockWith(ctx, xin.sessionUUID, &acquireRequest{ttl: ttl, extend: true, continues: xin.continues})
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if res != nil {
+		o.carryExtension(ctx, xin, res)
+		o.syncTemps(ctx, res)
+	} else {
+		o.loseLease(xin)
+		o.captureStolen(ctx, xin, "extend", ExtendNotOwner.String())
+	}
+	return res, nil
+}
+
+// carryExtension carries the hold state of the session over to its extended session
+// carryExtension 将会话的持有状态转移到延期后的会话
+func (o *Suo) carryExtension(ctx context.Context, xin *Xin, res *Xin) {
+	// Keep the first acquisition time so hold durations span extensions
+	// 保留首次获取时间，使持有时长跨越延期
+	res.acquiredAt = xin.acquiredAt
+	res.extensions = xin.extensions + 1
+	res.temps = xin.temps
+	res.rate = xin.rate
+	res.failovers = o.Failovers()
+	// Extensions built outside the acquire script keep the token of the hold
+	// 在获取脚本之外构建的延期会话保留持有的令牌
+	if res.fencingToken == 0 {
+		res.fencingToken = xin.fencingToken
+	}
+	o.trackExtend(xin, res)
+	o.trackLive(res)
+	o.extendExpiry(xin, res)
+	o.extendLease(xin, res)
+	xin.closer.follow(res)
+	o.emit(EventExtended, xin.sessionUUID, o.clock.Now().Sub(xin.acquiredAt))
+	o.hookExtended(ctx, res)
 }
@@ -0,0 +1,156 @@
+package redissuo
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: locks in sorted sequence / ARGV: session, TTL milliseconds
+	// Takes every lock or none, gives back 0 once all are set, otherwise the position of the first lock held elsewhere
+	// KEYS: 按排序排列的锁 / ARGV: 会话、TTL 毫秒数
+	// 要么获取全部锁要么一个都不获取，全部设置后返回 0，否则返回第一个被其它会话持有的锁的位置
+	commandAcquireMulti = `for i = 1, #KEYS do
+    local v = redis.call("GET", KEYS[i])
+    if v and v ~= ARGV[1] then
+        return i
+    end
+end
+for i = 1, #KEYS do
+    redis.call("SET", KEYS[i], ARGV[1], "PX", ARGV[2])
+end
+return 0`
+)
+
+// AcquireMulti acquires the locks of several resources in one script, all or nothing, sharing one session UUID
+// Each lock shares the TTL, clock, hooks and error mode of this lock, just the name differs, this lock itself is not part of the set
+// The one script writes plain lock values, so a lock with fencing, metadata or guards set is refused with an error
+// In dry-run mode the set is granted without touching Redis
+// Keys get sorted and deduplicated, so callers taking overlapping sets see the same sequence
+// Gives back nil when any key is held elsewhere (ErrLockHeld in typed error mode), a script failing halfway gets its partial writes released
+// In cluster mode the keys must share one hash tag, since one script runs on a single slot
+// Close the returned scope to release everything
+//
+// AcquireMulti 通过一次脚本获取多个资源的锁，要么全部获取要么一个都不获取，共用同一个会话 UUID
+// 每个锁共享该锁的 TTL、时钟、钩子和错误模式，仅名称不同，该锁自身不在集合之内
+// 该脚本只写入普通的锁值，因此设置了防护令牌、元数据或守卫的锁会被拒绝并返回错误
+// 试运行模式下不访问 Redis 直接授予整个集合
+// 键会被排序并去重，因此获取重叠集合的调用方看到相同的顺序
+// 任一键被其它会话持有时返回 nil（类型化错误模式下为 ErrLockHeld），脚本中途失败时会释放其已写入的部分
+// 集群模式下这些键必须共享同一个哈希标签，因为一个脚本只在单个槽上运行
+// 关闭返回的作用域即可释放全部锁
+func (o *Suo) AcquireMulti(ctx context.Context, keys []string) (*LockScope, error) {
+	sorted := sortedKeys(must.Have(keys))
+	if err := o.checkMultiSettings(); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := o.checkMultiSlot(sorted); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := o.admitFresh(ctx); err != nil {
+		return nil, erero.Wro(err)
+	}
+	sessionUUID := utils.NewUUID()
+	ttl := o.freshTTL()
+	startTime := o.clock.Now()
+	if o.dryRun {
+		o.acquireLOG.DebugLog("试运行-模拟批量申请锁成功", zap.Strings("keys", sorted), zap.Bool("dry_run", true))
+	} else {
+		result, err := o.eval(ctx, commandAcquireMulti, sorted, sessionUUID, strconv.FormatInt(ttl.Milliseconds(), 10))
+		if err != nil {
+			o.acquireLOG.ErrorLog("批量申请锁报错", zap.Strings("keys", sorted), zap.Error(err))
+			o.releaseMulti(ctx, sorted, sessionUUID)
+			return nil, erero.Wro(err)
+		}
+		position, ok := result.(int64)
+		if !ok || position < 0 || position > int64(len(sorted)) {
+			return nil, erero.Errorf("unexpected acquire multi reply: %v", result)
+		}
+		if position > 0 {
+			o.acquireLOG.DebugLog("批量申请锁-部分键被占用", zap.String("held", sorted[position-1]))
+			if o.typedErrors {
+				return nil, o.newError(CodeLockHeld)
+			}
+			return nil, nil
+		}
+	}
+	expireTime, optimisticExpire := o.expiryOf(startTime, o.clock.Now(), ttl)
+	scope := NewLockScope()
+	for _, key := range sorted {
+		sibling := o.sibling(key)
+		xin := &Xin{key: key, sessionUUID: sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, acquiredAt: startTime}
+		sibling.emit(EventAcquired, sessionUUID, 0)
+		sibling.trackLive(xin)
+		scope.Add(sibling, attachCloser(xin, o.language, sibling.releaseSession))
+	}
+	return scope, nil
+}
+
+// sortedKeys gets back the keys sorted and deduplicated
+// sortedKeys 返回排序并去重后的键
+func sortedKeys(keys []string) []string {
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+	results := sorted[:0]
+	for idx, key := range sorted {
+		if idx == 0 || key != sorted[idx-1] {
+			results = append(results, key)
+		}
+	}
+	return results
+}
+
+// checkMultiSettings refuses settings the multi acquisition script cannot honour
+// checkMultiSettings 拒绝批量获取脚本无法满足的设置
+func (o *Suo) checkMultiSettings() error {
+	switch {
+	case o.fencing:
+		return erero.New("acquire multi does not issue fencing tokens")
+	case o.hasMetadata():
+		return erero.New("acquire multi does not write metadata")
+	case len(o.guards) > 0:
+		return erero.New("acquire multi does not check guards")
+	}
+	return nil
+}
+
+// checkMultiSlot refuses keys spread over several hash tags when the client is a cluster client
+// checkMultiSlot 在客户端为集群客户端时拒绝分布在多个哈希标签上的键
+func (o *Suo) checkMultiSlot(keys []string) error {
+	if _, ok := o.client().(*redis.ClusterClient); !ok {
+		return nil
+	}
+	slot := hashTagOf(keys[0])
+	for _, key := range keys[1:] {
+		if hashTagOf(key) != slot {
+			return erero.Errorf("keys %q and %q do not share a hash tag", keys[0], key)
+		}
+	}
+	return nil
+}
+
+// sibling derives the lock of another name sharing every setting of this lock
+// sibling 派生共享该锁全部设置的另一名称的锁
+func (o *Suo) sibling(key string) *Suo {
+	sibling := *o
+	sibling.key = must.Nice(key)
+	sibling.setLogger(o.logger)
+	return &sibling
+}
+
+// releaseMulti releases whatever a failed multi acquisition wrote, best-effort
+// releaseMulti 尽力释放失败的批量获取已写入的锁
+func (o *Suo) releaseMulti(ctx context.Context, keys []string, sessionUUID string) {
+	for _, key := range keys {
+		if _, err := o.sibling(key).release(ctx, sessionUUID, false); err != nil {
+			o.releaseLOG.ErrorLog("批量申请锁-回滚报错", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_AcquireMulti validates the keys get taken together in one script and none get taken when one is busy
+// TestSuo_AcquireMulti 验证多个键通过一次脚本一起获取，某个键被占用时一个都不获取
+func TestSuo_AcquireMulti(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	key1, key2, key3 := utils.NewUUID(), utils.NewUUID(), utils.NewUUID()
+
+	scope, err := suo.AcquireMulti(ctx, []string{key2, key1, key2})
+	require.NoError(t, err)
+	require.NotNil(t, scope)
+	require.Equal(t, 2, scope.Size())
+
+	holder1, err := caseRedisClient.Get(ctx, key1).Result()
+	require.NoError(t, err)
+	holder2, err := caseRedisClient.Get(ctx, key2).Result()
+	require.NoError(t, err)
+	require.Equal(t, holder1, holder2)
+	require.Positive(t, caseRedisClient.PTTL(ctx, key1).Val())
+
+	// key2 is busy, so key3 must not get written at all
+	non, err := suo.AcquireMulti(ctx, []string{key3, key2})
+	require.NoError(t, err)
+	require.Nil(t, non)
+	require.Zero(t, caseRedisClient.Exists(ctx, key3).Val())
+
+	require.NoError(t, scope.Close(ctx))
+	require.Zero(t, caseRedisClient.Exists(ctx, key1, key2).Val())
+
+	// Typed error mode reports the busy key through ErrLockHeld
+	require.NoError(t, caseRedisClient.Set(ctx, key2, "other", time.Second).Err())
+	_, err = suo.WithTypedErrors(true).AcquireMulti(ctx, []string{key1, key2})
+	require.ErrorIs(t, err, redissuo.ErrLockHeld)
+	require.Zero(t, caseRedisClient.Exists(ctx, key1).Val())
+}
+
+// TestSuo_AcquireMulti_DryRun validates a dry-run multi acquisition writes nothing to Redis
+// TestSuo_AcquireMulti_DryRun 验证试运行的批量获取不向 Redis 写入任何内容
+func TestSuo_AcquireMulti_DryRun(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewManager(caseRedisClient).WithDryRun(true).NewSuo(utils.NewUUID(), 5*time.Second)
+	key1, key2 := utils.NewUUID(), utils.NewUUID()
+
+	scope, err := suo.AcquireMulti(ctx, []string{key1, key2})
+	require.NoError(t, err)
+	require.NotNil(t, scope)
+	require.Equal(t, 2, scope.Size())
+	require.Zero(t, caseRedisClient.Exists(ctx, key1, key2).Val())
+	require.NoError(t, scope.Close(ctx))
+}
+
+// TestSuo_AcquireMulti_Unsupported validates settings the multi script cannot honour are refused ahead of any write
+// TestSuo_AcquireMulti_Unsupported 验证批量脚本无法满足的设置在任何写入之前即被拒绝
+func TestSuo_AcquireMulti_Unsupported(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	for _, suo := range []*redissuo.Suo{
+		redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithFencing(true),
+		redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"team": "payment"}),
+		redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithGuards(redissuo.GuardAbsent(utils.NewUUID())),
+	} {
+		scope, err := suo.AcquireMulti(ctx, []string{key})
+		require.Error(t, err)
+		require.Nil(t, scope)
+		require.Zero(t, caseRedisClient.Exists(ctx, key).Val())
+	}
+}
@@ -0,0 +1,52 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// ErrAdmissionDenied is returned when the admission callback vetoes an acquisition
+// The veto of the callback is kept as the cause, so errors.Is matches both of them
+//
+// ErrAdmissionDenied 在准入回调否决获取时返回
+// 回调的否决错误作为原因保留，因此 errors.Is 对两者都能匹配
+var ErrAdmissionDenied = NewError(CodeAdmissionDenied, LanguageEnglish, nil)
+
+// Admission decides whether a fresh acquisition of the lock name may go on, e.g. through a feature flag,
+// a budget or the state of a tenant, a non-nil problem vetoes the attempt before any Redis traffic
+//
+// Admission 决定该锁名的新获取能否继续，例如依据功能开关、预算或租户状态
+// 返回非空错误即在产生任何 Redis 请求之前否决该次尝试
+type Admission func(ctx context.Context, key string) error
+
+// WithAdmission sets the callback consulted ahead of each fresh acquisition, extensions skip it
+// WithAdmission 设置每次新获取之前调用的回调，延期时不调用
+func (o *Suo) WithAdmission(admission Admission) *Suo {
+	o.admission = admission
+	return o
+}
+
+// WithAdmission sets the callback consulted in locks created through the manager
+// Keeps the policy in one place instead of checks spread across call sites
+//
+// WithAdmission 设置通过管理器创建的锁所使用的准入回调
+// 使策略集中在一处，而不是分散在各个调用点
+func (m *Manager) WithAdmission(admission Admission) *Manager {
+	m.admission = admission
+	return m
+}
+
+// admit runs the admission callback, nil when no callback is set
+// admit 执行准入回调，未设置回调时返回 nil
+func (o *Suo) admit(ctx context.Context) error {
+	if o.admission == nil {
+		return nil
+	}
+	if veto := o.admission(ctx, o.key); veto != nil {
+		o.acquireLOG.DebugLog("准入回调否决申请", zap.Error(veto))
+		return erero.Wro(NewError(CodeAdmissionDenied, o.language, veto))
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package redissuo_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_WithAdmission validates the admission callback vetoes fresh acquisitions with the custom problem
+// TestManager_WithAdmission 验证准入回调以自定义错误否决新的获取
+func TestManager_WithAdmission(t *testing.T) {
+	ctx := context.Background()
+	errTenantFrozen := errors.New("tenant frozen")
+	manager := redissuo.NewManager(caseRedisClient).WithAdmission(func(ctx context.Context, key string) error {
+		if strings.HasPrefix(key, "frozen:") {
+			return errTenantFrozen
+		}
+		return nil
+	})
+
+	_, err := manager.NewSuo("frozen:"+utils.NewUUID(), 5*time.Second).Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrAdmissionDenied)
+	require.ErrorIs(t, err, errTenantFrozen)
+
+	xin, err := manager.NewSuo(utils.NewUUID(), 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+}
@@ -0,0 +1,48 @@
+package redissuo
+
+import (
+	"context"
+	"time"
+
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+const (
+	// awaitPollInterval caps the wait between two checks of AwaitRelease
+	// awaitPollInterval 限制 AwaitRelease 两次检查之间的等待时长
+	awaitPollInterval = 100 * time.Millisecond
+)
+
+// AwaitRelease blocks until the lock is free or the context ends, without taking the lock
+// Suits readers that only need a writer or a migration to finish before going on lock-free
+// Checks the remaining TTL so a lock about to expire gets noticed without extra polls
+//
+// AwaitRelease 阻塞直到锁空闲或上下文结束，不会获取锁
+// 适用于只需等待写入者或迁移完成后即可无锁继续的读取方
+// 通过检查剩余 TTL，使即将过期的锁无需额外轮询即可被发现
+func (o *Suo) AwaitRelease(ctx context.Context) error {
+	for {
+		pttl, err := o.client().PTTL(ctx, o.key).Result()
+		if err != nil {
+			o.logger.ErrorLog("等待释放报错", zap.String("k", o.key), zap.Error(err))
+			return erero.Wro(err)
+		}
+		// go-redis gives back the raw -2 on a missing key, the lock is free
+		// 键不存在时 go-redis 返回原始值 -2，锁已空闲
+		if pttl == -2 {
+			return nil
+		}
+		wait := awaitPollInterval
+		if pttl > 0 {
+			wait = min(wait, pttl)
+		}
+		timer := o.clock.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return erero.Wro(ctx.Err())
+		case <-timer.C():
+		}
+	}
+}
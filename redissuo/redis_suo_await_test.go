@@ -0,0 +1,38 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_AwaitRelease validates waiting returns once the holder releases, without taking the lock
+// TestSuo_AwaitRelease 验证持有者释放后等待立即返回，且不会获取锁
+func TestSuo_AwaitRelease(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	require.NoError(t, suo.AwaitRelease(ctx)) // Free lock returns at once
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, suo.AwaitRelease(timeoutCtx), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = suo.Release(ctx, xin)
+	}()
+	require.NoError(t, suo.AwaitRelease(ctx))
+
+	infos, err := redissuo.NewManager(caseRedisClient).InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.False(t, infos[0].Held())
+}
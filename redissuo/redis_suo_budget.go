@@ -0,0 +1,113 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// ErrBudgetExhausted is returned when a fail-fast hold budget has no slot left
+// ErrBudgetExhausted 在立即失败的持锁预算没有剩余名额时返回
+var ErrBudgetExhausted = NewError(CodeBudgetExhausted, LanguageEnglish, nil)
+
+// holdBudget is a semaphore bounding the sessions held through locks of one manager
+// A slot is taken ahead of the acquisition and given back on release, or at once when the acquisition misses
+//
+// holdBudget 是限制一个管理器的锁所持有会话数量的信号量
+// 在获取之前占用名额，释放时归还，获取未成功时立即归还
+type holdBudget struct {
+	slots    chan struct{}   // One item per taken slot // 每个已占用名额对应一个元素
+	failFast bool            // Fail with ErrBudgetExhausted instead of waiting // 返回 ErrBudgetExhausted 而不是等待
+	mutex    sync.Mutex      // Protects sessions // 保护 sessions
+	sessions map[string]bool // Held sessions owning a slot // 占有名额的已持有会话
+}
+
+// WithHoldBudget bounds how many locks the process may hold at once through locks of the manager
+// Excess fresh acquisitions wait on a slot, or fail with ErrBudgetExhausted when failFast is set
+// Guards against code paths fanning out across thousands of per-entity locks at once
+// Sessions lost through expiry keep their slot until released, like Held lists them
+//
+// WithHoldBudget 限制进程通过管理器的锁可同时持有的锁数量
+// 超出的新获取等待空闲名额，设置 failFast 时返回 ErrBudgetExhausted
+// 防止代码路径一次性扇出获取成千上万个按实体划分的锁
+// 因过期而丢失的会话在释放前仍占用名额，与 Held 的列举方式一致
+func (m *Manager) WithHoldBudget(limit int, failFast bool) *Manager {
+	must.True(limit > 0)
+	m.budget = &holdBudget{slots: make(chan struct{}, limit), failFast: failFast, sessions: map[string]bool{}}
+	return m
+}
+
+// BudgetInUse gets back the slots of the hold budget taken at present, 0 when no budget is set
+// BudgetInUse 返回持锁预算当前已占用的名额数，未设置预算时返回 0
+func (m *Manager) BudgetInUse() int {
+	if m.budget == nil {
+		return 0
+	}
+	return len(m.budget.slots)
+}
+
+// takeSlot reserves a slot ahead of a fresh acquisition, nil at once without a budget or in dry run
+// takeSlot 在新获取之前预留名额，没有预算或处于试运行时立即返回 nil
+func (o *Suo) takeSlot(ctx context.Context) error {
+	if o.budget == nil || o.dryRun {
+		return nil
+	}
+	if o.budget.failFast {
+		select {
+		case o.budget.slots <- struct{}{}:
+			return nil
+		default:
+			o.acquireLOG.DebugLog("持锁预算已用尽-拒绝申请", zap.Int("limit", cap(o.budget.slots)))
+			return o.newError(CodeBudgetExhausted)
+		}
+	}
+	select {
+	case o.budget.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return erero.Wro(ctx.Err())
+	}
+}
+
+// keepSlot hands the reserved slot to the acquired session, a session already owning one gives the spare back
+// keepSlot 将预留的名额交给已获取的会话，已占有名额的会话归还多余的名额
+func (o *Suo) keepSlot(xin *Xin) {
+	if o.budget == nil || o.dryRun {
+		return
+	}
+	o.budget.mutex.Lock()
+	defer o.budget.mutex.Unlock()
+	name := xin.key + "\x00" + xin.sessionUUID
+	if o.budget.sessions[name] {
+		<-o.budget.slots
+		return
+	}
+	o.budget.sessions[name] = true
+}
+
+// dropSlot gives a reserved slot back after a missed acquisition
+// dropSlot 在获取未成功后归还预留的名额
+func (o *Suo) dropSlot() {
+	if o.budget == nil || o.dryRun {
+		return
+	}
+	<-o.budget.slots
+}
+
+// freeSlot gives the slot of the session back once it is released, nothing when it owns none
+// freeSlot 在会话释放后归还其名额，未占有名额时不做任何事
+func (o *Suo) freeSlot(xin *Xin) {
+	if o.budget == nil {
+		return
+	}
+	o.budget.mutex.Lock()
+	defer o.budget.mutex.Unlock()
+	name := xin.key + "\x00" + xin.sessionUUID
+	if o.budget.sessions[name] {
+		delete(o.budget.sessions, name)
+		<-o.budget.slots
+	}
+}
@@ -0,0 +1,96 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_WithHoldBudget validates excess acquisitions wait on a slot and misses give theirs back
+// TestManager_WithHoldBudget 验证超出的获取等待空闲名额，未成功的获取归还名额
+func TestManager_WithHoldBudget(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithHoldBudget(2, false)
+	suoA := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	suoB := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	suoC := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	xinA, err := suoA.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xinA)
+	xinB, err := suoB.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xinB)
+	require.Equal(t, 2, manager.BudgetInUse())
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = suoC.Acquire(timeoutCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Extensions keep the slot of the session instead of taking another one
+	// 延期沿用会话的名额而不是占用新的名额
+	xinA, err = suoA.AcquireAgainExtendLock(ctx, xinA)
+	require.NoError(t, err)
+	require.NotNil(t, xinA)
+	require.Equal(t, 2, manager.BudgetInUse())
+
+	done := make(chan *redissuo.Xin)
+	go func() {
+		xin, err := suoC.Acquire(ctx)
+		require.NoError(t, err)
+		done <- xin
+	}()
+	time.Sleep(20 * time.Millisecond)
+	success, err := suoA.Release(ctx, xinA)
+	require.NoError(t, err)
+	require.True(t, success)
+	xinC := <-done
+	require.NotNil(t, xinC)
+
+	// A missed acquisition gives its slot back at once
+	// 未成功的获取立即归还名额
+	success, err = suoB.Release(ctx, xinB)
+	require.NoError(t, err)
+	require.True(t, success)
+	xin, err := manager.NewSuo(suoC.Key(), 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, xin)
+	require.Equal(t, 1, manager.BudgetInUse())
+
+	success, err = suoC.Release(ctx, xinC)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Zero(t, manager.BudgetInUse())
+}
+
+// TestManager_WithHoldBudget_FailFast validates excess acquisitions fail with ErrBudgetExhausted when fail-fast is set
+// TestManager_WithHoldBudget_FailFast 验证设置立即失败后超出的获取返回 ErrBudgetExhausted
+func TestManager_WithHoldBudget_FailFast(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithHoldBudget(1, true)
+	suoA := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	suoB := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	xinA, err := suoA.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xinA)
+
+	_, err = suoB.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrBudgetExhausted)
+
+	success, err := suoA.Release(ctx, xinA)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xinB, err := suoB.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xinB)
+	success, err = suoB.Release(ctx, xinB)
+	require.NoError(t, err)
+	require.True(t, success)
+}
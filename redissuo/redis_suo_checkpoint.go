@@ -0,0 +1,103 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: lock, checkpoint, optional metadata companion / ARGV: session, ttl milliseconds, cursor
+	// Extends the lease and stores the cursor in one step, just when the session still holds the lock
+	// The checkpoint carries no TTL so a resumed holder finds it past a crash or expiry
+	// KEYS: 锁、检查点、可选的元数据伴随键 / ARGV: 会话、TTL 毫秒数、游标
+	// 仅当会话仍持有锁时，一步完成租期延长和游标保存
+	// 检查点不设 TTL，使崩溃或过期后恢复的持有者仍能找到它
+	commandExtendCheckpoint = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+if KEYS[3] then
+    redis.call("PEXPIRE", KEYS[3], ARGV[2])
+end
+redis.call("SET", KEYS[2], ARGV[3])
+return 1`
+)
+
+// checkpointKey gets back the companion key holding the progress cursor
+// checkpointKey 返回保存进度游标的伴随键
+func (o *Suo) checkpointKey() string {
+	return companionKey(o.key, "checkpoint")
+}
+
+// ExtendWithCheckpoint extends the lease and stores the progress cursor atomically
+// Gives back nil when the session no longer holds the lock, the cursor is not written then
+// A holder resuming past a crash or expiry reads the cursor through Checkpoint and goes on from there
+//
+// ExtendWithCheckpoint 原子地延长租期并保存进度游标
+// 当会话已不再持有锁时返回 nil，此时不会写入游标
+// 崩溃或过期后恢复的持有者通过 Checkpoint 读取游标并从该处继续
+func (o *Suo) ExtendWithCheckpoint(ctx context.Context, xin *Xin, cursor string) (*Xin, error) {
+	o.checkOwner(xin)
+	must.Equals(xin.key, o.key)
+	ttl, err := o.extendTTL(xin)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	keys := []string{o.key, o.checkpointKey()}
+	if o.hasMetadata() || xin.continues != nil {
+		keys = append(keys, o.metaKey())
+	}
+	startTime := o.clock.Now()
+	var result int64 = 1
+	if !o.simulated("extend_checkpoint") {
+		result, err = o.client().Eval(ctx, commandExtendCheckpoint, keys, xin.sessionUUID, strconv.FormatInt(ttl.Milliseconds(), 10), cursor).Int64()
+	}
+	if err != nil {
+		o.logger.ErrorLog("保存检查点报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	if result == 0 {
+		o.logger.DebugLog("锁已丢失-不保存检查点", zap.String("k", o.key), zap.String("v", xin.sessionUUID))
+		o.loseLease(xin)
+		return nil, nil
+	}
+	expireTime, optimisticExpire := o.expiryOf(startTime, o.clock.Now(), ttl)
+	res := &Xin{key: o.key, sessionUUID: xin.sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, continues: xin.continues}
+	o.carryExtension(ctx, xin, res)
+	o.syncTemps(ctx, res)
+	return res, nil
+}
+
+// Checkpoint gets back the last stored progress cursor, blank when none was stored
+// Checkpoint 返回最近保存的进度游标，从未保存时为空
+func (o *Suo) Checkpoint(ctx context.Context) (string, error) {
+	if o.simulated("checkpoint") {
+		return "", nil
+	}
+	cursor, err := o.client().Get(ctx, o.checkpointKey()).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	} else if err != nil {
+		return "", erero.Wro(err)
+	}
+	return cursor, nil
+}
+
+// ClearCheckpoint removes the progress cursor once the whole batch is done
+// ClearCheckpoint 在整个批处理完成后删除进度游标
+func (o *Suo) ClearCheckpoint(ctx context.Context) error {
+	if o.simulated("clear_checkpoint") {
+		return nil
+	}
+	if err := o.client().Del(ctx, o.checkpointKey()).Err(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ExtendWithCheckpoint validates the cursor gets stored with the extension and survives a lost lock
+// TestSuo_ExtendWithCheckpoint 验证游标随延期保存，并在锁丢失后依然保留
+func TestSuo_ExtendWithCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	cursor, err := suo.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cursor)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.ExtendWithCheckpoint(ctx, xin, "page-7")
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 1, xin.Extensions())
+
+	// The lock gets lost, so the next checkpoint is refused
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+	non, err := suo.ExtendWithCheckpoint(ctx, xin, "page-8")
+	require.NoError(t, err)
+	require.Nil(t, non)
+
+	// A resumed holder picks up from the last stored cursor
+	cursor, err = suo.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "page-7", cursor)
+
+	require.NoError(t, suo.ClearCheckpoint(ctx))
+	cursor, err = suo.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cursor)
+}
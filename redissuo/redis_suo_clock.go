@@ -0,0 +1,103 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/yyle88/erero"
+)
+
+// Clock supplies the time, sleeps and timers used in lock bookkeeping and by the runner
+// Swapping it with a virtual clock lets scenarios run on simulated time, expiry watches and backoff waits included
+//
+// Clock 提供锁记账和运行器使用的时间、休眠与定时器
+// 替换为虚拟时钟后可以在模拟时间上运行场景，包括过期监视和退避等待
+type Clock interface {
+	Now() time.Time
+	Sleep(duration time.Duration)
+	NewTimer(duration time.Duration) Timer              // Timer sending the time on C once the duration passed // 时长过后在 C 上发送时间的定时器
+	AfterFunc(duration time.Duration, run func()) Timer // Timer calling run once the duration passed, C is nil // 时长过后调用 run 的定时器，C 为 nil
+}
+
+// Timer is a pending wake-up scheduled through a Clock, with the semantics of time.Timer
+// Timer 是通过 Clock 安排的待触发唤醒，语义与 time.Timer 相同
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(duration time.Duration) bool
+}
+
+// systemTimer adapts time.Timer to Timer
+// systemTimer 将 time.Timer 适配为 Timer
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t *systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *systemTimer) Reset(duration time.Duration) bool {
+	return t.timer.Reset(duration)
+}
+
+// systemClock is the wall clock
+// systemClock 是系统时钟
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+func (systemClock) NewTimer(duration time.Duration) Timer {
+	return &systemTimer{timer: time.NewTimer(duration)}
+}
+
+func (systemClock) AfterFunc(duration time.Duration, run func()) Timer {
+	return &systemTimer{timer: time.AfterFunc(duration, run)}
+}
+
+// SystemClock gets back the wall clock used when no clock is set
+// SystemClock 返回未设置时钟时使用的系统时钟
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// WithClock sets the clock used in computing expiry and hold durations
+// WithClock 设置计算过期时间和持有时长使用的时钟
+func (o *Suo) WithClock(clock Clock) *Suo {
+	o.clock = clock
+	return o
+}
+
+// Clock gets back the clock of the lock, used through the runner in sleeps between attempts
+// Clock 返回锁的时钟，供运行器在尝试之间休眠时使用
+func (o *Suo) Clock() Clock {
+	return o.clock
+}
+
+// WithClock sets the clock of locks created through the manager
+// WithClock 设置通过管理器创建的锁的时钟
+func (m *Manager) WithClock(clock Clock) *Manager {
+	m.clock = clock
+	return m
+}
+
+// serverLowerBound gets back the exclusive score bound of leases still live on the Redis clock
+// serverLowerBound 返回依据 Redis 时钟仍然存活的租期的开区间分数下界
+func (o *Suo) serverLowerBound(ctx context.Context) (string, error) {
+	now, err := o.client().Time(ctx).Result()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return "(" + strconv.FormatInt(now.UnixMilli(), 10), nil
+}
@@ -0,0 +1,94 @@
+package redissuo
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var _ io.Closer = (*Xin)(nil)
+
+// closeReleaseTimeout bounds the release run through Close, which carries no caller context
+// closeReleaseTimeout 限制通过 Close 执行的释放时长，Close 不携带调用方上下文
+const closeReleaseTimeout = 10 * time.Second
+
+// xinCloser releases a hold once, shared across the sessions a hold passes through on extension
+// It keeps a copy of the latest session, a pointer would tie the session to itself and keep it from being collected
+//
+// xinCloser 只释放一次持有，在持有经延期产生的各会话之间共享
+// 它保存最新会话的副本，保存指针会使会话引用自身而无法被回收
+type xinCloser struct {
+	mutex    sync.Mutex                                        // Serializes Close calls // 串行化 Close 调用
+	latest   Xin                                               // Latest session of the hold // 持有的最新会话
+	release  func(ctx context.Context, xin *Xin) (bool, error) // Release of the lock owning the hold // 持有所属锁的释放方法
+	language Language                                          // Language of the lost-lock error // 锁丢失错误的语言
+	done     atomic.Bool                                       // Released already, through Close or an explicit release // 已通过 Close 或显式释放完成释放
+	err      error                                             // Outcome of the release done through Close // 通过 Close 完成的释放结果
+}
+
+// attachCloser makes Close on the session run the release, the session then starts its hold
+// attachCloser 使会话上的 Close 执行该释放，该会话即为持有的起点
+func attachCloser(xin *Xin, language Language, release func(ctx context.Context, xin *Xin) (bool, error)) *Xin {
+	if xin != nil {
+		xin.closer = &xinCloser{release: release, language: language}
+		xin.closer.latest = *xin
+	}
+	return xin
+}
+
+// follow hands the closer over to the extended session, so closing any session of the hold releases the latest one
+// follow 将关闭器交给延期后的会话，使关闭该持有的任一会话都会释放最新的会话
+func (c *xinCloser) follow(res *Xin) {
+	if c == nil || res == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res.closer = c
+	c.latest = *res
+}
+
+// markDone notes the hold went through an explicit release, Close then has nothing left to do
+// markDone 记录该持有已经显式释放，之后 Close 无需再做任何事
+func (c *xinCloser) markDone() {
+	if c != nil {
+		c.done.Store(true)
+	}
+}
+
+// Close releases the hold once, fitting the lock into `defer xin.Close()` patterns
+// Gives back nil once released and ErrLockLost when another session took the lock meanwhile
+// Later calls give back the same outcome without touching Redis, a connection problem leaves the next call free to try again
+// Handles built outside a lock, e.g. through Locker implementations, close as a no-op
+// The release gets at most closeReleaseTimeout, so an unreachable Redis cannot hang the deferred call
+//
+// Close 只释放一次持有，使锁适用于 `defer xin.Close()` 模式
+// 释放后返回 nil，期间锁被其它会话获取时返回 ErrLockLost
+// 之后的调用返回相同结果且不访问 Redis，连接错误时下一次调用仍可重试
+// 在锁之外构建的句柄（例如通过 Locker 实现）关闭时不做任何事
+// 释放最多耗时 closeReleaseTimeout，因此 Redis 不可达时延迟调用不会一直挂起
+func (s *Xin) Close() error {
+	c := s.closer
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.done.Load() {
+		return c.err
+	}
+	latest := c.latest
+	ctx, cancel := context.WithTimeout(context.Background(), closeReleaseTimeout)
+	defer cancel()
+	released, err := c.release(ctx, &latest)
+	if err != nil {
+		return err
+	}
+	c.done.Store(true)
+	if !released {
+		c.err = NewError(CodeLockLost, c.language, &StatusError{Script: ScriptRelease, Status: ReleaseNotOwner.String(), Code: int64(ReleaseNotOwner)})
+	}
+	return c.err
+}
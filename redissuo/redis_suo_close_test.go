@@ -0,0 +1,65 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestXin_Close validates Close releases the latest session of the hold once
+// TestXin_Close 验证 Close 只释放持有的最新会话一次
+func TestXin_Close(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	extended, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, extended)
+
+	require.NoError(t, xin.Close())
+	exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+
+	// A later holder is not touched through a second Close
+	// 第二次 Close 不会影响之后的持有者
+	other, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	require.NoError(t, extended.Close())
+	holder, err := caseRedisClient.Get(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Equal(t, other.SessionUUID(), holder)
+
+	success, err := suo.Release(ctx, other)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.NoError(t, other.Close())
+}
+
+// TestXin_Close_Lost validates Close reports ErrLockLost once another session took the lock
+// TestXin_Close_Lost 验证锁被其它会话获取后 Close 报告 ErrLockLost
+func TestXin_Close_Lost(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Set(ctx, suo.Key(), "usurper", time.Minute).Err())
+
+	require.ErrorIs(t, xin.Close(), redissuo.ErrLockLost)
+	require.ErrorIs(t, xin.Close(), redissuo.ErrLockLost)
+
+	holder, err := caseRedisClient.Get(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Equal(t, "usurper", holder)
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+}
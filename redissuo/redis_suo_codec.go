@@ -0,0 +1,201 @@
+package redissuo
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// Codec serializes the values stored next to the lock, such as the metadata companion value
+// Pick the codec matching the conventions of other services reading the same keys
+// Other formats, e.g. msgpack, plug in through implementing the interface over the chosen package
+//
+// Codec 序列化与锁一同存储的值，例如元数据伴随键的值
+// 选择与读取相同键的其它服务约定一致的编解码器
+// 其它格式（例如 msgpack）可通过基于所选包实现该接口接入
+type Codec interface {
+	Name() string                               // Short name used in logs // 日志中使用的简短名称
+	Marshal(v interface{}) ([]byte, error)      // Encodes the value // 编码该值
+	Unmarshal(data []byte, v interface{}) error // Decodes the data into the value // 将数据解码到该值中
+}
+
+// JSONCodec encodes values as JSON, the default
+// JSONCodec 将值编码为 JSON，为默认编解码器
+type JSONCodec struct{}
+
+// Name gets back "json"
+// Name 返回 "json"
+func (JSONCodec) Name() string {
+	return "json"
+}
+
+// Marshal encodes the value as JSON
+// Marshal 将值编码为 JSON
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return data, nil
+}
+
+// Unmarshal decodes the JSON data into the value
+// Unmarshal 将 JSON 数据解码到该值中
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// RawCodec stores plain strings as is and metadata as a URL query string
+// Tags become "tag.<name>=<value>" pairs, so shell scripts and other languages read them without a JSON parser
+//
+// RawCodec 将普通字符串原样存储，将元数据存储为 URL 查询字符串
+// 标签变为 "tag.<name>=<value>" 键值对，使 shell 脚本和其它语言无需 JSON 解析器即可读取
+type RawCodec struct{}
+
+// Name gets back "raw"
+// Name 返回 "raw"
+func (RawCodec) Name() string {
+	return "raw"
+}
+
+// Marshal encodes strings, byte slices and metadata, other types are refused
+// Marshal 编码字符串、字节切片和元数据，拒绝其它类型
+func (RawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case string:
+		return []byte(value), nil
+	case []byte:
+		return value, nil
+	case *Metadata:
+		return []byte(encodeMetadataQuery(value)), nil
+	}
+	return nil, erero.Errorf("raw codec cannot marshal %T", v)
+}
+
+// Unmarshal decodes into string pointers, byte slice pointers and metadata, other types are refused
+// Unmarshal 解码到字符串指针、字节切片指针和元数据，拒绝其它类型
+func (RawCodec) Unmarshal(data []byte, v interface{}) error {
+	switch value := v.(type) {
+	case *string:
+		*value = string(data)
+	case *[]byte:
+		*value = append((*value)[:0], data...)
+	case *Metadata:
+		return decodeMetadataQuery(string(data), value)
+	default:
+		return erero.Errorf("raw codec cannot unmarshal into %T", v)
+	}
+	return nil
+}
+
+// encodeMetadataQuery writes the metadata in URL query form with sorted names
+// encodeMetadataQuery 以名称排序的 URL 查询形式写出元数据
+func encodeMetadataQuery(metadata *Metadata) string {
+	values := url.Values{}
+	for name, value := range metadata.Tags {
+		values.Set("tag."+name, value)
+	}
+	if metadata.Stack != "" {
+		values.Set("stack", metadata.Stack)
+	}
+	if metadata.Continues != nil {
+		values.Set("continues", metadata.Continues.Session)
+		if metadata.Continues.FencingToken != 0 {
+			values.Set("continues_token", strconv.FormatInt(metadata.Continues.FencingToken, 10))
+		}
+	}
+	if metadata.Estimate > 0 {
+		values.Set("estimate_ms", strconv.FormatInt(metadata.Estimate.Milliseconds(), 10))
+	}
+	if metadata.Hostname != "" {
+		values.Set("hostname", metadata.Hostname)
+	}
+	if metadata.PID != 0 {
+		values.Set("pid", strconv.Itoa(metadata.PID))
+	}
+	if metadata.AcquiredAt != nil {
+		values.Set("acquired_at", metadata.AcquiredAt.Format(time.RFC3339Nano))
+	}
+	return values.Encode()
+}
+
+// decodeMetadataQuery parses the URL query form back into the metadata
+// decodeMetadataQuery 将 URL 查询形式解析回元数据
+func decodeMetadataQuery(data string, metadata *Metadata) error {
+	values, err := url.ParseQuery(data)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	for name := range values {
+		value := values.Get(name)
+		switch {
+		case strings.HasPrefix(name, "tag."):
+			if metadata.Tags == nil {
+				metadata.Tags = map[string]string{}
+			}
+			metadata.Tags[strings.TrimPrefix(name, "tag.")] = value
+		case name == "stack":
+			metadata.Stack = value
+		case name == "continues":
+			if metadata.Continues == nil {
+				metadata.Continues = &Continuation{}
+			}
+			metadata.Continues.Session = value
+		case name == "continues_token":
+			token, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			if metadata.Continues == nil {
+				metadata.Continues = &Continuation{}
+			}
+			metadata.Continues.FencingToken = token
+		case name == "estimate_ms":
+			milliseconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			metadata.Estimate = time.Duration(milliseconds) * time.Millisecond
+		case name == "hostname":
+			metadata.Hostname = value
+		case name == "pid":
+			pid, err := strconv.Atoi(value)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			metadata.PID = pid
+		case name == "acquired_at":
+			acquiredAt, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			metadata.AcquiredAt = &acquiredAt
+		}
+	}
+	return nil
+}
+
+// WithCodec sets the codec of the metadata companion value
+// Every process sharing the lock names must use the same codec, or inspections fail to decode
+//
+// WithCodec 设置元数据伴随键值的编解码器
+// 共享锁名的所有进程必须使用相同的编解码器，否则检查时无法解码
+func (o *Suo) WithCodec(codec Codec) *Suo {
+	o.codec = must.Nice(codec)
+	return o
+}
+
+// WithCodec sets the codec used in locks created through the manager and in inspections
+// WithCodec 设置通过管理器创建的锁以及检查时使用的编解码器
+func (m *Manager) WithCodec(codec Codec) *Manager {
+	m.codec = must.Nice(codec)
+	return m
+}
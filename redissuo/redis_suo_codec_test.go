@@ -0,0 +1,95 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_WithCodec validates the metadata companion value follows the codec
+// Tests the raw codec writes a query string and inspections decode it back
+//
+// TestManager_WithCodec 验证元数据伴随键的值遵循编解码器
+// 测试原始编解码器写入查询字符串且检查时能解码回来
+func TestManager_WithCodec(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithCodec(redissuo.RawCodec{})
+
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"team": "payment", "job": "settle"})
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	value, err := caseRedisClient.Get(ctx, "{"+suo.Key()+"}:meta").Result()
+	require.NoError(t, err)
+	require.Equal(t, "tag.job=settle&tag.team=payment", value)
+
+	infos, err := manager.InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, xin.SessionUUID(), infos[0].Holder)
+	require.Equal(t, map[string]string{"team": "payment", "job": "settle"}, infos[0].Metadata.Tags)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestRawCodec validates the raw codec round trips metadata and strings and refuses other types
+// TestRawCodec 验证原始编解码器能往返元数据和字符串并拒绝其它类型
+func TestRawCodec(t *testing.T) {
+	codec := redissuo.RawCodec{}
+	acquiredAt := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	metadata := &redissuo.Metadata{
+		Tags:       map[string]string{"team": "a&b"},
+		Stack:      "main.run()",
+		Continues:  &redissuo.Continuation{Session: "abc", FencingToken: 7},
+		Estimate:   3 * time.Second,
+		Hostname:   "worker-1",
+		PID:        4242,
+		AcquiredAt: &acquiredAt,
+	}
+	data, err := codec.Marshal(metadata)
+	require.NoError(t, err)
+	var decoded redissuo.Metadata
+	require.NoError(t, codec.Unmarshal(data, &decoded))
+	require.Equal(t, metadata, &decoded)
+
+	data, err = codec.Marshal("holder")
+	require.NoError(t, err)
+	var text string
+	require.NoError(t, codec.Unmarshal(data, &text))
+	require.Equal(t, "holder", text)
+
+	_, err = codec.Marshal(42)
+	require.Error(t, err)
+}
+
+// failingCodec refuses each value, standing in a codec broken through misconfiguration
+// failingCodec 拒绝每个值，模拟因配置错误而失效的编解码器
+type failingCodec struct{}
+
+func (failingCodec) Name() string { return "failing" }
+func (failingCodec) Marshal(v interface{}) ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+func (failingCodec) Unmarshal(data []byte, v interface{}) error {
+	return errors.New("cannot unmarshal")
+}
+
+// TestSuo_WithCodec_Failing validates a codec problem fails the acquisition instead of panicking, leaving the lock free
+// TestSuo_WithCodec_Failing 验证编解码器错误使获取失败而不是 panic，且锁保持空闲
+func TestSuo_WithCodec_Failing(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithCodec(failingCodec{}).WithTags(map[string]string{"team": "payment"})
+
+	xin, err := suo.Acquire(ctx)
+	require.ErrorContains(t, err, "cannot marshal")
+	require.Nil(t, xin)
+	require.Zero(t, caseRedisClient.Exists(ctx, suo.Key()).Val())
+}
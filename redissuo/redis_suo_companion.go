@@ -0,0 +1,69 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// ErrLockHeld is returned when Cleanup finds the lock held, companions stay in place then
+// Acquisitions in typed error mode fail with it too, see WithTypedErrors
+//
+// ErrLockHeld 在 Cleanup 发现锁仍被持有时返回，此时伴随键保持不变
+// 类型化错误模式下的获取同样以它失败，参见 WithTypedErrors
+var ErrLockHeld = NewError(CodeLockHeld, LanguageEnglish, nil)
+
+const (
+	// KEYS: lock, companions / ARGV: none
+	// Deletes the companions just when the lock is free, -1 when held
+	// KEYS: 锁、伴随键 / ARGV: 无
+	// 仅当锁空闲时删除伴随键，被持有时返回 -1
+	commandCleanupCompanions = `if redis.call("EXISTS", KEYS[1]) == 1 then
+    return -1
+end
+local count = 0
+for i = 2, #KEYS do
+    count = count + redis.call("DEL", KEYS[i])
+end
+return count`
+)
+
+// companionKeys gets back every fixed companion key of the lock name, in one place so cleanup reaches new ones
+// Lifetimes differ: meta follows the lock, queue and holds use companionTTL, permits follow the longest permit,
+// the writer intent lapses one TTL past the last refused writer, the records index follows the longest kept record, while the checkpoint and the token counter persist until cleanup
+//
+// companionKeys 返回该锁名的所有固定伴随键，集中在一处使清理能覆盖新增的伴随键
+// 存活时间各不相同：meta 跟随锁，queue 和 holds 使用 companionTTL，permits 跟随最久的许可，
+// 写者意向在最后一次被拒绝的写者之后一个 TTL 失效，记录索引跟随保存最久的记录，而检查点和令牌计数器一直保留直到被清理
+func (o *Suo) companionKeys() []string {
+	return []string{o.metaKey(), o.checkpointKey(), o.queueKey(), o.holdsKey(), o.permitsKey(), o.recordsKey(), o.readersKey(), o.writerIntentKey(), o.aliveKey(), o.tokenKey()}
+}
+
+// Cleanup deletes every companion key of the lock name, including execution records and the checkpoint
+// Meant in retiring a lock name, refuses with ErrLockHeld while the lock is held so live state is never dropped
+// Gives back the count of deleted keys
+//
+// Cleanup 删除该锁名的所有伴随键，包括执行记录和检查点
+// 适用于停用某个锁名，锁被持有时以 ErrLockHeld 拒绝，避免删除存活状态
+// 返回被删除的键数量
+func (o *Suo) Cleanup(ctx context.Context) (int64, error) {
+	if o.simulated("cleanup") {
+		return 0, nil
+	}
+	records, err := o.client().SMembers(ctx, o.recordsKey()).Result()
+	if err != nil {
+		o.logger.ErrorLog("清理伴随键报错", zap.String("k", o.key), zap.Error(err))
+		return 0, erero.Wro(err)
+	}
+	keys := append(append([]string{o.key}, o.companionKeys()...), records...)
+	count, err := o.client().Eval(ctx, commandCleanupCompanions, keys).Int64()
+	if err != nil {
+		o.logger.ErrorLog("清理伴随键报错", zap.String("k", o.key), zap.Error(err))
+		return 0, erero.Wro(err)
+	}
+	if count < 0 {
+		return 0, NewError(CodeLockHeld, o.language, nil)
+	}
+	return count, nil
+}
@@ -0,0 +1,52 @@
+package redissuo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_Cleanup validates companions survive while the lock is held and get removed once it is free
+// TestSuo_Cleanup 验证锁被持有时伴随键保留，锁空闲后伴随键被删除
+func TestSuo_Cleanup(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	xin, err = suo.ExtendWithCheckpoint(ctx, xin, "cursor-1")
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	ok, err := suo.CompleteRun(ctx, xin, "2026-10-14", redissuo.RunSucceeded, "", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = suo.CompleteRun(ctx, xin, "2026-10-15", redissuo.RunSucceeded, "", 0)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = suo.Cleanup(ctx)
+	require.True(t, errors.Is(err, redissuo.ErrLockHeld))
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	// Checkpoint, two records and the records index
+	count, err := suo.Cleanup(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), count)
+
+	record, err := suo.ExecutionRecord(ctx, "2026-10-15")
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	count, err = suo.Cleanup(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
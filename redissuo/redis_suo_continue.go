@@ -0,0 +1,49 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/must"
+)
+
+// Continuation proves a new hold resumes the work of an earlier session of the same logical job
+// Recorded in the lock metadata, so consumers can tell "same job resumed" from "a different holder"
+//
+// Continuation 证明新的持有延续了同一逻辑任务中先前会话的工作
+// 记录在锁元数据中，使消费方能够区分"同一任务恢复"和"不同的持有者"
+type Continuation struct {
+	Session      string `json:"session"`                 // Session UUID of the earlier hold // 先前持有的会话 UUID
+	FencingToken int64  `json:"fencing_token,omitempty"` // Fencing token of the earlier hold, 0 when unknown // 先前持有的防护令牌，未知时为 0
+}
+
+// Continuation gets back the proof a later hold presents to resume the work of this session
+// Continuation 返回后续持有用来延续本会话工作的凭证
+func (s *Xin) Continuation() *Continuation {
+	return &Continuation{Session: s.sessionUUID, FencingToken: s.fencingToken}
+}
+
+// Continues gets back the earlier session this hold resumes, nil when the hold is fresh
+// Continues 返回本次持有所延续的先前会话，全新持有时为 nil
+func (s *Xin) Continues() *Continuation {
+	return s.continues
+}
+
+// AcquireContinuing acquires the lock through a fresh session recording the continuation in metadata
+// Meant in resumable batch jobs that lost the lock through expiry and got it back later
+// Extensions of the new session keep the continuation, release removes it together with the lock
+//
+// AcquireContinuing 使用新会话获取锁，并在元数据中记录延续凭证
+// 适用于因过期丢失锁、之后重新获取的可恢复批处理任务
+// 新会话的延期会保留延续凭证，释放时与锁一同删除
+func (o *Suo) AcquireContinuing(ctx context.Context, continuation *Continuation) (*Xin, error) {
+	must.Nice(continuation)
+	must.OK(continuation.Session)
+	return o.acquireLockWith(ctx, utils.NewUUID(), &acquireRequest{ttl: o.freshTTL(), continues: continuation})
+}
+
+// ContinuesFrom reports whether the holder declared it resumes the given earlier session
+// ContinuesFrom 判断持有者是否声明延续了给定的先前会话
+func (m *Metadata) ContinuesFrom(session string) bool {
+	return m != nil && m.Continues != nil && m.Continues.Session == session
+}
@@ -0,0 +1,50 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_AcquireContinuing validates a resumed hold records the earlier session in metadata across extensions
+// TestSuo_AcquireContinuing 验证恢复的持有在元数据中记录先前会话，并在延期后保留
+func TestSuo_AcquireContinuing(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Nil(t, xin.Continues())
+
+	// The first hold gets lost, e.g. through expiry
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+
+	resumed, err := suo.AcquireContinuing(ctx, xin.Continuation())
+	require.NoError(t, err)
+	require.NotNil(t, resumed)
+	require.NotEqual(t, xin.SessionUUID(), resumed.SessionUUID())
+
+	resumed, err = suo.AcquireAgainExtendLock(ctx, resumed)
+	require.NoError(t, err)
+	require.NotNil(t, resumed)
+	require.Equal(t, xin.SessionUUID(), resumed.Continues().Session)
+
+	infos, err := manager.InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.True(t, infos[0].Metadata.ContinuesFrom(xin.SessionUUID()))
+
+	success, err := suo.Release(ctx, resumed)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	infos, err = manager.InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.False(t, infos[0].Held())
+	require.Nil(t, infos[0].Metadata)
+}
@@ -0,0 +1,109 @@
+package redissuo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yyle88/erero"
+)
+
+var (
+	// scriptCallPattern matches redis.call and redis.pcall with the command and its first argument
+	// scriptCallPattern 匹配 redis.call 和 redis.pcall 及其命令和第一个参数
+	scriptCallPattern = regexp.MustCompile(`redis\.p?call\(\s*([^,)]*)\s*(?:,\s*([^,)]+))?`)
+	// scriptKeyLocalPattern matches locals bound to a KEYS item, e.g. "local key = KEYS[1 + i]"
+	// scriptKeyLocalPattern 匹配绑定到 KEYS 元素的局部变量，例如 "local key = KEYS[1 + i]"
+	scriptKeyLocalPattern = regexp.MustCompile(`local\s+([A-Za-z_][A-Za-z0-9_]*)\s*=\s*KEYS\[[^\]]+\]`)
+	// scriptKeysItemPattern matches a direct KEYS item argument
+	// scriptKeysItemPattern 匹配直接引用 KEYS 元素的参数
+	scriptKeysItemPattern = regexp.MustCompile(`^KEYS\[[^\]]+\]$`)
+)
+
+// scriptKeylessCommands lists the commands used in scripts that take no key
+// scriptKeylessCommands 列出脚本中使用的不带键的命令
+var scriptKeylessCommands = map[string]bool{"TIME": true}
+
+// ValidateScripts checks every script, composed acquire variants included, reaches keys just through KEYS
+// Redis Cluster routes a script through its KEYS, a key built inside the script may live on another node
+// The check reads the first key argument of each call, it catches literal and computed keys ahead of production
+//
+// ValidateScripts 检查所有脚本（包括组合的获取变体）仅通过 KEYS 访问键
+// Redis Cluster 依据 KEYS 路由脚本，脚本内构造的键可能位于其它节点
+// 检查读取每次调用的第一个键参数，在上线前发现字面量键和计算得到的键
+func ValidateScripts() error {
+	scripts := Scripts()
+	// Composed in the same sequence as acquireScript: metadata wrapper, fencing wrapper, strict prefix, guard prefix
+	// 按与 acquireScript 相同的顺序组合：元数据包装、防护包装、严格模式前缀、守卫前缀
+	for _, name := range []string{ScriptAcquire, ScriptAcquireServerTime, ScriptAcquireModern} {
+		composed := commandMetaWrapperHead + scripts[name] + commandMetaWrapperTail
+		scripts[name+"_composed"] = commandGuardPrefix + commandStrictPrefix + commandFencingWrapperHead + composed + commandFencingWrapperTail
+	}
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateScript(name, scripts[name]); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// validateScript checks each call of the script takes its key from KEYS or a local bound to a KEYS item
+// validateScript 检查脚本的每次调用都从 KEYS 或绑定到 KEYS 元素的局部变量获取键
+func validateScript(name string, script string) error {
+	locals := map[string]bool{}
+	for _, match := range scriptKeyLocalPattern.FindAllStringSubmatch(script, -1) {
+		locals[match[1]] = true
+	}
+	for _, match := range scriptCallPattern.FindAllStringSubmatch(script, -1) {
+		command, argument := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+		if !strings.HasPrefix(command, `"`) {
+			return erero.Errorf("script %s: dynamic command %s", name, command)
+		}
+		if scriptKeylessCommands[strings.ToUpper(strings.Trim(command, `"`))] {
+			continue
+		}
+		if !scriptKeysItemPattern.MatchString(argument) && !locals[argument] {
+			return erero.Errorf("script %s: %s reaches key %q outside KEYS", name, command, argument)
+		}
+	}
+	return nil
+}
+
+// Validate checks the scripts together with the keys of this lock against the cluster contract
+// Companion and guard keys must share the hash tag of the lock, so each script touches a single slot
+//
+// Validate 按集群约定检查脚本以及该锁的键
+// 伴随键和守卫键必须与锁共享哈希标签，使每个脚本只访问单个槽
+func (o *Suo) Validate() error {
+	if err := ValidateScripts(); err != nil {
+		return erero.Wro(err)
+	}
+	slot := hashTagOf(o.key)
+	for _, key := range o.companionKeys() {
+		if hashTagOf(key) != slot {
+			return erero.Errorf("companion %q does not share the hash tag of %q", key, o.key)
+		}
+	}
+	for _, guard := range o.guards {
+		if hashTagOf(guard.key) != slot {
+			return erero.Errorf("guard %q does not share the hash tag of %q", guard.key, o.key)
+		}
+	}
+	return nil
+}
+
+// hashTagOf gets back the bytes Redis Cluster hashes in the key, the non-blank {...} tag or else the whole key
+// hashTagOf 返回 Redis Cluster 对该键计算哈希的字节，即非空的 {...} 标签，否则为整个键
+func hashTagOf(key string) string {
+	if !hasHashTag(key) {
+		return key
+	}
+	start := strings.IndexByte(key, '{')
+	end := strings.IndexByte(key[start+1:], '}')
+	return key[start+1 : start+1+end]
+}
@@ -0,0 +1,42 @@
+package redissuo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateScripts validates the bundled scripts reach keys just through KEYS
+// TestValidateScripts 验证内置脚本仅通过 KEYS 访问键
+func TestValidateScripts(t *testing.T) {
+	require.NoError(t, ValidateScripts())
+}
+
+// TestValidateScript validates literal, computed and dynamic keys are refused
+// TestValidateScript 验证字面量键、计算得到的键和动态命令会被拒绝
+func TestValidateScript(t *testing.T) {
+	require.NoError(t, validateScript("ok", `local key = KEYS[2]
+redis.call("SET", KEYS[1], ARGV[1])
+return redis.call("GET", key)`))
+	require.Error(t, validateScript("literal", `return redis.call("GET", "other")`))
+	require.Error(t, validateScript("computed", `return redis.call("GET", KEYS[1] .. ":meta")`))
+	require.Error(t, validateScript("dynamic", `return redis.call(ARGV[1], KEYS[1])`))
+}
+
+// TestSuo_Validate validates guards outside the lock's hash tag are reported, no Redis round trip involved
+// TestSuo_Validate 验证不在锁哈希标签内的守卫会被报告，不涉及 Redis 往返
+func TestSuo_Validate(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	defer func() { _ = redisClient.Close() }()
+
+	suo := NewSuo(redisClient, "job", time.Second)
+	require.NoError(t, suo.Validate())
+	require.NoError(t, suo.WithGuards(GuardAbsent("{job}:switch")).Validate())
+	require.Error(t, suo.WithGuards(GuardAbsent("switch")).Validate())
+
+	tagged := NewSuo(redisClient, "{orders}:settle", time.Second)
+	require.NoError(t, tagged.WithGuards(GuardExists("{orders}:ready")).Validate())
+	require.Equal(t, "orders", hashTagOf(tagged.metaKey()))
+}
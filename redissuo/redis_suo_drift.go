@@ -0,0 +1,82 @@
+package redissuo
+
+import (
+	"time"
+
+	"github.com/yyle88/must"
+)
+
+// WithDriftFactor sets the share of the TTL set aside against skew between the client clock and the Redis clock
+// Expire then ends the drift margin (TTL times the factor plus 2ms) ahead of the acquisition-time estimate, as Redlock does
+// The runner budgets its work through Expire, so a margin stops runs ahead of a lease the server sees lapsing first
+// A factor of 0, the default, keeps Expire at the acquisition-time estimate
+// In server time mode Expire is anchored through the measured offset instead, see WithServerTime
+//
+// WithDriftFactor 设置为客户端时钟与 Redis 时钟之间的偏差预留的 TTL 比例
+// 此时 Expire 会在获取耗时估算的基础上提前漂移余量（TTL 乘以比例再加 2ms）结束，与 Redlock 的做法一致
+// 运行器依据 Expire 安排工作时长，因此余量能在服务端先判定租期失效之前停止运行
+// 比例为 0（默认值）时 Expire 保持为获取耗时估算
+// 服务端时间模式下 Expire 改为通过测得的偏移锚定，参见 WithServerTime
+func (o *Suo) WithDriftFactor(factor float64) *Suo {
+	must.True(factor >= 0 && factor < 1)
+	o.driftFactor = factor
+	return o
+}
+
+// WithDriftFactor sets the drift factor of locks created through the manager, see Suo.WithDriftFactor
+// WithDriftFactor 设置通过管理器创建的锁的漂移比例，参见 Suo.WithDriftFactor
+func (m *Manager) WithDriftFactor(factor float64) *Manager {
+	must.True(factor >= 0 && factor < 1)
+	m.driftFactor = factor
+	return m
+}
+
+// expiryOf gets back the pessimistic and the optimistic expiry of a lease of ttl written between startTime and nowTime
+// The pessimistic one counts the lease from the start of the round trip and takes off the drift margin,
+// the optimistic one counts it from the end of the round trip and adds the drift margin on,
+// the latest the key may live on the client clock when the server clock runs slow
+//
+// expiryOf 返回在 startTime 与 nowTime 之间写入的 ttl 租期的悲观和乐观过期时间
+// 悲观值从往返开始计算租期并扣除漂移余量，
+// 乐观值从往返结束计算租期并加上漂移余量，即服务端时钟偏慢时按客户端时钟该键最晚的存活时间
+func (o *Suo) expiryOf(startTime time.Time, nowTime time.Time, ttl time.Duration) (time.Time, time.Time) {
+	var drift time.Duration
+	if o.driftFactor > 0 {
+		drift = time.Duration(float64(ttl)*o.driftFactor) + driftAllowance
+	}
+	return nowTime.Add(ttl - nowTime.Sub(startTime) - drift), nowTime.Add(ttl + drift)
+}
+
+// anchoredExpiry reads the lease the server stamped at serverTime back on the client clock, given the round trip between startTime and nowTime
+// The offset takes the client midpoint of the round trip as the instant of the TIME call, which is off by at most half of it,
+// so the pessimistic expiry takes that half off and the optimistic one adds it on
+//
+// anchoredExpiry 根据 startTime 与 nowTime 之间的往返，将服务端在 serverTime 记录的租期换算到客户端时钟上
+// 偏移以往返的客户端中点作为 TIME 调用的时刻，误差至多为半个往返时间，
+// 因此悲观值扣除该半程，乐观值加上该半程
+func anchoredExpiry(startTime time.Time, nowTime time.Time, serverTime time.Time, ttl time.Duration) (time.Duration, time.Time, time.Time) {
+	half := nowTime.Sub(startTime) / 2
+	offset := serverTime.Sub(startTime.Add(half))
+	expire := serverTime.Add(ttl).Add(-offset).In(nowTime.Location())
+	return offset, expire.Add(-half), expire.Add(half)
+}
+
+// PessimisticExpire gets back the earliest time the lease may end, the same as Expire
+// PessimisticExpire 返回租期可能结束的最早时间，与 Expire 相同
+func (s *Xin) PessimisticExpire() time.Time {
+	return s.expire
+}
+
+// OptimisticExpire gets back the latest time the lease may end, counting the TTL from the end of the acquisition round trip plus the drift margin
+// Meant in deciding when the key is surely gone, e.g. ahead of taking over work, never in bounding work under the lock
+// Falls back on Expire when the session carries no separate estimate
+//
+// OptimisticExpire 返回租期可能结束的最晚时间，从获取往返结束时开始计算 TTL 并加上漂移余量
+// 适用于判断该键何时必然消失，例如接管工作之前，切勿用于限定持锁期间的工作
+// 会话没有单独的估算时回退为 Expire
+func (s *Xin) OptimisticExpire() time.Time {
+	if s.optimisticExpire.IsZero() {
+		return s.expire
+	}
+	return s.optimisticExpire
+}
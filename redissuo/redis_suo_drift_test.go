@@ -0,0 +1,44 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithDriftFactor validates the drift margin comes off the pessimistic expiry and goes onto the optimistic one
+// TestSuo_WithDriftFactor 验证漂移余量从悲观过期时间中扣除，并加到乐观过期时间上
+func TestSuo_WithDriftFactor(t *testing.T) {
+	ctx := context.Background()
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 10*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, xin.Expire(), xin.PessimisticExpire())
+	require.False(t, xin.OptimisticExpire().Before(xin.Expire()))
+	require.Less(t, xin.OptimisticExpire().Sub(xin.Expire()), 100*time.Millisecond)
+
+	manager := redissuo.NewManager(caseRedisClient).WithDriftFactor(0.01)
+	drifted := manager.NewSuo(utils.NewUUID(), 10*time.Second)
+	res, err := drifted.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	// The margin is 1% of the TTL plus 2ms, taken off one bound and added onto the other
+	require.Equal(t, 10*time.Second-102*time.Millisecond, res.Expire().Sub(res.AcquiredAt()))
+	require.GreaterOrEqual(t, res.OptimisticExpire().Sub(res.AcquiredAt()), 10*time.Second+102*time.Millisecond)
+	require.Less(t, res.OptimisticExpire().Sub(res.AcquiredAt()), 10*time.Second+200*time.Millisecond)
+
+	// Extensions keep the margin on both bounds
+	res, err = drifted.AcquireAgainExtendLock(ctx, res)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	require.GreaterOrEqual(t, res.OptimisticExpire().Sub(res.Expire()), 204*time.Millisecond)
+	released, err := drifted.Release(ctx, res)
+	require.NoError(t, err)
+	require.True(t, released)
+}
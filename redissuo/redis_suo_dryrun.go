@@ -0,0 +1,39 @@
+package redissuo
+
+import (
+	"go.uber.org/zap"
+)
+
+// WithDryRun simulates the lock operations of locks created through the manager locally
+// Acquisitions, extensions and releases always succeed and never touch Redis, each one logged with dry_run
+// Permits, read-write leases, queue entries, execution records, checkpoints and cleanup are simulated as well,
+// inspection and force release see a free lock
+// Meant in staging, so fresh integrations can be load-tested and traced before Redis capacity exists
+// Provides no mutual exclusion at all, never enable it in production
+//
+// WithDryRun 在本地模拟通过管理器创建的锁的操作
+// 获取、延期和释放总是成功且从不访问 Redis，每次操作都带 dry_run 标记记录日志
+// 许可、读写租期、队列条目、执行记录、检查点和清理同样是模拟的，
+// 检查和强制释放看到的是空闲的锁
+// 适用于预发环境，使新接入方在 Redis 容量就绪之前即可压测和追踪
+// 完全不提供互斥，切勿在生产环境中启用
+func (m *Manager) WithDryRun(enable bool) *Manager {
+	m.dryRun = enable
+	if enable {
+		m.logger.ErrorLog("试运行模式已开启-锁操作不访问Redis", zap.Bool("dry_run", true))
+	}
+	return m
+}
+
+// simulated reports whether the lock runs dry, logging the skipped operation when it does
+// Entry points touching Redis check it first and give back the outcome of an uncontended lock
+//
+// simulated 判断锁是否处于试运行模式，是则记录被跳过的操作
+// 访问 Redis 的入口先检查它，并返回无竞争时锁的结果
+func (o *Suo) simulated(operation string) bool {
+	if !o.dryRun {
+		return false
+	}
+	o.logger.DebugLog("试运行-跳过Redis操作", zap.String("k", o.key), zap.String("operation", operation), zap.Bool("dry_run", true))
+	return true
+}
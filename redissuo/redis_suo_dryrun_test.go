@@ -0,0 +1,131 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestManager_WithDryRun validates dry-run locks always succeed and leave Redis untouched
+// TestManager_WithDryRun 验证试运行的锁总是成功且不改动 Redis
+func TestManager_WithDryRun(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	manager := redissuo.NewManager(caseRedisClient).WithDryRun(true)
+
+	xin1, err := manager.NewSuo(key, 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+	suo := manager.NewSuo(key, 5*time.Second)
+	xin2, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin2)
+
+	exists, err := caseRedisClient.Exists(ctx, key).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+
+	xin2, err = suo.AcquireAgainExtendLock(ctx, xin2)
+	require.NoError(t, err)
+	require.NotNil(t, xin2)
+	success, err := suo.Release(ctx, xin2)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestManager_WithDryRun_Companions validates the entry points beyond acquire and release leave Redis empty in dry-run mode
+// Tests permits, read-write leases, the waiter queue, execution records, checkpoints, cleanup, inspection and force release
+//
+// TestManager_WithDryRun_Companions 验证试运行模式下获取与释放之外的入口也使 Redis 保持为空
+// 测试许可、读写租期、等待队列、执行记录、检查点、清理、检查和强制释放
+func TestManager_WithDryRun_Companions(t *testing.T) {
+	miniRedis := rese.P1(miniredis.Run())
+	t.Cleanup(miniRedis.Close)
+	redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	ctx := context.Background()
+	key := utils.NewUUID()
+	manager := redissuo.NewManager(redisClient).WithDryRun(true)
+	suo := manager.NewSuo(key, 5*time.Second).WithWaitQueue(true)
+	requireEmpty := func() {
+		require.Empty(t, miniRedis.Keys())
+	}
+
+	permit, err := suo.AcquirePermit(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, permit)
+	requireEmpty()
+	count, err := suo.Permits(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	released, err := suo.ReleasePermit(ctx, permit)
+	require.NoError(t, err)
+	require.True(t, released)
+	requireEmpty()
+
+	rw := manager.NewRWSuo(key, 5*time.Second)
+	reader, err := rw.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	reader, err = rw.ExtendRead(ctx, reader)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	requireEmpty()
+	released, err = rw.ReleaseRead(ctx, reader)
+	require.NoError(t, err)
+	require.True(t, released)
+	writer, err := rw.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, writer)
+	requireEmpty()
+	require.NoError(t, writer.Close())
+
+	waiter, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+	requireEmpty()
+	status, err := waiter.Status(ctx)
+	require.NoError(t, err)
+	require.Zero(t, status.Position)
+	require.NoError(t, waiter.Leave(ctx))
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	xin, err = suo.ExtendWithCheckpoint(ctx, xin, "cursor")
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	requireEmpty()
+	cursor, err := suo.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Empty(t, cursor)
+	require.NoError(t, suo.ClearCheckpoint(ctx))
+
+	runID := utils.NewUUID()
+	success, err := suo.CompleteRun(ctx, xin, runID, redissuo.RunSucceeded, "", time.Minute)
+	require.NoError(t, err)
+	require.True(t, success)
+	requireEmpty()
+	record, err := suo.ExecutionRecord(ctx, runID)
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	info, err := suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.False(t, info.Held())
+	holder, err := suo.ForceRelease(ctx)
+	require.NoError(t, err)
+	require.Empty(t, holder)
+	deleted, err := suo.Cleanup(ctx)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+	require.NoError(t, xin.Close())
+	requireEmpty()
+}
@@ -0,0 +1,175 @@
+package redissuo
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Code is a stable machine-readable error code, suitable in alerting rules and runbooks
+// Code 是稳定的机器可读错误码，适用于告警规则和运维手册
+type Code string
+
+const (
+	CodeGuardRejected     Code = "SUO_GUARD_REJECTED"      // Guard predicate blocked acquisition // 守卫条件阻止获取
+	CodeAlreadyHeld       Code = "SUO_ALREADY_HELD"        // Same session acquired twice in strict mode // 严格模式下同一会话重复获取
+	CodeMaxHold           Code = "SUO_MAX_HOLD"            // Max hold duration reached // 达到最大持有时长
+	CodeNotQueued         Code = "SUO_NOT_QUEUED"          // Waiter no longer queued // 等待者已不在队列中
+	CodeTooManyWaiters    Code = "SUO_TOO_MANY_WAITERS"    // Per-process waiter limit reached // 达到进程内等待者上限
+	CodePanicRecovered    Code = "SUO_PANIC_RECOVERED"     // Protected function panicked // 受保护的函数发生崩溃
+	CodeStaleFencingToken Code = "SUO_STALE_FENCING_TOKEN" // Write carried an outdated fencing token // 写入携带了过期的防护令牌
+	CodeFollowedRunFailed Code = "SUO_FOLLOWED_RUN_FAILED" // Run followed through a waiter failed in the holder // 等待者跟随的运行在持有者处失败
+	CodeMaintenance       Code = "SUO_MAINTENANCE"         // Maintenance gate froze the lock name // 维护开关冻结了该锁名
+	CodePaused            Code = "SUO_PAUSED"              // Manager paused acquisitions // 管理器暂停了获取
+	CodeAdmissionDenied   Code = "SUO_ADMISSION_DENIED"    // Admission callback vetoed acquisition // 准入回调否决了获取
+	CodeLockLost          Code = "SUO_LOCK_LOST"           // Session stopped holding the lock // 会话已不再持有锁
+	CodeLockHeld          Code = "SUO_LOCK_HELD"           // Lock still held where it must be free // 锁在需要空闲时仍被持有
+	CodeDetachedRun       Code = "SUO_DETACHED_RUN"        // Run outlived its deadline and still runs in this process // 运行超过截止时间后仍在本进程中运行
+	CodeWaitTimeout       Code = "SUO_WAIT_TIMEOUT"        // Lock not obtained within the bounded wait // 在有限等待时间内未获取到锁
+	CodeQuorumLost        Code = "SUO_QUORUM_LOST"         // Too few healthy nodes left to reach the quorum // 健康节点过少，无法达到法定数量
+	CodeExtendThrottled   Code = "SUO_EXTEND_THROTTLED"    // Session extended past the extension rate limit // 会话延期超过延期频率限制
+	CodeNotOwner          Code = "SUO_NOT_OWNER"           // Another session holds the lock // 锁被其它会话持有
+	CodeLockExpired       Code = "SUO_LOCK_EXPIRED"        // Lock lapsed ahead of the release // 锁在释放之前已失效
+	CodeBudgetExhausted   Code = "SUO_BUDGET_EXHAUSTED"    // Process holds as many locks as its budget allows // 进程持有的锁已达到预算上限
+)
+
+// Language selects the language of error messages surfaced to callers
+// Language 选择返回给调用方的错误消息语言
+type Language string
+
+const (
+	LanguageChinese Language = "zh" // Chinese messages // 中文消息
+	LanguageEnglish Language = "en" // English messages // 英文消息
+)
+
+// errorMessages holds the message of each code in each language
+// errorMessages 保存各错误码在各语言下的消息
+var errorMessages = map[Language]map[Code]string{
+	LanguageEnglish: {
+		CodeGuardRejected:     "acquisition rejected by guard",
+		CodeAlreadyHeld:       "lock already held through the same session",
+		CodeMaxHold:           "max hold duration reached",
+		CodeNotQueued:         "waiter not in queue",
+		CodeTooManyWaiters:    "too many waiters on the lock",
+		CodePanicRecovered:    "recovered from panic",
+		CodeStaleFencingToken: "stale fencing token",
+		CodeFollowedRunFailed: "run failed in the lock holder",
+		CodeMaintenance:       "lock frozen for maintenance",
+		CodePaused:            "acquisitions paused",
+		CodeAdmissionDenied:   "acquisition denied by admission",
+		CodeLockLost:          "lock lost",
+		CodeLockHeld:          "lock still held",
+		CodeDetachedRun:       "detached run of the lock still alive",
+		CodeWaitTimeout:       "lock not obtained within the wait",
+		CodeQuorumLost:        "quorum not reachable",
+		CodeExtendThrottled:   "extensions throttled",
+		CodeNotOwner:          "lock owned through another session",
+		CodeLockExpired:       "lock expired ahead of the release",
+		CodeBudgetExhausted:   "hold budget of the process exhausted",
+	},
+	LanguageChinese: {
+		CodeGuardRejected:     "守卫条件不满足-拒绝申请",
+		CodeAlreadyHeld:       "会话已持有锁-拒绝重复申请",
+		CodeMaxHold:           "已达最大持有时长",
+		CodeNotQueued:         "等待者不在队列中",
+		CodeTooManyWaiters:    "等待者过多",
+		CodePanicRecovered:    "错误(已从崩溃中恢复)",
+		CodeStaleFencingToken: "防护令牌已过期",
+		CodeFollowedRunFailed: "持有者运行失败",
+		CodeMaintenance:       "维护模式-锁已冻结",
+		CodePaused:            "已暂停申请",
+		CodeAdmissionDenied:   "准入回调否决申请",
+		CodeLockLost:          "锁已丢失",
+		CodeLockHeld:          "锁仍被持有",
+		CodeDetachedRun:       "该锁的脱离运行仍未结束",
+		CodeWaitTimeout:       "等待超时-未获取到锁",
+		CodeQuorumLost:        "健康节点不足-无法达到法定数量",
+		CodeExtendThrottled:   "延期过于频繁-已限流",
+		CodeNotOwner:          "锁被其它会话持有",
+		CodeLockExpired:       "释放之前锁已过期",
+		CodeBudgetExhausted:   "进程持锁预算已用尽",
+	},
+}
+
+// Error is an error carrying a stable code, a localized message and an optional cause
+// Two errors match through errors.Is when their codes are equal, whatever the language
+//
+// Error 是携带稳定错误码、本地化消息和可选原因的错误
+// 两个错误的错误码相同时即可通过 errors.Is 匹配，与语言无关
+type Error struct {
+	Code    Code   // Stable machine-readable code // 稳定的机器可读错误码
+	Message string // Localized message // 本地化消息
+	Cause   error  // Underlying problem, nil when none // 底层错误，没有时为 nil
+}
+
+// NewError creates a coded error with the message in the given language
+// Unknown languages fall back to English
+//
+// NewError 创建带错误码的错误，消息使用给定语言
+// 未知语言回退为英文
+func NewError(code Code, language Language, cause error) *Error {
+	messages, ok := errorMessages[language]
+	if !ok {
+		messages = errorMessages[LanguageEnglish]
+	}
+	return &Error{Code: code, Message: messages[code], Cause: cause}
+}
+
+// Error renders the code ahead of the message, e.g. "SUO_MAX_HOLD: max hold duration reached"
+// Error 将错误码放在消息前面输出，例如 "SUO_MAX_HOLD: max hold duration reached"
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return string(e.Code) + ": " + e.Message + ": " + e.Cause.Error()
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+// Unwrap gets back the cause so errors.Is and errors.As reach it
+// Unwrap 返回原因，使 errors.Is 和 errors.As 可以访问它
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the target is a coded error with the same code
+// Is 判断目标是否为错误码相同的错误
+func (e *Error) Is(target error) bool {
+	var other *Error
+	if !errors.As(target, &other) {
+		return false
+	}
+	return other.Code == e.Code
+}
+
+// CodeOf gets back the code of the first coded error in the chain, blank when none
+// CodeOf 返回错误链中第一个带错误码错误的错误码，没有时返回空值
+func CodeOf(err error) Code {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ""
+}
+
+// WithErrorLanguage sets the language of error messages surfaced to callers
+// WithErrorLanguage 设置返回给调用方的错误消息语言
+func (o *Suo) WithErrorLanguage(language Language) *Suo {
+	o.language = language
+	return o
+}
+
+// ErrorLanguage gets back the language of error messages, used through the runner
+// ErrorLanguage 返回错误消息语言，供运行器使用
+func (o *Suo) ErrorLanguage() Language {
+	return o.language
+}
+
+// WithErrorLanguage sets the language of error messages in locks created through the manager
+// WithErrorLanguage 设置通过管理器创建的锁的错误消息语言
+func (m *Manager) WithErrorLanguage(language Language) *Manager {
+	m.language = language
+	return m
+}
+
+// newError creates a coded error in the lock's language
+// newError 使用锁的语言创建带错误码的错误
+func (o *Suo) newError(code Code) *Error {
+	return NewError(code, o.language, nil)
+}
@@ -0,0 +1,57 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewError validates code rendering and matching across languages
+// Tests that errors with the same code match whatever the message language
+//
+// TestNewError 验证错误码输出以及跨语言匹配
+// 测试错误码相同的错误无论消息语言如何都能匹配
+func TestNewError(t *testing.T) {
+	cause := errors.New("boom")
+
+	erk := redissuo.NewError(redissuo.CodePanicRecovered, redissuo.LanguageEnglish, cause)
+	require.Equal(t, "SUO_PANIC_RECOVERED: recovered from panic: boom", erk.Error())
+	require.ErrorIs(t, erk, cause)
+
+	erc := redissuo.NewError(redissuo.CodePanicRecovered, redissuo.LanguageChinese, nil)
+	require.ErrorIs(t, erk, erc)
+	require.NotErrorIs(t, erk, redissuo.ErrGuardRejected)
+
+	require.Equal(t, redissuo.CodePanicRecovered, redissuo.CodeOf(errors.WithMessage(erk, "wrapped")))
+	require.Equal(t, redissuo.Code(""), redissuo.CodeOf(cause))
+}
+
+// TestManager_WithErrorLanguage validates the language passes to locks from the manager
+// Tests that strict rejection surfaces the English message with its code
+//
+// TestManager_WithErrorLanguage 验证语言会传递给管理器创建的锁
+// 测试严格模式拒绝时返回带错误码的英文消息
+func TestManager_WithErrorLanguage(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithErrorLanguage(redissuo.LanguageEnglish)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second).WithStrict(true)
+	require.Equal(t, redissuo.LanguageEnglish, suo.ErrorLanguage())
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	_, err = suo.AcquireLockWithSession(ctx, xin.SessionUUID())
+	require.ErrorIs(t, err, redissuo.ErrAlreadyHeld)
+	require.Equal(t, redissuo.CodeAlreadyHeld, redissuo.CodeOf(err))
+	require.Contains(t, err.Error(), "SUO_ALREADY_HELD: lock already held through the same session")
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
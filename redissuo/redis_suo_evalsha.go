@@ -0,0 +1,46 @@
+package redissuo
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// scriptCache holds the go-redis script of each Lua command, keyed by source, so the SHA1 is computed once
+// scriptCache 保存每个 Lua 命令对应的 go-redis 脚本，以源码为键，使 SHA1 只计算一次
+var scriptCache sync.Map
+
+// scriptOf gets back the cached script of the command
+// scriptOf 返回该命令的缓存脚本
+func scriptOf(command string) *redis.Script {
+	if script, ok := scriptCache.Load(command); ok {
+		return script.(*redis.Script)
+	}
+	script, _ := scriptCache.LoadOrStore(command, redis.NewScript(command))
+	return script.(*redis.Script)
+}
+
+// PreloadScripts loads the scripts of the package into the Redis script cache, e.g. at startup
+// Hot paths then run through EVALSHA from the first call, scripts missing later still fall back to EVAL
+//
+// PreloadScripts 将包中的脚本加载到 Redis 脚本缓存中，例如在启动时调用
+// 热路径从首次调用起即可通过 EVALSHA 执行，之后缺失的脚本仍会回退到 EVAL
+func (o *Suo) PreloadScripts(ctx context.Context) error {
+	scripts := Scripts()
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := scriptOf(scripts[name]).Load(ctx, o.client()).Err(); err != nil {
+			o.logger.ErrorLog("预加载脚本报错", zap.String("k", o.key), zap.String("script", name), zap.Error(err))
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
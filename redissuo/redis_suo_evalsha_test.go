@@ -0,0 +1,37 @@
+package redissuo_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_PreloadScripts validates the scripts land in the Redis script cache and lock operations survive a flushed cache
+// TestSuo_PreloadScripts 验证脚本进入 Redis 脚本缓存，且脚本缓存被清空后锁操作依然正常
+func TestSuo_PreloadScripts(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	require.NoError(t, caseRedisClient.ScriptFlush(ctx).Err())
+	require.NoError(t, suo.PreloadScripts(ctx))
+
+	sum := sha1.Sum([]byte(redissuo.Scripts()[redissuo.ScriptRelease]))
+	exists, err := caseRedisClient.ScriptExists(ctx, hex.EncodeToString(sum[:])).Result()
+	require.NoError(t, err)
+	require.Equal(t, []bool{true}, exists)
+
+	// NOSCRIPT falls back to EVAL
+	require.NoError(t, caseRedisClient.ScriptFlush(ctx).Err())
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,299 @@
+package redissuo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/pkg/errors"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// EventKind names a step in the lock lifecycle
+// EventKind 表示锁生命周期中的一个步骤
+type EventKind string
+
+const (
+	EventAcquired EventKind = "acquired" // Lock acquired // 锁已获取
+	EventExtended EventKind = "extended" // Lease extended through the same session // 同一会话延长了租期
+	EventReleased EventKind = "released" // Lock released // 锁已释放
+)
+
+// Event is one serialized lock lifecycle event delivered to sinks
+// HeldFor spans extensions, so sinks can raise alerts on long holds
+// The JSON form follows EventSchema, Version names the schema revision
+//
+// Event 是投递给接收端的单个序列化锁生命周期事件
+// HeldFor 跨越延期计算，接收端可据此对长时间持有发出告警
+// JSON 形式遵循 EventSchema，Version 表示模式的修订版本
+type Event struct {
+	Version   int           `json:"version"`             // Schema revision, set on emit // 模式修订版本，发出时设置
+	Kind      EventKind     `json:"kind"`                // Lifecycle step // 生命周期步骤
+	Key       string        `json:"key"`                 // Lock name ID // 锁名标识符
+	Session   string        `json:"session"`             // Session UUID // 会话 UUID
+	Time      time.Time     `json:"time"`                // Time of the event // 事件时间
+	HeldFor   time.Duration `json:"held_for,omitempty"`  // Hold duration so far, blank on acquisition // 到目前为止的持有时长，获取时为空
+	Operation string        `json:"operation,omitempty"` // Slow operation name, blank outside EventSlowOperation // 慢操作名称，非 EventSlowOperation 时为空
+	Latency   time.Duration `json:"latency,omitempty"`   // Slow round trip, blank outside EventSlowOperation // 慢往返延迟，非 EventSlowOperation 时为空
+	Forensic  *Forensic     `json:"forensic,omitempty"`  // Usurper snapshot, nil outside EventStolen // 抢占者快照，非 EventStolen 时为 nil
+	Status    string        `json:"status,omitempty"`    // Script status name, e.g. "missing" on EventReleased // 脚本状态名称，例如 EventReleased 上的 "missing"
+	Operator  string        `json:"operator,omitempty"`  // Host and PID forcing the release, blank outside EventForceReleased // 强制释放的主机和 PID，非 EventForceReleased 时为空
+}
+
+// EventSink receives batches of lock events, e.g. a webhook or a Kafka producer
+// Returning a problem makes the dispatcher send the same batch again
+//
+// EventSink 接收批量锁事件，例如 webhook 或 Kafka 生产者
+// 返回错误时分发器会重新发送同一批事件
+type EventSink interface {
+	Send(ctx context.Context, events []*Event) error
+}
+
+// EventSinkFunc adapts a plain function into an EventSink
+// EventSinkFunc 将普通函数适配为 EventSink
+type EventSinkFunc func(ctx context.Context, events []*Event) error
+
+// Send calls the function
+// Send 调用该函数
+func (f EventSinkFunc) Send(ctx context.Context, events []*Event) error {
+	return f(ctx, events)
+}
+
+// WebhookSink posts each batch as a JSON array to an HTTP endpoint
+// WebhookSink 将每批事件以 JSON 数组形式 POST 到 HTTP 端点
+type WebhookSink struct {
+	url        string       // Endpoint URL // 端点 URL
+	httpClient *http.Client // HTTP client // HTTP 客户端
+}
+
+// NewWebhookSink creates a webhook sink posting to the given URL
+// NewWebhookSink 创建向给定 URL 发送的 webhook 接收端
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:        must.Nice(url),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithHTTPClient sets the HTTP client used in posting
+// WithHTTPClient 设置发送时使用的 HTTP 客户端
+func (w *WebhookSink) WithHTTPClient(httpClient *http.Client) *WebhookSink {
+	w.httpClient = must.Nice(httpClient)
+	return w
+}
+
+// Send posts the batch and treats non-2xx responses as failures
+// Send 发送该批事件，非 2xx 响应视为失败
+func (w *WebhookSink) Send(ctx context.Context, events []*Event) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return errors.WithMessage(err, "marshal events")
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return errors.WithMessage(err, "new request")
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := w.httpClient.Do(request)
+	if err != nil {
+		return errors.WithMessage(err, "post events")
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("webhook status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// EventDispatcher buffers lock events and delivers them to a sink in batches
+// Emitting never blocks lock operations, events are dropped when the buffer is full
+// Failed batches are sent again with a fixed backoff up to the retry limit
+//
+// EventDispatcher 缓冲锁事件并分批投递给接收端
+// 发出事件不会阻塞锁操作，缓冲区满时丢弃事件
+// 失败的批次按固定退避重新发送，直到达到重试上限
+type EventDispatcher struct {
+	sink          EventSink      // Destination of batches // 批次的目标
+	events        chan *Event    // Buffered events // 缓冲的事件
+	batchSize     int            // Max events in one batch // 单批最大事件数
+	flushInterval time.Duration  // Max wait ahead of sending a partial batch // 发送不满批次前的最大等待时间
+	retries       int            // Send attempts past the first one // 首次之后的发送重试次数
+	backoff       time.Duration  // Wait between attempts // 重试之间的等待时间
+	logger        logging.Logger // Logger instance // 日志记录器实例
+	startOnce     sync.Once      // Guards Start // 保护 Start
+	closeOnce     sync.Once      // Guards Close // 保护 Close
+	done          chan struct{}  // Closed when the loop exits // 循环退出时关闭
+}
+
+// NewEventDispatcher creates a dispatcher buffering up to the given number of events
+// Call Start to begin delivery and Close to flush the rest
+//
+// NewEventDispatcher 创建最多缓冲给定数量事件的分发器
+// 调用 Start 开始投递，调用 Close 发送剩余事件
+func NewEventDispatcher(sink EventSink, bufferSize int) *EventDispatcher {
+	return &EventDispatcher{
+		sink:          must.Nice(sink),
+		events:        make(chan *Event, must.Nice(bufferSize)),
+		batchSize:     100,
+		flushInterval: time.Second,
+		retries:       3,
+		backoff:       time.Second,
+		logger:        logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+		done:          make(chan struct{}),
+	}
+}
+
+// WithBatch sets the max batch size and the max wait ahead of sending a partial batch
+// WithBatch 设置最大批次大小以及发送不满批次前的最大等待时间
+func (d *EventDispatcher) WithBatch(batchSize int, flushInterval time.Duration) *EventDispatcher {
+	d.batchSize = must.Nice(batchSize)
+	d.flushInterval = must.Nice(flushInterval)
+	return d
+}
+
+// WithRetry sets the number of send retries and the wait between them
+// WithRetry 设置发送重试次数以及重试之间的等待时间
+func (d *EventDispatcher) WithRetry(retries int, backoff time.Duration) *EventDispatcher {
+	d.retries = retries
+	d.backoff = backoff
+	return d
+}
+
+// WithLogger sets custom logger used in reporting dropped events and failed batches
+// WithLogger 设置用于报告丢弃事件和失败批次的自定义日志记录器
+func (d *EventDispatcher) WithLogger(logger logging.Logger) *EventDispatcher {
+	d.logger = logger
+	return d
+}
+
+// Start launches the delivery loop in a background goroutine
+// Start 在后台 goroutine 中启动投递循环
+func (d *EventDispatcher) Start() *EventDispatcher {
+	d.startOnce.Do(func() {
+		go d.loop()
+	})
+	return d
+}
+
+// Close stops accepting events and waits until buffered events are delivered or ctx ends
+// Close 停止接收事件并等待缓冲事件投递完成或 ctx 结束
+func (d *EventDispatcher) Close(ctx context.Context) error {
+	d.closeOnce.Do(func() {
+		close(d.events)
+	})
+	d.Start()
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return erero.Wro(ctx.Err())
+	}
+}
+
+// Emit queues one event without blocking, dropping it when the buffer is full
+// Events without a version get the current EventSchemaVersion
+//
+// Emit 非阻塞地排队一个事件，缓冲区满时丢弃
+// 未设置版本的事件使用当前的 EventSchemaVersion
+func (d *EventDispatcher) Emit(event *Event) {
+	if event.Version == 0 {
+		event.Version = EventSchemaVersion
+	}
+	defer func() {
+		// Sending on a closed channel panics once Close is called, drop the event then
+		// 调用 Close 后向已关闭的通道发送会 panic，此时丢弃事件
+		if recover() != nil {
+			d.logger.ErrorLog("锁事件已关闭-丢弃事件", zap.String("k", event.Key), zap.String("v", event.Session))
+		}
+	}()
+	select {
+	case d.events <- event:
+	default:
+		d.logger.ErrorLog("锁事件缓冲已满-丢弃事件", zap.String("k", event.Key), zap.String("v", event.Session))
+	}
+}
+
+// loop collects events into batches and sends them when full or on each flush tick
+// loop 将事件收集成批，在批次满或每次刷新时发送
+func (d *EventDispatcher) loop() {
+	defer close(d.done)
+	ticker := time.NewTicker(d.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Event, 0, d.batchSize)
+	for {
+		select {
+		case event, ok := <-d.events:
+			if !ok {
+				d.send(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= d.batchSize {
+				d.send(batch)
+				batch = make([]*Event, 0, d.batchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				d.send(batch)
+				batch = make([]*Event, 0, d.batchSize)
+			}
+		}
+	}
+}
+
+// send delivers one batch, retrying with backoff and dropping it past the retry limit
+// send 投递一批事件，按退避重试，超过重试上限后丢弃
+func (d *EventDispatcher) send(batch []*Event) {
+	if len(batch) == 0 {
+		return
+	}
+	for attempt := 0; ; attempt++ {
+		err := d.sink.Send(context.Background(), batch)
+		if err == nil {
+			return
+		}
+		if attempt >= d.retries {
+			d.logger.ErrorLog("锁事件发送失败-丢弃批次", zap.Int("count", len(batch)), zap.Error(err))
+			return
+		}
+		d.logger.DebugLog("锁事件发送失败-稍后重试", zap.Int("attempt", attempt+1), zap.Error(err))
+		time.Sleep(d.backoff)
+	}
+}
+
+// WithEvents sets the dispatcher receiving lifecycle events of this lock
+// WithEvents 设置接收该锁生命周期事件的分发器
+func (o *Suo) WithEvents(dispatcher *EventDispatcher) *Suo {
+	o.events = dispatcher
+	return o
+}
+
+// WithEvents sets the dispatcher receiving lifecycle events of locks created through the manager
+// WithEvents 设置接收通过管理器创建的锁的生命周期事件的分发器
+func (m *Manager) WithEvents(dispatcher *EventDispatcher) *Manager {
+	m.events = dispatcher
+	return m
+}
+
+// emit sends one lifecycle event when a dispatcher is configured
+// emit 在配置了分发器时发送一个生命周期事件
+func (o *Suo) emit(kind EventKind, sessionUUID string, heldFor time.Duration) {
+	o.emitStatus(kind, sessionUUID, heldFor, "")
+}
+
+// emitStatus sends one lifecycle event carrying the status name of the script behind it
+// emitStatus 发送一个携带其背后脚本状态名称的生命周期事件
+func (o *Suo) emitStatus(kind EventKind, sessionUUID string, heldFor time.Duration, status string) {
+	if o.events == nil {
+		return
+	}
+	o.events.Emit(&Event{Kind: kind, Key: o.key, Session: sessionUUID, Time: o.clock.Now(), HeldFor: heldFor, Status: status})
+}
@@ -0,0 +1,97 @@
+package redissuo_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithEvents validates lifecycle events reach the sink in order
+// Tests that a failing first send is retried with the same batch
+//
+// TestSuo_WithEvents 验证生命周期事件按顺序到达接收端
+// 测试首次发送失败时会使用同一批次重试
+func TestSuo_WithEvents(t *testing.T) {
+	var mutex sync.Mutex
+	var events []*redissuo.Event
+	var attempts int
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		attempts++
+		if attempts == 1 {
+			return errors.New("sink unavailable")
+		}
+		events = append(events, batch...)
+		return nil
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16).
+		WithBatch(10, 10*time.Millisecond).
+		WithRetry(2, time.Millisecond)
+
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithEvents(dispatcher)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Equal(t, 2, attempts)
+	require.Len(t, events, 3)
+	require.Equal(t, redissuo.EventAcquired, events[0].Kind)
+	require.Equal(t, redissuo.EventExtended, events[1].Kind)
+	require.Equal(t, redissuo.EventReleased, events[2].Kind)
+	for _, event := range events {
+		require.Equal(t, suo.Key(), event.Key)
+		require.Equal(t, xin.SessionUUID(), event.Session)
+	}
+}
+
+// TestWebhookSink validates batches are posted as a JSON array
+// Tests that non-2xx responses surface as send failures
+//
+// TestWebhookSink 验证批次以 JSON 数组形式发送
+// 测试非 2xx 响应会作为发送失败返回
+func TestWebhookSink(t *testing.T) {
+	var received []*redissuo.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if len(received) > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	sink := redissuo.NewWebhookSink(server.URL)
+	event := &redissuo.Event{Kind: redissuo.EventReleased, Key: "demo", Session: "abc", Time: time.Now(), HeldFor: time.Minute}
+
+	require.NoError(t, sink.Send(context.Background(), []*redissuo.Event{event}))
+	require.Len(t, received, 1)
+	require.Equal(t, redissuo.EventReleased, received[0].Kind)
+	require.Equal(t, time.Minute, received[0].HeldFor)
+
+	require.Error(t, sink.Send(context.Background(), []*redissuo.Event{event, event}))
+}
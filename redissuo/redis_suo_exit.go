@@ -0,0 +1,165 @@
+package redissuo
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/yyle88/erero"
+)
+
+// ExitReleaser keeps the live sessions of this process so they can be released at exit
+// Sessions join on acquisition and leave on release, extensions keep their place
+// A graceful shutdown calls ReleaseAll, so peers take over at once instead of waiting out the TTL
+//
+// ExitReleaser 记录本进程的存活会话，以便在退出时释放
+// 会话在获取时加入、释放时离开，延期时保留原有位置
+// 优雅退出时调用 ReleaseAll，使其它进程立即接管而无需等待 TTL 过期
+type ExitReleaser struct {
+	mutex   sync.Mutex   // Protects entries // 保护条目
+	entries []scopeEntry // Live sessions in sequence of acquisition // 按获取顺序排列的存活会话
+}
+
+// NewExitReleaser creates a blank exit releaser
+// NewExitReleaser 创建空的退出释放器
+func NewExitReleaser() *ExitReleaser {
+	return &ExitReleaser{}
+}
+
+// WithExitReleaser puts sessions acquired through the lock into the exit releaser
+// WithExitReleaser 将通过该锁获取的会话放入退出释放器
+func (o *Suo) WithExitReleaser(releaser *ExitReleaser) *Suo {
+	o.exitReleaser = releaser
+	return o
+}
+
+// WithExitReleaser puts sessions of locks created through the manager into the exit releaser
+// WithExitReleaser 将通过管理器创建的锁的会话放入退出释放器
+func (m *Manager) WithExitReleaser(releaser *ExitReleaser) *Manager {
+	m.exitReleaser = releaser
+	return m
+}
+
+// trackLive hands the session to the hold set of the manager and the exit releaser when set
+// trackLive 将会话交给管理器的持有集合以及已设置的退出释放器
+func (o *Suo) trackLive(xin *Xin) {
+	if o.holds != nil {
+		o.holds.put(xin)
+	}
+	if o.exitReleaser != nil {
+		o.exitReleaser.track(o, xin)
+	}
+}
+
+// forgetLive takes the session out of the hold set of the manager and the exit releaser when set
+// forgetLive 将会话从管理器的持有集合以及已设置的退出释放器中移除
+func (o *Suo) forgetLive(xin *Xin) {
+	if o.holds != nil {
+		o.holds.drop(xin)
+	}
+	if o.exitReleaser != nil {
+		o.exitReleaser.forget(o, xin)
+	}
+}
+
+// track adds a fresh session, or swaps in the extended session of the same hold
+// track 加入新会话，或替换为同一持有的延期后会话
+func (r *ExitReleaser) track(suo *Suo, xin *Xin) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for idx := range r.entries {
+		if r.entries[idx].suo == suo && r.entries[idx].xin.sessionUUID == xin.sessionUUID {
+			r.entries[idx].xin = xin
+			return
+		}
+	}
+	r.entries = append(r.entries, scopeEntry{suo: suo, xin: xin})
+}
+
+// forget removes the session once released
+// forget 在会话释放后将其移除
+func (r *ExitReleaser) forget(suo *Suo, xin *Xin) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for idx := range r.entries {
+		if r.entries[idx].suo == suo && r.entries[idx].xin.sessionUUID == xin.sessionUUID {
+			r.entries = append(r.entries[:idx], r.entries[idx+1:]...)
+			return
+		}
+	}
+}
+
+// Size gets back the count of live sessions
+// Size 返回存活会话的数量
+func (r *ExitReleaser) Size() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.entries)
+}
+
+// ReleaseAll releases each live session in reverse sequence of acquisition, best-effort
+// Continues past failures and gives back the joined problems, nil when all got released
+// Meant as the shutdown hook, the releaser is empty afterwards
+//
+// ReleaseAll 按获取顺序的逆序尽力释放每个存活会话
+// 遇到失败继续执行并返回合并后的错误，全部释放成功时返回 nil
+// 用作退出钩子，执行后释放器为空
+func (r *ExitReleaser) ReleaseAll(ctx context.Context) error {
+	r.mutex.Lock()
+	entries := r.entries
+	r.entries = nil
+	r.mutex.Unlock()
+
+	var errs []error
+	for idx := len(entries) - 1; idx >= 0; idx-- {
+		entry := entries[idx]
+		success, err := entry.suo.Release(ctx, entry.xin)
+		if err != nil {
+			errs = append(errs, erero.WithMessagef(err, "release %s", entry.xin.key))
+		} else if !success {
+			errs = append(errs, erero.Errorf("release %s: lock owned through a different session", entry.xin.key))
+		}
+	}
+	return erero.Joins(errs)
+}
+
+// ReleaseOnSignal releases all live sessions when the process receives one of the signals
+// Defaults to SIGTERM and interrupt, each release pass is bounded through the timeout
+// The signal is then delivered again past this watcher, so the process still exits without other handlers
+// Gives back a function that stops watching the signals
+//
+// ReleaseOnSignal 在进程收到指定信号时释放全部存活会话
+// 默认监听 SIGTERM 和中断信号，每轮释放受超时时间限制
+// 随后绕过本监听再次投递该信号，没有其它处理器时进程仍会退出
+// 返回停止监听信号的函数
+func (r *ExitReleaser) ReleaseOnSignal(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	channel := make(chan os.Signal, 1)
+	signal.Notify(channel, signals...)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-channel:
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			_ = r.ReleaseAll(ctx) // Problems are logged through each lock // 错误已由各个锁记录日志
+			cancel()
+			signal.Stop(channel)
+			if process, err := os.FindProcess(os.Getpid()); err == nil {
+				_ = process.Signal(sig)
+			}
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(channel)
+			close(done)
+		})
+	}
+}
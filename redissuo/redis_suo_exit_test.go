@@ -0,0 +1,53 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExitReleaser_ReleaseAll validates sessions still held at exit get released, released ones leave the releaser
+// TestExitReleaser_ReleaseAll 验证退出时仍持有的会话被释放，已释放的会话离开释放器
+func TestExitReleaser_ReleaseAll(t *testing.T) {
+	ctx := context.Background()
+	releaser := redissuo.NewExitReleaser()
+	manager := redissuo.NewManager(caseRedisClient).WithExitReleaser(releaser)
+
+	suo1 := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	suo2 := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	xin1, err := suo1.Acquire(ctx)
+	require.NoError(t, err)
+	xin2, err := suo2.Acquire(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, releaser.Size())
+
+	// Extension keeps the single entry of the hold
+	_, err = suo1.AcquireAgainExtendLock(ctx, xin1)
+	require.NoError(t, err)
+	require.Equal(t, 2, releaser.Size())
+
+	success, err := suo2.Release(ctx, xin2)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Equal(t, 1, releaser.Size())
+
+	require.NoError(t, releaser.ReleaseAll(ctx))
+	require.Equal(t, 0, releaser.Size())
+
+	infos, err := manager.InspectMany(ctx, suo1.Key())
+	require.NoError(t, err)
+	require.False(t, infos[0].Held())
+}
+
+// TestExitReleaser_ReleaseOnSignal validates the stop function detaches the watcher
+// TestExitReleaser_ReleaseOnSignal 验证停止函数能解除信号监听
+func TestExitReleaser_ReleaseOnSignal(t *testing.T) {
+	releaser := redissuo.NewExitReleaser()
+	stop := releaser.ReleaseOnSignal(time.Second)
+	stop()
+	stop()
+}
@@ -0,0 +1,123 @@
+package redissuo
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventExpiringSoon marks a held lock whose lease dropped below the warning threshold without extension
+// EventExpiringSoon 表示已持有锁的租期低于警告阈值且未被延期
+const EventExpiringSoon EventKind = "expiring_soon"
+
+// WithExpiringSoon warns when the remaining lease of a held session drops below the threshold
+// and no extension has succeeded, giving the holder a chance to checkpoint and stop cleanly
+// The handler runs on a timer goroutine and may be nil, Xin.ExpiringSoon closes either way
+//
+// WithExpiringSoon 在已持有会话的剩余租期低于阈值且没有成功延期时发出警告
+// 使持有者有机会保存进度并干净地停止
+// 处理函数在定时器 goroutine 中执行且可以为空，无论如何 Xin.ExpiringSoon 都会关闭
+func (o *Suo) WithExpiringSoon(threshold time.Duration, handler func(xin *Xin)) *Suo {
+	o.expiringSoon = threshold
+	o.onExpiringSoon = handler
+	return o
+}
+
+// expiryWatch fires once when the lease of a hold runs low, extensions push it back
+// expiryWatch 在持有的租期即将耗尽时触发一次，延期会推迟触发时间
+type expiryWatch struct {
+	mutex  sync.Mutex    // Guards the fields below // 保护下面的字段
+	timer  Timer         // Fires at expiry minus threshold // 在过期前阈值时刻触发
+	fired  bool          // Warning already sent // 警告已发出
+	closed chan struct{} // Closed when the warning fires // 警告触发时关闭
+}
+
+// ExpiringSoon gets back a channel closed once the lease runs low without extension
+// Gives back nil, which blocks forever in select, when WithExpiringSoon was not set
+//
+// ExpiringSoon 返回一个通道，在租期即将耗尽且未延期时关闭
+// 未设置 WithExpiringSoon 时返回 nil，在 select 中会永久阻塞
+func (s *Xin) ExpiringSoon() <-chan struct{} {
+	if s.expiry == nil {
+		return nil
+	}
+	return s.expiry.closed
+}
+
+// watchExpiry arms the warning of a fresh hold
+// watchExpiry 为新的持有设置警告
+func (o *Suo) watchExpiry(xin *Xin) {
+	if o.expiringSoon <= 0 {
+		return
+	}
+	watch := &expiryWatch{closed: make(chan struct{})}
+	watch.timer = o.clock.AfterFunc(o.expiryDelay(xin), func() {
+		o.fireExpiry(watch, xin)
+	})
+	xin.expiry = watch
+}
+
+// extendExpiry pushes the warning back past a successful extension, re-arming it when it fired already
+// extendExpiry 在成功延期后推迟警告，若警告已触发则重新设置
+func (o *Suo) extendExpiry(xin *Xin, res *Xin) {
+	watch := xin.expiry
+	if watch == nil {
+		return
+	}
+	watch.mutex.Lock()
+	defer watch.mutex.Unlock()
+	if watch.fired {
+		o.watchExpiry(res)
+		return
+	}
+	watch.timer.Stop()
+	watch.timer = o.clock.AfterFunc(o.expiryDelay(res), func() {
+		o.fireExpiry(watch, res)
+	})
+	res.expiry = watch
+}
+
+// stopExpiry disarms the warning once the hold ends
+// stopExpiry 在持有结束后取消警告
+func (o *Suo) stopExpiry(xin *Xin) {
+	if watch := xin.expiry; watch != nil {
+		watch.mutex.Lock()
+		defer watch.mutex.Unlock()
+		watch.timer.Stop()
+	}
+}
+
+// expiryDelay gets back the wait until the lease of the session drops below the threshold
+// expiryDelay 返回会话租期降至阈值以下之前的等待时长
+func (o *Suo) expiryDelay(xin *Xin) time.Duration {
+	return max(xin.expire.Sub(o.clock.Now())-o.expiringSoon, 0)
+}
+
+// warnExpiry fires the warning of the hold at once, meant once the hold can no longer be extended
+// warnExpiry 立即触发持有的警告，适用于持有已无法再延期时
+func (o *Suo) warnExpiry(xin *Xin) {
+	if watch := xin.expiry; watch != nil {
+		o.stopExpiry(xin)
+		o.fireExpiry(watch, xin)
+	}
+}
+
+// fireExpiry sends the warning to the log, the events, the channel and the handler
+// fireExpiry 将警告发送到日志、事件、通道和处理函数
+func (o *Suo) fireExpiry(watch *expiryWatch, xin *Xin) {
+	watch.mutex.Lock()
+	if watch.fired {
+		watch.mutex.Unlock()
+		return
+	}
+	watch.fired = true
+	close(watch.closed)
+	watch.mutex.Unlock()
+
+	o.logger.ErrorLog("锁即将过期-未延期", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Duration("threshold", o.expiringSoon))
+	o.emit(EventExpiringSoon, xin.sessionUUID, o.clock.Now().Sub(xin.acquiredAt))
+	if o.onExpiringSoon != nil {
+		o.onExpiringSoon(xin)
+	}
+}
@@ -0,0 +1,64 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithExpiringSoon validates the warning fires when the lease runs low and extensions push it back
+// TestSuo_WithExpiringSoon 验证租期即将耗尽时触发警告，延期会推迟警告
+func TestSuo_WithExpiringSoon(t *testing.T) {
+	ctx := context.Background()
+	var count atomic.Int64
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 200*time.Millisecond).
+		WithExpiringSoon(100*time.Millisecond, func(xin *redissuo.Xin) { count.Add(1) })
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	time.Sleep(60 * time.Millisecond)
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	// The extension pushed the warning past the first deadline
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, int64(0), count.Load())
+
+	select {
+	case <-xin.ExpiringSoon():
+	case <-time.After(time.Second):
+		require.Fail(t, "expiring warning did not fire")
+	}
+	require.Eventually(t, func() bool { return count.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	_, err = suo.Release(ctx, xin)
+	require.NoError(t, err)
+}
+
+// TestSuo_WithExpiringSoon_Released validates a released session never gets the warning
+// TestSuo_WithExpiringSoon_Released 验证已释放的会话不会收到警告
+func TestSuo_WithExpiringSoon_Released(t *testing.T) {
+	ctx := context.Background()
+	var count atomic.Int64
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 100*time.Millisecond).
+		WithExpiringSoon(50*time.Millisecond, func(xin *redissuo.Xin) { count.Add(1) })
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, int64(0), count.Load())
+}
@@ -0,0 +1,71 @@
+package redissuo
+
+import (
+	"math"
+	"time"
+
+	"github.com/yyle88/must"
+)
+
+// WithExtendThreshold sets the fraction of the TTL below which the remaining lease calls for extension
+// KeepAlive and the auto extension of redissuorun consult ShouldExtend on each tick, so short jobs skip extensions they never need
+// A fraction of 0 disables the policy, each extension then goes ahead
+//
+// WithExtendThreshold 设置剩余租期低于 TTL 的多少比例时才需要延期
+// KeepAlive 和 redissuorun 的自动延期在每个间隔通过 ShouldExtend 判断，使短任务跳过不需要的延期
+// 比例为 0 时禁用该策略，每次延期都会执行
+func (o *Suo) WithExtendThreshold(fraction float64) *Suo {
+	must.True(fraction >= 0 && fraction <= 1)
+	o.extendFraction = fraction
+	return o
+}
+
+// ShouldExtend reports whether the remaining lease of the session dropped below the extend threshold
+// Always true when no threshold is set, so manual extenders can call it unconditionally
+//
+// ShouldExtend 判断会话的剩余租期是否已低于延期阈值
+// 未设置阈值时始终为 true，因此手动延期的调用方可以无条件调用
+func (o *Suo) ShouldExtend(xin *Xin) bool {
+	if o.extendFraction <= 0 {
+		return true
+	}
+	threshold := time.Duration(float64(o.ttl) * o.extendFraction)
+	return xin.expire.Sub(o.clock.Now()) < threshold
+}
+
+// extendWait gets back how long until the remaining lease of the session drops below the extend threshold
+// Renewal loops skipping a tick wait no longer than this, so the skip never carries the lease past the threshold
+//
+// extendWait 返回会话剩余租期还需多久才会低于延期阈值
+// 续期循环跳过某个间隔后等待的时长不超过该值，避免跳过使租期越过阈值
+func (o *Suo) extendWait(xin *Xin) time.Duration {
+	threshold := time.Duration(float64(o.ttl) * o.extendFraction)
+	return max(xin.expire.Sub(o.clock.Now())-threshold, 0)
+}
+
+// WithGrowingTTL makes each successive extension apply a larger lease, the TTL times factor per extension
+// Leases stop growing at maxTTL, so long jobs renew less often while short jobs keep short leases
+// A factor of 1 or below disables the growth
+//
+// WithGrowingTTL 使每次后续延期使用更长的租期，每次延期在 TTL 基础上乘以 factor
+// 租期增长到 maxTTL 为止，使长任务减少续期次数而短任务保持短租期
+// factor 不大于 1 时禁用增长
+func (o *Suo) WithGrowingTTL(factor float64, maxTTL time.Duration) *Suo {
+	must.True(factor <= 1 || maxTTL >= o.ttl)
+	o.growFactor = factor
+	o.growMaxTTL = maxTTL
+	return o
+}
+
+// growTTL gets back the lease of the given extension, counting from 1
+// growTTL 返回第几次延期（从 1 开始计数）的租期
+func (o *Suo) growTTL(extension int) time.Duration {
+	if o.growFactor <= 1 {
+		return o.ttl
+	}
+	lease := float64(o.ttl) * math.Pow(o.growFactor, float64(extension))
+	if lease >= float64(o.growMaxTTL) {
+		return o.growMaxTTL
+	}
+	return time.Duration(lease)
+}
@@ -0,0 +1,62 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ShouldExtend validates extension is due only once the remaining lease drops below the threshold
+// TestSuo_ShouldExtend 验证仅当剩余租期低于阈值时才需要延期
+func TestSuo_ShouldExtend(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 200*time.Millisecond).WithExtendThreshold(0.5)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.False(t, suo.ShouldExtend(xin))
+
+	time.Sleep(120 * time.Millisecond)
+	require.True(t, suo.ShouldExtend(xin))
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.False(t, suo.ShouldExtend(xin))
+
+	// Without threshold each extension goes ahead
+	require.True(t, suo.WithExtendThreshold(0).ShouldExtend(xin))
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_WithGrowingTTL validates each extension applies a larger lease up to the cap
+// TestSuo_WithGrowingTTL 验证每次延期使用更长的租期直到上限
+func TestSuo_WithGrowingTTL(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithGrowingTTL(2, 3*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 0, xin.Extensions())
+
+	for _, lease := range []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+		require.WithinDuration(t, time.Now().Add(lease), xin.Expire(), 50*time.Millisecond)
+	}
+	require.Equal(t, 3, xin.Extensions())
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,68 @@
+package redissuo
+
+import (
+	"context"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// defaultSafetyFactor is the lease per unit of the remaining work estimate unless set through WithSafetyFactor
+// defaultSafetyFactor 是未通过 WithSafetyFactor 设置时每单位剩余工作预估对应的租期倍数
+const defaultSafetyFactor = 1.5
+
+// WithSafetyFactor sets the lease ExtendFor grants per unit of the remaining work estimate
+// The factor must be at least 1, leaving headroom when the work runs late
+//
+// WithSafetyFactor 设置 ExtendFor 每单位剩余工作预估所授予的租期倍数
+// 倍数不能小于 1，为工作延迟留出余量
+func (o *Suo) WithSafetyFactor(factor float64) *Suo {
+	must.True(factor >= 1)
+	o.safetyFactor = factor
+	return o
+}
+
+// ExtendFor extends the held session by the remaining work estimate times the safety factor
+// The estimate is written into the metadata companion, so inspections show why the lease got its length
+// The lease is clamped to the max hold duration like other extensions, nil when the lock was lost
+//
+// ExtendFor 按剩余工作预估乘以安全倍数延期所持会话
+// 预估值写入元数据伴随键，使检查时可以看到租期长度的由来
+// 租期与其它延期一样受最大持有时长限制，锁已丢失时返回 nil
+func (o *Suo) ExtendFor(ctx context.Context, xin *Xin, estimatedRemaining time.Duration) (*Xin, error) {
+	return o.typedExtend(o.extendFor(ctx, xin, estimatedRemaining))
+}
+
+// extendFor is ExtendFor without typed errors, nil once the lock was lost
+// extendFor 是不带类型化错误的 ExtendFor，锁已丢失时返回 nil
+func (o *Suo) extendFor(ctx context.Context, xin *Xin, estimatedRemaining time.Duration) (*Xin, error) {
+	o.checkOwner(xin)
+	must.Equals(xin.key, o.key)
+	must.True(estimatedRemaining > 0)
+	if err := o.throttleExtend(xin); err != nil {
+		return nil, erero.Wro(err)
+	}
+	lease := max(time.Duration(float64(estimatedRemaining)*o.safetyFactor), time.Millisecond)
+	ttl, err := o.clampHold(xin, lease)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := o.verifyFailover(ctx, xin); err != nil {
+		return nil, erero.Wro(err)
+	}
+	o.logger.DebugLog("按预估延期", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Duration("estimate", estimatedRemaining), zap.Duration("ttl", ttl))
+	res, err := o.acquireLockWith(ctx, xin.sessionUUID, &acquireRequest{ttl: ttl, extend: true, continues: xin.continues, estimate: estimatedRemaining})
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if res != nil {
+		o.carryExtension(ctx, xin, res)
+		o.syncTemps(ctx, res)
+	} else {
+		o.loseLease(xin)
+		o.captureStolen(ctx, xin, "extend", ExtendNotOwner.String())
+	}
+	return res, nil
+}
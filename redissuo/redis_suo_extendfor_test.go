@@ -0,0 +1,64 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ExtendFor validates the lease follows the estimate times the safety factor and the estimate lands in the metadata
+// TestSuo_ExtendFor 验证租期为预估乘以安全倍数，且预估写入元数据
+func TestSuo_ExtendFor(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithSafetyFactor(2)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.ExtendFor(ctx, xin, 3*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 1, xin.Extensions())
+
+	ttl, err := caseRedisClient.PTTL(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Greater(t, ttl, 5*time.Second)
+	require.LessOrEqual(t, ttl, 6*time.Second)
+
+	infos, err := redissuo.NewManager(caseRedisClient).InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.NotNil(t, infos[0].Metadata)
+	require.Equal(t, 3*time.Second, infos[0].Metadata.Estimate)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_ExtendFor_MaxHold validates the estimate lease is clamped to the max hold duration
+// TestSuo_ExtendFor_MaxHold 验证预估租期被限制在最大持有时长之内
+func TestSuo_ExtendFor_MaxHold(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithMaxHold(2 * time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.ExtendFor(ctx, xin, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	ttl, err := caseRedisClient.PTTL(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.LessOrEqual(t, ttl, 2*time.Second)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,66 @@
+package redissuo
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrExtendThrottled is returned when a session extends more often than the extension rate limit allows
+// ErrExtendThrottled 在会话的延期次数超过延期频率限制时返回
+var ErrExtendThrottled = NewError(CodeExtendThrottled, LanguageEnglish, nil)
+
+// EventExtendThrottled is emitted once per window when a session hits the extension rate limit
+// EventExtendThrottled 在会话触达延期频率限制时每个窗口发出一次
+const EventExtendThrottled EventKind = "extend_throttled"
+
+// extendRate counts the extensions of one session in the current window, shared across its extended sessions
+// extendRate 统计单个会话在当前窗口内的延期次数，在其延期后的会话之间共享
+type extendRate struct {
+	mutex  sync.Mutex // Protects the fields below // 保护以下字段
+	start  time.Time  // Start of the current window // 当前窗口的开始时间
+	count  int        // Extensions within the window // 窗口内的延期次数
+	warned bool       // Limit hit already reported in the window // 本窗口内已报告触达限制
+}
+
+// WithExtendRateLimit caps the extensions of each session at limit per window, extra calls fail with ErrExtendThrottled
+// Guards Redis against a buggy watchdog renewing in a tight loop, the lock itself stays held
+//
+// WithExtendRateLimit 将每个会话的延期次数限制为每个窗口 limit 次，超出的调用以 ErrExtendThrottled 失败
+// 防止有缺陷的看门狗在紧密循环中续期压垮 Redis，锁本身仍保持持有
+func (o *Suo) WithExtendRateLimit(limit int, window time.Duration) *Suo {
+	o.extendLimit = limit
+	o.extendWindow = window
+	return o
+}
+
+// throttleExtend counts one extension of the session, failing once the session is past the limit of the window
+// throttleExtend 为该会话计入一次延期，超过窗口限制时失败
+func (o *Suo) throttleExtend(xin *Xin) error {
+	if o.extendLimit <= 0 || o.extendWindow <= 0 {
+		return nil
+	}
+	if xin.rate == nil {
+		xin.rate = &extendRate{}
+	}
+	rate := xin.rate
+	rate.mutex.Lock()
+	defer rate.mutex.Unlock()
+	now := o.clock.Now()
+	if rate.start.IsZero() || now.Sub(rate.start) >= o.extendWindow {
+		rate.start = now
+		rate.count = 0
+		rate.warned = false
+	}
+	if rate.count < o.extendLimit {
+		rate.count++
+		return nil
+	}
+	if !rate.warned {
+		rate.warned = true
+		o.logger.ErrorLog("延期过于频繁-已限流", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Int("limit", o.extendLimit), zap.Duration("window", o.extendWindow))
+		o.emit(EventExtendThrottled, xin.sessionUUID, now.Sub(xin.acquiredAt))
+	}
+	return o.newError(CodeExtendThrottled)
+}
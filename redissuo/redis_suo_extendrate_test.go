@@ -0,0 +1,70 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithExtendRateLimit validates extensions past the limit fail while the lock stays held
+// Tests the warning event goes out once per window whatever the count of refused calls
+//
+// TestSuo_WithExtendRateLimit 验证超过限制的延期失败而锁仍保持持有
+// 测试无论被拒绝的调用有多少次，警告事件每个窗口只发出一次
+func TestSuo_WithExtendRateLimit(t *testing.T) {
+	var mutex sync.Mutex
+	var events []*redissuo.Event
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, batch...)
+		return nil
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16)
+
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithExtendRateLimit(2, time.Minute).WithEvents(dispatcher)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	xin, err = suo.ExtendFor(ctx, xin, time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	for range 3 {
+		res, err := suo.AcquireAgainExtendLock(ctx, xin)
+		require.ErrorIs(t, err, redissuo.ErrExtendThrottled)
+		require.Nil(t, res)
+	}
+
+	holder, err := caseRedisClient.Get(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), holder)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var throttled int
+	for _, event := range events {
+		if event.Kind == redissuo.EventExtendThrottled {
+			throttled++
+		}
+	}
+	require.Equal(t, 1, throttled)
+}
@@ -0,0 +1,78 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: lock, optional metadata companion / ARGV: session, ttl milliseconds, minimum remaining milliseconds
+	// Extends the lease just when the session holds the lock and at least the minimum of the former lease remains
+	// Gives back 1 when extended, 0 when the session does not hold the lock, -1 when too little of the lease remained
+	// KEYS: 锁、可选的元数据伴随键 / ARGV: 会话、TTL 毫秒数、最小剩余毫秒数
+	// 仅当会话持有锁且原租期至少还剩最小值时延长租期
+	// 延期成功返回 1，会话未持有锁返回 0，剩余租期不足返回 -1
+	commandExtendRemaining = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+local left = redis.call("PTTL", KEYS[1])
+if left >= 0 and left < tonumber(ARGV[3]) then
+    return -1
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+if KEYS[2] then
+    redis.call("PEXPIRE", KEYS[2], ARGV[2])
+end
+return 1`
+)
+
+// ExtendIfRemaining extends the held session just when at least minRemaining of the former lease is left
+// Plain extensions re-create a lock whose key already lapsed, though another holder may have come and gone in between
+// This variant never re-creates the key and refuses a lease cut too close, both fail with ErrLockLost
+//
+// ExtendIfRemaining 仅当原租期至少还剩 minRemaining 时延期所持会话
+// 普通延期会重新创建已过期的锁键，而期间可能已有其它持有者获取又释放了锁
+// 该变体从不重新创建键，并拒绝剩余过少的租期，两种情况都以 ErrLockLost 失败
+func (o *Suo) ExtendIfRemaining(ctx context.Context, xin *Xin, minRemaining time.Duration) (*Xin, error) {
+	o.checkOwner(xin)
+	must.Equals(xin.key, o.key)
+	must.True(minRemaining >= 0)
+	ttl, err := o.extendTTL(xin)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	startTime := o.clock.Now()
+	if !o.dryRun {
+		keys := []string{o.key}
+		if o.hasMetadata() || xin.continues != nil {
+			keys = append(keys, o.metaKey())
+		}
+		result, err := o.eval(ctx, commandExtendRemaining, keys, xin.sessionUUID, strconv.FormatInt(ttl.Milliseconds(), 10), strconv.FormatInt(minRemaining.Milliseconds(), 10))
+		if err != nil {
+			o.logger.ErrorLog("校验剩余租期延期报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+			return nil, erero.Wro(err)
+		}
+		status, err := ParseExtendStatus(result)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if status != ExtendDone {
+			o.logger.DebugLog("剩余租期不足-拒绝延期", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Stringer("status", status), zap.Duration("min_remaining", minRemaining))
+			o.loseLease(xin)
+			if status == ExtendNotOwner {
+				o.captureStolen(ctx, xin, "extend", status.String())
+			}
+			return nil, NewError(CodeLockLost, o.language, extendStatusError(ScriptExtendRemaining, status))
+		}
+	}
+	expireTime, optimisticExpire := o.expiryOf(startTime, o.clock.Now(), ttl)
+	res := &Xin{key: o.key, sessionUUID: xin.sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, continues: xin.continues}
+	o.carryExtension(ctx, xin, res)
+	o.syncTemps(ctx, res)
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ExtendIfRemaining validates the extension goes ahead while enough of the lease remains
+// TestSuo_ExtendIfRemaining 验证剩余租期充足时延期成功
+func TestSuo_ExtendIfRemaining(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.ExtendIfRemaining(ctx, xin, 500*time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 1, xin.Extensions())
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_ExtendIfRemaining_TooLate validates a lease cut too close is refused with ErrLockLost
+// TestSuo_ExtendIfRemaining_TooLate 验证剩余过少的租期以 ErrLockLost 被拒绝
+func TestSuo_ExtendIfRemaining_TooLate(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.PExpire(ctx, suo.Key(), 100*time.Millisecond).Err())
+
+	res, err := suo.ExtendIfRemaining(ctx, xin, 500*time.Millisecond)
+	require.ErrorIs(t, err, redissuo.ErrLockLost)
+	require.Nil(t, res)
+
+	ttl, err := caseRedisClient.PTTL(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.LessOrEqual(t, ttl, 100*time.Millisecond)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_ExtendIfRemaining_Expired validates a lapsed lock is not re-created through the extension
+// TestSuo_ExtendIfRemaining_Expired 验证延期不会重新创建已过期的锁
+func TestSuo_ExtendIfRemaining_Expired(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+
+	res, err := suo.ExtendIfRemaining(ctx, xin, 0)
+	require.ErrorIs(t, err, redissuo.ErrLockLost)
+	require.Nil(t, res)
+
+	exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
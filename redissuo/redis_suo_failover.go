@@ -0,0 +1,119 @@
+package redissuo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// failoverSet keeps the ordered clients of a lock and the index of the one in use
+// failoverSet 保存锁的有序客户端列表以及正在使用的客户端索引
+type failoverSet struct {
+	clients   []redis.UniversalClient // Primary first, then the fallbacks // 主客户端在前，其后为备用客户端
+	threshold int                     // Connection problems in a row causing a switch // 触发切换的连续连接错误数
+	active    atomic.Int64            // Index of the client in use // 正在使用的客户端索引
+	failures  atomic.Int64            // Connection problems in a row on the active client // 当前客户端上的连续连接错误数
+	switches  atomic.Int64            // Switches done so far // 已完成的切换次数
+}
+
+// WithFailover adds fallback clients, e.g. other endpoints or proxies, tried in sequence after the primary
+// Lock operations move on to the next client once threshold connection problems come in a row, then run again there
+// Extensions of sessions acquired ahead of a switch check the session still owns the lock on the new client first
+// Meant in client-side failover setups, Sentinel and Cluster clients fail over by themselves
+//
+// WithFailover 添加备用客户端，例如其它端点或代理，在主客户端之后依次尝试
+// 连续出现 threshold 次连接错误后，锁操作切换到下一个客户端并在其上重新执行
+// 切换之前获取的会话在延期时会先在新客户端上确认该会话仍持有锁
+// 适用于客户端侧故障切换的部署，Sentinel 和 Cluster 客户端会自行切换
+func (o *Suo) WithFailover(threshold int, fallbacks ...redis.UniversalClient) *Suo {
+	must.Have(fallbacks)
+	o.failover = &failoverSet{
+		clients:   append([]redis.UniversalClient{o.redisClient}, fallbacks...),
+		threshold: max(threshold, 1),
+	}
+	return o
+}
+
+// Failovers gets back the count of client switches, 0 when failover is disabled
+// Failovers 返回客户端切换次数，未启用故障切换时为 0
+func (o *Suo) Failovers() int64 {
+	if o.failover == nil {
+		return 0
+	}
+	return o.failover.switches.Load()
+}
+
+// client gets back the client the lock operations run on
+// client 返回执行锁操作的客户端
+func (o *Suo) client() redis.UniversalClient {
+	if o.failover == nil {
+		return o.redisClient
+	}
+	return o.failover.clients[o.failover.active.Load()]
+}
+
+// isConnectionError reports whether the problem came from reaching the server rather than from its reply
+// Missing keys, script errors and ending contexts do not count
+//
+// isConnectionError 判断错误是否来自连接服务端而非服务端的回复
+// 键不存在、脚本错误和上下文结束均不计入
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var reply redis.Error
+	return !errors.As(err, &reply)
+}
+
+// record counts the outcome of a call on the client at the index, reporting whether to run the call again on the next client
+// A switch done through another goroutine meanwhile counts as a switch too
+//
+// record 统计在该索引客户端上调用的结果，判断是否需要在下一个客户端上重新执行
+// 期间由其它 goroutine 完成的切换同样视为切换
+func (f *failoverSet) record(index int64, err error) bool {
+	if f == nil {
+		return false
+	}
+	if !isConnectionError(err) {
+		f.failures.Store(0)
+		return false
+	}
+	if f.active.Load() != index {
+		return true
+	}
+	if f.failures.Add(1) < int64(f.threshold) {
+		return false
+	}
+	if f.active.CompareAndSwap(index, (index+1)%int64(len(f.clients))) {
+		f.failures.Store(0)
+		f.switches.Add(1)
+	}
+	return true
+}
+
+// verifyFailover checks the session still owns the lock on the client in use when a switch came after its last contact
+// Without it an extension would re-create a lock the new client never saw, giving a second holder a chance to overlap
+//
+// verifyFailover 在会话最后一次访问之后发生过切换时，确认该会话在当前客户端上仍持有锁
+// 否则延期会重新创建新客户端上从未存在的锁，使其它持有者有机会与之重叠
+func (o *Suo) verifyFailover(ctx context.Context, xin *Xin) error {
+	if o.failover == nil || xin.failovers == o.failover.switches.Load() {
+		return nil
+	}
+	holder, err := o.client().Get(ctx, o.key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return erero.Wro(err)
+	}
+	if holder != xin.sessionUUID {
+		o.logger.ErrorLog("故障切换后锁已不归属本会话", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.String("holder", holder))
+		o.loseLease(xin)
+		return o.newError(CodeLockLost)
+	}
+	xin.failovers = o.failover.switches.Load()
+	return nil
+}
@@ -0,0 +1,70 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestSuo_WithFailover validates lock operations move on to the fallback once the primary is unreachable
+// TestSuo_WithFailover 验证主客户端不可达时锁操作切换到备用客户端
+func TestSuo_WithFailover(t *testing.T) {
+	ctx := context.Background()
+	dead := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { _ = dead.Close() }()
+
+	suo := redissuo.NewSuo(dead, utils.NewUUID(), 5*time.Second).WithFailover(1, caseRedisClient)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, int64(1), suo.Failovers())
+
+	holder, err := caseRedisClient.Get(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), holder)
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_WithFailover_Verify validates a session acquired ahead of the switch is not re-created on the fallback
+// TestSuo_WithFailover_Verify 验证切换之前获取的会话不会在备用客户端上被重新创建
+func TestSuo_WithFailover_Verify(t *testing.T) {
+	ctx := context.Background()
+	primaryServer := rese.P1(miniredis.Run())
+	defer primaryServer.Close()
+	primary := redis.NewClient(&redis.Options{Addr: primaryServer.Addr(), MaxRetries: -1})
+	defer func() { _ = primary.Close() }()
+
+	suo := redissuo.NewSuo(primary, utils.NewUUID(), 5*time.Second).WithFailover(1, caseRedisClient)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Zero(t, suo.Failovers())
+
+	primaryServer.Close()
+	res, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.Error(t, err)
+	require.Nil(t, res)
+	require.Equal(t, int64(1), suo.Failovers())
+
+	res, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.ErrorIs(t, err, redissuo.ErrLockLost)
+	require.Nil(t, res)
+
+	exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
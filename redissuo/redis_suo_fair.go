@@ -0,0 +1,132 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: queue, heartbeats / ARGV: session, queue TTL milliseconds, heartbeat deadline milliseconds
+	// Enqueues the session like commandEnqueue and stamps its heartbeat in the same step
+	// Scores count microseconds, waiters arriving in the same millisecond keep their sequence
+	// KEYS: 队列、心跳 / ARGV: 会话、队列 TTL 毫秒数、心跳截止毫秒数
+	// 与 commandEnqueue 一样将会话入队，并在同一步中记录其心跳
+	// 分数以微秒计，同一毫秒内到达的等待者也保持其顺序
+	commandEnqueueFair = `redis.replicate_commands()
+local now = redis.call("TIME")
+local us = tonumber(now[1]) * 1000000 + tonumber(now[2])
+redis.call("ZADD", KEYS[1], "NX", us, ARGV[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+redis.call("ZADD", KEYS[2], ARGV[3], ARGV[1])
+redis.call("PEXPIRE", KEYS[2], ARGV[2])
+return redis.call("ZRANK", KEYS[1], ARGV[1])`
+
+	// KEYS: queue, heartbeats / ARGV: session, now milliseconds, heartbeat deadline milliseconds, queue TTL milliseconds
+	// Prunes waiters whose heartbeat lapsed, then stamps the heartbeat of the session and gives back its rank
+	// Gives back -1 when the session is no longer queued
+	// Heartbeats use client clock milliseconds, skew across clients shifts the liveness window alike
+	// KEYS: 队列、心跳 / ARGV: 会话、当前毫秒数、心跳截止毫秒数、队列 TTL 毫秒数
+	// 清理心跳已失效的等待者，然后记录该会话的心跳并返回其排名
+	// 会话已不在队列中时返回 -1
+	// 心跳使用客户端时钟毫秒数，客户端之间的时钟偏差会同样地平移存活窗口
+	commandQueueHead = `for _, member in ipairs(redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[2])) do
+    redis.call("ZREM", KEYS[1], member)
+    redis.call("ZREM", KEYS[2], member)
+end
+local rank = redis.call("ZRANK", KEYS[1], ARGV[1])
+if not rank then
+    return -1
+end
+redis.call("ZADD", KEYS[2], ARGV[3], ARGV[1])
+redis.call("PEXPIRE", KEYS[2], ARGV[4])
+return rank`
+)
+
+// WithFairQueue grants the lock to queued waiters in arrival sequence, so late arrivals cannot starve long waiters
+// Just the head of the queue acquires through its Waiter, each attempt doubles as the heartbeat of the waiter
+// A waiter silent past the TTL is pruned, so a crashed waiter does not block the queue
+// Enables WithWaitQueue, fairness holds among waiters, a plain Acquire still goes around the queue
+//
+// WithFairQueue 按到达顺序将锁授予排队的等待者，使后到者无法饿死长时间等待者
+// 只有队首可以通过其 Waiter 获取锁，每次尝试同时作为该等待者的心跳
+// 超过 TTL 仍无心跳的等待者会被清理，使崩溃的等待者不会阻塞队列
+// 会启用 WithWaitQueue，公平性在等待者之间成立，普通的 Acquire 仍会绕过队列
+func (o *Suo) WithFairQueue(enable bool) *Suo {
+	o.fairQueue = enable
+	if enable {
+		o.waitQueue = true
+	}
+	return o
+}
+
+// FairQueue reports whether the lock is granted in arrival sequence
+// FairQueue 判断锁是否按到达顺序授予
+func (o *Suo) FairQueue() bool {
+	return o.fairQueue
+}
+
+// aliveKey gets back the sorted set of waiter heartbeat deadlines in fair mode
+// aliveKey 返回公平模式下等待者心跳截止时间的有序集合
+func (o *Suo) aliveKey() string {
+	return companionKey(o.key, "alive")
+}
+
+// enqueueFair adds the session to the queue together with its heartbeat, giving back the count of waiters ahead
+// enqueueFair 将会话连同其心跳加入队列，返回前方等待者数量
+func (o *Suo) enqueueFair(ctx context.Context, sessionUUID string) (int64, error) {
+	args := []string{
+		sessionUUID,
+		strconv.FormatInt(o.companionTTL().Milliseconds(), 10),
+		strconv.FormatInt(o.clock.Now().Add(o.ttl).UnixMilli(), 10),
+	}
+	position, err := o.client().Eval(ctx, commandEnqueueFair, []string{o.queueKey(), o.aliveKey()}, args).Int64()
+	if err != nil {
+		o.logger.ErrorLog("排队报错", zap.String("k", o.key), zap.Error(err))
+		return 0, erero.Wro(err)
+	}
+	return position, nil
+}
+
+// atHead stamps the heartbeat of the waiter and reports whether it heads the queue
+// A waiter pruned in between joins again at the tail, having lost its place
+//
+// atHead 记录等待者的心跳并判断其是否位于队首
+// 期间被清理的等待者重新在队尾排队，失去原来的位置
+func (w *Waiter) atHead(ctx context.Context) (bool, error) {
+	if w.suo.simulated("queue_head") {
+		return true, nil
+	}
+	o := w.suo
+	must.True(o.fairQueue)
+	nowTime := o.clock.Now()
+	args := []string{
+		w.sessionUUID,
+		strconv.FormatInt(nowTime.UnixMilli(), 10),
+		strconv.FormatInt(nowTime.Add(o.ttl).UnixMilli(), 10),
+		strconv.FormatInt(o.companionTTL().Milliseconds(), 10),
+	}
+	rank, err := o.eval(ctx, commandQueueHead, []string{o.queueKey(), o.aliveKey()}, args)
+	if err != nil {
+		o.logger.ErrorLog("查询队列报错", zap.String("k", o.key), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	position, _ := rank.(int64)
+	if position < 0 {
+		o.logger.DebugLog("等待者已被清理-重新排队", zap.String("k", o.key), zap.String("v", w.sessionUUID))
+		if position, err = o.enqueueFair(ctx, w.sessionUUID); err != nil {
+			return false, erero.Wro(err)
+		}
+	}
+	w.position.Store(position)
+	return position == 0, nil
+}
+
+// QueuePosition gets back the count of waiters ahead as seen through the last enqueue or attempt, 0 means head of the queue
+// QueuePosition 返回最近一次入队或尝试时观察到的前方等待者数量，0 表示位于队首
+func (w *Waiter) QueuePosition() int64 {
+	return w.position.Load()
+}
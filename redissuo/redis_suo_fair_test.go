@@ -0,0 +1,71 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithFairQueue validates waiters win the lock in arrival sequence
+// TestSuo_WithFairQueue 验证等待者按到达顺序赢得锁
+func TestSuo_WithFairQueue(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithFairQueue(true)
+	require.True(t, suo.FairQueue())
+
+	first, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), first.QueuePosition())
+	second, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), second.QueuePosition())
+
+	// The lock is free, still the second waiter has to let the first one go ahead
+	xin, err := second.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, xin)
+	require.Equal(t, int64(1), second.QueuePosition())
+
+	xin, err = first.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xin, err = second.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, int64(0), second.QueuePosition())
+	success, err = suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_WithFairQueue_Prune validates a silent waiter is pruned so it does not block the queue
+// TestSuo_WithFairQueue_Prune 验证无心跳的等待者被清理，不会阻塞队列
+func TestSuo_WithFairQueue_Prune(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 100*time.Millisecond).WithFairQueue(true)
+
+	_, err := suo.Enqueue(ctx) // Crashes right away, never polling again
+	require.NoError(t, err)
+	waiter, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+
+	xin, err := waiter.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, xin)
+
+	time.Sleep(150 * time.Millisecond)
+	xin, err = waiter.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
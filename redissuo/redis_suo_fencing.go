@@ -0,0 +1,149 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// ErrStaleFencingToken is returned when a guarded write carries a token older than the stored one
+// ErrStaleFencingToken 在受保护的写入携带的令牌比已存储的令牌更旧时返回
+var ErrStaleFencingToken = NewError(CodeStaleFencingToken, LanguageEnglish, nil)
+
+// FencingSQL builds SQL fragments guarding row writes with a fencing token column
+// Rows accept writes carrying a token at least as new as the stored one, so the same holder may write again
+// Fragments use "?" placeholders, rebind them on drivers expecting "$1" style
+//
+// FencingSQL 构建使用防护令牌列保护行写入的 SQL 片段
+// 行只接受令牌不旧于已存储令牌的写入，因此同一持有者可以重复写入
+// 片段使用 "?" 占位符，对于使用 "$1" 风格的驱动需要重新绑定
+type FencingSQL struct {
+	column string // Fencing token column name // 防护令牌列名
+}
+
+// NewFencingSQL creates the fragment builder of the given column, e.g. "fencing_token"
+// NewFencingSQL 创建给定列的片段构建器，例如 "fencing_token"
+func NewFencingSQL(column string) *FencingSQL {
+	return &FencingSQL{column: must.Nice(column)}
+}
+
+// Where gets back the condition admitting the write, e.g. "fencing_token <= ?", with its argument
+// Where 返回允许写入的条件，例如 "fencing_token <= ?"，以及对应的参数
+func (f *FencingSQL) Where(token int64) (string, []interface{}) {
+	return f.column + " <= ?", []interface{}{token}
+}
+
+// Set gets back the assignment recording the token on the row, e.g. "fencing_token = ?", with its argument
+// Set 返回在行上记录令牌的赋值语句，例如 "fencing_token = ?"，以及对应的参数
+func (f *FencingSQL) Set(token int64) (string, []interface{}) {
+	return f.column + " = ?", []interface{}{token}
+}
+
+const (
+	// KEYS: value key, fence key / ARGV: value, token
+	// Writes the value and raises the fence unless a newer token got there first
+	// KEYS: 值键、防护键 / ARGV: 值、令牌
+	// 写入值并抬高防护值，除非更新的令牌已先行写入
+	commandFencedSet = `local fence = tonumber(redis.call("GET", KEYS[2]) or "-1")
+local token = tonumber(ARGV[2])
+if token < fence then
+    return 0
+end
+redis.call("SET", KEYS[1], ARGV[1])
+redis.call("SET", KEYS[2], ARGV[2])
+return 1`
+)
+
+const (
+	// The fencing wrapper pops the token counter off KEYS so inner wrappers keep their layout
+	// A session already holding the lock keeps its token, any other success raises the counter
+	// 防护包装从 KEYS 中取出令牌计数器，使内层包装保持原有布局
+	// 已持有锁的会话保留其令牌，其它成功的获取会抬高计数器
+	commandFencingWrapperHead = `local fence = KEYS[#KEYS]
+KEYS[#KEYS] = nil
+local held = redis.call("GET", KEYS[1]) == ARGV[1]
+local function fenced()
+`
+	commandFencingWrapperTail = `
+end
+local res = fenced()
+if res and (res == "OK" or res.ok == "OK" or res[1] == "OK") then
+    local token = held and tonumber(redis.call("GET", fence))
+    if not token then
+        token = redis.call("INCR", fence)
+    end
+    local reply = {"OK"}
+    if type(res) == "table" and res[1] == "OK" then
+        reply = res
+    end
+    reply[#reply + 1] = token
+    return reply
+end
+return res`
+)
+
+// WithFencing makes each acquisition issue a fencing token from a counter kept next to the lock
+// Tokens only grow, so downstream resources reject writes of a holder whose lease lapsed, see FencedSet and FencingSQL
+//
+// WithFencing 使每次获取从锁旁保存的计数器中签发防护令牌
+// 令牌只增不减，使下游资源能拒绝租期已失效的持有者的写入，参见 FencedSet 和 FencingSQL
+func (o *Suo) WithFencing(enable bool) *Suo {
+	o.fencing = enable
+	return o
+}
+
+// FencingToken gets back the fencing token issued to the session, 0 when fencing is disabled
+// Extensions keep the token, a lapsed lock taken back through an extension gets a fresh one
+//
+// FencingToken 返回签发给该会话的防护令牌，未启用防护时为 0
+// 延期保留该令牌，通过延期重新获取已失效的锁时会得到新的令牌
+func (s *Xin) FencingToken() int64 {
+	return s.fencingToken
+}
+
+// tokenKey gets back the companion key counting the fencing tokens
+// tokenKey 返回对防护令牌计数的伴随键
+func (o *Suo) tokenKey() string {
+	return companionKey(o.key, "token")
+}
+
+// splitFencingToken takes the token off the end of a fenced acquire reply
+// Replies other than a success table pass through untouched
+//
+// splitFencingToken 从带防护的获取回复末尾取出令牌
+// 成功表以外的回复原样透传
+func splitFencingToken(result interface{}) (int64, interface{}, bool) {
+	items, ok := result.([]interface{})
+	if !ok {
+		return 0, result, true
+	}
+	if len(items) < 2 {
+		return 0, nil, false
+	}
+	token, ok := items[len(items)-1].(int64)
+	if !ok {
+		return 0, nil, false
+	}
+	if len(items) == 2 {
+		return token, items[0], true
+	}
+	return token, items[:len(items)-1], true
+}
+
+// FencedSet writes the value under the key, guarded through the fencing token kept in a companion key
+// Gives back ErrStaleFencingToken when a newer token already wrote, so a stale holder cannot overwrite
+//
+// FencedSet 在键下写入值，并通过伴随键中保存的防护令牌进行保护
+// 当更新的令牌已经写入时返回 ErrStaleFencingToken，使过期的持有者无法覆盖
+func FencedSet(ctx context.Context, rds redis.UniversalClient, key string, value string, token int64) error {
+	result, err := rds.Eval(ctx, commandFencedSet, []string{key, companionKey(key, "fence")}, value, token).Int64()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if result == 0 {
+		return ErrStaleFencingToken
+	}
+	return nil
+}
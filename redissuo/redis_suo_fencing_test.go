@@ -0,0 +1,90 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFencedSet validates writes with an older token get refused while the same or newer tokens pass
+// TestFencedSet 验证携带旧令牌的写入被拒绝，相同或更新的令牌可以写入
+func TestFencedSet(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+
+	require.NoError(t, redissuo.FencedSet(ctx, caseRedisClient, key, "a", 5))
+	require.NoError(t, redissuo.FencedSet(ctx, caseRedisClient, key, "b", 5))
+	require.ErrorIs(t, redissuo.FencedSet(ctx, caseRedisClient, key, "c", 4), redissuo.ErrStaleFencingToken)
+	require.NoError(t, redissuo.FencedSet(ctx, caseRedisClient, key, "d", 6))
+
+	value, err := caseRedisClient.Get(ctx, key).Result()
+	require.NoError(t, err)
+	require.Equal(t, "d", value)
+}
+
+// TestFencingSQL validates the fragments and their arguments
+// TestFencingSQL 验证片段及其参数
+func TestFencingSQL(t *testing.T) {
+	fencing := redissuo.NewFencingSQL("fencing_token")
+
+	where, args := fencing.Where(7)
+	require.Equal(t, "fencing_token <= ?", where)
+	require.Equal(t, []interface{}{int64(7)}, args)
+
+	set, args := fencing.Set(7)
+	require.Equal(t, "fencing_token = ?", set)
+	require.Equal(t, []interface{}{int64(7)}, args)
+}
+
+// TestSuo_WithFencing validates each acquisition gets a larger token while extensions keep it
+// Covers the metadata and server time variants, whose replies the token gets appended to
+//
+// TestSuo_WithFencing 验证每次获取得到更大的令牌，而延期保留该令牌
+// 覆盖元数据和服务端时间变体，令牌会追加到它们的回复之后
+func TestSuo_WithFencing(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	variants := []*redissuo.Suo{
+		redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithFencing(true),
+		redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithFencing(true).WithTags(map[string]string{"team": "payment"}),
+		redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithFencing(true).WithServerTime(true),
+	}
+
+	var last int64
+	for _, suo := range variants {
+		xin, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+		require.Greater(t, xin.FencingToken(), last)
+		last = xin.FencingToken()
+
+		xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+		require.Equal(t, last, xin.FencingToken())
+		require.Equal(t, last, xin.Continuation().FencingToken)
+
+		xin, err = suo.ExtendIfRemaining(ctx, xin, 0)
+		require.NoError(t, err)
+		require.Equal(t, last, xin.FencingToken())
+
+		require.NoError(t, redissuo.FencedSet(ctx, caseRedisClient, key+":resource", "v", xin.FencingToken()))
+
+		success, err := suo.Release(ctx, xin)
+		require.NoError(t, err)
+		require.True(t, success)
+	}
+	require.ErrorIs(t, redissuo.FencedSet(ctx, caseRedisClient, key+":resource", "stale", 1), redissuo.ErrStaleFencingToken)
+
+	plain := redissuo.NewSuo(caseRedisClient, key, 5*time.Second)
+	xin, err := plain.Acquire(ctx)
+	require.NoError(t, err)
+	require.Zero(t, xin.FencingToken())
+	success, err := plain.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,63 @@
+package redissuo
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// EventForceReleased marks a lock deleted through ForceRelease regardless of its holder
+// EventForceReleased 表示通过 ForceRelease 不论持有者而删除的锁
+const EventForceReleased EventKind = "force_released"
+
+const (
+	// KEYS: lock, metadata / gets back the holder it deleted, false when the lock is free
+	// KEYS: 锁、元数据 / 返回被删除的持有者，锁空闲时返回 false
+	commandForceRelease = `local v = redis.call("GET", KEYS[1])
+if not v then
+    return false
+end
+redis.call("DEL", KEYS[1])
+redis.call("DEL", KEYS[2])
+return v`
+)
+
+// ForceRelease deletes the lock regardless of its holder, gives back the session it took away, blank when free
+// DANGEROUS: the holder keeps running as if it owned the lock, so two holders may overlap
+// Meant in operational recovery once a holder crashed with a very long TTL, never in regular code paths
+// Each call leaves an audit log line and EventForceReleased naming the host and PID that forced it
+//
+// ForceRelease 不论持有者删除该锁，返回被夺走的会话，锁空闲时为空
+// 危险：原持有者仍会认为自己持有锁继续运行，可能出现两个持有者重叠
+// 仅适用于持有者以很长的 TTL 崩溃后的运维恢复，切勿用在常规代码路径中
+// 每次调用都会留下审计日志以及标明发起主机和 PID 的 EventForceReleased
+func (o *Suo) ForceRelease(ctx context.Context) (string, error) {
+	if o.simulated("force_release") {
+		return "", nil
+	}
+	operator := hostname() + ":" + strconv.Itoa(os.Getpid())
+	result, err := o.eval(ctx, o.forceReleaseCommand(ctx), []string{o.key, o.metaKey()})
+	if errors.Is(err, redis.Nil) {
+		o.logger.ErrorLog("强制释放锁-锁已空闲", zap.String("k", o.key), zap.String("operator", operator))
+		return "", nil
+	} else if err != nil {
+		o.logger.ErrorLog("强制释放锁报错", zap.String("k", o.key), zap.String("operator", operator), zap.Error(err))
+		return "", erero.Wro(err)
+	}
+	holder, ok := result.(string)
+	if !ok {
+		return "", erero.Errorf("unexpected force release reply: %v", result)
+	}
+	o.logger.ErrorLog("强制释放锁", zap.String("k", o.key), zap.String("v", holder), zap.String("operator", operator))
+	o.unregister(ctx, holder)
+	if o.events != nil {
+		o.events.Emit(&Event{Kind: EventForceReleased, Key: o.key, Session: holder, Time: o.clock.Now(), Operator: operator})
+	}
+	o.publishRelease(ctx, holder)
+	return holder, nil
+}
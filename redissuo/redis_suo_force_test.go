@@ -0,0 +1,61 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ForceRelease validates the lock gets deleted regardless of its holder and the force is audited
+// TestSuo_ForceRelease 验证锁不论持有者都被删除且强制操作被审计
+func TestSuo_ForceRelease(t *testing.T) {
+	var mutex sync.Mutex
+	var events []*redissuo.Event
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, batch...)
+		return nil
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16)
+
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Hour).WithEvents(dispatcher).WithHolderIdentity(true)
+
+	holder, err := suo.ForceRelease(ctx)
+	require.NoError(t, err)
+	require.Empty(t, holder)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	holder, err = suo.ForceRelease(ctx)
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), holder)
+	info, err := suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.False(t, info.Held())
+	require.Nil(t, info.Metadata)
+
+	other, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	success, err := suo.Release(ctx, other)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Equal(t, redissuo.EventForceReleased, events[1].Kind)
+	require.Equal(t, xin.SessionUUID(), events[1].Session)
+	require.NotEmpty(t, events[1].Operator)
+}
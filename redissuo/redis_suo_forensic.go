@@ -0,0 +1,64 @@
+package redissuo
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventStolen marks a session finding the lock held through another session on release or extension
+// EventStolen 表示会话在释放或延期时发现锁已被其它会话持有
+const EventStolen EventKind = "stolen"
+
+// Forensic is the snapshot taken once a session finds its lock held through another session
+// Such incidents are hard to reconstruct afterwards, so the usurper state is read right at detection
+// Fencing tokens are 0 when unknown, e.g. when the holds carry none
+//
+// Forensic 是会话发现其锁被其它会话持有时采集的快照
+// 此类事件事后难以还原，因此在发现时立即读取抢占者的状态
+// 防护令牌未知时为 0，例如持有未携带令牌时
+type Forensic struct {
+	Operation       string        `json:"operation"`                  // Operation detecting the loss, "release" or "extend" // 发现丢失的操作，"release" 或 "extend"
+	Session         string        `json:"session"`                    // Session that lost the lock // 丢失锁的会话
+	SessionToken    int64         `json:"session_token,omitempty"`    // Fencing token of the session that lost the lock // 丢失锁的会话的防护令牌
+	Usurper         string        `json:"usurper"`                    // Value found in the lock key // 锁键中发现的值
+	UsurperToken    int64         `json:"usurper_token,omitempty"`    // Fencing token of the usurper // 抢占者的防护令牌
+	UsurperMetadata *Metadata     `json:"usurper_metadata,omitempty"` // Metadata of the usurper, nil when not stored // 抢占者的元数据，未存储时为 nil
+	UsurperPTTL     time.Duration `json:"usurper_pttl"`               // Remaining TTL in the server // 服务端的剩余 TTL
+	HeldFor         time.Duration `json:"held_for"`                   // Hold duration of the session at detection // 发现时会话的持有时长
+	Status          string        `json:"status,omitempty"`           // Script status name reporting the loss, e.g. "not_owner" // 报告丢失的脚本状态名称，例如 "not_owner"
+}
+
+// captureStolen reads the usurper state once the session lost the lock, logging it and emitting EventStolen
+// Nothing is captured when the lock turns out free or still held through the session
+//
+// captureStolen 在会话丢失锁后读取抢占者的状态，记录日志并发出 EventStolen
+// 锁实际空闲或仍由该会话持有时不采集
+func (o *Suo) captureStolen(ctx context.Context, xin *Xin, operation string, status string) {
+	if o.dryRun {
+		return
+	}
+	info, err := parseLockInfo(o.key, o.client().Eval(ctx, commandInspectMeta, []string{o.key, o.metaKey()}), o.codec)
+	if err != nil {
+		o.logger.DebugLog("采集抢占信息报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return
+	}
+	if info.Holder == "" || info.Holder == xin.sessionUUID {
+		return
+	}
+	forensic := &Forensic{
+		Operation:       operation,
+		Session:         xin.sessionUUID,
+		SessionToken:    xin.fencingToken,
+		Usurper:         info.Holder,
+		UsurperMetadata: info.Metadata,
+		UsurperPTTL:     info.TTL,
+		HeldFor:         o.clock.Now().Sub(xin.acquiredAt),
+		Status:          status,
+	}
+	o.logger.ErrorLog("锁被抢占-取证记录", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.String("operation", operation), zap.String("usurper", info.Holder), zap.Duration("usurper_pttl", info.TTL))
+	if o.events != nil {
+		o.events.Emit(&Event{Kind: EventStolen, Key: o.key, Session: xin.sessionUUID, Time: o.clock.Now(), HeldFor: forensic.HeldFor, Forensic: forensic, Status: status})
+	}
+}
@@ -0,0 +1,67 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_Forensic validates a stolen lock emits the usurper snapshot on extension and on release
+// TestSuo_Forensic 验证锁被抢占时在延期和释放时发出抢占者快照
+func TestSuo_Forensic(t *testing.T) {
+	ctx := context.Background()
+
+	var mutex sync.Mutex
+	var stolen []*redissuo.Event
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for _, event := range batch {
+			if event.Kind == redissuo.EventStolen {
+				stolen = append(stolen, event)
+			}
+		}
+		return nil
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16).WithBatch(16, 10*time.Millisecond).Start()
+
+	key := utils.NewUUID()
+	suo := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithEvents(dispatcher)
+	usurper := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithTags(map[string]string{"team": "usurper"})
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Del(ctx, key).Err()) // The lease lapses early
+	other, err := usurper.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+
+	lost, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.Nil(t, lost)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, success)
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, stolen, 2)
+	require.Equal(t, "extend", stolen[0].Forensic.Operation)
+	require.Equal(t, "release", stolen[1].Forensic.Operation)
+	forensic := stolen[1].Forensic
+	require.Equal(t, xin.SessionUUID(), forensic.Session)
+	require.Equal(t, other.SessionUUID(), forensic.Usurper)
+	require.Equal(t, "usurper", forensic.UsurperMetadata.Tags["team"])
+	require.Greater(t, forensic.UsurperPTTL, time.Duration(0))
+
+	success, err = usurper.Release(ctx, other)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,98 @@
+package redissuo
+
+import (
+	"strconv"
+)
+
+// ErrGuardRejected is returned when a guard predicate blocks the acquisition
+// ErrGuardRejected 在守卫条件阻止获取锁时返回
+var ErrGuardRejected = NewError(CodeGuardRejected, LanguageEnglish, nil)
+
+// guardKind names the predicate checked inside the acquire script
+// guardKind 表示在获取脚本内检查的条件类型
+type guardKind string
+
+const (
+	guardAbsent guardKind = "absent" // Guard key must not exist // 守卫键必须不存在
+	guardExists guardKind = "exists" // Guard key must exist // 守卫键必须存在
+	guardEquals guardKind = "equals" // Guard key must hold the value // 守卫键必须等于指定值
+)
+
+// Guard is a predicate on another key evaluated atomically ahead of acquisition
+// The guard key travels through KEYS, in cluster mode it must share the lock's hash slot
+//
+// Guard 是在获取锁前原子检查的另一个键上的条件
+// 守卫键通过 KEYS 传递，集群模式下必须与锁共享哈希槽
+type Guard struct {
+	key   string    // Guard key name // 守卫键名
+	kind  guardKind // Predicate type // 条件类型
+	value string    // Expected value of equals guard // 相等条件的期望值
+}
+
+// GuardAbsent acquires just when the key does not exist, e.g. a maintenance switch
+// GuardAbsent 仅当键不存在时才获取，例如维护模式开关
+func GuardAbsent(key string) Guard {
+	return Guard{key: key, kind: guardAbsent}
+}
+
+// GuardExists acquires just when the key exists
+// GuardExists 仅当键存在时才获取
+func GuardExists(key string) Guard {
+	return Guard{key: key, kind: guardExists}
+}
+
+// GuardEquals acquires just when the key holds the given value, e.g. a config version
+// GuardEquals 仅当键等于给定值时才获取，例如配置版本号
+func GuardEquals(key string, value string) Guard {
+	return Guard{key: key, kind: guardEquals, value: value}
+}
+
+// WithGuards sets predicates checked atomically in each acquisition and extension
+// Acquisition fails with ErrGuardRejected when any guard does not hold
+//
+// WithGuards 设置在每次获取和延期时原子检查的条件
+// 任一条件不满足时获取失败并返回 ErrGuardRejected
+func (o *Suo) WithGuards(guards ...Guard) *Suo {
+	o.guards = guards
+	return o
+}
+
+const (
+	// Guard keys follow the lock key in KEYS, ARGV[3] holds the guard count
+	// Each guard takes a (kind, value) pair in ARGV starting at ARGV[4]
+	// 守卫键在 KEYS 中位于锁键之后，ARGV[3] 保存守卫数量
+	// 每个守卫在 ARGV 中从 ARGV[4] 开始占用一对 (类型, 值)
+	commandGuardPrefix = `for i = 1, tonumber(ARGV[3]) do
+    local key = KEYS[1 + i]
+    local kind = ARGV[2 + i * 2]
+    if kind == "absent" then
+        if redis.call("EXISTS", key) == 1 then
+            return "GUARD"
+        end
+    elseif kind == "exists" then
+        if redis.call("EXISTS", key) == 0 then
+            return "GUARD"
+        end
+    elseif kind == "equals" then
+        if redis.call("GET", key) ~= ARGV[3 + i * 2] then
+            return "GUARD"
+        end
+    end
+end
+`
+
+	// guardRejectedMessage is the script reply marking a failed guard
+	// guardRejectedMessage 是表示守卫失败的脚本回复
+	guardRejectedMessage = "GUARD"
+)
+
+// guardKeysArgs appends the guard count, guard keys and (kind, value) pairs to the script parameters
+// guardKeysArgs 将守卫数量、守卫键和 (类型, 值) 对追加到脚本参数中
+func (o *Suo) guardKeysArgs(keys []string, args []string) ([]string, []string) {
+	args = append(args, strconv.Itoa(len(o.guards)))
+	for _, guard := range o.guards {
+		keys = append(keys, guard.key)
+		args = append(args, string(guard.kind), guard.value)
+	}
+	return keys, args
+}
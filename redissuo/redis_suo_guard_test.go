@@ -0,0 +1,70 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithGuards_Absent validates acquisition blocked while a maintenance key exists
+// Tests that removing the guard key lets the acquisition go through
+//
+// TestSuo_WithGuards_Absent 验证维护键存在时获取被阻止
+// 测试删除守卫键后获取可以成功
+func TestSuo_WithGuards_Absent(t *testing.T) {
+	ctx := context.Background()
+
+	maintenanceKey := utils.NewUUID()
+	require.NoError(t, caseRedisClient.Set(ctx, maintenanceKey, "on", time.Minute).Err())
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithGuards(redissuo.GuardAbsent(maintenanceKey))
+
+	xin, err := suo.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrGuardRejected)
+	require.Nil(t, xin)
+
+	require.NoError(t, caseRedisClient.Del(ctx, maintenanceKey).Err())
+
+	xin, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_WithGuards_Equals validates acquisition bound to a config version value
+// Tests that a mismatched version rejects and the matching version acquires
+//
+// TestSuo_WithGuards_Equals 验证绑定到配置版本值的获取
+// 测试版本不匹配时拒绝，版本匹配时获取成功
+func TestSuo_WithGuards_Equals(t *testing.T) {
+	ctx := context.Background()
+
+	versionKey := utils.NewUUID()
+	require.NoError(t, caseRedisClient.Set(ctx, versionKey, "v1", time.Minute).Err())
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithGuards(
+		redissuo.GuardExists(versionKey),
+		redissuo.GuardEquals(versionKey, "v2"),
+	)
+
+	xin, err := suo.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrGuardRejected)
+	require.Nil(t, xin)
+
+	require.NoError(t, caseRedisClient.Set(ctx, versionKey, "v2", time.Minute).Err())
+
+	xin, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,88 @@
+package redissuo
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// HeldLock describes one session held by this process, as last seen through acquisition or extension
+// HeldLock 描述本进程持有的一个会话，取自最近一次获取或延期时的状态
+type HeldLock struct {
+	Key        string    // Lock name ID // 锁名标识符
+	Session    string    // Session UUID // 会话 UUID
+	AcquiredAt time.Time // First acquisition time // 首次获取时间
+	Expire     time.Time // Conservative expiration estimate // 保守的过期时间估算
+	Extensions int       // Count of extensions // 延期次数
+}
+
+// holdSet keeps the sessions held by locks of one manager, keyed by lock name and session
+// Keeps plain values instead of Xin pointers so dropped sessions stay collectable
+//
+// holdSet 记录一个管理器的锁所持有的会话，以锁名和会话为键
+// 保存普通值而非 Xin 指针，使被丢弃的会话仍可被回收
+type holdSet struct {
+	mutex sync.Mutex           // Protects holds // 保护 holds
+	holds map[string]*HeldLock // Held sessions // 已持有的会话
+}
+
+// newHoldSet creates a blank hold set
+// newHoldSet 创建空的持有集合
+func newHoldSet() *holdSet {
+	return &holdSet{holds: map[string]*HeldLock{}}
+}
+
+// put records the session, replacing the entry of the same hold past an extension
+// put 记录会话，延期后替换同一持有的条目
+func (h *holdSet) put(xin *Xin) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.holds[xin.key+"\x00"+xin.sessionUUID] = &HeldLock{
+		Key:        xin.key,
+		Session:    xin.sessionUUID,
+		AcquiredAt: xin.acquiredAt,
+		Expire:     xin.expire,
+		Extensions: xin.extensions,
+	}
+}
+
+// drop removes the session once released
+// drop 在会话释放后将其移除
+func (h *holdSet) drop(xin *Xin) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	delete(h.holds, xin.key+"\x00"+xin.sessionUUID)
+}
+
+// has reports whether the session of the lock name is held
+// has 判断该锁名的会话是否被持有
+func (h *holdSet) has(key string, sessionUUID string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, ok := h.holds[key+"\x00"+sessionUUID]
+	return ok
+}
+
+// Held gets back the sessions currently held through locks of this manager, sorted through lock name
+// Meant in health endpoints and shutdown logic reporting what this instance owns
+// Sessions lost through expiry stay listed until released, compare Expire with the present time
+//
+// Held 返回当前通过此管理器的锁持有的会话，按锁名排序
+// 适用于健康检查端点和退出逻辑报告本实例持有的锁
+// 因过期而丢失的会话在释放前仍会列出，可将 Expire 与当前时间比较
+func (m *Manager) Held() []*HeldLock {
+	m.holds.mutex.Lock()
+	defer m.holds.mutex.Unlock()
+	results := make([]*HeldLock, 0, len(m.holds.holds))
+	for _, hold := range m.holds.holds {
+		copied := *hold
+		results = append(results, &copied)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Key != results[j].Key {
+			return results[i].Key < results[j].Key
+		}
+		return results[i].Session < results[j].Session
+	})
+	return results
+}
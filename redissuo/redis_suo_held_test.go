@@ -0,0 +1,40 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_Held validates the manager lists sessions held in this process, following extensions and releases
+// TestManager_Held 验证管理器列出本进程持有的会话，并跟随延期和释放更新
+func TestManager_Held(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	require.Empty(t, manager.Held())
+
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	held := manager.Held()
+	require.Len(t, held, 1)
+	require.Equal(t, xin.Key(), held[0].Key)
+	require.Equal(t, xin.SessionUUID(), held[0].Session)
+	require.Equal(t, 1, held[0].Extensions)
+	require.Equal(t, xin.Expire(), held[0].Expire)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Empty(t, manager.Held())
+}
@@ -0,0 +1,355 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// Hierarchy provides directory-style locking across parent and child lock names
+// A child such as "orders:123" registers itself under the parent "orders"
+// Nested names register under every ancestor, so "orders:eu:123" counts under both "orders:eu" and "orders"
+// An exclusive parent acquisition waits until no descendant is active and blocks new descendants meanwhile
+// In cluster mode use hash tags such as "{orders}" and "{orders}:123" so the keys share one slot
+//
+// Hierarchy 提供跨父子锁名的目录式锁定
+// 子锁如 "orders:123" 会在父锁 "orders" 下登记自己
+// 嵌套名称会在每一级祖先下登记，因此 "orders:eu:123" 同时计入 "orders:eu" 和 "orders"
+// 父锁的独占获取会等待没有活跃的后代，并在等待期间阻止新的后代
+// 集群模式下使用 "{orders}" 和 "{orders}:123" 这样的哈希标签使键落在同一槽位
+type Hierarchy struct {
+	redisClient redis.UniversalClient // Redis client connection // Redis 客户端连接
+	ttl         time.Duration         // Lock expiration timeout // 锁过期超时时间
+	separator   string                // Separator between parent and child names // 父子名称间的分隔符
+	logger      logging.Logger        // Logger instance used in operations // 操作中使用的日志记录器实例
+	clock       Clock                 // Source of current time in expiry estimates // 过期估算使用的当前时间来源
+}
+
+// NewHierarchy creates a hierarchy helper using ":" as the name separator
+// Settings must be non-blank otherwise the function panics via must.Nice
+//
+// NewHierarchy 创建使用 ":" 作为名称分隔符的层级锁工具
+// 设置不能为空否则函数会通过 must.Nice 触发 panic
+func NewHierarchy(rds redis.UniversalClient, ttl time.Duration) *Hierarchy {
+	return &Hierarchy{
+		redisClient: must.Nice(rds),
+		ttl:         must.Nice(ttl),
+		separator:   ":",
+		logger:      logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+		clock:       SystemClock(),
+	}
+}
+
+// WithSeparator sets the separator splitting the parent name away from the child name
+// WithSeparator 设置拆分父名称和子名称的分隔符
+func (h *Hierarchy) WithSeparator(separator string) *Hierarchy {
+	h.separator = must.Nice(separator)
+	return h
+}
+
+// WithLogger sets custom logger used in hierarchy operations
+// WithLogger 为层级锁操作设置自定义日志记录器
+func (h *Hierarchy) WithLogger(logger logging.Logger) *Hierarchy {
+	h.logger = logger
+	return h
+}
+
+// WithClock sets the clock used in computing expiry and hold durations
+// WithClock 设置计算过期时间和持有时长使用的时钟
+func (h *Hierarchy) WithClock(clock Clock) *Hierarchy {
+	h.clock = must.Nice(clock)
+	return h
+}
+
+const (
+	// KEYS: child, then per ancestor nearest first: ancestor, its children set, its intent / ARGV: session, ttl milliseconds
+	// A session already holding the child just refreshes it, a held or pending ancestor only blocks newcomers
+	// KEYS: 子锁，然后按由近及远的每个祖先：祖先锁、其子集合、其意向 / ARGV: 会话、TTL 毫秒数
+	// 已持有子锁的会话仅刷新它，被持有或等待中的祖先只阻止新来者
+	commandAcquireChild = `redis.replicate_commands()
+local cur = redis.call("GET", KEYS[1])
+if cur and cur ~= ARGV[1] then
+    return false
+end
+if not cur then
+    for i = 2, #KEYS, 3 do
+        if redis.call("EXISTS", KEYS[i]) == 1 or redis.call("EXISTS", KEYS[i + 2]) == 1 then
+            return "PARENT"
+        end
+    end
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+for i = 3, #KEYS, 3 do
+    redis.call("ZADD", KEYS[i], ms + tonumber(ARGV[2]), KEYS[1])
+    if redis.call("PTTL", KEYS[i]) < tonumber(ARGV[2]) then
+        redis.call("PEXPIRE", KEYS[i], ARGV[2])
+    end
+end
+return "OK"`
+
+	// KEYS: parent, parent children set, parent intent, then ancestor triples as in commandAcquireChild / ARGV: session, ttl milliseconds
+	// Expired descendants are pruned, active descendants make the parent record its intent and wait
+	// A held parent registers under its own ancestors like a child
+	// KEYS: 父锁、父锁子集合、父锁意向，然后是与 commandAcquireChild 相同的祖先三元组 / ARGV: 会话、TTL 毫秒数
+	// 清理已过期的后代，存在活跃后代时父锁记录意向并等待
+	// 持有的父锁像子锁一样在其祖先下登记
+	commandAcquireParent = `redis.replicate_commands()
+local cur = redis.call("GET", KEYS[1])
+if cur and cur ~= ARGV[1] then
+    return false
+end
+if not cur then
+    for i = 4, #KEYS, 3 do
+        if redis.call("EXISTS", KEYS[i]) == 1 or redis.call("EXISTS", KEYS[i + 2]) == 1 then
+            return "PARENT"
+        end
+    end
+end
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", ms)
+if redis.call("ZCARD", KEYS[2]) > 0 then
+    local intent = redis.call("GET", KEYS[3])
+    if (not intent) or intent == ARGV[1] then
+        redis.call("SET", KEYS[3], ARGV[1], "PX", ARGV[2])
+    end
+    return "CHILDREN"
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+if redis.call("GET", KEYS[3]) == ARGV[1] then
+    redis.call("DEL", KEYS[3])
+end
+for i = 5, #KEYS, 3 do
+    redis.call("ZADD", KEYS[i], ms + tonumber(ARGV[2]), KEYS[1])
+    if redis.call("PTTL", KEYS[i]) < tonumber(ARGV[2]) then
+        redis.call("PEXPIRE", KEYS[i], ARGV[2])
+    end
+end
+return "OK"`
+
+	// KEYS: child, then the children set of each ancestor / ARGV: session
+	// Status codes follow commandRelease
+	// KEYS: 子锁，然后是每个祖先的子集合 / ARGV: 会话
+	// 状态码与 commandRelease 一致
+	commandReleaseChild = `local ch = redis.call("GET", KEYS[1])
+if ch and ch ~= ARGV[1] then
+    return 3
+end
+for i = 2, #KEYS do
+    redis.call("ZREM", KEYS[i], KEYS[1])
+end
+if (ch == false) then
+    return 2
+end
+return redis.call("DEL", KEYS[1])`
+
+	// KEYS: parent, parent intent, then the children set of each ancestor / ARGV: session
+	// Clears a pending intent left behind through an abandoned wait
+	// Status codes follow commandRelease
+	// KEYS: 父锁、父锁意向，然后是每个祖先的子集合 / ARGV: 会话
+	// 清除放弃等待时遗留的意向
+	// 状态码与 commandRelease 一致
+	commandReleaseParent = `if redis.call("GET", KEYS[2]) == ARGV[1] then
+    redis.call("DEL", KEYS[2])
+end
+local ch = redis.call("GET", KEYS[1])
+if ch and ch ~= ARGV[1] then
+    return 3
+end
+for i = 3, #KEYS do
+    redis.call("ZREM", KEYS[i], KEYS[1])
+end
+if (ch == false) then
+    return 2
+end
+return redis.call("DEL", KEYS[1])`
+)
+
+// ParentOf gets back the parent name of a child lock name, blank when there is no separator
+// ParentOf 返回子锁名的父名称，不含分隔符时返回空值
+func (h *Hierarchy) ParentOf(key string) string {
+	if idx := strings.LastIndex(key, h.separator); idx > 0 {
+		return key[:idx]
+	}
+	return ""
+}
+
+// ancestorsOf gets back the ancestor names of a lock name, nearest first
+// ancestorsOf 返回锁名的各级祖先名称，由近及远
+func (h *Hierarchy) ancestorsOf(key string) []string {
+	var ancestors []string
+	for parent := h.ParentOf(key); parent != ""; parent = h.ParentOf(parent) {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// ancestorKeys appends the lock, children set and intent of each ancestor of the key, nearest first
+// ancestorKeys 由近及远追加该键每个祖先的锁、子集合和意向
+func (h *Hierarchy) ancestorKeys(keys []string, key string) []string {
+	for _, ancestor := range h.ancestorsOf(key) {
+		keys = append(keys, ancestor, h.childrenKey(ancestor), h.intentKey(ancestor))
+	}
+	return keys
+}
+
+// ancestorChildrenKeys appends the children set of each ancestor of the key, nearest first
+// ancestorChildrenKeys 由近及远追加该键每个祖先的子集合
+func (h *Hierarchy) ancestorChildrenKeys(keys []string, key string) []string {
+	for _, ancestor := range h.ancestorsOf(key) {
+		keys = append(keys, h.childrenKey(ancestor))
+	}
+	return keys
+}
+
+// childrenKey gets back the sorted set tracking active descendants of a parent
+// childrenKey 返回跟踪父锁活跃后代的有序集合键
+func (h *Hierarchy) childrenKey(parent string) string {
+	return parent + h.separator + "children"
+}
+
+// intentKey gets back the key recording a pending exclusive parent acquisition
+// intentKey 返回记录等待中的父锁独占获取的键
+func (h *Hierarchy) intentKey(parent string) string {
+	return parent + h.separator + "intent"
+}
+
+// AcquireChild attempts acquiring a child lock and registering it under each of its ancestors
+// Gives back nil when the child is held through a different session or any ancestor is held or pending
+//
+// AcquireChild 尝试获取子锁并在其每一级祖先下登记
+// 当子锁被其他会话持有或任一祖先已被持有或等待中时返回 nil
+func (h *Hierarchy) AcquireChild(ctx context.Context, key string) (*Xin, error) {
+	return h.acquire(ctx, commandAcquireChild, h.childKeys(key), utils.NewUUID())
+}
+
+// AcquireAgainChild extends a held child lock using the same session
+// Pending parent intent does not block the extension of an active child
+//
+// AcquireAgainChild 使用相同会话延期已持有的子锁
+// 等待中的父锁意向不会阻止活跃子锁的延期
+func (h *Hierarchy) AcquireAgainChild(ctx context.Context, xin *Xin) (*Xin, error) {
+	res, err := h.acquire(ctx, commandAcquireChild, h.childKeys(xin.key), xin.sessionUUID)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if res != nil {
+		res.acquiredAt = xin.acquiredAt
+		res.extensions = xin.extensions + 1
+	}
+	return res, nil
+}
+
+// childKeys gets back the KEYS of commandAcquireChild
+// childKeys 返回 commandAcquireChild 的 KEYS
+func (h *Hierarchy) childKeys(key string) []string {
+	must.OK(h.ParentOf(key)) // Child name must contain the separator // 子锁名必须包含分隔符
+	return h.ancestorKeys([]string{key}, key)
+}
+
+// AcquireParent attempts acquiring an exclusive parent lock
+// Gives back nil while descendants are active, recording intent that blocks new descendants
+// Gives back nil as well while an ancestor of the parent is held or pending
+// Call it again through a retry loop until the remaining children finish
+//
+// AcquireParent 尝试获取独占父锁
+// 存在活跃后代时返回 nil，并记录阻止新后代的意向
+// 父锁的祖先被持有或等待中时同样返回 nil
+// 通过重试循环再次调用直到剩余子锁结束
+func (h *Hierarchy) AcquireParent(ctx context.Context, key string) (*Xin, error) {
+	return h.AcquireParentWithSession(ctx, key, utils.NewUUID())
+}
+
+// AcquireParentWithSession attempts acquiring an exclusive parent lock using the given session
+// Reusing the session across retries keeps ownership of the recorded intent
+//
+// AcquireParentWithSession 使用给定会话尝试获取独占父锁
+// 在重试中复用会话可保持对已记录意向的所有权
+func (h *Hierarchy) AcquireParentWithSession(ctx context.Context, key string, sessionUUID string) (*Xin, error) {
+	return h.acquire(ctx, commandAcquireParent, h.ancestorKeys([]string{key, h.childrenKey(key), h.intentKey(key)}, key), sessionUUID)
+}
+
+// ReleaseChild releases a child lock and removes it from the registry of each ancestor
+// ReleaseChild 释放子锁并将其从每一级祖先的登记中移除
+func (h *Hierarchy) ReleaseChild(ctx context.Context, xin *Xin) (bool, error) {
+	must.OK(h.ParentOf(xin.key))
+	return h.release(ctx, commandReleaseChild, h.ancestorChildrenKeys([]string{xin.key}, xin.key), xin.sessionUUID)
+}
+
+// ReleaseParent releases an exclusive parent lock together with its pending intent and its ancestor registrations
+// ReleaseParent 释放独占父锁及其等待中的意向和在祖先下的登记
+func (h *Hierarchy) ReleaseParent(ctx context.Context, xin *Xin) (bool, error) {
+	return h.release(ctx, commandReleaseParent, h.ancestorChildrenKeys([]string{xin.key, h.intentKey(xin.key)}, xin.key), xin.sessionUUID)
+}
+
+// acquire runs one of the hierarchy acquire scripts, keys[0] is the lock being taken
+// acquire 执行层级获取脚本之一，keys[0] 是要获取的锁
+func (h *Hierarchy) acquire(ctx context.Context, command string, keys []string, sessionUUID string) (*Xin, error) {
+	LOG := h.logger.WithMeta(
+		zap.String("action", "申请层级锁"),
+		zap.String("k", keys[0]),
+		zap.String("v", sessionUUID),
+	)
+
+	var startTime = h.clock.Now()
+	result, err := h.redisClient.Eval(ctx, command, keys, []string{sessionUUID, strconv.FormatInt(h.ttl.Milliseconds(), 10)}).Result()
+	if errors.Is(err, redis.Nil) {
+		LOG.DebugLog("锁已经被占用-申请不到-请等待释放")
+		return nil, nil
+	} else if err != nil {
+		LOG.ErrorLog("请求报错", zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	switch result {
+	case "OK":
+		LOG.DebugLog("锁已成功申请")
+		// Start time plus TTL equals the conservative estimate of AcquireLockWithSession
+		// 开始时间加 TTL 等同于 AcquireLockWithSession 的保守估算
+		return &Xin{key: keys[0], sessionUUID: sessionUUID, expire: startTime.Add(h.ttl), acquiredAt: startTime}, nil
+	case "PARENT":
+		LOG.DebugLog("父锁已被占用或等待中-请等待释放")
+		return nil, nil
+	case "CHILDREN":
+		LOG.DebugLog("子锁仍在使用-已登记意向-请等待释放")
+		return nil, nil
+	default:
+		LOG.ErrorLog("回复非预期内容", zap.Any("result", result))
+		return nil, nil
+	}
+}
+
+// release runs one of the hierarchy release scripts sharing the commandRelease status codes
+// release 执行与 commandRelease 状态码一致的层级释放脚本之一
+func (h *Hierarchy) release(ctx context.Context, command string, keys []string, sessionUUID string) (bool, error) {
+	LOG := h.logger.WithMeta(
+		zap.String("action", "释放层级锁"),
+		zap.String("k", keys[0]),
+		zap.String("v", sessionUUID),
+	)
+
+	statusCode, err := h.redisClient.Eval(ctx, command, keys, []string{sessionUUID}).Int64()
+	if err != nil {
+		LOG.ErrorLog("请求报错", zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	switch statusCode {
+	case 0, 1, 2:
+		LOG.DebugLog("锁已释放", zap.Int64("statusCode", statusCode))
+		return true, nil
+	default:
+		LOG.DebugLog("释放出错-锁被其它线程占用", zap.Int64("statusCode", statusCode))
+		return false, nil
+	}
+}
@@ -0,0 +1,124 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHierarchy_ParentWaitsChildren validates that a parent waits until children finish
+// Tests that a pending parent intent blocks new children and the parent then blocks children
+//
+// TestHierarchy_ParentWaitsChildren 验证父锁等待子锁结束
+// 测试等待中的父锁意向阻止新子锁，父锁获取后继续阻止子锁
+func TestHierarchy_ParentWaitsChildren(t *testing.T) {
+	ctx := context.Background()
+	hierarchy := redissuo.NewHierarchy(caseRedisClient, 5*time.Second)
+
+	parent := "{" + utils.NewUUID() + "}"
+
+	child, err := hierarchy.AcquireChild(ctx, parent+":1")
+	require.NoError(t, err)
+	require.NotNil(t, child)
+
+	sessionUUID := utils.NewUUID()
+	xin, err := hierarchy.AcquireParentWithSession(ctx, parent, sessionUUID)
+	require.NoError(t, err)
+	require.Nil(t, xin) // Child active, intent recorded
+
+	other, err := hierarchy.AcquireChild(ctx, parent+":2")
+	require.NoError(t, err)
+	require.Nil(t, other) // Blocked through parent intent
+
+	again, err := hierarchy.AcquireAgainChild(ctx, child)
+	require.NoError(t, err)
+	require.NotNil(t, again) // Existing child keeps refreshing
+
+	success, err := hierarchy.ReleaseChild(ctx, again)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xin, err = hierarchy.AcquireParentWithSession(ctx, parent, sessionUUID)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	other, err = hierarchy.AcquireChild(ctx, parent+":2")
+	require.NoError(t, err)
+	require.Nil(t, other) // Blocked through held parent
+
+	success, err = hierarchy.ReleaseParent(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	other, err = hierarchy.AcquireChild(ctx, parent+":2")
+	require.NoError(t, err)
+	require.NotNil(t, other)
+
+	success, err = hierarchy.ReleaseChild(ctx, other)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestHierarchy_Nested validates nested names register under every ancestor, not just the nearest one
+// TestHierarchy_Nested 验证嵌套名称在每一级祖先下登记，而不仅仅是最近的一级
+func TestHierarchy_Nested(t *testing.T) {
+	ctx := context.Background()
+	hierarchy := redissuo.NewHierarchy(caseRedisClient, 5*time.Second)
+
+	root := "{" + utils.NewUUID() + "}"
+	region := root + ":eu"
+
+	child, err := hierarchy.AcquireChild(ctx, region+":1")
+	require.NoError(t, err)
+	require.NotNil(t, child)
+	require.False(t, child.AcquiredAt().IsZero())
+
+	sessionUUID := utils.NewUUID()
+	xin, err := hierarchy.AcquireParentWithSession(ctx, root, sessionUUID)
+	require.NoError(t, err)
+	require.Nil(t, xin) // Grandchild active, intent recorded
+
+	blocked, err := hierarchy.AcquireParent(ctx, region)
+	require.NoError(t, err)
+	require.Nil(t, blocked) // Grandparent pending
+
+	success, err := hierarchy.ReleaseChild(ctx, child)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xin, err = hierarchy.AcquireParentWithSession(ctx, root, sessionUUID)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	other, err := hierarchy.AcquireChild(ctx, region+":2")
+	require.NoError(t, err)
+	require.Nil(t, other) // Grandparent held
+
+	success, err = hierarchy.ReleaseParent(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	middle, err := hierarchy.AcquireParent(ctx, region)
+	require.NoError(t, err)
+	require.NotNil(t, middle)
+
+	sessionUUID = utils.NewUUID()
+	xin, err = hierarchy.AcquireParentWithSession(ctx, root, sessionUUID)
+	require.NoError(t, err)
+	require.Nil(t, xin) // Descendant parent held
+
+	success, err = hierarchy.ReleaseParent(ctx, middle)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xin, err = hierarchy.AcquireParentWithSession(ctx, root, sessionUUID)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err = hierarchy.ReleaseParent(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
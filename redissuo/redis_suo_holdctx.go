@@ -0,0 +1,136 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+)
+
+// ErrLockLost is the cause of a hold context cancelled since the lease ran out or an extension was refused
+// ErrLockLost 是租期耗尽或延期被拒绝而取消的持有上下文的原因
+var ErrLockLost = NewError(CodeLockLost, LanguageEnglish, nil)
+
+// leaseWatch cancels the hold contexts of a session once exclusivity is gone, extensions push it back
+// leaseWatch 在失去独占后取消会话的持有上下文，延期会推迟取消时间
+type leaseWatch struct {
+	mutex   sync.Mutex                // Guards the fields below // 保护下面的字段
+	timer   Timer                     // Fires at the conservative expiry // 在保守过期时刻触发
+	cancels []context.CancelCauseFunc // Hold contexts of the session // 会话的持有上下文
+	done    bool                      // Contexts cancelled already // 上下文已被取消
+}
+
+// HoldContext derives a context cancelled once the session stops holding the lock
+// Cancellation comes when the lease runs out without extension, when an extension is refused,
+// and when the session is released, context.Cause gives back ErrLockLost in the first two cases
+// Extensions through this lock keep the context alive, call it from the goroutine holding the session
+//
+// HoldContext 派生一个在会话不再持有锁时取消的上下文
+// 租期耗尽且未延期、延期被拒绝以及会话被释放时都会取消，前两种情况下 context.Cause 返回 ErrLockLost
+// 通过该锁的延期会使上下文保持有效，请在持有该会话的 goroutine 中调用
+func (o *Suo) HoldContext(parent context.Context, xin *Xin) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	watch := xin.lease
+	if watch == nil {
+		watch = &leaseWatch{}
+		watch.timer = o.clock.AfterFunc(max(xin.expire.Sub(o.clock.Now()), 0), func() {
+			watch.cancelAll(o.newError(CodeLockLost))
+		})
+		xin.lease = watch
+	}
+	watch.mutex.Lock()
+	if watch.done {
+		cancel(o.newError(CodeLockLost))
+	} else {
+		watch.cancels = append(watch.cancels, cancel)
+	}
+	watch.mutex.Unlock()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// extendLease pushes the cancellation back past a successful extension
+// extendLease 在成功延期后推迟取消时间
+func (o *Suo) extendLease(xin *Xin, res *Xin) {
+	watch := xin.lease
+	if watch == nil {
+		return
+	}
+	watch.mutex.Lock()
+	defer watch.mutex.Unlock()
+	if !watch.done {
+		watch.timer.Reset(max(res.expire.Sub(o.clock.Now()), 0))
+	}
+	res.lease = watch
+}
+
+// loseLease cancels the hold contexts once an extension is refused
+// loseLease 在延期被拒绝后取消持有上下文
+func (o *Suo) loseLease(xin *Xin) {
+	if watch := xin.lease; watch != nil {
+		watch.cancelAll(o.newError(CodeLockLost))
+	}
+}
+
+// stopLease cancels the hold contexts once the session is released
+// stopLease 在会话释放后取消持有上下文
+func (o *Suo) stopLease(xin *Xin) {
+	if watch := xin.lease; watch != nil {
+		watch.cancelAll(context.Canceled)
+	}
+}
+
+// cancelAll cancels every hold context with the cause, just the first call counts
+// cancelAll 以给定原因取消全部持有上下文，仅首次调用生效
+func (w *leaseWatch) cancelAll(cause error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.done {
+		return
+	}
+	w.done = true
+	w.timer.Stop()
+	for _, cancel := range w.cancels {
+		cancel(cause)
+	}
+	w.cancels = nil
+}
+
+// HoldGroup runs goroutines inside the protected section, all of them stop once the lock is lost
+// Works like errgroup: the first problem cancels the shared context and Wait gives it back
+//
+// HoldGroup 在受保护区域内运行 goroutine，一旦丢失锁它们全部停止
+// 用法与 errgroup 相同：首个错误会取消共享上下文，并由 Wait 返回
+type HoldGroup struct {
+	cancel context.CancelFunc // Cancels the shared context // 取消共享上下文
+	wg     sync.WaitGroup     // Running goroutines // 运行中的 goroutine
+	once   sync.Once          // Keeps the first problem // 保留首个错误
+	err    error              // First problem // 首个错误
+}
+
+// HoldGroup creates a group whose context is the hold context of the session
+// HoldGroup 创建以会话的持有上下文作为上下文的分组
+func (o *Suo) HoldGroup(parent context.Context, xin *Xin) (*HoldGroup, context.Context) {
+	ctx, cancel := o.HoldContext(parent, xin)
+	return &HoldGroup{cancel: cancel}, ctx
+}
+
+// Go runs the function in a fresh goroutine, the first problem cancels the context of the group
+// Go 在新的 goroutine 中运行函数，首个错误会取消分组的上下文
+func (g *HoldGroup) Go(run func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := run(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+// Wait blocks until all goroutines return and gives back the first problem
+// Wait 阻塞直到全部 goroutine 返回，并返回首个错误
+func (g *HoldGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
@@ -0,0 +1,78 @@
+package redissuo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_HoldContext validates the hold context survives extensions and ends with ErrLockLost once the lease runs out
+// TestSuo_HoldContext 验证持有上下文在延期后仍有效，并在租期耗尽时以 ErrLockLost 结束
+func TestSuo_HoldContext(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 100*time.Millisecond)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	holdCtx, cancel := suo.HoldContext(ctx, xin)
+	defer cancel()
+
+	time.Sleep(60 * time.Millisecond)
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, holdCtx.Err())
+
+	<-holdCtx.Done()
+	require.ErrorIs(t, context.Cause(holdCtx), redissuo.ErrLockLost)
+}
+
+// TestSuo_HoldContext_Release validates releasing the session cancels the hold context
+// TestSuo_HoldContext_Release 验证释放会话会取消持有上下文
+func TestSuo_HoldContext_Release(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+
+	holdCtx, cancel := suo.HoldContext(ctx, xin)
+	defer cancel()
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.ErrorIs(t, holdCtx.Err(), context.Canceled)
+	require.NotErrorIs(t, context.Cause(holdCtx), redissuo.ErrLockLost)
+}
+
+// TestSuo_HoldGroup validates the first problem stops the sibling goroutines and comes back through Wait
+// TestSuo_HoldGroup 验证首个错误会停止同组 goroutine 并由 Wait 返回
+func TestSuo_HoldGroup(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+
+	errBoom := errors.New("boom")
+	group, groupCtx := suo.HoldGroup(ctx, xin)
+	group.Go(func() error {
+		<-groupCtx.Done()
+		return nil
+	})
+	group.Go(func() error {
+		return errBoom
+	})
+	require.ErrorIs(t, group.Wait(), errBoom)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,21 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+)
+
+// Holder gets back the session holding the lock at present, blank when the lock is free
+// Holder 返回当前持有锁的会话，锁空闲时为空
+func (o *Suo) Holder(ctx context.Context) (string, error) {
+	holder, err := o.client().Get(ctx, o.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	} else if err != nil {
+		return "", erero.Wro(err)
+	}
+	return holder, nil
+}
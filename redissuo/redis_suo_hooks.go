@@ -0,0 +1,63 @@
+package redissuo
+
+import (
+	"context"
+)
+
+// Hooks plugs side effects into the lock lifecycle, e.g. metrics, audit records or admission control
+// Each hook is optional, nil hooks are skipped, and hooks run on the goroutine of the lock operation
+// OnRetry is called through the runner between attempts, a problem from it ends the wait with that problem
+//
+// Hooks 在锁生命周期中接入副作用，例如指标、审计记录或准入控制
+// 每个钩子都是可选的，为 nil 时跳过，钩子在锁操作所在的 goroutine 上运行
+// OnRetry 由运行器在两次尝试之间调用，其返回的错误会以该错误结束等待
+type Hooks struct {
+	OnAcquire func(ctx context.Context, xin *Xin)                                 // Fresh session acquired // 获取到新会话
+	OnExtend  func(ctx context.Context, xin *Xin)                                 // Session extended // 会话已延期
+	OnRelease func(ctx context.Context, xin *Xin, released bool)                  // Release done, released false when the lock was lost // 释放完成，锁已丢失时 released 为 false
+	OnRetry   func(ctx context.Context, key string, attempt int, err error) error // Attempt missed, err nil when the lock was busy // 尝试未成功，锁被占用时 err 为 nil
+}
+
+// WithHooks sets the lifecycle hooks of this lock
+// WithHooks 设置该锁的生命周期钩子
+func (o *Suo) WithHooks(hooks *Hooks) *Suo {
+	o.hooks = hooks
+	return o
+}
+
+// WithHooks sets the lifecycle hooks of locks created through the manager
+// WithHooks 设置通过管理器创建的锁的生命周期钩子
+func (m *Manager) WithHooks(hooks *Hooks) *Manager {
+	m.hooks = hooks
+	return m
+}
+
+// Hooks gets back the lifecycle hooks of the lock, nil when unset
+// Hooks 返回锁的生命周期钩子，未设置时为 nil
+func (o *Suo) Hooks() *Hooks {
+	return o.hooks
+}
+
+// hookAcquired runs OnAcquire once a fresh session got its lease
+// hookAcquired 在新会话获得租期后运行 OnAcquire
+func (o *Suo) hookAcquired(ctx context.Context, xin *Xin) {
+	if o.hooks != nil && o.hooks.OnAcquire != nil {
+		o.hooks.OnAcquire(ctx, xin)
+	}
+}
+
+// hookExtended runs OnExtend once the hold state moved over to the extended session
+// hookExtended 在持有状态转移到延期后的会话后运行 OnExtend
+func (o *Suo) hookExtended(ctx context.Context, xin *Xin) {
+	if o.hooks != nil && o.hooks.OnExtend != nil {
+		o.hooks.OnExtend(ctx, xin)
+	}
+}
+
+// hookReleased runs OnRelease once the release got its outcome
+// hookReleased 在释放得到结果后运行 OnRelease
+func (o *Suo) hookReleased(ctx context.Context, xin *Xin, released bool) {
+	if o.hooks != nil && o.hooks.OnRelease != nil {
+		o.hooks.OnRelease(ctx, xin, released)
+	}
+}
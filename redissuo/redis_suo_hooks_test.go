@@ -0,0 +1,47 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithHooks validates the hooks run at acquisition, extension and release
+// TestSuo_WithHooks 验证钩子在获取、延期和释放时运行
+func TestSuo_WithHooks(t *testing.T) {
+	ctx := context.Background()
+	var steps []string
+	hooks := &redissuo.Hooks{
+		OnAcquire: func(ctx context.Context, xin *redissuo.Xin) {
+			steps = append(steps, "acquire")
+		},
+		OnExtend: func(ctx context.Context, xin *redissuo.Xin) {
+			require.Equal(t, 1, xin.Extensions())
+			steps = append(steps, "extend")
+		},
+		OnRelease: func(ctx context.Context, xin *redissuo.Xin, released bool) {
+			require.True(t, released)
+			steps = append(steps, "release")
+		},
+	}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithHooks(hooks)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	other, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, other)
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	require.Equal(t, []string{"acquire", "extend", "release"}, steps)
+}
@@ -0,0 +1,74 @@
+package redissuo_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithHolderIdentity validates Inspect shows the hostname, PID and labels of the holder
+// TestSuo_WithHolderIdentity 验证 Inspect 显示持有者的主机名、PID 和标签
+func TestSuo_WithHolderIdentity(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).
+		WithHolderIdentity(true).
+		WithTags(map[string]string{"job": "billing"})
+
+	info, err := suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.False(t, info.Held())
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	info, err = suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.True(t, info.Held())
+	require.Equal(t, xin.SessionUUID(), info.Holder)
+	require.Positive(t, info.TTL)
+	require.NotNil(t, info.Metadata)
+	hostname, _ := os.Hostname()
+	require.Equal(t, hostname, info.Metadata.Hostname)
+	require.Equal(t, os.Getpid(), info.Metadata.PID)
+	require.NotNil(t, info.Metadata.AcquiredAt)
+	require.Equal(t, "billing", info.Metadata.Tags["job"])
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_UpdateMetadata validates the metadata gets rewritten in place for the holder and refused once the lock is gone
+// TestSuo_UpdateMetadata 验证持有者可原地改写元数据，锁已不存在时被拒绝
+func TestSuo_UpdateMetadata(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"stage": "load"})
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	suo.WithTags(map[string]string{"stage": "merge"})
+	updated, err := suo.UpdateMetadata(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	info, err := suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "merge", info.Metadata.Tags["stage"])
+	require.Positive(t, info.TTL)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	updated, err = suo.UpdateMetadata(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, updated)
+}
@@ -0,0 +1,63 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+const (
+	// KEYS: lock, metadata companion / ARGV: session
+	// Reads holder, remaining TTL and metadata like commandInspectMeta, then whether the session is the holder
+	// KEYS: 锁、元数据伴随键 / ARGV: 会话
+	// 与 commandInspectMeta 一样读取持有者、剩余 TTL 和元数据，再判断该会话是否为持有者
+	commandInspectSession = `local v = redis.call("GET", KEYS[1])
+if not v then
+    return false
+end
+local owned = 0
+if v == ARGV[1] then
+    owned = 1
+end
+return {v, redis.call("PTTL", KEYS[1]), redis.call("GET", KEYS[2]) or "", owned}`
+)
+
+// Inspect reads holder, remaining TTL and metadata of the lock in one atomic step
+// The holder is blank and the TTL zero when the lock is free
+// Owned marks a holder held through the manager of the lock, outside a manager use InspectSession
+//
+// Inspect 原子读取锁的持有者、剩余 TTL 和元数据
+// 锁空闲时持有者为空且 TTL 为零
+// Owned 标记持有者为该锁所属管理器持有的会话，不属于管理器时请使用 InspectSession
+func (o *Suo) Inspect(ctx context.Context) (*LockInfo, error) {
+	if o.simulated("inspect") {
+		return &LockInfo{Key: o.key}, nil
+	}
+	result, err := o.eval(ctx, commandInspectMeta, []string{o.key, o.metaKey()})
+	info, err := parseLockReply(o.key, result, err, o.codec)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	info.Owned = info.Held() && o.holds != nil && o.holds.has(o.key, info.Holder)
+	return info, nil
+}
+
+// InspectSession reads the lock like Inspect, with Owned marking whether the given session is the holder
+// The session is compared with the stored value in the same script, so it works with or without a manager
+// Pass xin.SessionUUID() of a held lock, or a session UUID carried over from elsewhere
+//
+// InspectSession 与 Inspect 一样读取锁，Owned 标记给定会话是否为持有者
+// 会话在同一脚本中与存储的值比较，因此无论是否使用管理器都有效
+// 传入所持锁的 xin.SessionUUID()，或从其它地方传来的会话 UUID
+func (o *Suo) InspectSession(ctx context.Context, sessionUUID string) (*LockInfo, error) {
+	if o.simulated("inspect") {
+		return &LockInfo{Key: o.key}, nil
+	}
+	result, err := o.eval(ctx, commandInspectSession, []string{o.key, o.metaKey()}, must.Nice(sessionUUID))
+	info, err := parseLockReply(o.key, result, err, o.codec)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return info, nil
+}
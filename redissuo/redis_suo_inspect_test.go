@@ -0,0 +1,88 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_Inspect validates Inspect reports the holder, the TTL and whether the caller owns the lock
+// TestSuo_Inspect 验证 Inspect 报告持有者、TTL 以及调用方是否持有锁
+func TestSuo_Inspect(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	info, err := suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), info.Holder)
+	require.Greater(t, info.TTL, 4*time.Second)
+	require.True(t, info.Owned)
+	require.True(t, info.OwnedBy(xin))
+
+	// Another process holding the lock is not owned here
+	// 其它进程持有的锁在此处不属于调用方
+	require.NoError(t, caseRedisClient.Set(ctx, suo.Key(), "elsewhere", time.Second).Err())
+	info, err = suo.Inspect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "elsewhere", info.Holder)
+	require.False(t, info.Owned)
+	require.False(t, info.OwnedBy(xin))
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, success)
+
+	// A standalone lock checks the session through OwnedBy
+	// 独立的锁通过 OwnedBy 检查会话
+	standalone := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+	xin, err = standalone.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	info, err = standalone.Inspect(ctx)
+	require.NoError(t, err)
+	require.False(t, info.Owned)
+	require.True(t, info.OwnedBy(xin))
+	success, err = standalone.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_InspectSession validates the session is compared with the stored holder without a manager
+// TestSuo_InspectSession 验证无需管理器即可将会话与存储的持有者比较
+func TestSuo_InspectSession(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"job": "billing"})
+
+	info, err := suo.InspectSession(ctx, utils.NewUUID())
+	require.NoError(t, err)
+	require.False(t, info.Held())
+	require.False(t, info.Owned)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	info, err = suo.InspectSession(ctx, xin.SessionUUID())
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), info.Holder)
+	require.True(t, info.Owned)
+	require.Equal(t, "billing", info.Metadata.Tags["job"])
+
+	info, err = suo.InspectSession(ctx, utils.NewUUID())
+	require.NoError(t, err)
+	require.True(t, info.Held())
+	require.False(t, info.Owned)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,52 @@
+package redissuo
+
+import (
+	"time"
+
+	"github.com/yyle88/must"
+)
+
+// WithTTLJitter spreads the lease of each fresh acquisition across ttl ± fraction*ttl, e.g. 0.1 gives ±10%
+// Locks created in one burst with the same TTL otherwise lapse in one burst too and get contended again at once
+// Extensions keep their own lease, the jitter draws from the random source set through WithRandom
+// A fraction of 0 disables the jitter
+//
+// WithTTLJitter 使每次新获取的租期分布在 ttl ± fraction*ttl 之间，例如 0.1 表示 ±10%
+// 否则同一批以相同 TTL 创建的锁也会同一批失效，并立即再次被争抢
+// 延期保持其自身的租期，抖动从 WithRandom 设置的随机源中取数
+// fraction 为 0 时禁用抖动
+func (o *Suo) WithTTLJitter(fraction float64) *Suo {
+	must.True(fraction >= 0 && fraction < 1)
+	o.ttlJitter = fraction
+	return o
+}
+
+// WithTTLJitter sets the TTL jitter of locks created through the manager, see Suo.WithTTLJitter
+// WithTTLJitter 设置通过管理器创建的锁的 TTL 抖动，参见 Suo.WithTTLJitter
+func (m *Manager) WithTTLJitter(fraction float64) *Manager {
+	must.True(fraction >= 0 && fraction < 1)
+	m.ttlJitter = fraction
+	return m
+}
+
+// jitterTTL draws the lease of one fresh acquisition from the jitter band around the TTL
+// jitterTTL 从 TTL 周围的抖动区间中抽取单次新获取的租期
+func (o *Suo) jitterTTL() time.Duration {
+	band := time.Duration(float64(o.ttl) * o.ttlJitter)
+	if band <= 0 {
+		return o.ttl
+	}
+	return max(o.ttl-band+time.Duration(o.random.Int63n(int64(2*band)+1)), time.Millisecond)
+}
+
+// freshTTL gets back the lease of one fresh acquisition, jittered and capped at the max hold duration
+// freshTTL 返回单次新获取的租期，经过抖动并以最大持有时长为上限
+func (o *Suo) freshTTL() time.Duration {
+	// The first lease never outlasts the max hold duration
+	// 首次租期不会超过最大持有时长
+	ttl := o.jitterTTL()
+	if o.maxHold > 0 {
+		ttl = min(ttl, o.maxHold)
+	}
+	return ttl
+}
@@ -0,0 +1,38 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithTTLJitter validates fresh leases spread within the band while the plain TTL stays exact
+// TestSuo_WithTTLJitter 验证新租期分布在抖动区间内，而未设置抖动时 TTL 保持精确
+func TestSuo_WithTTLJitter(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithRandom(redissuo.NewSeededRandom(1)).WithTTLJitter(0.1)
+
+	leases := map[time.Duration]bool{}
+	for range 20 {
+		suo := manager.NewSuo(utils.NewUUID(), 10*time.Second)
+		xin, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+		lease := caseRedisClient.PTTL(ctx, suo.Key()).Val()
+		require.GreaterOrEqual(t, lease, 9*time.Second)
+		require.LessOrEqual(t, lease, 11*time.Second)
+		require.WithinDuration(t, time.Now().Add(lease), xin.Expire(), 100*time.Millisecond)
+		leases[lease] = true
+	}
+	require.Greater(t, len(leases), 1)
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 10*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 10*time.Second, caseRedisClient.PTTL(ctx, suo.Key()).Val())
+}
@@ -0,0 +1,123 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// KeepAlive extends a held session on a background goroutine, a watchdog of jobs with unpredictable duration
+// Renewal goes on until the context ends, Stop is called, or an extension fails, Done tells when it is over
+//
+// KeepAlive 在后台 goroutine 中延期所持会话，是执行时长不可预测的任务的看门狗
+// 续期持续进行，直到上下文结束、调用 Stop 或延期失败，Done 告知何时结束
+type KeepAlive struct {
+	suo    *Suo               // Lock of the session // 会话所属的锁
+	cancel context.CancelFunc // Stops the goroutine // 停止 goroutine
+	done   chan struct{}      // Closed once renewal is over // 续期结束后关闭
+	mutex  sync.Mutex         // Protects xin and err // 保护 xin 和 err
+	xin    *Xin               // Latest session // 最新的会话
+	err    error              // Problem ending the renewal, nil when stopped on purpose // 导致续期结束的错误，主动停止时为 nil
+}
+
+// KeepAlive starts renewing the session at the interval, 0 picks a third of the TTL
+// A refused extension ends the renewal with ErrLockLost, a Redis problem ends it with that problem
+//
+// KeepAlive 开始按该间隔续期会话，0 表示使用 TTL 的三分之一
+// 延期被拒绝时以 ErrLockLost 结束续期，Redis 错误时以该错误结束
+func (o *Suo) KeepAlive(ctx context.Context, xin *Xin, interval time.Duration) *KeepAlive {
+	must.Nice(xin)
+	must.True(interval >= 0)
+	if interval == 0 {
+		interval = max(o.ttl/3, time.Millisecond)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	k := &KeepAlive{suo: o, cancel: cancel, done: make(chan struct{}), xin: xin}
+	go k.run(ctx, interval)
+	return k
+}
+
+// run extends the session at each tick until the context ends or an extension fails
+// Ticks where ShouldExtend reports the lease still above the extend threshold skip the extension
+//
+// run 在每个间隔延期会话，直到上下文结束或延期失败
+// ShouldExtend 判断租期仍高于延期阈值时，该间隔跳过延期
+func (k *KeepAlive) run(ctx context.Context, interval time.Duration) {
+	defer close(k.done)
+	o := k.suo
+	timer := o.clock.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		}
+		xin := k.Xin()
+		if !o.ShouldExtend(xin) {
+			timer.Reset(min(interval, o.extendWait(xin)))
+			continue
+		}
+		res, err := o.extendHold(ctx, xin)
+		if err != nil {
+			if ctx.Err() != nil {
+				return // Stopped during the extension // 在延期期间被停止
+			}
+			o.logger.ErrorLog("自动续期报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+			k.finish(nil, erero.Wro(err))
+			return
+		}
+		if res == nil {
+			o.logger.ErrorLog("自动续期失败-锁已丢失", zap.String("k", o.key), zap.String("v", xin.sessionUUID))
+			k.finish(nil, o.newError(CodeLockLost))
+			return
+		}
+		k.finish(res, nil)
+		timer.Reset(interval)
+	}
+}
+
+// finish stores the latest session or the problem ending the renewal
+// finish 保存最新的会话或导致续期结束的错误
+func (k *KeepAlive) finish(xin *Xin, err error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	if xin != nil {
+		k.xin = xin
+	}
+	k.err = err
+}
+
+// Xin gets back the latest session, the one to release once the work is done
+// Xin 返回最新的会话，即工作完成后需要释放的会话
+func (k *KeepAlive) Xin() *Xin {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.xin
+}
+
+// Err gets back the problem ending the renewal, nil while running or when stopped on purpose
+// Err 返回导致续期结束的错误，运行中或主动停止时为 nil
+func (k *KeepAlive) Err() error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.err
+}
+
+// Done gets back a channel closed once the renewal is over
+// Done 返回续期结束后关闭的通道
+func (k *KeepAlive) Done() <-chan struct{} {
+	return k.done
+}
+
+// Stop ends the renewal and waits for the goroutine, giving back the latest session and the problem ending it, if any
+// Stop 结束续期并等待 goroutine 退出，返回最新的会话以及导致其结束的错误（如有）
+func (k *KeepAlive) Stop() (*Xin, error) {
+	k.cancel()
+	<-k.done
+	return k.Xin(), k.Err()
+}
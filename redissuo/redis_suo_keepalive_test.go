@@ -0,0 +1,91 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_KeepAlive validates the watchdog keeps the lock past its TTL until stopped
+// TestSuo_KeepAlive 验证看门狗使锁在超过 TTL 后仍被持有，直到停止
+func TestSuo_KeepAlive(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 90*time.Millisecond)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	keepAlive := suo.KeepAlive(ctx, xin, 0)
+	time.Sleep(250 * time.Millisecond)
+
+	holder, err := suo.Holder(ctx)
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), holder)
+
+	xin, err = keepAlive.Stop()
+	require.NoError(t, err)
+	require.Greater(t, xin.Extensions(), 0)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_KeepAlive_LockLost validates the watchdog ends with ErrLockLost once another session took the lock
+// TestSuo_KeepAlive_LockLost 验证锁被其它会话占用后看门狗以 ErrLockLost 结束
+func TestSuo_KeepAlive_LockLost(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Set(ctx, suo.Key(), "usurper", 5*time.Second).Err())
+
+	keepAlive := suo.KeepAlive(ctx, xin, 10*time.Millisecond)
+	select {
+	case <-keepAlive.Done():
+	case <-time.After(time.Second):
+		t.Fatal("renewal must end once the lock is lost")
+	}
+	require.ErrorIs(t, keepAlive.Err(), redissuo.ErrLockLost)
+
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+}
+
+// TestSuo_KeepAlive_ExtendThreshold validates the watchdog skips ticks while the lease stays above the extend threshold
+// Extensions begin once the lease drops below the threshold, and the lock stays held past its TTL
+//
+// TestSuo_KeepAlive_ExtendThreshold 验证租期仍高于延期阈值时看门狗跳过该间隔
+// 租期低于阈值后才开始延期，且锁在超过 TTL 后仍被持有
+func TestSuo_KeepAlive_ExtendThreshold(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 300*time.Millisecond).WithExtendThreshold(0.3)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	keepAlive := suo.KeepAlive(ctx, xin, 20*time.Millisecond)
+	time.Sleep(120 * time.Millisecond)
+	require.Zero(t, keepAlive.Xin().Extensions())
+
+	time.Sleep(300 * time.Millisecond)
+	holder, err := suo.Holder(ctx)
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), holder)
+
+	xin, err = keepAlive.Stop()
+	require.NoError(t, err)
+	require.Greater(t, xin.Extensions(), 0)
+	require.Less(t, xin.Extensions(), 10)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,67 @@
+package redissuo
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// keyspaceFreed lists the keyspace events freeing the lock key
+// keyspaceFreed 列出使锁键被释放的键空间事件
+var keyspaceFreed = map[string]bool{"del": true, "expired": true, "evicted": true}
+
+// WithKeyspaceNotify makes SubscribeRelease also listen on the keyspace notifications of the lock key
+// Waiters then learn of every way the lock frees up, TTL expiry and DEL included, without polling
+// Needs notify-keyspace-events with K plus g and x (or A) on the server, otherwise waiters fall back on polling
+//
+// WithKeyspaceNotify 使 SubscribeRelease 同时监听锁键的键空间通知
+// 等待者因此能感知锁的各种释放方式，包括 TTL 过期和 DEL，而无需轮询
+// 需要服务端的 notify-keyspace-events 包含 K 以及 g 和 x（或 A），否则等待者退回轮询
+func (o *Suo) WithKeyspaceNotify() *Suo {
+	o.keyspaceNotify = true
+	return o
+}
+
+// KeyspaceNotify reports whether SubscribeRelease listens on keyspace notifications
+// KeyspaceNotify 判断 SubscribeRelease 是否监听键空间通知
+func (o *Suo) KeyspaceNotify() bool {
+	return o.keyspaceNotify
+}
+
+// keyspacePattern gets back the pattern matching the keyspace channel of the lock key in any database
+// Glob characters of the key get escaped so the pattern matches the key alone
+//
+// keyspacePattern 返回匹配任意数据库中锁键的键空间频道的模式
+// 键中的通配字符会被转义，使模式只匹配该键
+func (o *Suo) keyspacePattern() string {
+	var escaped strings.Builder
+	for _, c := range o.key {
+		if strings.ContainsRune(`*?[]\`, c) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(c)
+	}
+	return "__keyspace@*__:" + escaped.String()
+}
+
+// keyspaceEnabled reports whether the server sends the keyspace events freeing the lock key
+// A server refusing CONFIG GET counts as disabled, e.g. managed offerings blocking the command
+//
+// keyspaceEnabled 判断服务端是否发送使锁键被释放的键空间事件
+// 拒绝 CONFIG GET 的服务端视为未开启，例如屏蔽该命令的托管服务
+func (o *Suo) keyspaceEnabled(ctx context.Context) bool {
+	config, err := o.client().ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		o.logger.DebugLog("键空间通知未开启-退回轮询", zap.String("k", o.key), zap.Error(err))
+		return false
+	}
+	flags := config["notify-keyspace-events"]
+	generic := strings.ContainsAny(flags, "gA")
+	expired := strings.ContainsAny(flags, "xA")
+	if !strings.Contains(flags, "K") || !generic || !expired {
+		o.logger.DebugLog("键空间通知未开启-退回轮询", zap.String("k", o.key), zap.String("flags", flags))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// keyspaceClient reports keyspace notifications as enabled, miniredis does not serve CONFIG GET
+// keyspaceClient 报告键空间通知已开启，miniredis 不支持 CONFIG GET
+type keyspaceClient struct {
+	redis.UniversalClient
+}
+
+func (c *keyspaceClient) ConfigGet(ctx context.Context, parameter string) *redis.MapStringStringCmd {
+	return redis.NewMapStringStringResult(map[string]string{parameter: "Kgx"}, nil)
+}
+
+// TestSuo_WithKeyspaceNotify validates subscribers wake on keyspace events freeing the key and skip other writes
+// TestSuo_WithKeyspaceNotify 验证订阅者在使键被释放的键空间事件上被唤醒，并忽略其它写入
+func TestSuo_WithKeyspaceNotify(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(&keyspaceClient{UniversalClient: caseRedisClient}, utils.NewUUID(), 5*time.Second).WithKeyspaceNotify()
+	require.True(t, suo.KeyspaceNotify())
+
+	sub, err := suo.SubscribeRelease(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sub.Close()) }()
+
+	// Stand in for the server, which publishes keyspace events on its own
+	// 代替服务端发布键空间事件
+	channel := "__keyspace@0__:" + suo.Key()
+	require.NoError(t, caseRedisClient.Publish(ctx, channel, "set").Err())
+	select {
+	case <-sub.C():
+		t.Fatal("writes must not wake subscribers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, caseRedisClient.Publish(ctx, channel, "expired").Err())
+	select {
+	case <-sub.C():
+	case <-time.After(time.Second):
+		t.Fatal("the expiry must wake subscribers")
+	}
+}
+
+// TestSuo_WithKeyspaceNotify_Disabled validates the subscription still works when the server sends no keyspace events
+// TestSuo_WithKeyspaceNotify_Disabled 验证服务端不发送键空间事件时订阅仍然可用
+func TestSuo_WithKeyspaceNotify_Disabled(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithKeyspaceNotify().WithReleaseNotify()
+
+	sub, err := suo.SubscribeRelease(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sub.Close()) }()
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	select {
+	case <-sub.C():
+	case <-time.After(time.Second):
+		t.Fatal("the release must wake subscribers")
+	}
+}
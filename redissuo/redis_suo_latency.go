@@ -0,0 +1,140 @@
+package redissuo
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// EventSlowOperation marks a lock round trip far slower than the recent ones, often the first symptom of Redis trouble
+// EventSlowOperation 表示某次锁操作往返远慢于近期水平，这通常是 Redis 故障的最早征兆
+const EventSlowOperation EventKind = "slow_operation"
+
+// Names of the operations measured through LatencyTracker
+// 通过 LatencyTracker 测量的操作名称
+const (
+	OperationAcquire = "acquire" // Acquire and extend round trips // 获取和延期的往返
+	OperationRelease = "release" // Release round trips // 释放的往返
+)
+
+const (
+	defaultLatencyWindow     = 256 // Recent samples kept per operation // 每种操作保留的近期样本数
+	defaultLatencyMinSamples = 32  // Samples needed ahead of the first warning // 首次警告之前需要的样本数
+)
+
+// LatencyTracker keeps a sliding window of round trip latencies per operation
+// A round trip beyond factor times the percentile of the window counts as an outlier
+// Shared across locks, so the window reflects the whole Redis path of the process
+//
+// LatencyTracker 为每种操作保留往返延迟的滑动窗口
+// 超过窗口分位数乘以系数的往返视为异常值
+// 可在多个锁之间共享，使窗口反映本进程的整个 Redis 访问路径
+type LatencyTracker struct {
+	mutex      sync.Mutex                 // Guards the windows // 保护窗口
+	percentile float64                    // Percentile of the threshold, e.g. 0.99 // 阈值的分位数，例如 0.99
+	factor     float64                    // Multiple of the percentile counted as outlier // 视为异常值的分位数倍数
+	window     int                        // Samples kept per operation // 每种操作保留的样本数
+	minSamples int                        // Samples needed ahead of the first warning // 首次警告之前需要的样本数
+	samples    map[string][]time.Duration // Recent latencies per operation // 每种操作的近期延迟
+	cursor     map[string]int             // Next slot to overwrite per operation // 每种操作下一个被覆盖的位置
+}
+
+// NewLatencyTracker creates a tracker warning on round trips beyond factor times the percentile
+// NewLatencyTracker 创建在往返超过分位数乘以系数时发出警告的跟踪器
+func NewLatencyTracker(percentile float64, factor float64) *LatencyTracker {
+	must.True(percentile > 0 && percentile <= 1)
+	must.True(factor >= 1)
+	return &LatencyTracker{
+		percentile: percentile,
+		factor:     factor,
+		window:     defaultLatencyWindow,
+		minSamples: defaultLatencyMinSamples,
+		samples:    map[string][]time.Duration{},
+		cursor:     map[string]int{},
+	}
+}
+
+// WithWindow sets the count of recent samples kept per operation and needed ahead of the first warning
+// WithWindow 设置每种操作保留的近期样本数以及首次警告之前需要的样本数
+func (t *LatencyTracker) WithWindow(window int, minSamples int) *LatencyTracker {
+	must.True(window > 0)
+	must.True(minSamples <= window)
+	t.window = window
+	t.minSamples = minSamples
+	return t
+}
+
+// Threshold gets back the present outlier threshold of the operation, 0 while samples are short
+// Threshold 返回该操作当前的异常阈值，样本不足时返回 0
+func (t *LatencyTracker) Threshold(operation string) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.threshold(operation)
+}
+
+// threshold computes factor times the percentile of the window, the caller holds the mutex
+// threshold 计算窗口分位数乘以系数，调用方需持有互斥锁
+func (t *LatencyTracker) threshold(operation string) time.Duration {
+	samples := t.samples[operation]
+	if len(samples) < t.minSamples || len(samples) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	index := int(math.Ceil(t.percentile*float64(len(sorted)))) - 1
+	return time.Duration(float64(sorted[max(index, 0)]) * t.factor)
+}
+
+// observe checks the latency against the threshold of the window, then adds it to the window
+// observe 先以窗口阈值检查该延迟，再将其加入窗口
+func (t *LatencyTracker) observe(operation string, latency time.Duration) (time.Duration, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	threshold := t.threshold(operation)
+	samples := t.samples[operation]
+	if len(samples) < t.window {
+		t.samples[operation] = append(samples, latency)
+	} else {
+		samples[t.cursor[operation]] = latency
+		t.cursor[operation] = (t.cursor[operation] + 1) % t.window
+	}
+	return threshold, threshold > 0 && latency > threshold
+}
+
+// WithLatencyTracker measures the acquire and release round trips of this lock
+// Outliers are logged and sent as EventSlowOperation when events are enabled
+//
+// WithLatencyTracker 测量该锁获取和释放的往返延迟
+// 异常值会记录日志，启用事件时还会以 EventSlowOperation 发送
+func (o *Suo) WithLatencyTracker(tracker *LatencyTracker) *Suo {
+	o.latency = tracker
+	return o
+}
+
+// WithLatencyTracker measures round trips of locks created through the manager
+// WithLatencyTracker 测量通过管理器创建的锁的往返延迟
+func (m *Manager) WithLatencyTracker(tracker *LatencyTracker) *Manager {
+	m.latency = tracker
+	return m
+}
+
+// observeLatency feeds the round trip into the tracker and warns on an outlier
+// observeLatency 将往返延迟交给跟踪器，并在出现异常值时发出警告
+func (o *Suo) observeLatency(operation string, sessionUUID string, startTime time.Time) {
+	if o.latency == nil {
+		return
+	}
+	latency := o.clock.Now().Sub(startTime)
+	threshold, outlier := o.latency.observe(operation, latency)
+	if !outlier {
+		return
+	}
+	o.logger.ErrorLog("锁操作延迟异常", zap.String("k", o.key), zap.String("operation", operation), zap.Duration("latency", latency), zap.Duration("threshold", threshold))
+	if o.events != nil {
+		o.events.Emit(&Event{Kind: EventSlowOperation, Key: o.key, Session: sessionUUID, Time: o.clock.Now(), Operation: operation, Latency: latency})
+	}
+}
@@ -0,0 +1,95 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// steppingClock advances a fixed step on each reading, so each round trip measures exactly one step
+// steppingClock 每次读取时前进固定步长，使每次往返恰好测得一个步长
+type steppingClock struct {
+	mutex sync.Mutex
+	now   time.Time
+	step  time.Duration
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func (c *steppingClock) Sleep(duration time.Duration) {}
+
+func (c *steppingClock) NewTimer(duration time.Duration) redissuo.Timer {
+	return redissuo.SystemClock().NewTimer(duration)
+}
+
+func (c *steppingClock) AfterFunc(duration time.Duration, run func()) redissuo.Timer {
+	return redissuo.SystemClock().AfterFunc(duration, run)
+}
+
+func (c *steppingClock) setStep(step time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.step = step
+}
+
+// TestSuo_WithLatencyTracker validates a round trip far beyond the recent percentile is sent as EventSlowOperation
+// TestSuo_WithLatencyTracker 验证远超近期分位数的往返以 EventSlowOperation 发送
+func TestSuo_WithLatencyTracker(t *testing.T) {
+	var mutex sync.Mutex
+	var events []*redissuo.Event
+	dispatcher := redissuo.NewEventDispatcher(redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, batch...)
+		return nil
+	}), 64)
+
+	ctx := context.Background()
+	clock := &steppingClock{now: time.Now(), step: time.Millisecond}
+	tracker := redissuo.NewLatencyTracker(0.9, 3).WithWindow(64, 8)
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithClock(clock).WithLatencyTracker(tracker).WithEvents(dispatcher)
+
+	for idx := 0; idx < 10; idx++ {
+		xin, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		_, err = suo.Release(ctx, xin)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3*time.Millisecond, tracker.Threshold(redissuo.OperationAcquire))
+
+	clock.setStep(50 * time.Millisecond)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var slow []*redissuo.Event
+	for _, event := range events {
+		if event.Kind == redissuo.EventSlowOperation {
+			slow = append(slow, event)
+		}
+	}
+	require.Len(t, slow, 1)
+	require.Equal(t, redissuo.OperationAcquire, slow[0].Operation)
+	require.Equal(t, 50*time.Millisecond, slow[0].Latency)
+}
+
+func (c *steppingClock) advance(duration time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(duration)
+}
@@ -0,0 +1,112 @@
+package redissuo
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/must"
+)
+
+// Locker is the lock contract shared across backends, Suo being the Redis one
+// Code written against Locker switches backends through config, without changes at call sites
+// redissuorun.LockerRun drives the runner on any Locker
+//
+// Locker 是各后端共享的锁契约，Suo 是其 Redis 实现
+// 面向 Locker 编写的代码可通过配置切换后端，而无需修改调用点
+// redissuorun.LockerRun 可在任意 Locker 上驱动运行器
+type Locker interface {
+	// Key gets back the lock name ID // Key 返回锁名标识符
+	Key() string
+	// Acquire acquires the lock, nil when held elsewhere // Acquire 获取锁，被其它会话持有时返回 nil
+	Acquire(ctx context.Context) (*Xin, error)
+	// AcquireAgainExtendLock extends the held session, nil when lost // AcquireAgainExtendLock 延期已持有的会话，丢失时返回 nil
+	AcquireAgainExtendLock(ctx context.Context, xin *Xin) (*Xin, error)
+	// Release releases the session, false when held elsewhere // Release 释放会话，被其它会话持有时返回 false
+	Release(ctx context.Context, xin *Xin) (bool, error)
+}
+
+var _ Locker = (*Suo)(nil)
+
+// NopLocker grants each acquisition at once without any coordination
+// Lets single-instance deployments of a multi-instance codebase switch distributed locking off
+//
+// NopLocker 立即批准每次获取而不做任何协调
+// 使多实例代码库的单实例部署可以关闭分布式锁
+type NopLocker struct {
+	key   string        // Lock name ID // 锁名标识符
+	ttl   time.Duration // Lease reported on the sessions // 会话上报告的租期
+	clock Clock         // Source of time // 时间来源
+}
+
+var _ Locker = (*NopLocker)(nil)
+
+// NewNopLocker creates a locker granting each acquisition of the key
+// NewNopLocker 创建批准该键每次获取的锁
+func NewNopLocker(key string, ttl time.Duration) *NopLocker {
+	return &NopLocker{key: must.Nice(key), ttl: must.Nice(ttl), clock: SystemClock()}
+}
+
+// Key gets back the lock name ID
+// Key 返回锁名标识符
+func (n *NopLocker) Key() string {
+	return n.key
+}
+
+// Acquire always grants a fresh session
+// Acquire 总是批准一个新会话
+func (n *NopLocker) Acquire(ctx context.Context) (*Xin, error) {
+	now := n.clock.Now()
+	return &Xin{key: n.key, sessionUUID: utils.NewUUID(), expire: now.Add(n.ttl), acquiredAt: now}, nil
+}
+
+// AcquireAgainExtendLock always extends the session
+// AcquireAgainExtendLock 总是延期该会话
+func (n *NopLocker) AcquireAgainExtendLock(ctx context.Context, xin *Xin) (*Xin, error) {
+	return &Xin{key: n.key, sessionUUID: xin.sessionUUID, expire: n.clock.Now().Add(n.ttl), acquiredAt: xin.acquiredAt, extensions: xin.extensions + 1}, nil
+}
+
+// Release always succeeds
+// Release 总是成功
+func (n *NopLocker) Release(ctx context.Context, xin *Xin) (bool, error) {
+	return true, nil
+}
+
+// WithDistributedLocking switches distributed locking of NewLocker on and off, on as default
+// WithDistributedLocking 开启或关闭 NewLocker 的分布式锁，默认开启
+func (m *Manager) WithDistributedLocking(enable bool) *Manager {
+	m.nopLocking = !enable
+	return m
+}
+
+// NewLocker creates the locker of the key: a NopLocker when distributed locking is off,
+// a MemoryLocker when a memory store is set, a Suo otherwise
+//
+// NewLocker 创建该键的锁：关闭分布式锁时为 NopLocker，
+// 设置了内存存储时为 MemoryLocker，否则为 Suo
+func (m *Manager) NewLocker(key string, ttl time.Duration) Locker {
+	if m.nopLocking {
+		locker := NewNopLocker(key, ttl)
+		locker.clock = m.clock
+		return locker
+	}
+	if m.memoryStore != nil {
+		return m.memoryStore.NewLocker(key, ttl)
+	}
+	return m.NewSuo(key, ttl)
+}
+
+// NewXin creates the session of a fresh hold, meant in Locker backends outside this package
+// NewXin 创建全新持有的会话，供本包之外的 Locker 后端使用
+func NewXin(key string, sessionUUID string, expire time.Time, acquiredAt time.Time) *Xin {
+	return &Xin{key: must.Nice(key), sessionUUID: must.Nice(sessionUUID), expire: expire, acquiredAt: acquiredAt}
+}
+
+// NewExtendedXin creates the session of an extended hold, keeping the first acquisition time and counting the extension
+// Meant in Locker backends outside this package
+//
+// NewExtendedXin 创建延期后持有的会话，保留首次获取时间并累计延期次数
+// 供本包之外的 Locker 后端使用
+func NewExtendedXin(xin *Xin, expire time.Time) *Xin {
+	return &Xin{key: xin.key, sessionUUID: xin.sessionUUID, expire: expire, acquiredAt: xin.acquiredAt, extensions: xin.extensions + 1, continues: xin.continues}
+}
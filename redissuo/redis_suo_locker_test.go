@@ -0,0 +1,41 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_NewLocker validates the manager hands out a NopLocker granting each acquisition once locking is off
+// TestManager_NewLocker 验证关闭分布式锁后管理器返回批准每次获取的 NopLocker
+func TestManager_NewLocker(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+
+	manager := redissuo.NewManager(caseRedisClient)
+	require.IsType(t, &redissuo.Suo{}, manager.NewLocker(key, 5*time.Second))
+
+	manager.WithDistributedLocking(false)
+	locker := manager.NewLocker(key, 5*time.Second)
+	require.IsType(t, &redissuo.NopLocker{}, locker)
+	require.Equal(t, key, locker.Key())
+
+	xin1, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+	xin2, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin2)
+	require.NotEqual(t, xin1.SessionUUID(), xin2.SessionUUID())
+
+	xin1, err = locker.AcquireAgainExtendLock(ctx, xin1)
+	require.NoError(t, err)
+	require.Equal(t, 1, xin1.Extensions())
+	success, err := locker.Release(ctx, xin1)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,62 @@
+package redissuo
+
+import (
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+)
+
+// LogStyle customizes structured field keys and message language of lock logs
+// Messages are keyed through the built-in Chinese text, missing entries keep that text
+// Use it when operators cannot act on the built-in Chinese messages in alerts
+//
+// LogStyle 自定义锁日志的结构化字段键和消息语言
+// 消息以内置中文文本为键，缺失的条目保持原文
+// 当运维人员无法理解告警中的内置中文消息时使用
+type LogStyle struct {
+	KeyField    string            // Field key of lock name, default "k" // 锁名字段键，默认 "k"
+	ValueField  string            // Field key of session, default "v" // 会话字段键，默认 "v"
+	ActionField string            // Field key of operation, default "action" // 操作字段键，默认 "action"
+	Messages    map[string]string // Built-in message to rendered message // 内置消息到输出消息
+}
+
+// ChineseLogStyle gets back the built-in style with Chinese messages
+// ChineseLogStyle 返回带中文消息的内置样式
+func ChineseLogStyle() *LogStyle {
+	return &LogStyle{KeyField: "k", ValueField: "v", ActionField: "action"}
+}
+
+// EnglishLogStyle gets back a style with English messages and descriptive field keys
+// EnglishLogStyle 返回带英文消息和描述性字段键的样式
+func EnglishLogStyle() *LogStyle {
+	return &LogStyle{KeyField: "lock_key", ValueField: "session", ActionField: "action", Messages: logging.EnglishMessages}
+}
+
+// Wrap applies the style to a logger, nil style keeps the logger unchanged
+// Wrap 将样式应用到日志记录器，样式为 nil 时保持不变
+func (s *LogStyle) Wrap(logger logging.Logger) logging.Logger {
+	if s == nil {
+		return logger
+	}
+	fieldKeys := map[string]string{}
+	for key, name := range map[string]string{"k": s.KeyField, "v": s.ValueField, "action": s.ActionField} {
+		if name != "" && name != key {
+			fieldKeys[key] = name
+		}
+	}
+	return logging.NewStyledLogger(logger, fieldKeys, s.Messages)
+}
+
+// WithLogStyle sets field keys and message language of the lock logs
+// WithLogStyle 设置锁日志的字段键和消息语言
+func (o *Suo) WithLogStyle(style *LogStyle) *Suo {
+	o.style = style
+	o.setLogger(style.Wrap(o.logger))
+	return o
+}
+
+// WithLogStyle sets field keys and message language used in manager operations and created locks
+// WithLogStyle 设置管理器操作和创建的锁使用的字段键和消息语言
+func (m *Manager) WithLogStyle(style *LogStyle) *Manager {
+	m.style = style
+	m.logger = style.Wrap(m.logger)
+	return m
+}
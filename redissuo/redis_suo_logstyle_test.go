@@ -0,0 +1,45 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestSuo_WithLogStyle validates English messages and renamed field keys in lock logs
+// Tests that the style keeps applying when the logger gets replaced afterwards
+//
+// TestSuo_WithLogStyle 验证锁日志中的英文消息和重命名的字段键
+// 测试之后替换日志记录器时样式依然生效
+func TestSuo_WithLogStyle(t *testing.T) {
+	ctx := context.Background()
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).
+		WithLogStyle(redissuo.EnglishLogStyle()).
+		WithLogger(logging.NewZapLogger(zap.New(core)))
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	entries := logs.FilterMessage("lock acquired").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, suo.Key(), fields["lock_key"])
+	require.Equal(t, xin.SessionUUID(), fields["session"])
+	require.Equal(t, "acquire lock", fields["action"])
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Len(t, logs.FilterMessage("lock released").All(), 1)
+}
@@ -0,0 +1,54 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaintenanceGate validates a frozen prefix blocks fresh acquisitions but lets held locks extend
+// TestMaintenanceGate 验证被冻结的前缀阻止新的获取，但允许已持有的锁延期
+func TestMaintenanceGate(t *testing.T) {
+	ctx := context.Background()
+	gate := redissuo.NewMaintenanceGate(caseRedisClient, utils.NewUUID())
+	manager := redissuo.NewManager(caseRedisClient).WithMaintenanceGate(gate)
+
+	prefix := utils.NewUUID() + ":"
+	suo := manager.NewSuo(prefix+"job", 5*time.Second)
+	other := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	require.NoError(t, gate.Freeze(ctx, prefix, "deploy"))
+	frozen, err := gate.Frozen(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{prefix: "deploy"}, frozen)
+
+	// Running holds keep extending through the freeze
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	_, err = suo.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrMaintenance)
+	require.Contains(t, err.Error(), "deploy")
+
+	// Lock names outside the prefix stay open
+	xin, err = other.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	require.NoError(t, gate.Thaw(ctx, prefix))
+	xin, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+}
@@ -0,0 +1,233 @@
+package redissuo
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// Manager groups lock instances sharing one Redis client and logger
+// Creates Suo instances and provides operations spanning many lock names
+// Thread-safe when used across multiple goroutines
+//
+// Manager 管理共享同一 Redis 客户端和日志记录器的锁实例
+// 创建 Suo 实例并提供跨多个锁名的操作
+// 在多个 goroutine 中使用时是线程安全的
+type Manager struct {
+	redisClient   redis.UniversalClient // Redis client connection // Redis 客户端连接
+	logger        logging.Logger        // Logger shared with created locks // 与创建的锁共享的日志记录器
+	registryKey   string                // Registry hash listing held locks, blank when disabled // 列出已持有锁的注册表哈希，为空时禁用
+	style         *LogStyle             // Field keys and message language of logs // 日志的字段键和消息语言
+	language      Language              // Language of error messages // 错误消息语言
+	events        *EventDispatcher      // Receives lifecycle events, nil when disabled // 接收生命周期事件，为空时禁用
+	clock         Clock                 // Source of time and sleeps // 时间和休眠的来源
+	dedicated     bool                  // Client is a dedicated pool owned by the manager // 客户端是管理器持有的专用连接池
+	exitReleaser  *ExitReleaser         // Keeps live sessions released at exit, nil when disabled // 记录退出时释放的存活会话，为空时禁用
+	holds         *holdSet              // Sessions held through locks of the manager // 通过管理器的锁持有的会话
+	maintenance   *MaintenanceGate      // Freezes fresh acquisitions during maintenance, nil when disabled // 维护期间冻结新获取，为空时禁用
+	pause         *pauseSwitch          // Holds fresh acquisitions back while paused // 暂停期间拦住新获取
+	admission     Admission             // Vetoes fresh acquisitions, nil when unset // 否决新获取，未设置时为空
+	latency       *LatencyTracker       // Measures round trips, nil when disabled // 测量往返延迟，为空时禁用
+	dryRun        bool                  // Simulate lock operations locally without Redis // 在本地模拟锁操作而不访问 Redis
+	nopLocking    bool                  // NewLocker hands out NopLocker // NewLocker 返回 NopLocker
+	memoryStore   *MemoryStore          // NewLocker hands out in-process lockers, nil when unset // NewLocker 返回进程内锁，未设置时为空
+	codec         Codec                 // Serializes the metadata companion value // 序列化元数据伴随键的值
+	redirectLimit int                   // Retries of scripts hitting cluster redirections // 脚本遇到集群重定向时的重试次数
+	redirects     *atomic.Int64         // Redirections met through locks of the manager // 通过管理器的锁遇到的重定向次数
+	random        Random                // Source of jitter // 抖动的随机来源
+	hooks         *Hooks                // Side effects at lifecycle points, nil when unset // 生命周期各节点的副作用，未设置时为空
+	tracer        Tracer                // Starts spans around lock operations, nil when disabled // 在锁操作周围开启 span，为空时禁用
+	budget        *holdBudget           // Bounds the locks held at once, nil when unbounded // 限制同时持有的锁数量，为空时不限制
+	ttlJitter     float64               // Fraction of the TTL fresh leases spread across, 0 means disabled // 新租期分布的 TTL 比例，0 表示禁用
+	driftFactor   float64               // Share of the TTL set aside against clock drift, 0 means disabled // 为时钟漂移预留的 TTL 比例，0 表示禁用
+}
+
+// NewManager creates a lock manager using the given Redis client
+// The client must be non-blank otherwise the function panics via must.Nice
+//
+// NewManager 使用给定的 Redis 客户端创建锁管理器
+// 客户端不能为空否则函数会通过 must.Nice 触发 panic
+func NewManager(rds redis.UniversalClient) *Manager {
+	return &Manager{
+		redisClient:   must.Nice(rds),
+		logger:        logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+		language:      LanguageChinese,
+		clock:         SystemClock(),
+		random:        SystemRandom(),
+		holds:         newHoldSet(),
+		pause:         newPauseSwitch(),
+		codec:         JSONCodec{},
+		redirectLimit: defaultRedirectLimit,
+		redirects:     &atomic.Int64{},
+	}
+}
+
+// WithLogger sets custom logger used in manager operations and created locks
+// Returns the manager supporting method chaining
+//
+// WithLogger 为管理器操作和创建的锁设置自定义日志记录器
+// 返回管理器以支持方法链式调用
+func (m *Manager) WithLogger(logger logging.Logger) *Manager {
+	m.logger = m.style.Wrap(logger)
+	return m
+}
+
+// NewSuo creates a lock instance bound to the manager's client and logger
+//
+// NewSuo 创建绑定到管理器客户端和日志记录器的锁实例
+func (m *Manager) NewSuo(key string, ttl time.Duration) *Suo {
+	suo := NewSuo(m.redisClient, key, ttl).WithLogger(m.logger)
+	suo.style = m.style
+	suo.language = m.language
+	suo.events = m.events
+	suo.clock = m.clock
+	suo.random = m.random
+	suo.registry = m.registryKey
+	suo.exitReleaser = m.exitReleaser
+	suo.holds = m.holds
+	suo.maintenance = m.maintenance
+	suo.pause = m.pause
+	suo.admission = m.admission
+	suo.latency = m.latency
+	suo.dryRun = m.dryRun
+	suo.codec = m.codec
+	suo.redirectLimit = m.redirectLimit
+	suo.redirects = m.redirects
+	suo.budget = m.budget
+	suo.tracer = m.tracer
+	suo.hooks = m.hooks
+	suo.ttlJitter = m.ttlJitter
+	suo.driftFactor = m.driftFactor
+	return suo
+}
+
+// LockInfo describes the state of one lock name at inspection time
+// Holder is blank and TTL is zero when the lock is free
+// TTL is negative when the key exists without expiration
+//
+// LockInfo 描述检查时刻某个锁名的状态
+// 锁空闲时 Holder 为空且 TTL 为零
+// 当键存在但没有过期时间时 TTL 为负数
+type LockInfo struct {
+	Key      string        // Lock name ID // 锁名标识符
+	Holder   string        // Session UUID holding the lock // 持有锁的会话 UUID
+	TTL      time.Duration // Remaining time to live // 剩余存活时间
+	Metadata *Metadata     // Holder metadata, nil when not stored or not fetched // 持有者元数据，未存储或未读取时为 nil
+	Owned    bool          // Holder is the inspecting session, or a session held through the manager of the inspecting lock // 持有者是进行检查的会话，或进行检查的锁所属管理器持有的会话
+}
+
+// Held reports whether the lock was held at inspection time
+// 判断检查时刻锁是否被持有
+func (i *LockInfo) Held() bool {
+	return i.Holder != ""
+}
+
+// OwnedBy reports whether the session held the lock at inspection time
+// OwnedBy 判断检查时刻该会话是否持有锁
+func (i *LockInfo) OwnedBy(xin *Xin) bool {
+	return i.Held() && xin != nil && xin.key == i.Key && xin.sessionUUID == i.Holder
+}
+
+const (
+	// Reads holder, remaining TTL and metadata of one lock in one atomic step, false when the lock is free
+	// 原子读取单个锁的持有者、剩余 TTL 和元数据，锁空闲时返回 false
+	commandInspectMeta = `local v = redis.call("GET", KEYS[1])
+if not v then
+    return false
+end
+return {v, redis.call("PTTL", KEYS[1]), redis.call("GET", KEYS[2]) or ""}`
+)
+
+// InspectMany fetches holder, remaining TTL and metadata of many lock names in one pipeline
+// Each key is read through an atomic script, so the pipeline stays valid in cluster mode
+// Returns the results in the same sequence as the given keys
+//
+// InspectMany 通过一次管道获取多个锁名的持有者、剩余 TTL 和元数据
+// 每个键通过原子脚本读取，因此管道在集群模式下依然有效
+// 按给定键的顺序返回结果
+func (m *Manager) InspectMany(ctx context.Context, keys ...string) ([]*LockInfo, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	pipe := m.redisClient.Pipeline()
+	cmds := make([]*redis.Cmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.Eval(ctx, commandInspectMeta, []string{key, companionKey(key, "meta")}))
+	}
+	// Per command problems are checked one by one below, redis.Nil marks a free lock
+	// 单个命令的错误在下面逐个检查，redis.Nil 表示锁空闲
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		m.logger.ErrorLog("批量检查报错", zap.Int("size", len(keys)), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+
+	infos := make([]*LockInfo, 0, len(keys))
+	for idx, cmd := range cmds {
+		info, err := parseLockInfo(keys[idx], cmd, m.codec)
+		if err != nil {
+			m.logger.ErrorLog("检查结果报错", zap.String("k", keys[idx]), zap.Error(err))
+			return nil, erero.Wro(err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// parseLockInfo converts the inspect script reply into a LockInfo
+// 将检查脚本的回复转换为 LockInfo
+func parseLockInfo(key string, cmd *redis.Cmd, codec Codec) (*LockInfo, error) {
+	result, err := cmd.Result()
+	return parseLockReply(key, result, err, codec)
+}
+
+// parseLockReply converts the result of the inspect script into a LockInfo, redis.Nil marks a free lock
+// parseLockReply 将检查脚本的结果转换为 LockInfo，redis.Nil 表示锁空闲
+func parseLockReply(key string, result interface{}, err error, codec Codec) (*LockInfo, error) {
+	if errors.Is(err, redis.Nil) {
+		return &LockInfo{Key: key}, nil
+	} else if err != nil {
+		return nil, erero.Wro(err)
+	}
+	items, ok := result.([]interface{})
+	if !ok || len(items) < 2 {
+		return nil, erero.Errorf("unexpected inspect reply: %v", result)
+	}
+	holder, ok := items[0].(string)
+	if !ok {
+		return nil, erero.Errorf("unexpected inspect holder: %v", items[0])
+	}
+	pttl, ok := items[1].(int64)
+	if !ok {
+		return nil, erero.Errorf("unexpected inspect pttl: %v", items[1])
+	}
+	info := &LockInfo{Key: key, Holder: holder, TTL: time.Duration(pttl) * time.Millisecond}
+	if len(items) > 2 {
+		// The third item is the metadata companion value, blank when absent
+		// 第三项是元数据伴随键的值，不存在时为空
+		data, ok := items[2].(string)
+		if !ok {
+			return nil, erero.Errorf("unexpected inspect metadata: %v", items[2])
+		}
+		if info.Metadata, err = parseMetadata(data, codec); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if len(items) > 3 {
+		// The fourth item marks the inspecting session as the holder
+		// 第四项标记进行检查的会话是否为持有者
+		owned, ok := items[3].(int64)
+		if !ok {
+			return nil, erero.Errorf("unexpected inspect owned: %v", items[3])
+		}
+		info.Owned = owned == 1
+	}
+	return info, nil
+}
@@ -0,0 +1,44 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_InspectMany validates bulk inspection of held and free locks
+// Tests that holders and TTL come back in the sequence of the given keys
+//
+// TestManager_InspectMany 验证对已持有和空闲锁的批量检查
+// 测试持有者和 TTL 按给定键的顺序返回
+func TestManager_InspectMany(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+
+	suo1 := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	xin1, err := suo1.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+
+	suo2 := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	infos, err := manager.InspectMany(ctx, suo1.Key(), suo2.Key())
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	require.True(t, infos[0].Held())
+	require.Equal(t, xin1.SessionUUID(), infos[0].Holder)
+	require.Greater(t, infos[0].TTL, time.Duration(0))
+	require.LessOrEqual(t, infos[0].TTL, 5*time.Second)
+
+	require.False(t, infos[1].Held())
+	require.Equal(t, suo2.Key(), infos[1].Key)
+
+	success, err := suo1.Release(ctx, xin1)
+	require.NoError(t, err)
+	require.True(t, success)
+}
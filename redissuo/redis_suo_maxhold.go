@@ -0,0 +1,63 @@
+package redissuo
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrMaxHoldReached is returned when an extension would keep the lock past the max hold duration
+// ErrMaxHoldReached 在延期会使锁持有超过最大持有时长时返回
+var ErrMaxHoldReached = NewError(CodeMaxHold, LanguageEnglish, nil)
+
+// EventMaxHoldReached is emitted when the max hold duration stops the renewal of a session, the lease then runs out on its own
+// EventMaxHoldReached 在最大持有时长使会话停止续期时发出，此后租期将自行耗尽
+const EventMaxHoldReached EventKind = "max_hold_reached"
+
+// WithMaxHold sets a hard cap on the total hold duration across extensions
+// Extensions get clamped to the cap and fail with ErrMaxHoldReached past it
+// Protects the system from a wedged job renewing a lock forever
+//
+// WithMaxHold 设置跨延期的总持有时长硬上限
+// 延期会被限制在上限之内，超过上限时以 ErrMaxHoldReached 失败
+// 防止卡住的任务永远续期锁
+func (o *Suo) WithMaxHold(maxHold time.Duration) *Suo {
+	o.maxHold = maxHold
+	return o
+}
+
+// extendTTL gets back the lease of the next extension of the session
+// The lease is the TTL, grown through the growth policy, clamped to what is left of the max hold duration
+// Extensions past the extension rate limit fail ahead of that
+//
+// extendTTL 返回会话下一次延期的租期
+// 租期为经增长策略放大的 TTL，并被限制在最大持有时长的剩余部分之内
+// 超过延期频率限制的延期会先行失败
+func (o *Suo) extendTTL(xin *Xin) (time.Duration, error) {
+	if err := o.throttleExtend(xin); err != nil {
+		return 0, err
+	}
+	return o.clampHold(xin, o.growTTL(xin.extensions+1))
+}
+
+// clampHold clamps the lease of the next extension to what is left of the max hold duration
+// Once nothing is left it emits EventMaxHoldReached and fires the expiring warning at once,
+// so the holder learns the lock is about to lapse ahead of the lease running out
+//
+// clampHold 将下一次延期的租期限制在最大持有时长的剩余部分之内
+// 没有剩余时长时发出 EventMaxHoldReached 并立即触发即将过期警告，
+// 使持有者在租期耗尽之前得知锁即将失效
+func (o *Suo) clampHold(xin *Xin, ttl time.Duration) (time.Duration, error) {
+	if o.maxHold <= 0 {
+		return ttl, nil
+	}
+	heldFor := o.clock.Now().Sub(xin.acquiredAt)
+	leftover := o.maxHold - heldFor
+	if leftover < time.Millisecond {
+		o.logger.DebugLog("已达最大持有时长-停止续期", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Duration("max_hold", o.maxHold))
+		o.emit(EventMaxHoldReached, xin.sessionUUID, heldFor)
+		o.warnExpiry(xin)
+		return 0, o.newError(CodeMaxHold)
+	}
+	return min(ttl, leftover), nil
+}
@@ -0,0 +1,109 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithMaxHold validates that extensions stop at the max hold duration
+// Tests that the last lease gets clamped and a later extension fails with the event going out
+//
+// TestSuo_WithMaxHold 验证延期在最大持有时长处停止
+// 测试最后一次租期被限制，之后的延期失败并发出事件
+func TestSuo_WithMaxHold(t *testing.T) {
+	var mutex sync.Mutex
+	var events []*redissuo.Event
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, batch...)
+		return nil
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16)
+
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 100*time.Millisecond).WithMaxHold(150 * time.Millisecond).WithEvents(dispatcher)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	time.Sleep(80 * time.Millisecond)
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.WithinDuration(t, xin.AcquiredAt().Add(150*time.Millisecond), xin.Expire(), 20*time.Millisecond)
+
+	time.Sleep(80 * time.Millisecond)
+
+	non, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.ErrorIs(t, err, redissuo.ErrMaxHoldReached)
+	require.Nil(t, non)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var reached []*redissuo.Event
+	for _, event := range events {
+		if event.Kind == redissuo.EventMaxHoldReached {
+			reached = append(reached, event)
+		}
+	}
+	require.Len(t, reached, 1)
+	require.Equal(t, xin.SessionUUID(), reached[0].Session)
+	require.GreaterOrEqual(t, reached[0].HeldFor, 150*time.Millisecond)
+}
+
+// TestSuo_WithMaxHold_ExpiringSoon validates reaching the max hold duration fires the expiring warning at once
+// The lease is far from its threshold, the warning comes from the cap stopping renewal
+//
+// TestSuo_WithMaxHold_ExpiringSoon 验证达到最大持有时长时立即触发即将过期警告
+// 租期离阈值尚远，警告来自上限停止续期
+func TestSuo_WithMaxHold_ExpiringSoon(t *testing.T) {
+	ctx := context.Background()
+	var warned atomic.Pointer[redissuo.Xin]
+	clock := &steppingClock{now: time.Now()}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithClock(clock).WithMaxHold(6*time.Second).WithExpiringSoon(time.Second, func(xin *redissuo.Xin) {
+		warned.Store(xin)
+	})
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	clock.advance(7 * time.Second)
+	select {
+	case <-xin.ExpiringSoon():
+		t.Fatal("warning must wait for the cap")
+	default:
+	}
+
+	non, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.ErrorIs(t, err, redissuo.ErrMaxHoldReached)
+	require.Nil(t, non)
+	select {
+	case <-xin.ExpiringSoon():
+	default:
+		t.Fatal("warning must fire once the cap stops renewal")
+	}
+	require.NotNil(t, warned.Load())
+	require.Equal(t, xin.SessionUUID(), warned.Load().SessionUUID())
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,160 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/must"
+)
+
+// MemoryStore keeps lock holds inside the process, standing in place of Redis
+// Lockers of one store exclude each other, so unit tests and single-node deployments need no Redis
+// Expired holds are dropped on the next access of the key, and swept across all keys as takes go on
+// The sweep runs once the takes since the previous one reach the count of holds, so its cost stays amortized constant
+//
+// MemoryStore 在进程内保存锁的持有状态，用以替代 Redis
+// 同一存储的锁之间相互排斥，使单元测试和单节点部署无需 Redis
+// 过期的持有在下次访问该键时清除，并随获取的进行在所有键上清扫
+// 自上次清扫以来的获取次数达到持有数量时执行清扫，使其均摊成本保持为常数
+type MemoryStore struct {
+	mutex sync.Mutex             // Protects holds // 保护 holds
+	holds map[string]*memoryHold // Holds keyed by lock name // 以锁名为键的持有状态
+	clock Clock                  // Source of time // 时间来源
+	takes int                    // Takes since the previous sweep // 自上次清扫以来的获取次数
+}
+
+// memoryHold is one lock held in the store
+// memoryHold 是存储中的一个被持有的锁
+type memoryHold struct {
+	session string    // Session UUID // 会话 UUID
+	expire  time.Time // Expiration time // 过期时间
+}
+
+// NewMemoryStore creates a blank in-process store
+// NewMemoryStore 创建空的进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{holds: map[string]*memoryHold{}, clock: SystemClock()}
+}
+
+// WithClock sets the source of time of expirations, letting tests step through TTLs
+// WithClock 设置过期计算的时间来源，使测试可以逐步推进 TTL
+func (s *MemoryStore) WithClock(clock Clock) *MemoryStore {
+	s.clock = must.Nice(clock)
+	return s
+}
+
+// NewLocker creates a locker of the key backed through the store
+// NewLocker 创建以该存储为后端的指定键的锁
+func (s *MemoryStore) NewLocker(key string, ttl time.Duration) *MemoryLocker {
+	return &MemoryLocker{store: s, key: must.Nice(key), ttl: must.Nice(ttl)}
+}
+
+// take sets the hold when the key is free, expired or held through the same session
+// take 在键空闲、已过期或由同一会话持有时设置持有状态
+func (s *MemoryStore) take(key string, session string, ttl time.Duration) (time.Time, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := s.clock.Now()
+	if s.takes++; s.takes >= len(s.holds) {
+		s.prune(now)
+	}
+	if hold, ok := s.holds[key]; ok && hold.session != session && now.Before(hold.expire) {
+		return time.Time{}, false
+	}
+	expire := now.Add(ttl)
+	s.holds[key] = &memoryHold{session: session, expire: expire}
+	return expire, true
+}
+
+// drop removes the hold when the session still holds the key
+// drop 在会话仍持有该键时移除持有状态
+func (s *MemoryStore) drop(key string, session string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	hold, ok := s.holds[key]
+	if !ok {
+		return false
+	}
+	if !s.clock.Now().Before(hold.expire) {
+		delete(s.holds, key)
+		return false
+	}
+	if hold.session != session {
+		return false
+	}
+	delete(s.holds, key)
+	return true
+}
+
+// prune removes the expired holds of all keys
+// prune 移除所有键中已过期的持有状态
+func (s *MemoryStore) prune(now time.Time) {
+	for key, hold := range s.holds {
+		if !now.Before(hold.expire) {
+			delete(s.holds, key)
+		}
+	}
+	s.takes = 0
+}
+
+// Len gets back the count of holds kept in the store, expired ones not swept yet included
+// Len 返回存储中保存的持有数量，包括尚未清扫的已过期持有
+func (s *MemoryStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.holds)
+}
+
+// MemoryLocker is a Locker backed through a MemoryStore
+// MemoryLocker 是以 MemoryStore 为后端的 Locker
+type MemoryLocker struct {
+	store *MemoryStore  // Backing store // 后端存储
+	key   string        // Lock name ID // 锁名标识符
+	ttl   time.Duration // Lease of each acquisition // 每次获取的租期
+}
+
+var _ Locker = (*MemoryLocker)(nil)
+
+// Key gets back the lock name ID
+// Key 返回锁名标识符
+func (l *MemoryLocker) Key() string {
+	return l.key
+}
+
+// Acquire acquires the lock under a fresh session, nil when held elsewhere
+// Acquire 使用新会话获取锁，被其它会话持有时返回 nil
+func (l *MemoryLocker) Acquire(ctx context.Context) (*Xin, error) {
+	session := utils.NewUUID()
+	expire, ok := l.store.take(l.key, session, l.ttl)
+	if !ok {
+		return nil, nil
+	}
+	return &Xin{key: l.key, sessionUUID: session, expire: expire, acquiredAt: expire.Add(-l.ttl)}, nil
+}
+
+// AcquireAgainExtendLock extends the held session, nil when lost
+// AcquireAgainExtendLock 延期已持有的会话，丢失时返回 nil
+func (l *MemoryLocker) AcquireAgainExtendLock(ctx context.Context, xin *Xin) (*Xin, error) {
+	must.Equals(xin.key, l.key)
+	expire, ok := l.store.take(l.key, xin.sessionUUID, l.ttl)
+	if !ok {
+		return nil, nil
+	}
+	return &Xin{key: l.key, sessionUUID: xin.sessionUUID, expire: expire, acquiredAt: xin.acquiredAt, extensions: xin.extensions + 1}, nil
+}
+
+// Release releases the session, false when held elsewhere or expired
+// Release 释放会话，被其它会话持有或已过期时返回 false
+func (l *MemoryLocker) Release(ctx context.Context, xin *Xin) (bool, error) {
+	must.Equals(xin.key, l.key)
+	return l.store.drop(l.key, xin.sessionUUID), nil
+}
+
+// WithMemoryStore makes NewLocker hand out lockers backed through the in-process store
+// WithMemoryStore 使 NewLocker 返回以进程内存储为后端的锁
+func (m *Manager) WithMemoryStore(store *MemoryStore) *Manager {
+	m.memoryStore = store
+	return m
+}
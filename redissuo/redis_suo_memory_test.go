@@ -0,0 +1,90 @@
+package redissuo_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryLocker validates in-process lockers exclude each other, extend, release and expire
+// TestMemoryLocker 验证进程内锁相互排斥，并能延期、释放和过期
+func TestMemoryLocker(t *testing.T) {
+	ctx := context.Background()
+	clock := &steppingClock{now: time.Now()}
+	store := redissuo.NewMemoryStore().WithClock(clock)
+	manager := redissuo.NewManager(caseRedisClient).WithMemoryStore(store)
+
+	locker := manager.NewLocker("job", time.Second)
+	require.IsType(t, &redissuo.MemoryLocker{}, locker)
+	other := store.NewLocker("job", time.Second)
+
+	xin, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	busy, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, busy)
+
+	clock.advance(800 * time.Millisecond)
+	xin, err = locker.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	clock.advance(800 * time.Millisecond)
+	busy, err = other.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, busy)
+
+	success, err := locker.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	taken, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, taken)
+
+	// The lease lapses and the lock frees up without release
+	clock.advance(time.Second)
+	xin, err = locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err = other.Release(ctx, taken)
+	require.NoError(t, err)
+	require.False(t, success)
+
+	clock.advance(time.Second)
+	success, err = locker.Release(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, success)
+}
+
+// TestMemoryStore_Prune validates expired holds of keys never accessed again get swept, so the store does not grow without bound
+// TestMemoryStore_Prune 验证不再被访问的键的过期持有会被清扫，使存储不会无限增长
+func TestMemoryStore_Prune(t *testing.T) {
+	ctx := context.Background()
+	clock := &steppingClock{now: time.Now()}
+	store := redissuo.NewMemoryStore().WithClock(clock)
+
+	for idx := 0; idx < 100; idx++ {
+		xin, err := store.NewLocker(fmt.Sprintf("job-%d", idx), time.Second).Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+	}
+	require.Equal(t, 100, store.Len())
+
+	clock.advance(2 * time.Second)
+	for idx := 0; idx < 100; idx++ {
+		xin, err := store.NewLocker("fresh", time.Second).Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+		if store.Len() == 1 {
+			break
+		}
+		_, err = store.NewLocker("fresh", time.Second).Release(ctx, xin)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, store.Len())
+}
@@ -0,0 +1,186 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// Metadata describes the lock holder, stored in a companion key expiring together with the lock
+// The lock value itself stays the session UUID, so ownership checks are unchanged
+//
+// Metadata 描述锁持有者，存储在与锁一同过期的伴随键中
+// 锁的值本身仍是会话 UUID，因此所有权检查保持不变
+type Metadata struct {
+	Tags       map[string]string `json:"tags,omitempty"`        // Labels such as team or job type // 如团队或任务类型等标签
+	Stack      string            `json:"stack,omitempty"`       // Truncated stack of the acquiring goroutine // 获取锁的 goroutine 的截断堆栈
+	Continues  *Continuation     `json:"continues,omitempty"`   // Earlier session this hold resumes, nil when fresh // 本次持有所延续的先前会话，全新持有时为 nil
+	Estimate   time.Duration     `json:"estimate,omitempty"`    // Remaining work estimate of the last ExtendFor, 0 when none // 最近一次 ExtendFor 的剩余工作预估，没有时为 0
+	Hostname   string            `json:"hostname,omitempty"`    // Host of the holder, blank unless WithHolderIdentity // 持有者的主机名，未启用 WithHolderIdentity 时为空
+	PID        int               `json:"pid,omitempty"`         // Process ID of the holder, 0 unless WithHolderIdentity // 持有者的进程 ID，未启用 WithHolderIdentity 时为 0
+	AcquiredAt *time.Time        `json:"acquired_at,omitempty"` // Holder clock at the acquisition, nil unless WithHolderIdentity // 获取时持有者的时钟，未启用 WithHolderIdentity 时为 nil
+}
+
+// MatchTags reports whether the metadata carries each of the given tag values
+// MatchTags 判断元数据是否包含给定的每个标签值
+func (m *Metadata) MatchTags(tags map[string]string) bool {
+	for name, value := range tags {
+		if m == nil || m.Tags[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// WithTags attaches tags (team, job type, priority) to the lock metadata
+// The metadata companion key is written atomically with each acquisition
+//
+// WithTags 为锁元数据附加标签（团队、任务类型、优先级）
+// 元数据伴随键在每次获取时原子写入
+func (o *Suo) WithTags(tags map[string]string) *Suo {
+	o.tags = tags
+	return o
+}
+
+// hasMetadata reports whether acquisitions write the metadata companion key
+// hasMetadata 判断获取时是否写入元数据伴随键
+func (o *Suo) hasMetadata() bool {
+	return len(o.tags) > 0 || o.stackLimit > 0 || o.identity
+}
+
+// metadata builds the metadata stored with the acquisition
+// metadata 构建本次获取时存储的元数据
+func (o *Suo) metadata(request *acquireRequest) *Metadata {
+	meta := &Metadata{Tags: o.tags, Stack: o.captureStack(), Continues: request.continues, Estimate: request.estimate}
+	o.stampIdentity(meta)
+	return meta
+}
+
+// UpdateMetadata rewrites the metadata companion of the held session with the current tags and holder identity
+// The companion keeps its remaining TTL, SET KEEPTTL does so in one write on Redis >= 6.0
+// Gives back false when the session no longer holds the lock
+//
+// UpdateMetadata 以当前标签和持有者标识改写所持会话的元数据伴随键
+// 伴随键保留其剩余 TTL，在 Redis >= 6.0 上通过一次 SET KEEPTTL 写入完成
+// 会话已不再持有锁时返回 false
+func (o *Suo) UpdateMetadata(ctx context.Context, xin *Xin) (bool, error) {
+	o.checkOwner(xin)
+	must.Equals(xin.key, o.key)
+	if o.dryRun {
+		return true, nil
+	}
+	data, err := o.codec.Marshal(o.metadata(&acquireRequest{continues: xin.continues}))
+	if err != nil {
+		o.logger.ErrorLog("编码元数据报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	result, err := o.eval(ctx, o.updateMetaCommand(ctx), []string{o.key, o.metaKey()}, xin.sessionUUID, string(data))
+	if err != nil {
+		o.logger.ErrorLog("更新元数据报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	if updated, _ := result.(int64); updated != 1 {
+		o.logger.DebugLog("锁已丢失-不更新元数据", zap.String("k", o.key), zap.String("v", xin.sessionUUID))
+		return false, nil
+	}
+	return true, nil
+}
+
+// metaKey gets back the companion key holding the lock metadata
+// metaKey 返回保存锁元数据的伴随键
+func (o *Suo) metaKey() string {
+	return companionKey(o.key, "meta")
+}
+
+// companionKey derives a key sharing the lock's hash slot
+// Keys with a hash tag keep it, other keys get wrapped in braces, so "a:b" maps to "{a:b}:meta"
+// Both forms hash the same bytes, keeping companions on the lock's cluster node
+//
+// companionKey 派生与锁共享哈希槽的键
+// 带哈希标签的键保持原样，其他键用大括号包裹，即 "a:b" 映射为 "{a:b}:meta"
+// 两种形式对相同字节计算哈希，使伴随键与锁位于同一集群节点
+func companionKey(key string, name string) string {
+	if hasHashTag(key) {
+		return key + ":" + name
+	}
+	return "{" + key + "}:" + name
+}
+
+// hasHashTag reports whether the key carries a non-blank {...} hash tag
+// hasHashTag 判断键是否带有非空的 {...} 哈希标签
+func hasHashTag(key string) bool {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return false
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	return end > 0
+}
+
+const (
+	// The acquire script runs as a function so the metadata write happens just on success
+	// Success is "OK" as string, status reply table, or the first item of the server time reply
+	// 获取脚本作为函数运行，使元数据仅在成功时写入
+	// 成功可能是字符串 "OK"、状态回复表或服务端时间回复的第一项
+	commandMetaWrapperHead = `local function acquire()
+`
+	commandMetaWrapperTail = `
+end
+local res = acquire()
+if res and (res == "OK" or res.ok == "OK" or res[1] == "OK") then
+    redis.call("SET", KEYS[#KEYS], ARGV[#ARGV], "PX", ARGV[2])
+end
+return res`
+)
+
+// acquireScript composes the acquire script together with its KEYS and ARGV
+// KEYS: lock, guard keys, optional metadata companion, optional token counter
+// ARGV: session, ttl milliseconds, guard count, guard pairs, optional metadata
+// Gives back the problem of the codec when the metadata cannot be encoded
+//
+// acquireScript 组合获取脚本及其 KEYS 和 ARGV
+// KEYS: 锁、守卫键、可选的元数据伴随键、可选的令牌计数器
+// ARGV: 会话、TTL 毫秒数、守卫数量、守卫参数对、可选的元数据
+// 元数据无法编码时返回编解码器的错误
+func (o *Suo) acquireScript(ctx context.Context, value string, milliseconds int64, request *acquireRequest) (string, []string, []string, error) {
+	command := o.acquireCommand(ctx)
+	keys, args := o.guardKeysArgs([]string{o.key}, []string{value, strconv.FormatInt(milliseconds, 10)})
+	if o.hasMetadata() || request.continues != nil || request.estimate > 0 {
+		command = commandMetaWrapperHead + command + commandMetaWrapperTail
+		keys = append(keys, o.metaKey())
+		data, err := o.codec.Marshal(o.metadata(request))
+		if err != nil {
+			return "", nil, nil, erero.Wro(err)
+		}
+		args = append(args, string(data))
+	}
+	if o.fencing {
+		command = commandFencingWrapperHead + command + commandFencingWrapperTail
+		keys = append(keys, o.tokenKey())
+	}
+	if o.strict && !request.extend && !request.reclaim {
+		command = commandStrictPrefix + command
+	}
+	if len(o.guards) > 0 {
+		command = commandGuardPrefix + command
+	}
+	return command, keys, args, nil
+}
+
+// parseMetadata decodes the metadata companion value through the codec, nil when blank
+// parseMetadata 通过编解码器解码元数据伴随键的值，为空时返回 nil
+func parseMetadata(data string, codec Codec) (*Metadata, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var metadata Metadata
+	if err := codec.Unmarshal([]byte(data), &metadata); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &metadata, nil
+}
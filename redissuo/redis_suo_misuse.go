@@ -0,0 +1,145 @@
+package redissuo
+
+import (
+	"runtime"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// MisuseKind names a misuse pattern caught in debug mode
+// MisuseKind 表示调试模式下捕获的误用模式
+type MisuseKind string
+
+const (
+	MisuseExpiredRelease MisuseKind = "expired_release" // Release past expiry without ever extending // 从未延期且在过期后释放
+	MisuseDoubleRelease  MisuseKind = "double_release"  // Release on a session already released // 对已释放的会话再次释放
+	MisuseWrongSuo       MisuseKind = "wrong_suo"       // Xin used with a different Suo // 使用其它 Suo 操作 Xin
+	MisuseHeldPastTTL    MisuseKind = "held_past_ttl"   // Lease ran out while still held // 租期耗尽时仍在持有
+	MisuseLeakedSession  MisuseKind = "leaked_session"  // Xin garbage collected without release // Xin 未释放就被垃圾回收
+)
+
+// Misuse describes one misuse caught in debug mode
+// Misuse 描述调试模式下捕获的一次误用
+type Misuse struct {
+	Kind    MisuseKind // Misuse pattern // 误用模式
+	Key     string     // Lock name ID // 锁名标识符
+	Session string     // Session UUID // 会话 UUID
+}
+
+// WithMisuseDetector enables debug mode catching misuse patterns
+// Each misuse is logged at error level and passed to the handler when one is set
+// Costs a timer and a finalizer per held session, meant in development and tests
+//
+// WithMisuseDetector 启用捕获误用模式的调试模式
+// 每次误用都以错误级别记录日志，并在设置了处理函数时传递给它
+// 每个持有的会话占用一个定时器和一个终结器，适用于开发和测试
+func (o *Suo) WithMisuseDetector(enable bool) *Suo {
+	o.misuse = enable
+	return o
+}
+
+// WithMisuseHandler sets a function receiving each misuse caught in debug mode, e.g. to fail a test
+// WithMisuseHandler 设置接收调试模式下捕获的每次误用的函数，例如用于使测试失败
+func (o *Suo) WithMisuseHandler(handler func(misuse *Misuse)) *Suo {
+	o.onMisuse = handler
+	return o
+}
+
+// holdTracker follows one hold across its extensions in debug mode
+// holdTracker 在调试模式下跟踪一次持有及其延期
+type holdTracker struct {
+	mutex    sync.Mutex // Guards the fields below // 保护下面的字段
+	suo      *Suo       // Lock that acquired the session // 获取该会话的锁
+	extended bool       // Extended at least once // 至少延期过一次
+	released bool       // Released already // 已释放
+	timer    Timer      // Fires when the lease runs out // 租期耗尽时触发
+}
+
+// trackHold starts following a fresh hold when debug mode is on
+// trackHold 在调试模式开启时开始跟踪一次新的持有
+func (o *Suo) trackHold(xin *Xin) {
+	if !o.misuse {
+		return
+	}
+	// The timer closure keeps the session text but not the Xin, so a dropped Xin stays collectable
+	// 定时器闭包只保留会话文本而不引用 Xin，使被丢弃的 Xin 仍可被回收
+	sessionUUID := xin.sessionUUID
+	tracker := &holdTracker{suo: o}
+	tracker.timer = o.clock.AfterFunc(xin.expire.Sub(o.clock.Now()), func() {
+		o.reportMisuse(MisuseHeldPastTTL, sessionUUID)
+	})
+	xin.tracker = tracker
+	runtime.SetFinalizer(xin, finalizeXin)
+}
+
+// finalizeXin reports a session garbage collected while its hold was never released
+// finalizeXin 报告持有从未释放就被垃圾回收的会话
+func finalizeXin(xin *Xin) {
+	tracker := xin.tracker
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if tracker.released {
+		return
+	}
+	tracker.timer.Stop()
+	tracker.suo.reportMisuse(MisuseLeakedSession, xin.sessionUUID)
+}
+
+// trackExtend carries the tracker over to the extended session and pushes the timer back
+// trackExtend 将跟踪器转移到延期后的会话并推迟定时器
+func (o *Suo) trackExtend(xin *Xin, res *Xin) {
+	tracker := xin.tracker
+	if tracker == nil {
+		return
+	}
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.extended = true
+	tracker.timer.Reset(res.expire.Sub(o.clock.Now()))
+	res.tracker = tracker
+	// The extended session takes over the leak watch of the hold
+	// 延期后的会话接管该持有的泄漏监测
+	runtime.SetFinalizer(xin, nil)
+	runtime.SetFinalizer(res, finalizeXin)
+}
+
+// checkOwner reports a Xin used with a Suo other than the one that acquired it
+// checkOwner 报告使用非获取方 Suo 操作 Xin 的情况
+func (o *Suo) checkOwner(xin *Xin) {
+	if !o.misuse {
+		return
+	}
+	if xin.key != o.key || (xin.tracker != nil && xin.tracker.suo != o) {
+		o.reportMisuse(MisuseWrongSuo, xin.sessionUUID)
+	}
+}
+
+// trackRelease reports double releases and releases past expiry of never extended holds
+// trackRelease 报告重复释放以及从未延期的持有在过期后释放的情况
+func (o *Suo) trackRelease(xin *Xin) {
+	tracker := xin.tracker
+	if tracker == nil {
+		return
+	}
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if tracker.released {
+		o.reportMisuse(MisuseDoubleRelease, xin.sessionUUID)
+		return
+	}
+	tracker.released = true
+	tracker.timer.Stop()
+	if !tracker.extended && o.clock.Now().After(xin.expire) {
+		o.reportMisuse(MisuseExpiredRelease, xin.sessionUUID)
+	}
+}
+
+// reportMisuse logs the misuse and hands it to the handler
+// reportMisuse 记录误用并交给处理函数
+func (o *Suo) reportMisuse(kind MisuseKind, sessionUUID string) {
+	o.logger.ErrorLog("检测到锁误用", zap.String("k", o.key), zap.String("v", sessionUUID), zap.String("misuse", string(kind)))
+	if o.onMisuse != nil {
+		o.onMisuse(&Misuse{Kind: kind, Key: o.key, Session: sessionUUID})
+	}
+}
@@ -0,0 +1,127 @@
+package redissuo_test
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// caseMisuseRecorder collects misuse kinds reported through the handler
+// caseMisuseRecorder 收集通过处理函数报告的误用类型
+type caseMisuseRecorder struct {
+	mutex sync.Mutex
+	kinds []redissuo.MisuseKind
+}
+
+func (r *caseMisuseRecorder) handle(misuse *redissuo.Misuse) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.kinds = append(r.kinds, misuse.Kind)
+}
+
+func (r *caseMisuseRecorder) snapshot() []redissuo.MisuseKind {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]redissuo.MisuseKind{}, r.kinds...)
+}
+
+// TestSuo_WithMisuseDetector_DoubleRelease validates a second release of the same hold is reported
+// Tests that releasing the original Xin past an extension also counts as a double release
+//
+// TestSuo_WithMisuseDetector_DoubleRelease 验证同一次持有的第二次释放会被报告
+// 测试延期后再释放原始 Xin 同样算作重复释放
+func TestSuo_WithMisuseDetector_DoubleRelease(t *testing.T) {
+	ctx := context.Background()
+	recorder := &caseMisuseRecorder{}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).
+		WithMisuseDetector(true).
+		WithMisuseHandler(recorder.handle)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	extended, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, extended)
+
+	_, err = suo.Release(ctx, extended)
+	require.NoError(t, err)
+	require.Empty(t, recorder.snapshot())
+
+	_, err = suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.Equal(t, []redissuo.MisuseKind{redissuo.MisuseDoubleRelease}, recorder.snapshot())
+}
+
+// TestSuo_WithMisuseDetector_Expired validates holding past the TTL without extending is reported
+// Tests both the timer firing at expiry and the late release
+//
+// TestSuo_WithMisuseDetector_Expired 验证未延期且持有超过 TTL 会被报告
+// 测试过期时触发的定时器以及延迟的释放
+func TestSuo_WithMisuseDetector_Expired(t *testing.T) {
+	ctx := context.Background()
+	recorder := &caseMisuseRecorder{}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 50*time.Millisecond).
+		WithMisuseDetector(true).
+		WithMisuseHandler(recorder.handle)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.Equal(t, []redissuo.MisuseKind{redissuo.MisuseHeldPastTTL, redissuo.MisuseExpiredRelease}, recorder.snapshot())
+}
+
+// TestSuo_WithMisuseDetector_WrongSuo validates a Xin used through another Suo of the same key is reported
+// TestSuo_WithMisuseDetector_WrongSuo 验证通过同键的其它 Suo 使用 Xin 会被报告
+func TestSuo_WithMisuseDetector_WrongSuo(t *testing.T) {
+	ctx := context.Background()
+	recorder := &caseMisuseRecorder{}
+	key := utils.NewUUID()
+	suo := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithMisuseDetector(true)
+	other := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).
+		WithMisuseDetector(true).
+		WithMisuseHandler(recorder.handle)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := other.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Equal(t, []redissuo.MisuseKind{redissuo.MisuseWrongSuo}, recorder.snapshot())
+}
+
+// TestSuo_WithMisuseDetector_Leaked validates a Xin dropped without release is reported once collected
+// TestSuo_WithMisuseDetector_Leaked 验证未释放就被丢弃的 Xin 在回收后会被报告
+func TestSuo_WithMisuseDetector_Leaked(t *testing.T) {
+	ctx := context.Background()
+	recorder := &caseMisuseRecorder{}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).
+		WithMisuseDetector(true).
+		WithMisuseHandler(recorder.handle)
+
+	func() {
+		xin, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+	}()
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return len(recorder.snapshot()) > 0
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []redissuo.MisuseKind{redissuo.MisuseLeakedSession}, recorder.snapshot())
+}
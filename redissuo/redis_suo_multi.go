@@ -0,0 +1,286 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultDriftFactor is the share of the TTL set aside against clock drift between the nodes
+	// defaultDriftFactor 是为节点之间的时钟漂移预留的 TTL 比例
+	defaultDriftFactor = 0.01
+	// driftAllowance is the fixed part of the drift margin, covering the expiry precision of Redis
+	// driftAllowance 是漂移余量的固定部分，覆盖 Redis 过期时间的精度
+	driftAllowance = 2 * time.Millisecond
+)
+
+// MultiSuo locks the same key on independent Redis nodes and counts it held just when a quorum grants it (Redlock style)
+// The lock stays valid for the TTL minus the acquisition time and the drift margin, a partial acquisition is rolled back
+// Meant in setups where one Redis node cannot be trusted with correctness, e.g. through failover losing writes
+//
+// MultiSuo 在多个独立 Redis 节点上锁定同一个键，仅当法定数量的节点授予时才视为持有（Redlock 方式）
+// 锁的有效期为 TTL 减去获取耗时和漂移余量，未达到法定数量的部分获取会被回滚
+// 适用于无法依赖单个 Redis 节点保证正确性的场景，例如故障切换会丢失写入
+type MultiSuo struct {
+	nodes       []*Suo         // One lock per node // 每个节点一个锁
+	key         string         // Lock name // 锁名
+	ttl         time.Duration  // Lease duration // 租期
+	quorum      int            // Majority of the nodes // 节点的多数
+	driftFactor float64        // Share of the TTL set aside against clock drift // 为时钟漂移预留的 TTL 比例
+	options     QuorumOptions  // Per-node timeout and hedging // 单节点超时和对冲
+	logger      logging.Logger // Logger instance // 日志记录器实例
+}
+
+// NewMultiSuo creates a quorum lock of the key over the independent nodes, the quorum is the majority
+// NewMultiSuo 在多个独立节点上创建该键的法定数量锁，法定数量为多数
+func NewMultiSuo(nodes []redis.UniversalClient, key string, ttl time.Duration) *MultiSuo {
+	must.Have(nodes)
+	suos := make([]*Suo, 0, len(nodes))
+	for _, node := range nodes {
+		suos = append(suos, NewSuo(node, key, ttl))
+	}
+	return &MultiSuo{
+		nodes:       suos,
+		key:         key,
+		ttl:         ttl,
+		quorum:      len(nodes)/2 + 1,
+		driftFactor: defaultDriftFactor,
+		options:     QuorumOptions{NodeTimeout: max(ttl/10, 50*time.Millisecond)},
+		logger:      logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+	}
+}
+
+// WithDriftFactor sets the share of the TTL set aside against clock drift between the nodes
+// WithDriftFactor 设置为节点之间的时钟漂移预留的 TTL 比例
+func (m *MultiSuo) WithDriftFactor(factor float64) *MultiSuo {
+	must.True(factor >= 0 && factor < 1)
+	m.driftFactor = factor
+	return m
+}
+
+// WithOptions sets the per-node timeout and hedging, keep the node timeout well below the TTL
+// WithOptions 设置单节点超时和对冲，单节点超时应远小于 TTL
+func (m *MultiSuo) WithOptions(options QuorumOptions) *MultiSuo {
+	m.options = options
+	return m
+}
+
+// WithLogger sets custom logger used in the quorum and in each node lock
+// WithLogger 设置法定数量锁及每个节点锁使用的自定义日志记录器
+func (m *MultiSuo) WithLogger(logger logging.Logger) *MultiSuo {
+	m.logger = logger
+	for _, node := range m.nodes {
+		node.WithLogger(logger)
+	}
+	return m
+}
+
+// Key gets back the lock name
+// Key 返回锁名
+func (m *MultiSuo) Key() string {
+	return m.key
+}
+
+// Quorum gets back the count of nodes needed to hold the lock
+// Quorum 返回持有锁所需的节点数量
+func (m *MultiSuo) Quorum() int {
+	return m.quorum
+}
+
+// MultiXin is a quorum lock session, valid until Expire
+// MultiXin 是法定数量锁的会话，在 Expire 之前有效
+type MultiXin struct {
+	key         string         // Lock name // 锁名
+	sessionUUID string         // Session shared across the nodes // 各节点共享的会话
+	expire      time.Time      // Validity end, drift margin taken off // 有效期结束时间，已扣除漂移余量
+	granted     int            // Nodes that granted the lock // 授予锁的节点数量
+	inflight    *multiInflight // Node calls of the session still running past their round // 超出其轮次仍在运行的会话节点调用
+}
+
+// multiInflight tracks the node calls of one session still running once their quorum round returned
+// Stragglers may still write the lock, so rollback and release cancel them and wait ahead of deleting
+//
+// multiInflight 跟踪某个会话在其法定数量轮次返回后仍在运行的节点调用
+// 慢节点仍可能写入锁，因此回滚和释放在删除之前先取消并等待它们
+type multiInflight struct {
+	wg     sync.WaitGroup                         // Rounds with calls still running // 仍有调用在运行的轮次
+	mutex  sync.Mutex                             // Protects rounds // 保护 rounds
+	rounds map[*sync.WaitGroup]context.CancelFunc // Cancels of the running rounds // 运行中轮次的取消函数
+}
+
+// follow keeps the round tracked until each of its calls returned
+// follow 持续跟踪该轮次，直到其每个调用都已返回
+func (f *multiInflight) follow(calls *sync.WaitGroup, cancel context.CancelFunc) {
+	f.mutex.Lock()
+	f.rounds[calls] = cancel
+	f.mutex.Unlock()
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		calls.Wait()
+		cancel()
+		f.mutex.Lock()
+		delete(f.rounds, calls)
+		f.mutex.Unlock()
+	}()
+}
+
+// stop cancels the calls still running and waits until each returned
+// stop 取消仍在运行的调用并等待每个调用返回
+func (f *multiInflight) stop() {
+	f.mutex.Lock()
+	for _, cancel := range f.rounds {
+		cancel()
+	}
+	f.mutex.Unlock()
+	f.wg.Wait()
+}
+
+// Key gets back the lock name
+// Key 返回锁名
+func (s *MultiXin) Key() string {
+	return s.key
+}
+
+// SessionUUID gets back the session shared across the nodes
+// SessionUUID 返回各节点共享的会话
+func (s *MultiXin) SessionUUID() string {
+	return s.sessionUUID
+}
+
+// Expire gets back the end of the validity window, counting the acquisition time and the drift margin
+// Expire 返回有效期的结束时间，已计入获取耗时和漂移余量
+func (s *MultiXin) Expire() time.Time {
+	return s.expire
+}
+
+// Granted gets back the count of nodes that granted the lock
+// Granted 返回授予锁的节点数量
+func (s *MultiXin) Granted() int {
+	return s.granted
+}
+
+// Acquire attempts the lock on all nodes at once with a fresh session
+// Gives back nil when the quorum is not reached within the validity window, the partial acquisition is rolled back then
+//
+// Acquire 使用新会话同时在所有节点上尝试获取锁
+// 未能在有效期内达到法定数量时返回 nil，此时回滚部分获取
+func (m *MultiSuo) Acquire(ctx context.Context) (*MultiXin, error) {
+	return m.acquire(ctx, utils.NewUUID(), &multiInflight{rounds: map[*sync.WaitGroup]context.CancelFunc{}}, false)
+}
+
+// Extend renews the lease of the session on all nodes, same rules as Acquire
+// Gives back nil once the quorum is lost, the session is released on each node then
+//
+// Extend 在所有节点上续期该会话的租期，规则与 Acquire 相同
+// 失去法定数量时返回 nil，此时在每个节点上释放该会话
+func (m *MultiSuo) Extend(ctx context.Context, xin *MultiXin) (*MultiXin, error) {
+	must.Equals(xin.key, m.key)
+	return m.acquire(ctx, xin.sessionUUID, xin.inflight, true)
+}
+
+// acquire runs one quorum round of the session, fresh or extending
+// A missed round cancels the stragglers and waits on them ahead of the rollback, so no late write outlives it
+//
+// acquire 执行该会话的一轮法定数量获取，新获取或延期
+// 未成功的轮次在回滚之前取消并等待慢节点，使迟到的写入不会在回滚之后残留
+func (m *MultiSuo) acquire(ctx context.Context, sessionUUID string, inflight *multiInflight, extend bool) (*MultiXin, error) {
+	startTime := time.Now()
+	var mutex sync.Mutex
+	var problems []error
+	var calls sync.WaitGroup
+	roundCtx, cancel := context.WithCancel(ctx)
+	granted, _ := fanoutQuorum(roundCtx, len(m.nodes), m.quorum, m.options, &calls, func(ctx context.Context, index int) (bool, error) {
+		node := m.nodes[index]
+		xin, err := node.acquireLockWith(ctx, sessionUUID, &acquireRequest{ttl: node.ttl, extend: extend})
+		if err != nil {
+			mutex.Lock()
+			problems = append(problems, err)
+			mutex.Unlock()
+			return false, erero.Wro(err)
+		}
+		return xin != nil, nil
+	})
+	inflight.follow(&calls, cancel)
+
+	elapsed := time.Since(startTime)
+	drift := time.Duration(float64(m.ttl)*m.driftFactor) + driftAllowance
+	validity := m.ttl - elapsed - drift
+	if granted >= m.quorum && validity > 0 {
+		m.logger.DebugLog("法定数量锁已申请", zap.String("k", m.key), zap.String("v", sessionUUID), zap.Int("granted", granted), zap.Duration("validity", validity))
+		return &MultiXin{key: m.key, sessionUUID: sessionUUID, expire: startTime.Add(m.ttl - drift), granted: granted, inflight: inflight}, nil
+	}
+
+	m.logger.DebugLog("法定数量锁未达成-回滚", zap.String("k", m.key), zap.String("v", sessionUUID), zap.Int("granted", granted), zap.Int("quorum", m.quorum), zap.Duration("elapsed", elapsed))
+	inflight.stop()
+	m.releaseAll(context.WithoutCancel(ctx), sessionUUID)
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(problems) > len(m.nodes)-m.quorum {
+		// Too many nodes failed to ever reach the quorum, surface a problem instead of a plain miss
+		// 失败的节点过多，无法达到法定数量，返回错误而非普通的未获取
+		return nil, erero.Wro(problems[0])
+	}
+	return nil, nil
+}
+
+// Release gives the lock back on all nodes, true when the quorum of nodes released it
+// Release 在所有节点上归还锁，法定数量的节点释放成功时返回 true
+func (m *MultiSuo) Release(ctx context.Context, xin *MultiXin) (bool, error) {
+	must.Equals(xin.key, m.key)
+	// Stragglers of the earlier rounds stop ahead of the release, so none writes the lock back past it
+	// 较早轮次的慢节点在释放之前停止，使其不会在释放之后重新写入锁
+	xin.inflight.stop()
+	released, problem := m.releaseAll(ctx, xin.sessionUUID)
+	if released < m.quorum && problem != nil {
+		return false, erero.Wro(problem)
+	}
+	return released >= m.quorum, nil
+}
+
+// releaseAll releases the session on every node
+// Gives back the count of nodes that deleted it, nodes that never granted it or lost it do not count, and the first problem
+// A missing key counts as not released, it may never have been granted there
+//
+// releaseAll 在每个节点上释放该会话
+// 返回删除了该会话的节点数量（从未授予或已丢失的节点不计入）以及第一个错误
+// 键不存在时不计为已释放，该节点可能从未授予过锁
+func (m *MultiSuo) releaseAll(ctx context.Context, sessionUUID string) (int, error) {
+	var wg sync.WaitGroup
+	results := make([]ReleaseStatus, len(m.nodes))
+	problems := make([]error, len(m.nodes))
+	for idx, node := range m.nodes {
+		wg.Add(1)
+		go func(idx int, node *Suo) {
+			defer wg.Done()
+			nodeCtx, cancel := ctx, context.CancelFunc(func() {})
+			if m.options.NodeTimeout > 0 {
+				nodeCtx, cancel = context.WithTimeout(ctx, m.options.NodeTimeout)
+			}
+			defer cancel()
+			results[idx], _, problems[idx] = node.releaseStatus(nodeCtx, sessionUUID, node.hasMetadata())
+		}(idx, node)
+	}
+	wg.Wait()
+
+	released := 0
+	var problem error
+	for idx := range m.nodes {
+		if problems[idx] == nil && results[idx] == ReleaseDeleted {
+			released++
+		} else if problems[idx] != nil && problem == nil {
+			problem = problems[idx]
+			m.logger.ErrorLog("法定数量锁节点释放报错", zap.String("k", m.key), zap.String("v", sessionUUID), zap.Int("node", idx), zap.Error(problem))
+		}
+	}
+	return released, problem
+}
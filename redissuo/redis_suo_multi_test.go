@@ -0,0 +1,164 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// newMultiNodes starts independent miniredis nodes and gives back their clients
+// newMultiNodes 启动多个独立的 miniredis 节点并返回其客户端
+func newMultiNodes(t *testing.T, count int) []redis.UniversalClient {
+	nodes := make([]redis.UniversalClient, 0, count)
+	for idx := 0; idx < count; idx++ {
+		miniRedis := rese.P1(miniredis.Run())
+		t.Cleanup(miniRedis.Close)
+		redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+		t.Cleanup(func() { _ = redisClient.Close() })
+		nodes = append(nodes, redisClient)
+	}
+	return nodes
+}
+
+// TestMultiSuo validates quorum acquisition, mutual exclusion, extension and release
+// TestMultiSuo 验证法定数量获取、互斥、延期和释放
+func TestMultiSuo(t *testing.T) {
+	ctx := context.Background()
+	nodes := newMultiNodes(t, 3)
+	key := utils.NewUUID()
+	suo := redissuo.NewMultiSuo(nodes, key, 5*time.Second)
+	require.Equal(t, 2, suo.Quorum())
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.GreaterOrEqual(t, xin.Granted(), 2)
+	require.True(t, xin.Expire().After(time.Now()))
+
+	other, err := redissuo.NewMultiSuo(nodes, key, 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	xin, err = suo.Extend(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	for _, node := range nodes {
+		require.Zero(t, rese.V1(node.Exists(ctx, key).Result()))
+	}
+}
+
+// TestMultiSuo_Rollback validates a missed quorum leaves no partial acquisition behind
+// TestMultiSuo_Rollback 验证未达到法定数量时不会遗留部分获取
+func TestMultiSuo_Rollback(t *testing.T) {
+	ctx := context.Background()
+	nodes := newMultiNodes(t, 3)
+	key := utils.NewUUID()
+	for _, node := range nodes[1:] {
+		require.NoError(t, node.Set(ctx, key, "usurper", time.Minute).Err())
+	}
+
+	xin, err := redissuo.NewMultiSuo(nodes, key, 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, xin)
+	require.Zero(t, rese.V1(nodes[0].Exists(ctx, key).Result()))
+}
+
+// TestMultiSuo_Minority validates a dead minority does not block the lock
+// TestMultiSuo_Minority 验证少数节点宕机不会阻塞锁
+func TestMultiSuo_Minority(t *testing.T) {
+	ctx := context.Background()
+	dead := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { _ = dead.Close() }()
+	nodes := append(newMultiNodes(t, 2), dead)
+
+	suo := redissuo.NewMultiSuo(nodes, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	broken := redissuo.NewMultiSuo([]redis.UniversalClient{nodes[0], dead, dead}, utils.NewUUID(), 5*time.Second)
+	_, err = broken.Acquire(ctx)
+	require.Error(t, err)
+}
+
+// lateHook holds the scripts of a straggler node and lets them land past the caller giving up on them
+// lateHook 延迟慢节点的脚本，并使其在调用方放弃之后仍然写入
+type lateHook struct {
+	delay time.Duration
+}
+
+func (h *lateHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *lateHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "eval" || cmd.Name() == "evalsha" {
+			time.Sleep(h.delay)
+			return next(context.WithoutCancel(ctx), cmd)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *lateHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// TestMultiSuo_RollbackStraggler validates a straggler writing past the missed quorum gets rolled back too
+// TestMultiSuo_RollbackStraggler 验证在未达到法定数量之后才写入的慢节点同样被回滚
+func TestMultiSuo_RollbackStraggler(t *testing.T) {
+	ctx := context.Background()
+	nodes := newMultiNodes(t, 5)
+	key := utils.NewUUID()
+	for _, node := range nodes[:3] {
+		require.NoError(t, node.Set(ctx, key, "usurper", time.Minute).Err())
+	}
+	nodes[4].(*redis.Client).AddHook(&lateHook{delay: 200 * time.Millisecond})
+
+	xin, err := redissuo.NewMultiSuo(nodes, key, 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, xin)
+	require.Zero(t, rese.V1(nodes[3].Exists(ctx, key).Result()))
+	require.Zero(t, rese.V1(nodes[4].Exists(ctx, key).Result()))
+}
+
+// TestMultiSuo_ReleaseMissing validates nodes that never granted the lock or lost it do not count towards the release quorum
+// TestMultiSuo_ReleaseMissing 验证从未授予锁或已丢失锁的节点不计入释放的法定数量
+func TestMultiSuo_ReleaseMissing(t *testing.T) {
+	ctx := context.Background()
+	nodes := newMultiNodes(t, 3)
+	key := utils.NewUUID()
+	require.NoError(t, nodes[0].Set(ctx, key, "usurper", 5*time.Second).Err())
+
+	suo := redissuo.NewMultiSuo(nodes, key, 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 2, xin.Granted())
+
+	// The lease lapsed on one of the granting nodes, just one node still holds it
+	require.NoError(t, nodes[1].Del(ctx, key).Err())
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, success)
+
+	holder, err := nodes[0].Get(ctx, key).Result()
+	require.NoError(t, err)
+	require.Equal(t, "usurper", holder)
+	require.Zero(t, nodes[2].Exists(ctx, key).Val())
+}
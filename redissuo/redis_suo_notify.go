@@ -0,0 +1,119 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// WithReleaseNotify makes each successful release publish on the per-key release channel
+// Waiters subscribed through SubscribeRelease wake up at once instead of sleeping out their poll interval
+// Leases lapsing on their own publish nothing, so waiters keep the poll interval as a fallback
+// WithKeyspaceNotify covers those lapses too when the server sends keyspace notifications
+//
+// WithReleaseNotify 使每次成功释放都在该键的释放频道上发布消息
+// 通过 SubscribeRelease 订阅的等待者会立即被唤醒，而不是睡满轮询间隔
+// 自行过期的租期不会发布消息，因此等待者仍保留轮询间隔作为兜底
+// 在服务端发送键空间通知时，WithKeyspaceNotify 也能覆盖这些过期
+func (o *Suo) WithReleaseNotify() *Suo {
+	o.releaseNotify = true
+	return o
+}
+
+// ReleaseNotify reports whether releases publish on the release channel
+// ReleaseNotify 判断释放时是否在释放频道上发布消息
+func (o *Suo) ReleaseNotify() bool {
+	return o.releaseNotify
+}
+
+// releaseChannel gets back the pub/sub channel announcing releases of the lock
+// releaseChannel 返回通告锁释放的发布订阅频道
+func (o *Suo) releaseChannel() string {
+	return companionKey(o.key, "released")
+}
+
+// publishRelease announces the release best-effort, waiters fall back on polling when it gets lost
+// publishRelease 尽力通告释放，消息丢失时等待者退回轮询
+func (o *Suo) publishRelease(ctx context.Context, sessionUUID string) {
+	if !o.releaseNotify || o.dryRun {
+		return
+	}
+	if err := o.client().Publish(ctx, o.releaseChannel(), sessionUUID).Err(); err != nil {
+		o.logger.DebugLog("发布释放通知报错", zap.String("k", o.key), zap.String("v", sessionUUID), zap.Error(err))
+	}
+}
+
+// ReleaseSubscription delivers a wake-up each time the lock is released, bursts coalesce into one
+// ReleaseSubscription 在锁每次释放时发出唤醒信号，连续的多次释放合并为一次
+type ReleaseSubscription struct {
+	pubsub *redis.PubSub // Underlying subscription // 底层订阅
+	wake   chan struct{} // Pending wake-up, at most one // 待处理的唤醒信号，最多一个
+	done   chan struct{} // Closed once forwarding ends // 转发结束后关闭
+}
+
+// SubscribeRelease subscribes to the release channel of the lock, returning once the subscription is confirmed
+// Releases after the return are never missed, so an attempt made next sees any release racing with it
+// With WithKeyspaceNotify it also listens on the keyspace notifications of the key when the server sends them
+//
+// SubscribeRelease 订阅锁的释放频道，在订阅确认后返回
+// 返回之后的释放不会被遗漏，因此随后进行的尝试能感知与之竞争的释放
+// 设置 WithKeyspaceNotify 后，在服务端发送键空间通知时同时监听该键的键空间通知
+func (o *Suo) SubscribeRelease(ctx context.Context) (*ReleaseSubscription, error) {
+	pubsub := o.client().Subscribe(ctx, o.releaseChannel())
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		o.logger.ErrorLog("订阅释放通知报错", zap.String("k", o.key), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	if o.keyspaceNotify && o.keyspaceEnabled(ctx) {
+		if err := pubsub.PSubscribe(ctx, o.keyspacePattern()); err != nil {
+			_ = pubsub.Close()
+			o.logger.ErrorLog("订阅释放通知报错", zap.String("k", o.key), zap.Error(err))
+			return nil, erero.Wro(err)
+		}
+		if _, err := pubsub.Receive(ctx); err != nil {
+			_ = pubsub.Close()
+			o.logger.ErrorLog("订阅释放通知报错", zap.String("k", o.key), zap.Error(err))
+			return nil, erero.Wro(err)
+		}
+	}
+	sub := &ReleaseSubscription{pubsub: pubsub, wake: make(chan struct{}, 1), done: make(chan struct{})}
+	go sub.forward()
+	return sub, nil
+}
+
+// forward turns channel messages into coalesced wake-ups until the subscription closes
+// Keyspace messages wake just on the events freeing the key, writes such as SET and PEXPIRE pass by
+//
+// forward 将频道消息转换为合并后的唤醒信号，直到订阅关闭
+// 键空间消息仅在使键被释放的事件上唤醒，SET 和 PEXPIRE 等写入会被忽略
+func (s *ReleaseSubscription) forward() {
+	defer close(s.done)
+	for message := range s.pubsub.Channel() {
+		if message.Pattern != "" && !keyspaceFreed[message.Payload] {
+			continue
+		}
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// C gets back the channel receiving the wake-ups
+// C 返回接收唤醒信号的通道
+func (s *ReleaseSubscription) C() <-chan struct{} {
+	return s.wake
+}
+
+// Close ends the subscription
+// Close 结束订阅
+func (s *ReleaseSubscription) Close() error {
+	if err := s.pubsub.Close(); err != nil {
+		return erero.Wro(err)
+	}
+	<-s.done
+	return nil
+}
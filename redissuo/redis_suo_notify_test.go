@@ -0,0 +1,41 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_SubscribeRelease validates subscribers wake up once the lock is released
+// TestSuo_SubscribeRelease 验证锁释放后订阅者被唤醒
+func TestSuo_SubscribeRelease(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithReleaseNotify()
+	require.True(t, suo.ReleaseNotify())
+
+	sub, err := suo.SubscribeRelease(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, sub.Close()) }()
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	select {
+	case <-sub.C():
+		t.Fatal("acquisitions must not wake subscribers")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	select {
+	case <-sub.C():
+	case <-time.After(time.Second):
+		t.Fatal("the release must wake subscribers")
+	}
+}
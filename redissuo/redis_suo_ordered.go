@@ -0,0 +1,48 @@
+package redissuo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/erero"
+)
+
+// AcquireOrdered acquires the locks of many resources in one sorted pass sharing a single session UUID
+// Keys get sorted and deduplicated, so callers taking overlapping sets never deadlock on each other
+// Locks taken so far get released when one is unavailable or fails, giving back nil in that case
+// Close the returned scope to release everything in reverse sequence
+//
+// AcquireOrdered 在一次排序遍历中获取多个资源的锁，共用同一个会话 UUID
+// 键会被排序并去重，因此获取重叠集合的调用方不会相互死锁
+// 当某个锁不可用或失败时释放已获取的锁，此时返回 nil
+// 关闭返回的作用域会按逆序释放全部锁
+func (m *Manager) AcquireOrdered(ctx context.Context, keys []string, ttl time.Duration) (*LockScope, error) {
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+
+	sessionUUID := utils.NewUUID()
+	scope := NewLockScope()
+	for idx, key := range sorted {
+		if idx > 0 && key == sorted[idx-1] {
+			continue
+		}
+		suo := m.NewSuo(key, ttl)
+		xin, err := suo.AcquireLockWithSession(ctx, sessionUUID)
+		if err != nil {
+			if erb := scope.Close(ctx); erb != nil {
+				return nil, erero.Joins([]error{erero.Wro(err), erb})
+			}
+			return nil, erero.Wro(err)
+		}
+		if xin == nil {
+			if erb := scope.Close(ctx); erb != nil {
+				return nil, erero.Wro(erb)
+			}
+			return nil, nil
+		}
+		scope.Add(suo, xin)
+	}
+	return scope, nil
+}
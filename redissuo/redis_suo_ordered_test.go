@@ -0,0 +1,39 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_AcquireOrdered validates all keys get taken under one session and rolled back when one is busy
+// TestManager_AcquireOrdered 验证所有键在同一会话下获取，某个键被占用时回滚
+func TestManager_AcquireOrdered(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	key1, key2, key3 := utils.NewUUID(), utils.NewUUID(), utils.NewUUID()
+
+	scope, err := manager.AcquireOrdered(ctx, []string{key2, key1, key2}, 5*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, scope)
+	require.Equal(t, 2, scope.Size())
+
+	infos, err := manager.InspectMany(ctx, key1, key2)
+	require.NoError(t, err)
+	require.Equal(t, infos[0].Holder, infos[1].Holder)
+
+	// key2 is busy, so key3 must not stay held past the failed pass
+	non, err := manager.AcquireOrdered(ctx, []string{key3, key2}, 5*time.Second)
+	require.NoError(t, err)
+	require.Nil(t, non)
+
+	infos, err = manager.InspectMany(ctx, key3)
+	require.NoError(t, err)
+	require.False(t, infos[0].Held())
+
+	require.NoError(t, scope.Close(ctx))
+}
@@ -0,0 +1,95 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yyle88/erero"
+)
+
+// ErrPaused is returned when acquisitions of a paused manager fail fast
+// ErrPaused 在已暂停管理器的获取被设为立即失败时返回
+var ErrPaused = NewError(CodePaused, LanguageEnglish, nil)
+
+// pauseSwitch holds fresh acquisitions of one manager back without touching Redis
+// pauseSwitch 在不访问 Redis 的情况下拦住一个管理器的新获取
+type pauseSwitch struct {
+	mutex    sync.Mutex    // Protects the fields below // 保护下面的字段
+	paused   bool          // Acquisitions held back // 获取被拦住
+	failFast bool          // Fail with ErrPaused instead of blocking // 返回 ErrPaused 而不是阻塞
+	resumed  chan struct{} // Closed on resume, nil when running // 恢复时关闭，运行时为 nil
+}
+
+// newPauseSwitch creates a running switch
+// newPauseSwitch 创建处于运行状态的开关
+func newPauseSwitch() *pauseSwitch {
+	return &pauseSwitch{}
+}
+
+// Pause holds fresh acquisitions through locks of the manager back until Resume
+// Attempts block, or fail with ErrPaused when WithPauseFailFast is set, without any Redis traffic
+// Extensions and releases of running holds go on, so the process drains cleanly
+//
+// Pause 拦住通过管理器的锁发起的新获取，直到调用 Resume
+// 获取会阻塞，设置 WithPauseFailFast 时返回 ErrPaused，期间不产生任何 Redis 请求
+// 已持有锁的延期和释放照常进行，使进程干净地排空
+func (m *Manager) Pause() {
+	m.pause.mutex.Lock()
+	defer m.pause.mutex.Unlock()
+	if !m.pause.paused {
+		m.pause.paused = true
+		m.pause.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets acquisitions through again and wakes the blocked ones
+// Resume 重新放行获取并唤醒被阻塞的获取
+func (m *Manager) Resume() {
+	m.pause.mutex.Lock()
+	defer m.pause.mutex.Unlock()
+	if m.pause.paused {
+		m.pause.paused = false
+		close(m.pause.resumed)
+		m.pause.resumed = nil
+	}
+}
+
+// Paused reports whether the manager holds acquisitions back
+// Paused 判断管理器是否正在拦住获取
+func (m *Manager) Paused() bool {
+	m.pause.mutex.Lock()
+	defer m.pause.mutex.Unlock()
+	return m.pause.paused
+}
+
+// WithPauseFailFast makes acquisitions of the paused manager fail with ErrPaused instead of blocking
+// WithPauseFailFast 使已暂停管理器的获取返回 ErrPaused 而不是阻塞
+func (m *Manager) WithPauseFailFast(enable bool) *Manager {
+	m.pause.mutex.Lock()
+	defer m.pause.mutex.Unlock()
+	m.pause.failFast = enable
+	return m
+}
+
+// awaitResume waits while the manager is paused, nil at once outside a manager or when running
+// awaitResume 在管理器暂停期间等待，不属于管理器或处于运行状态时立即返回 nil
+func (o *Suo) awaitResume(ctx context.Context) error {
+	if o.pause == nil {
+		return nil
+	}
+	o.pause.mutex.Lock()
+	paused, failFast, resumed := o.pause.paused, o.pause.failFast, o.pause.resumed
+	o.pause.mutex.Unlock()
+	if !paused {
+		return nil
+	}
+	if failFast {
+		return o.newError(CodePaused)
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return erero.Wro(ctx.Err())
+	}
+}
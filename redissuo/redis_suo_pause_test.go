@@ -0,0 +1,66 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_Pause validates paused acquisitions block until resume while held locks still extend
+// TestManager_Pause 验证暂停期间获取阻塞直到恢复，已持有的锁仍可延期
+func TestManager_Pause(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	manager.Pause()
+	require.True(t, manager.Paused())
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = suo.Acquire(timeoutCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	done := make(chan *redissuo.Xin)
+	go func() {
+		xin, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		done <- xin
+	}()
+	time.Sleep(20 * time.Millisecond)
+	manager.Resume()
+	require.False(t, manager.Paused())
+	require.NotNil(t, <-done)
+}
+
+// TestManager_PauseFailFast validates paused acquisitions fail with ErrPaused when fail-fast is set
+// TestManager_PauseFailFast 验证设置立即失败后暂停期间的获取返回 ErrPaused
+func TestManager_PauseFailFast(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithPauseFailFast(true)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	manager.Pause()
+	_, err := suo.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrPaused)
+
+	manager.Resume()
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+}
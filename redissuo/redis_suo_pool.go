@@ -0,0 +1,78 @@
+package redissuo
+
+import (
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// NewDedicatedClient creates a client reaching the same servers as the given one through its own small pool
+// Lock traffic on the dedicated pool never queues behind bulk application commands sharing the original pool
+// Supports *redis.Client, *redis.ClusterClient and *redis.Ring, the caller owns and closes the new client
+//
+// NewDedicatedClient 创建一个通过独立小连接池访问与给定客户端相同服务端的客户端
+// 专用连接池上的锁流量不会排在共享原连接池的批量业务命令之后
+// 支持 *redis.Client、*redis.ClusterClient 和 *redis.Ring，新客户端由调用方持有并关闭
+func NewDedicatedClient(rds redis.UniversalClient, poolSize int) (redis.UniversalClient, error) {
+	must.Nice(poolSize)
+	switch client := rds.(type) {
+	case *redis.Client:
+		options := *client.Options()
+		options.PoolSize = poolSize
+		options.MinIdleConns = min(options.MinIdleConns, poolSize)
+		options.MaxIdleConns = min(options.MaxIdleConns, poolSize)
+		return redis.NewClient(&options), nil
+	case *redis.ClusterClient:
+		options := *client.Options()
+		options.PoolSize = poolSize
+		options.MinIdleConns = min(options.MinIdleConns, poolSize)
+		options.MaxIdleConns = min(options.MaxIdleConns, poolSize)
+		return redis.NewClusterClient(&options), nil
+	case *redis.Ring:
+		options := *client.Options()
+		options.PoolSize = poolSize
+		options.MinIdleConns = min(options.MinIdleConns, poolSize)
+		options.MaxIdleConns = min(options.MaxIdleConns, poolSize)
+		return redis.NewRing(&options), nil
+	default:
+		return nil, erero.Wro(errors.Errorf("unsupported client type %T", rds))
+	}
+}
+
+// WithDedicatedPool moves lock traffic of the manager and its locks onto a dedicated pool of the given size
+// Gives back an error when the client is not one of the types supported by NewDedicatedClient, the manager is left unchanged then
+// Calling it again replaces the dedicated pool and closes the former one, so call it ahead of creating locks
+// Call Close to release the dedicated pool once done
+//
+// WithDedicatedPool 将管理器及其锁的流量迁移到给定大小的专用连接池
+// 客户端不是 NewDedicatedClient 支持的类型时返回错误，此时管理器保持不变
+// 再次调用会替换专用连接池并关闭之前的连接池，因此应在创建锁之前调用
+// 使用完毕后调用 Close 释放专用连接池
+func (m *Manager) WithDedicatedPool(poolSize int) (*Manager, error) {
+	client, err := NewDedicatedClient(m.redisClient, poolSize)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	previous, dedicated := m.redisClient, m.dedicated
+	m.redisClient = client
+	m.dedicated = true
+	if dedicated {
+		if err := previous.Close(); err != nil {
+			return m, erero.Wro(err)
+		}
+	}
+	return m, nil
+}
+
+// Close releases the dedicated pool when WithDedicatedPool was used, the shared client is left open
+// Close 在使用了 WithDedicatedPool 时释放专用连接池，共享客户端保持打开
+func (m *Manager) Close() error {
+	if !m.dedicated {
+		return nil
+	}
+	if err := m.redisClient.Close(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
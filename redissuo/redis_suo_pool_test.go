@@ -0,0 +1,79 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestNewDedicatedClient validates the dedicated client reaches the same server through its own pool
+// TestNewDedicatedClient 验证专用客户端通过独立连接池访问同一服务端
+func TestNewDedicatedClient(t *testing.T) {
+	dedicated := rese.V1(redissuo.NewDedicatedClient(caseRedisClient, 2))
+	defer func() { _ = dedicated.Close() }()
+
+	client, ok := dedicated.(*redis.Client)
+	require.True(t, ok)
+	require.Equal(t, 2, client.Options().PoolSize)
+	require.Equal(t, caseRedisClient.(*redis.Client).Options().Addr, client.Options().Addr)
+
+	// Failover clients are *redis.Client too and get copied the same way
+	failover, err := redissuo.NewDedicatedClient(redis.NewUniversalClient(&redis.UniversalOptions{Addrs: []string{"a:1", "b:2"}, MasterName: "m"}), 2)
+	require.NoError(t, err)
+	require.NoError(t, failover.Close())
+}
+
+// TestManager_WithDedicatedPool validates locks of the manager work on the dedicated pool
+// TestManager_WithDedicatedPool 验证管理器的锁在专用连接池上正常工作
+func TestManager_WithDedicatedPool(t *testing.T) {
+	ctx := context.Background()
+	manager, err := redissuo.NewManager(caseRedisClient).WithDedicatedPool(2)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, manager.Close()) }()
+
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	infos, err := manager.InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.Equal(t, xin.SessionUUID(), infos[0].Holder)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	// The shared client stays open past the manager
+	require.NoError(t, caseRedisClient.Ping(ctx).Err())
+}
+
+// TestManager_WithDedicatedPool_Replace validates a second call closes the former dedicated pool, and unsupported clients get an error
+// TestManager_WithDedicatedPool_Replace 验证再次调用会关闭之前的专用连接池，且不支持的客户端返回错误
+func TestManager_WithDedicatedPool_Replace(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	manager, err := manager.WithDedicatedPool(2)
+	require.NoError(t, err)
+	former := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	manager, err = manager.WithDedicatedPool(3)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, manager.Close()) }()
+
+	_, err = former.Acquire(ctx)
+	require.ErrorIs(t, err, redis.ErrClosed)
+
+	xin, err := manager.NewSuo(utils.NewUUID(), 5*time.Second).Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	_, err = redissuo.NewManager(struct{ redis.UniversalClient }{caseRedisClient}).WithDedicatedPool(2)
+	require.Error(t, err)
+}
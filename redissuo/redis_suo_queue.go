@@ -0,0 +1,250 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// ErrNotQueued is returned when a waiter is no longer in the queue
+// ErrNotQueued 在等待者已不在队列中时返回
+var ErrNotQueued = NewError(CodeNotQueued, LanguageEnglish, nil)
+
+const (
+	// recentHoldsLimit bounds how many recent hold durations feed the wait estimate
+	// recentHoldsLimit 限制参与等待估算的最近持有时长数量
+	recentHoldsLimit = 20
+)
+
+const (
+	// KEYS: queue / ARGV: session, queue ttl milliseconds
+	// Scores use Redis TIME so arrival sequence does not depend on client clocks
+	// KEYS: 队列 / ARGV: 会话、队列 TTL 毫秒数
+	// 分数使用 Redis TIME，使到达顺序不依赖客户端时钟
+	commandEnqueue = `redis.replicate_commands()
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+redis.call("ZADD", KEYS[1], "NX", ms, ARGV[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return redis.call("ZRANK", KEYS[1], ARGV[1])`
+
+	// KEYS: queue, lock, holds / ARGV: session
+	// Gives back {waiters ahead, lock held 0/1, recent hold milliseconds...}, false when not queued
+	// KEYS: 队列、锁、持有时长 / ARGV: 会话
+	// 返回 {前方等待者数, 锁是否被持有 0/1, 最近持有毫秒数...}，未排队时返回 false
+	commandQueueStatus = `local rank = redis.call("ZRANK", KEYS[1], ARGV[1])
+if not rank then
+    return false
+end
+local res = {rank, redis.call("EXISTS", KEYS[2])}
+for _, ms in ipairs(redis.call("LRANGE", KEYS[3], 0, -1)) do
+    res[#res + 1] = tonumber(ms)
+end
+return res`
+
+	// KEYS: holds / ARGV: hold milliseconds, limit, ttl milliseconds
+	// KEYS: 持有时长 / ARGV: 持有毫秒数、上限、TTL 毫秒数
+	commandRecordHold = `redis.call("LPUSH", KEYS[1], ARGV[1])
+redis.call("LTRIM", KEYS[1], 0, tonumber(ARGV[2]) - 1)
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return 1`
+)
+
+// WithWaitQueue enables the waiter queue, letting waiters see their position and an estimated wait
+// Release records hold durations feeding the estimate
+//
+// WithWaitQueue 启用等待队列，使等待者可以查看其位置和估计等待时间
+// 释放时记录持有时长用于估算
+func (o *Suo) WithWaitQueue(enable bool) *Suo {
+	o.waitQueue = enable
+	return o
+}
+
+// queueKey gets back the sorted set of waiters ordered through arrival
+// queueKey 返回按到达顺序排列的等待者有序集合
+func (o *Suo) queueKey() string {
+	return companionKey(o.key, "queue")
+}
+
+// holdsKey gets back the list of recent hold durations in milliseconds
+// holdsKey 返回最近持有时长（毫秒）的列表
+func (o *Suo) holdsKey() string {
+	return companionKey(o.key, "holds")
+}
+
+// companionTTL gets back the lifetime of queue related companions, long enough to outlive many holds
+// companionTTL 返回队列相关伴随键的存活时间，足以覆盖多次持有
+func (o *Suo) companionTTL() time.Duration {
+	return max(o.ttl*recentHoldsLimit, time.Minute)
+}
+
+// recordHold saves the hold duration of a released session when the waiter queue is enabled
+// recordHold 在启用等待队列时保存已释放会话的持有时长
+func (o *Suo) recordHold(ctx context.Context, duration time.Duration) {
+	if !o.waitQueue || o.dryRun {
+		return
+	}
+	args := []string{
+		strconv.FormatInt(duration.Milliseconds(), 10),
+		strconv.Itoa(recentHoldsLimit),
+		strconv.FormatInt(o.companionTTL().Milliseconds(), 10),
+	}
+	if err := o.client().Eval(ctx, commandRecordHold, []string{o.holdsKey()}, args).Err(); err != nil {
+		o.logger.ErrorLog("记录持有时长报错", zap.String("k", o.key), zap.Error(err))
+	}
+}
+
+// Waiter is a queued caller waiting on the lock
+// Acquire through the waiter keeps the queue accurate, Leave removes an abandoned waiter
+//
+// Waiter 是在锁上排队等待的调用方
+// 通过等待者获取锁可保持队列准确，Leave 移除放弃等待的等待者
+type Waiter struct {
+	suo         *Suo         // Lock being waited on // 正在等待的锁
+	sessionUUID string       // Session used in queue and acquisition // 队列和获取中使用的会话
+	position    atomic.Int64 // Waiters ahead when last seen // 最近一次观察到的前方等待者数量
+}
+
+// Enqueue registers a waiter at the tail of the queue
+// Requires WithWaitQueue, the waiter stays queued until it acquires or leaves
+//
+// Enqueue 在队列尾部登记一个等待者
+// 需要启用 WithWaitQueue，等待者在获取锁或离开前一直在队列中
+func (o *Suo) Enqueue(ctx context.Context) (*Waiter, error) {
+	must.True(o.waitQueue) // Requires WithWaitQueue // 需要启用 WithWaitQueue
+	sessionUUID := utils.NewUUID()
+	position, err := o.enqueue(ctx, sessionUUID)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	waiter := &Waiter{suo: o, sessionUUID: sessionUUID}
+	waiter.position.Store(position)
+	return waiter, nil
+}
+
+// enqueue adds the session to the queue, keeping its arrival when queued already, and refreshes the queue TTL
+// Gives back the count of waiters ahead
+//
+// enqueue 将会话加入队列（已在队列中时保留其到达时间），并刷新队列 TTL
+// 返回前方等待者数量
+func (o *Suo) enqueue(ctx context.Context, sessionUUID string) (int64, error) {
+	if o.simulated("enqueue") {
+		return 0, nil
+	}
+	if o.fairQueue {
+		return o.enqueueFair(ctx, sessionUUID)
+	}
+	args := []string{sessionUUID, strconv.FormatInt(o.companionTTL().Milliseconds(), 10)}
+	position, err := o.client().Eval(ctx, commandEnqueue, []string{o.queueKey()}, args).Int64()
+	if err != nil {
+		o.logger.ErrorLog("排队报错", zap.String("k", o.key), zap.Error(err))
+		return 0, erero.Wro(err)
+	}
+	return position, nil
+}
+
+// SessionUUID gets back the session the waiter acquires with
+// SessionUUID 返回等待者获取锁时使用的会话
+func (w *Waiter) SessionUUID() string {
+	return w.sessionUUID
+}
+
+// QueueStatus describes a waiter's place in the queue
+// QueueStatus 描述等待者在队列中的位置
+type QueueStatus struct {
+	Position      int64         // Waiters ahead, 0 means head of the queue // 前方等待者数量，0 表示位于队首
+	Held          bool          // Lock held at present // 锁当前是否被持有
+	EstimatedWait time.Duration // Estimate from recent hold durations, 0 without history // 基于最近持有时长的估算，无历史时为 0
+}
+
+// Status gets back the waiter's position and estimated wait
+// The estimate multiplies the mean recent hold through the holds still to come
+//
+// Status 返回等待者的位置和估计等待时间
+// 估算值为最近平均持有时长乘以之前尚需经历的持有次数
+func (w *Waiter) Status(ctx context.Context) (*QueueStatus, error) {
+	o := w.suo
+	if o.simulated("queue_status") {
+		return &QueueStatus{}, nil
+	}
+	keys := []string{o.queueKey(), o.key, o.holdsKey()}
+	items, err := o.client().Eval(ctx, commandQueueStatus, keys, []string{w.sessionUUID}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, o.newError(CodeNotQueued)
+	} else if err != nil {
+		o.logger.ErrorLog("查询队列报错", zap.String("k", o.key), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	if len(items) < 2 {
+		return nil, erero.Errorf("unexpected queue reply: %v", items)
+	}
+	position, _ := items[0].(int64)
+	held, _ := items[1].(int64)
+
+	status := &QueueStatus{Position: position, Held: held == 1}
+	if len(items) > 2 {
+		var total int64
+		for _, item := range items[2:] {
+			ms, _ := item.(int64)
+			total += ms
+		}
+		mean := time.Duration(total/int64(len(items)-2)) * time.Millisecond
+		holds := position
+		if status.Held {
+			holds++
+		}
+		status.EstimatedWait = mean * time.Duration(holds)
+	}
+	return status, nil
+}
+
+// Acquire attempts acquiring the lock using the waiter's session, leaving the queue on success
+// Gives back nil when the lock is unavailable, the waiter then stays queued
+//
+// Acquire 使用等待者的会话尝试获取锁，成功时离开队列
+// 锁不可用时返回 nil，等待者继续留在队列中
+func (w *Waiter) Acquire(ctx context.Context) (*Xin, error) {
+	if w.suo.fairQueue {
+		// Just the head of the queue may go ahead in fair mode
+		// 公平模式下只有队首可以继续
+		if head, err := w.atHead(ctx); err != nil || !head {
+			return nil, err
+		}
+	}
+	xin, err := w.suo.acquireSession(ctx, w.sessionUUID)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if xin != nil {
+		// The lock is held already, a failed leave just skews the positions of others until the queue expires
+		// 锁已被持有，离开失败仅会使其他等待者的位置偏差直到队列过期
+		_ = w.Leave(ctx)
+	}
+	return xin, nil
+}
+
+// Leave removes the waiter from the queue, safe to call more than once
+// Leave 将等待者从队列中移除，可安全地多次调用
+func (w *Waiter) Leave(ctx context.Context) error {
+	if w.suo.simulated("leave_queue") {
+		return nil
+	}
+	if w.suo.fairQueue {
+		// The heartbeat goes together with the entry, a stale one would be pruned anyway
+		// 心跳与条目一同删除，残留的心跳也会被清理
+		_ = w.suo.client().ZRem(ctx, w.suo.aliveKey(), w.sessionUUID).Err()
+	}
+	if err := w.suo.client().ZRem(ctx, w.suo.queueKey(), w.sessionUUID).Err(); err != nil {
+		w.suo.logger.ErrorLog("离开队列报错", zap.String("k", w.suo.key), zap.Error(err))
+		return erero.Wro(err)
+	}
+	return nil
+}
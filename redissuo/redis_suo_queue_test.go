@@ -0,0 +1,73 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWaiter_Status validates queue positions and wait estimates
+// Tests that positions follow arrival and estimates follow recent hold durations
+//
+// TestWaiter_Status 验证队列位置和等待估算
+// 测试位置遵循到达顺序，估算遵循最近的持有时长
+func TestWaiter_Status(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithWaitQueue(true)
+
+	// Build a hold history of about 50ms
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	time.Sleep(50 * time.Millisecond)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xin, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	waiter1, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond) // Distinct arrival scores
+	waiter2, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+
+	status1, err := waiter1.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), status1.Position)
+	require.True(t, status1.Held)
+
+	status2, err := waiter2.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), status2.Position)
+	require.Greater(t, status2.EstimatedWait, status1.EstimatedWait)
+	require.GreaterOrEqual(t, status2.EstimatedWait, 100*time.Millisecond)
+	t.Log(status1.EstimatedWait, status2.EstimatedWait)
+
+	success, err = suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	xin, err = waiter1.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	_, err = waiter1.Status(ctx)
+	require.ErrorIs(t, err, redissuo.ErrNotQueued)
+
+	status2, err = waiter2.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), status2.Position)
+
+	require.NoError(t, waiter2.Leave(ctx))
+
+	success, err = suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
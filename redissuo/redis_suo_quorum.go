@@ -0,0 +1,113 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QuorumOptions tunes the concurrent fan-out of one operation across independent Redis nodes
+// NodeTimeout bounds each node, HedgeDelay issues a second request to a node still silent past the delay
+// Zero values disable the per-node timeout and the hedging
+//
+// QuorumOptions 调整单个操作在多个独立 Redis 节点上的并发分发
+// NodeTimeout 限制每个节点的耗时，HedgeDelay 在节点超过延迟仍未应答时再发出一次请求
+// 零值表示禁用单节点超时和对冲请求
+type QuorumOptions struct {
+	NodeTimeout time.Duration // Time budget of each node // 每个节点的时间预算
+	HedgeDelay  time.Duration // Delay before the hedged request to a straggler // 对慢节点发出对冲请求前的延迟
+}
+
+// quorumReply is the answer of one node, nil in the result when the node has not answered yet
+// quorumReply 是单个节点的应答，节点尚未应答时结果中为 nil
+type quorumReply struct {
+	index   int   // Node index // 节点索引
+	granted bool  // Operation succeeded on the node // 节点上操作成功
+	err     error // Node problem, nil on a clean answer // 节点错误，正常应答时为 nil
+}
+
+// fanoutQuorum runs the call on all nodes at once and returns as soon as the quorum is reached or lost
+// Latency stays near the slowest node of the majority instead of the sum of all round trips
+// Nodes still in flight keep running in the background and show as nil, calls counts each node until it returned,
+// so callers cancel the context and wait on calls ahead of undoing what the stragglers may still write
+// The call must be idempotent on one node since a hedged request may run next to the first one
+//
+// fanoutQuorum 在所有节点上同时执行调用，达到或无法达到法定数量时立即返回
+// 延迟接近多数节点中最慢的那个，而不是所有往返耗时之和
+// 仍在进行中的节点在后台运行，在结果中为 nil，calls 对每个节点计数直到其返回，
+// 因此调用方在撤销慢节点仍可能写入的内容之前，应先取消上下文并等待 calls
+// 由于对冲请求可能与首个请求并行执行，调用在同一节点上必须是幂等的
+func fanoutQuorum(ctx context.Context, nodes int, quorum int, options QuorumOptions, calls *sync.WaitGroup, call func(ctx context.Context, index int) (bool, error)) (int, []*quorumReply) {
+	answers := make(chan *quorumReply, nodes)
+	calls.Add(nodes)
+	for idx := 0; idx < nodes; idx++ {
+		go func(idx int) {
+			defer calls.Done()
+			answers <- hedgedCall(ctx, idx, options, call)
+		}(idx)
+	}
+
+	replies := make([]*quorumReply, nodes)
+	granted, refused := 0, 0
+	for count := 0; count < nodes && granted < quorum && refused <= nodes-quorum; count++ {
+		reply := <-answers
+		replies[reply.index] = reply
+		if reply.granted {
+			granted++
+		} else {
+			refused++
+		}
+	}
+	return granted, replies
+}
+
+// hedgedCall runs the call on one node, sending a second request when the first stays silent past the hedge delay
+// The first success wins, a failure is returned only after every issued request has answered
+// A request still running once the winner answered gets cancelled and waited on, so none outlives the call
+//
+// hedgedCall 在单个节点上执行调用，首个请求超过对冲延迟仍未应答时发出第二个请求
+// 首个成功的应答胜出，只有所有已发出的请求都应答后才返回失败
+// 胜出应答之后仍在运行的请求会被取消并等待其结束，因此不会有请求比本调用存活更久
+func hedgedCall(ctx context.Context, index int, options QuorumOptions, call func(ctx context.Context, index int) (bool, error)) *quorumReply {
+	nodeCtx, cancel := context.WithCancel(ctx)
+	if options.NodeTimeout > 0 {
+		nodeCtx, cancel = context.WithTimeout(ctx, options.NodeTimeout)
+	}
+	var attempts sync.WaitGroup
+	defer attempts.Wait()
+	defer cancel()
+
+	answers := make(chan *quorumReply, 2)
+	attempt := func() {
+		defer attempts.Done()
+		granted, err := call(nodeCtx, index)
+		answers <- &quorumReply{index: index, granted: granted, err: err}
+	}
+	attempts.Add(1)
+	go attempt()
+	pending := 1
+
+	var hedge <-chan time.Time
+	if options.HedgeDelay > 0 {
+		timer := time.NewTimer(options.HedgeDelay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+
+	var reply *quorumReply
+	for pending > 0 {
+		select {
+		case reply = <-answers:
+			pending--
+			if reply.granted {
+				return reply
+			}
+		case <-hedge:
+			hedge = nil
+			attempts.Add(1)
+			go attempt()
+			pending++
+		}
+	}
+	return reply
+}
@@ -0,0 +1,190 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// ErrQuorumLost is the veto of the quorum admission once too few nodes are healthy to reach the quorum
+// ErrQuorumLost 是健康节点过少、无法达到法定数量时法定数量准入给出的否决
+var ErrQuorumLost = NewError(CodeQuorumLost, LanguageEnglish, nil)
+
+// NodeHealth is the outcome of the last probe of one node
+// NodeHealth 是单个节点最近一次探测的结果
+type NodeHealth struct {
+	Index       int           // Node index in the set // 节点在集合中的索引
+	Reachable   bool          // Node answered the probe // 节点应答了探测
+	Healthy     bool          // Reachable with the clock offset within bounds // 可达且时钟偏移在范围之内
+	ClockOffset time.Duration // Server clock minus the local clock at the midpoint of the round trip // 服务端时钟减去往返中点时刻的本地时钟
+	RoundTrip   time.Duration // Duration of the probe // 探测耗时
+	CheckedAt   time.Time     // Time of the probe // 探测时间
+	Err         error         // Probe problem, nil when reachable // 探测错误，可达时为 nil
+}
+
+// QuorumHealth is a snapshot of the node set as seen through the last probe round
+// QuorumHealth 是最近一轮探测所见的节点集合快照
+type QuorumHealth struct {
+	Nodes    []NodeHealth // Health of each node // 每个节点的健康状况
+	Healthy  int          // Healthy nodes // 健康节点数量
+	Quorum   int          // Nodes needed in each operation // 每次操作需要的节点数量
+	Possible bool         // Enough healthy nodes to reach the quorum // 健康节点足以达到法定数量
+	Probed   bool         // At least one probe round done // 至少完成了一轮探测
+}
+
+// QuorumMonitor probes the reachability and clock offset of each node in an independent node set (Redlock style)
+// Wire Admission into the locks to fail fast while the quorum is mathematically impossible, instead of timing out slowly
+//
+// QuorumMonitor 探测独立节点集合（Redlock 方式）中每个节点的可达性和时钟偏移
+// 将 Admission 接入锁，使法定数量在数学上不可达时快速失败，而不是缓慢超时
+type QuorumMonitor struct {
+	nodes     []redis.UniversalClient // Independent Redis nodes // 独立的 Redis 节点
+	quorum    int                     // Majority of the nodes // 节点的多数
+	interval  time.Duration           // Gap between probe rounds // 探测轮次之间的间隔
+	maxOffset time.Duration           // Largest tolerated clock offset, 0 means unchecked // 可容忍的最大时钟偏移，0 表示不检查
+	options   QuorumOptions           // Per-node timeout and hedging of probes // 探测的单节点超时和对冲
+	logger    logging.Logger          // Logger instance // 日志记录器实例
+	mutex     sync.Mutex              // Protects health // 保护 health
+	health    QuorumHealth            // Latest snapshot // 最新快照
+}
+
+// NewQuorumMonitor creates a monitor of the node set probing at the interval, the quorum is the majority
+// NewQuorumMonitor 创建按该间隔探测节点集合的监视器，法定数量为多数
+func NewQuorumMonitor(nodes []redis.UniversalClient, interval time.Duration) *QuorumMonitor {
+	must.Have(nodes)
+	quorum := len(nodes)/2 + 1
+	return &QuorumMonitor{
+		nodes:    nodes,
+		quorum:   quorum,
+		interval: must.Nice(interval),
+		options:  QuorumOptions{NodeTimeout: time.Second},
+		logger:   logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+		health:   QuorumHealth{Quorum: quorum, Possible: true},
+	}
+}
+
+// WithMaxClockOffset marks nodes drifting past the offset as unhealthy, as drift breaks the lease validity of Redlock
+// WithMaxClockOffset 将时钟偏移超过该值的节点标记为不健康，因为时钟漂移会破坏 Redlock 的租期有效性
+func (q *QuorumMonitor) WithMaxClockOffset(maxOffset time.Duration) *QuorumMonitor {
+	q.maxOffset = maxOffset
+	return q
+}
+
+// WithOptions sets the per-node timeout and hedging of the probes
+// WithOptions 设置探测的单节点超时和对冲
+func (q *QuorumMonitor) WithOptions(options QuorumOptions) *QuorumMonitor {
+	q.options = options
+	return q
+}
+
+// WithLogger sets custom logger used in reporting failed probes
+// WithLogger 设置用于报告探测失败的自定义日志记录器
+func (q *QuorumMonitor) WithLogger(logger logging.Logger) *QuorumMonitor {
+	q.logger = logger
+	return q
+}
+
+// Run probes at once and then at each interval until the context ends
+// Run 立即探测一次，然后按间隔持续探测，直到上下文结束
+func (q *QuorumMonitor) Run(ctx context.Context) {
+	for {
+		q.Probe(ctx)
+		timer := time.NewTimer(q.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Probe runs one probe round over all nodes at once and stores the snapshot
+// Probe 同时对所有节点执行一轮探测并保存快照
+func (q *QuorumMonitor) Probe(ctx context.Context) QuorumHealth {
+	nodes := make([]NodeHealth, len(q.nodes))
+	var wg sync.WaitGroup
+	for idx := range q.nodes {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			nodes[idx] = q.probeNode(ctx, idx)
+		}(idx)
+	}
+	wg.Wait()
+
+	health := QuorumHealth{Nodes: nodes, Quorum: q.quorum, Probed: true}
+	for _, node := range nodes {
+		if node.Healthy {
+			health.Healthy++
+		}
+	}
+	health.Possible = health.Healthy >= q.quorum
+	if !health.Possible {
+		q.logger.ErrorLog("法定数量不可达", zap.Int("healthy", health.Healthy), zap.Int("quorum", q.quorum), zap.Int("nodes", len(nodes)))
+	}
+	q.mutex.Lock()
+	q.health = health
+	q.mutex.Unlock()
+	return health
+}
+
+// probeNode reads the server time of one node, measuring the round trip and the clock offset
+// probeNode 读取单个节点的服务端时间，测量往返耗时和时钟偏移
+func (q *QuorumMonitor) probeNode(ctx context.Context, index int) NodeHealth {
+	var node = NodeHealth{Index: index}
+	// A hedged probe may answer next to the first one, just the first answer gets measured
+	// 对冲探测可能与首个探测同时应答，仅测量首个应答
+	var measured sync.Once
+	reply := hedgedCall(ctx, index, q.options, func(ctx context.Context, index int) (bool, error) {
+		start := time.Now()
+		serverTime, err := q.nodes[index].Time(ctx).Result()
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		roundTrip := time.Since(start)
+		measured.Do(func() {
+			node.RoundTrip = roundTrip
+			node.ClockOffset = serverTime.Sub(start.Add(roundTrip / 2))
+		})
+		return true, nil
+	})
+	node.CheckedAt = time.Now()
+	node.Reachable = reply.granted
+	node.Err = reply.err
+	if !node.Reachable {
+		q.logger.DebugLog("节点探测失败", zap.Int("node", index), zap.Error(reply.err))
+		return node
+	}
+	node.Healthy = q.maxOffset <= 0 || (node.ClockOffset <= q.maxOffset && node.ClockOffset >= -q.maxOffset)
+	return node
+}
+
+// Health gets back the latest snapshot, safe to call from other goroutines, e.g. health probes
+// Before the first round the quorum counts as possible
+//
+// Health 返回最新快照，可在其它 goroutine 中安全调用，例如健康探针
+// 首轮探测之前法定数量视为可达
+func (q *QuorumMonitor) Health() QuorumHealth {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.health
+}
+
+// Admission gets back an admission vetoing fresh acquisitions with ErrQuorumLost while the quorum is impossible
+// Admission 返回一个准入回调，在法定数量不可达时以 ErrQuorumLost 否决新的获取
+func (q *QuorumMonitor) Admission() Admission {
+	return func(ctx context.Context, key string) error {
+		if !q.Health().Possible {
+			return ErrQuorumLost
+		}
+		return nil
+	}
+}
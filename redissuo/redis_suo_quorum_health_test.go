@@ -0,0 +1,43 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuorumMonitor validates the health snapshot and the fail-fast admission once the quorum is impossible
+// TestQuorumMonitor 验证健康快照以及法定数量不可达时快速失败的准入
+func TestQuorumMonitor(t *testing.T) {
+	ctx := context.Background()
+	dead := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer func() { _ = dead.Close() }()
+
+	healthy := redissuo.NewQuorumMonitor([]redis.UniversalClient{caseRedisClient, caseRedisClient, dead}, time.Second)
+	require.True(t, healthy.Health().Possible)
+	require.False(t, healthy.Health().Probed)
+
+	health := healthy.Probe(ctx)
+	require.True(t, health.Probed)
+	require.True(t, health.Possible)
+	require.Equal(t, 2, health.Healthy)
+	require.Equal(t, 2, health.Quorum)
+	require.True(t, health.Nodes[0].Reachable)
+	require.Less(t, health.Nodes[0].ClockOffset.Abs(), time.Second)
+	require.False(t, health.Nodes[2].Reachable)
+	require.Error(t, health.Nodes[2].Err)
+
+	broken := redissuo.NewQuorumMonitor([]redis.UniversalClient{caseRedisClient, dead, dead}, time.Second)
+	require.False(t, broken.Probe(ctx).Possible)
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithAdmission(broken.Admission())
+	xin, err := suo.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrQuorumLost)
+	require.ErrorIs(t, err, redissuo.ErrAdmissionDenied)
+	require.Nil(t, xin)
+}
@@ -0,0 +1,79 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFanoutQuorum validates the fan-out returns once the majority answered, without waiting on stragglers
+// TestFanoutQuorum 验证多数节点应答后分发立即返回，不等待慢节点
+func TestFanoutQuorum(t *testing.T) {
+	startTime := time.Now()
+	granted, replies := fanoutQuorum(context.Background(), 5, 3, QuorumOptions{}, &sync.WaitGroup{}, func(ctx context.Context, index int) (bool, error) {
+		if index >= 3 {
+			time.Sleep(time.Second)
+		}
+		return true, nil
+	})
+	require.Less(t, time.Since(startTime), 500*time.Millisecond)
+	require.Equal(t, 3, granted)
+	require.Len(t, replies, 5)
+	require.Nil(t, replies[3])
+	require.Nil(t, replies[4])
+}
+
+// TestFanoutQuorum_Lost validates the fan-out stops once the quorum can no longer be reached
+// TestFanoutQuorum_Lost 验证无法达到法定数量时分发立即停止
+func TestFanoutQuorum_Lost(t *testing.T) {
+	granted, replies := fanoutQuorum(context.Background(), 3, 2, QuorumOptions{NodeTimeout: 50 * time.Millisecond}, &sync.WaitGroup{}, func(ctx context.Context, index int) (bool, error) {
+		if index == 0 {
+			return true, nil
+		}
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+	require.Equal(t, 1, granted)
+	require.True(t, replies[0].granted)
+	require.ErrorIs(t, replies[1].err, context.DeadlineExceeded)
+}
+
+// TestFanoutQuorum_Hedged validates a hedged request rescues a node whose first request hangs
+// TestFanoutQuorum_Hedged 验证对冲请求能挽救首个请求卡住的节点
+func TestFanoutQuorum_Hedged(t *testing.T) {
+	var calls atomic.Int64
+	granted, replies := fanoutQuorum(context.Background(), 1, 1, QuorumOptions{NodeTimeout: time.Second, HedgeDelay: 20 * time.Millisecond}, &sync.WaitGroup{}, func(ctx context.Context, index int) (bool, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return false, ctx.Err()
+		}
+		return true, nil
+	})
+	require.Equal(t, 1, granted)
+	require.True(t, replies[0].granted)
+	require.Equal(t, int64(2), calls.Load())
+}
+
+// TestFanoutQuorum_Stragglers validates callers cancel the stragglers and wait on them past the quorum
+// TestFanoutQuorum_Stragglers 验证调用方在达到法定数量后可取消慢节点并等待其结束
+func TestFanoutQuorum_Stragglers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls sync.WaitGroup
+	var finished atomic.Int64
+	granted, _ := fanoutQuorum(ctx, 3, 2, QuorumOptions{}, &calls, func(ctx context.Context, index int) (bool, error) {
+		defer finished.Add(1)
+		if index == 2 {
+			<-ctx.Done()
+			return false, ctx.Err()
+		}
+		return true, nil
+	})
+	require.Equal(t, 2, granted)
+	cancel()
+	calls.Wait()
+	require.Equal(t, int64(3), finished.Load())
+}
@@ -0,0 +1,90 @@
+package redissuo
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/rand"
+	"sync"
+
+	"github.com/yyle88/must"
+)
+
+// Random supplies the randomness used in TTL jitter and backoff jitter
+// Seed it in tests to get repeatable runs, or back it with crypto/rand when predictability matters
+// Implementations must be safe across goroutines, *rand.Rand of math/rand is not, wrap it with NewSeededRandom
+//
+// Random 提供 TTL 抖动和退避抖动使用的随机数
+// 在测试中设定种子以获得可重复的运行，或在可预测性重要时以 crypto/rand 为后端
+// 实现必须在多个 goroutine 间安全，math/rand 的 *rand.Rand 并不安全，请使用 NewSeededRandom 包装
+type Random interface {
+	Int63n(n int64) int64 // Random number in [0, n), n must be positive // [0, n) 范围内的随机数，n 必须为正数
+}
+
+// systemRandom draws from the goroutine-safe global source of math/rand
+// systemRandom 从 math/rand 的并发安全全局源中取数
+type systemRandom struct{}
+
+func (systemRandom) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
+
+// SystemRandom gets back the random source used when none is set
+// SystemRandom 返回未设置随机源时使用的随机源
+func SystemRandom() Random {
+	return systemRandom{}
+}
+
+// seededRandom serializes draws of a seeded source
+// seededRandom 串行化对带种子随机源的取数
+type seededRandom struct {
+	mutex  sync.Mutex
+	source *rand.Rand
+}
+
+func (r *seededRandom) Int63n(n int64) int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.source.Int63n(n)
+}
+
+// NewSeededRandom creates a deterministic random source, the same seed gives the same sequence
+// NewSeededRandom 创建确定性的随机源，相同的种子产生相同的序列
+func NewSeededRandom(seed int64) Random {
+	return &seededRandom{source: rand.New(rand.NewSource(seed))}
+}
+
+// cryptoRandom draws from crypto/rand
+// cryptoRandom 从 crypto/rand 取数
+type cryptoRandom struct{}
+
+func (cryptoRandom) Int63n(n int64) int64 {
+	value, err := crand.Int(crand.Reader, big.NewInt(n))
+	must.Done(err)
+	return value.Int64()
+}
+
+// CryptoRandom gets back a random source backed through crypto/rand, so timings cannot be predicted
+// CryptoRandom 返回以 crypto/rand 为后端的随机源，使时间安排无法被预测
+func CryptoRandom() Random {
+	return cryptoRandom{}
+}
+
+// WithRandom sets the random source used in jitter
+// WithRandom 设置抖动使用的随机源
+func (o *Suo) WithRandom(random Random) *Suo {
+	o.random = must.Nice(random)
+	return o
+}
+
+// Random gets back the random source of the lock, used through the runner in backoff jitter
+// Random 返回锁的随机源，供运行器在退避抖动时使用
+func (o *Suo) Random() Random {
+	return o.random
+}
+
+// WithRandom sets the random source of locks created through the manager
+// WithRandom 设置通过管理器创建的锁的随机源
+func (m *Manager) WithRandom(random Random) *Manager {
+	m.random = must.Nice(random)
+	return m
+}
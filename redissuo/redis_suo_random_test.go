@@ -0,0 +1,34 @@
+package redissuo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewSeededRandom validates the same seed gives the same sequence and locks share the manager's source
+// TestNewSeededRandom 验证相同种子产生相同序列，且锁共享管理器的随机源
+func TestNewSeededRandom(t *testing.T) {
+	first, second := redissuo.NewSeededRandom(42), redissuo.NewSeededRandom(42)
+	for range 10 {
+		require.Equal(t, first.Int63n(1000), second.Int63n(1000))
+	}
+
+	random := redissuo.NewSeededRandom(7)
+	manager := redissuo.NewManager(caseRedisClient).WithRandom(random)
+	require.Equal(t, random, manager.NewSuo(utils.NewUUID(), time.Second).Random())
+}
+
+// TestCryptoRandom validates draws stay in range
+// TestCryptoRandom 验证取数保持在范围内
+func TestCryptoRandom(t *testing.T) {
+	random := redissuo.CryptoRandom()
+	for range 100 {
+		value := random.Int63n(5)
+		require.GreaterOrEqual(t, value, int64(0))
+		require.Less(t, value, int64(5))
+	}
+}
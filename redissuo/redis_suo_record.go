@@ -0,0 +1,138 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// ErrFollowedRunFailed is returned to followers when the holder recorded a failed run
+// ErrFollowedRunFailed 在持有者记录了失败的运行时返回给跟随者
+var ErrFollowedRunFailed = NewError(CodeFollowedRunFailed, LanguageEnglish, nil)
+
+// RunStatus is the outcome kept in an execution record
+// RunStatus 是执行记录中保存的结果
+type RunStatus string
+
+const (
+	RunSucceeded RunStatus = "succeeded" // Run completed, retries can skip it // 运行已完成，重试可以跳过
+	RunFailed    RunStatus = "failed"    // Run failed, retries go ahead // 运行失败，重试会继续执行
+)
+
+// ExecutionRecord is the persisted outcome of one logical run protected by the lock
+// Written at the end of the run while the lock is still held, so a retry sees what a previous holder finished
+//
+// ExecutionRecord 是受锁保护的单次逻辑运行的持久化结果
+// 在运行结束且仍持有锁时写入，使重试能看到先前持有者已完成的工作
+type ExecutionRecord struct {
+	Key         string    // Lock name ID // 锁名标识符
+	RunID       string    // Logical run ID, e.g. the business date of a daily job // 逻辑运行标识，例如日任务的业务日期
+	Status      RunStatus // Outcome of the run // 运行结果
+	ResultHash  string    // Digest of the result, blank when not tracked // 结果摘要，未跟踪时为空
+	CompletedAt time.Time // Time the run completed, on the Redis clock // 运行完成时间，依据 Redis 时钟
+}
+
+const (
+	// KEYS: lock, record, record index / ARGV: session, run ID, status, result hash, retention milliseconds
+	// Writes the record just when the session still holds the lock, stamped with Redis TIME milliseconds
+	// The index lists the record keys so Cleanup finds them, it lives as long as the longest kept record
+	// KEYS: 锁、记录、记录索引 / ARGV: 会话、运行标识、状态、结果摘要、保留时长毫秒数
+	// 仅当会话仍持有锁时写入记录，并以 Redis TIME 毫秒数标记时间
+	// 索引列出记录键以便 Cleanup 找到它们，其存活时间与保存最久的记录一致
+	commandCompleteRun = `redis.replicate_commands()
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+redis.call("HSET", KEYS[2], "run_id", ARGV[2], "status", ARGV[3], "result_hash", ARGV[4], "completed_at", ms)
+local retention = tonumber(ARGV[5])
+if retention > 0 then
+    redis.call("PEXPIRE", KEYS[2], retention)
+end
+local fresh = redis.call("EXISTS", KEYS[3]) == 0
+redis.call("SADD", KEYS[3], KEYS[2])
+if retention <= 0 then
+    redis.call("PERSIST", KEYS[3])
+elseif fresh then
+    redis.call("PEXPIRE", KEYS[3], retention)
+else
+    local ttl = redis.call("PTTL", KEYS[3])
+    if ttl >= 0 and ttl < retention then
+        redis.call("PEXPIRE", KEYS[3], retention)
+    end
+end
+return 1`
+)
+
+// recordKey gets back the companion key holding the execution record of the run
+// recordKey 返回保存该运行执行记录的伴随键
+func (o *Suo) recordKey(runID string) string {
+	return companionKey(o.key, "run:"+runID)
+}
+
+// recordsKey gets back the companion set indexing the record keys of the lock name
+// recordsKey 返回索引该锁名所有记录键的伴随集合
+func (o *Suo) recordsKey() string {
+	return companionKey(o.key, "runs")
+}
+
+// CompleteRun persists the execution record of the run atomically with an ownership check
+// Gives back false when the session no longer holds the lock, the record is not written then
+// Retention bounds how long the record is kept, 0 keeps it forever
+// The completion time comes from the Redis clock, so records of different clients compare with each other
+//
+// CompleteRun 在检查所有权的同时原子地持久化该运行的执行记录
+// 当会话已不再持有锁时返回 false，此时不会写入记录
+// 保留时长限制记录的保存时间，0 表示永久保存
+// 完成时间取自 Redis 时钟，因此不同客户端的记录可以相互比较
+func (o *Suo) CompleteRun(ctx context.Context, xin *Xin, runID string, status RunStatus, resultHash string, retention time.Duration) (bool, error) {
+	must.Equals(xin.key, o.key)
+	must.OK(runID)
+	if o.simulated("complete_run") {
+		return true, nil
+	}
+	args := []interface{}{
+		xin.sessionUUID,
+		runID,
+		string(status),
+		resultHash,
+		strconv.FormatInt(retention.Milliseconds(), 10),
+	}
+	result, err := o.client().Eval(ctx, commandCompleteRun, []string{o.key, o.recordKey(runID), o.recordsKey()}, args...).Int64()
+	if err != nil {
+		o.logger.ErrorLog("写入执行记录报错", zap.String("k", o.key), zap.String("run_id", runID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	return result == 1, nil
+}
+
+// ExecutionRecord gets back the execution record of the run, nil when none was written
+// ExecutionRecord 返回该运行的执行记录，从未写入时为 nil
+func (o *Suo) ExecutionRecord(ctx context.Context, runID string) (*ExecutionRecord, error) {
+	if o.simulated("execution_record") {
+		return nil, nil
+	}
+	fields, err := o.client().HGetAll(ctx, o.recordKey(runID)).Result()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	milliseconds, err := strconv.ParseInt(fields["completed_at"], 10, 64)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &ExecutionRecord{
+		Key:         o.key,
+		RunID:       fields["run_id"],
+		Status:      RunStatus(fields["status"]),
+		ResultHash:  fields["result_hash"],
+		CompletedAt: time.UnixMilli(milliseconds),
+	}, nil
+}
@@ -0,0 +1,45 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_CompleteRun validates the record gets written by the holder and refused once the lock is lost
+// TestSuo_CompleteRun 验证记录由持有者写入，锁丢失后写入被拒绝
+func TestSuo_CompleteRun(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	record, err := suo.ExecutionRecord(ctx, "2026-10-15")
+	require.NoError(t, err)
+	require.Nil(t, record)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	ok, err := suo.CompleteRun(ctx, xin, "2026-10-15", redissuo.RunSucceeded, "sha256:abc", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	record, err = suo.ExecutionRecord(ctx, "2026-10-15")
+	require.NoError(t, err)
+	require.Equal(t, "2026-10-15", record.RunID)
+	require.Equal(t, redissuo.RunSucceeded, record.Status)
+	require.Equal(t, "sha256:abc", record.ResultHash)
+	require.WithinDuration(t, time.Now(), record.CompletedAt, time.Second)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	ok, err = suo.CompleteRun(ctx, xin, "2026-10-16", redissuo.RunSucceeded, "", 0)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
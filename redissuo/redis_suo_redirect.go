@@ -0,0 +1,96 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// defaultRedirectLimit matches the MaxRedirects default of go-redis cluster clients
+// defaultRedirectLimit 与 go-redis 集群客户端 MaxRedirects 的默认值一致
+const defaultRedirectLimit = 3
+
+// isRedirect reports whether the error is a MOVED or ASK cluster redirection
+// isRedirect 判断错误是否为 MOVED 或 ASK 集群重定向
+func isRedirect(err error) bool {
+	return redis.HasErrorPrefix(err, "MOVED ") || redis.HasErrorPrefix(err, "ASK ")
+}
+
+// WithRedirectLimit bounds the retries of a script hitting MOVED or ASK during resharding, 0 disables them
+// Cluster clients get their slot map reloaded ahead of each retry, so the retry lands on the owning node
+//
+// WithRedirectLimit 限制脚本在重新分片期间遇到 MOVED 或 ASK 时的重试次数，0 表示禁用
+// 集群客户端在每次重试前重新加载槽映射，使重试落到拥有该槽的节点上
+func (o *Suo) WithRedirectLimit(limit int) *Suo {
+	o.redirectLimit = max(limit, 0)
+	return o
+}
+
+// WithRedirectLimit bounds redirection retries in locks created through the manager
+// WithRedirectLimit 限制通过管理器创建的锁的重定向重试次数
+func (m *Manager) WithRedirectLimit(limit int) *Manager {
+	m.redirectLimit = max(limit, 0)
+	return m
+}
+
+// Redirections gets back the count of redirections met by the lock, shared across locks of a manager
+// Redirections 返回该锁遇到的重定向次数，在同一管理器的锁之间共享
+func (o *Suo) Redirections() int64 {
+	return o.redirects.Load()
+}
+
+// Redirections gets back the count of redirections met by locks created through the manager
+// Redirections 返回通过管理器创建的锁遇到的重定向次数
+func (m *Manager) Redirections() int64 {
+	return m.redirects.Load()
+}
+
+// eval runs the script through EVALSHA, falling back to EVAL on NOSCRIPT, and retries it on cluster redirections up to the limit
+// With failover enabled the script runs again on the next client after a switch
+// Other errors and the last redirection come back unchanged
+//
+// eval 通过 EVALSHA 执行脚本，遇到 NOSCRIPT 时回退到 EVAL，并在遇到集群重定向时重试，直到达到上限
+// 启用故障切换时，切换后脚本会在下一个客户端上重新执行
+// 其它错误和最后一次重定向原样返回
+func (o *Suo) eval(ctx context.Context, command string, keys []string, args ...interface{}) (interface{}, error) {
+	return o.evalWith(ctx, true, command, keys, args...)
+}
+
+// evalWith runs the script like eval, running it again on the next client after a switch just when retry is set
+// The switch itself happens either way, so the next call lands on the next client
+//
+// evalWith 与 eval 一样执行脚本，仅在 retry 为真时切换后在下一个客户端上重新执行
+// 无论如何都会完成切换，使下一次调用落到下一个客户端上
+func (o *Suo) evalWith(ctx context.Context, retry bool, command string, keys []string, args ...interface{}) (interface{}, error) {
+	if o.failover == nil {
+		return o.evalOn(ctx, o.redisClient, command, keys, args...)
+	}
+	for attempt := 0; ; attempt++ {
+		index := o.failover.active.Load()
+		result, err := o.evalOn(ctx, o.failover.clients[index], command, keys, args...)
+		if !o.failover.record(index, err) {
+			return result, err
+		}
+		o.logger.ErrorLog("故障切换-改用下一个客户端", zap.String("k", o.key), zap.Int64("from", index), zap.Int64("to", o.failover.active.Load()), zap.Error(err))
+		if !retry || attempt+1 >= len(o.failover.clients) {
+			return result, err
+		}
+	}
+}
+
+// evalOn runs the script on the given client, retrying it on cluster redirections up to the limit
+// evalOn 在给定客户端上执行脚本，并在遇到集群重定向时重试，直到达到上限
+func (o *Suo) evalOn(ctx context.Context, client redis.UniversalClient, command string, keys []string, args ...interface{}) (interface{}, error) {
+	script := scriptOf(command)
+	result, err := script.Run(ctx, client, keys, args...).Result()
+	for attempt := 0; attempt < o.redirectLimit && isRedirect(err); attempt++ {
+		o.redirects.Add(1)
+		o.logger.DebugLog("集群重定向-重试请求", zap.String("k", o.key), zap.Int("attempt", attempt+1), zap.Error(err))
+		if cluster, ok := client.(*redis.ClusterClient); ok {
+			cluster.ReloadState(ctx)
+		}
+		result, err = script.Run(ctx, client, keys, args...).Result()
+	}
+	return result, err
+}
@@ -0,0 +1,72 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// movedError mimics the reply of a cluster node that no longer owns the slot
+// movedError 模拟已不再拥有该槽的集群节点的回复
+type movedError string
+
+func (e movedError) Error() string { return string(e) }
+func (e movedError) RedisError()   {}
+
+// movedHook fails the first scripts with MOVED as during resharding
+// movedHook 让前几个脚本以 MOVED 失败，模拟重新分片期间的情况
+type movedHook struct {
+	remaining atomic.Int64
+}
+
+func (h *movedHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *movedHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if (cmd.Name() == "eval" || cmd.Name() == "evalsha") && h.remaining.Add(-1) >= 0 {
+			cmd.SetErr(movedError("MOVED 3999 127.0.0.1:6381"))
+			return cmd.Err()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *movedHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// TestManager_WithRedirectLimit validates redirections get retried and counted up to the limit
+// TestManager_WithRedirectLimit 验证重定向会被重试并计数，直到达到上限
+func TestManager_WithRedirectLimit(t *testing.T) {
+	ctx := context.Background()
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: caseRedisClient.(*redis.Client).Options().Addr,
+	})
+	defer func() { _ = redisClient.Close() }()
+	hook := &movedHook{}
+	redisClient.AddHook(hook)
+
+	manager := redissuo.NewManager(redisClient).WithRedirectLimit(2)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+
+	hook.remaining.Store(2)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, int64(2), manager.Redirections())
+
+	hook.remaining.Store(3)
+	_, err = suo.Release(ctx, xin)
+	require.True(t, redis.HasErrorPrefix(err, "MOVED"))
+	require.Equal(t, int64(4), suo.Redirections())
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,169 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: reentrant lock / ARGV: session, TTL milliseconds
+	// Counts one more hold when the lock is free or held through the session, refreshing the TTL
+	// Gives back the hold count, 0 when another session holds the lock
+	// KEYS: 可重入锁 / ARGV: 会话、TTL 毫秒数
+	// 锁空闲或由该会话持有时增加一次持有计数并刷新 TTL
+	// 返回持有计数，被其它会话持有时返回 0
+	commandAcquireReentrant = `if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+    local count = redis.call("HINCRBY", KEYS[1], ARGV[1], 1)
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return count
+end
+return 0`
+
+	// KEYS: reentrant lock / ARGV: session
+	// Counts one hold less, deleting the lock once the last hold is given back
+	// Gives back the holds left, -1 when the session does not hold the lock
+	// KEYS: 可重入锁 / ARGV: 会话
+	// 减少一次持有计数，最后一次持有归还时删除锁
+	// 返回剩余持有次数，会话未持有锁时返回 -1
+	commandReleaseReentrant = `if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+    return -1
+end
+local count = redis.call("HINCRBY", KEYS[1], ARGV[1], -1)
+if count <= 0 then
+    redis.call("DEL", KEYS[1])
+    return 0
+end
+return count`
+
+	// KEYS: reentrant lock / ARGV: session, TTL milliseconds
+	// Refreshes the TTL just when the session holds the lock, the hold count stays
+	// KEYS: 可重入锁 / ARGV: 会话、TTL 毫秒数
+	// 仅当会话持有锁时刷新 TTL，持有计数保持不变
+	commandExtendReentrant = `if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 0 then
+    return 0
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1`
+)
+
+// ReentrantSuo is a lock the holding session may acquire again, counting its holds in a Redis hash
+// Each acquisition needs a matching release before the lock frees up, so nested functions guarding themselves
+// through the same session do not deadlock on themselves
+// The lock key holds a hash instead of a string, so do not share the key with a plain Suo
+//
+// ReentrantSuo 是持有会话可以再次获取的锁，在 Redis 哈希中统计其持有次数
+// 每次获取都需要一次对应的释放后锁才会空闲，使通过同一会话自我保护的嵌套函数不会自我死锁
+// 锁键保存的是哈希而非字符串，因此不要与普通 Suo 共用该键
+type ReentrantSuo struct {
+	suo *Suo // Lock name, TTL, clock and logger // 锁名、TTL、时钟和日志记录器
+}
+
+// NewReentrantSuo creates a reentrant lock of the key leasing the TTL
+// NewReentrantSuo 创建租期为该 TTL 的可重入锁
+func NewReentrantSuo(rds redis.UniversalClient, key string, ttl time.Duration) *ReentrantSuo {
+	return &ReentrantSuo{suo: NewSuo(rds, key, ttl)}
+}
+
+// WithLogger sets custom logger used in reentrant lock operations
+// WithLogger 设置可重入锁操作使用的自定义日志记录器
+func (o *ReentrantSuo) WithLogger(logger logging.Logger) *ReentrantSuo {
+	o.suo.WithLogger(logger)
+	return o
+}
+
+// Key gets back the lock name
+// Key 返回锁名
+func (o *ReentrantSuo) Key() string {
+	return o.suo.key
+}
+
+// Acquire takes the lock with a fresh session, nil when another session holds it
+// Acquire 使用新会话获取锁，被其它会话持有时返回 nil
+func (o *ReentrantSuo) Acquire(ctx context.Context) (*Xin, error) {
+	return o.AcquireWithSession(ctx, utils.NewUUID())
+}
+
+// AcquireWithSession takes the lock through the session, counting one more hold when the session holds it already
+// Nested functions pass the session of the outer hold, each of them releases once
+//
+// AcquireWithSession 通过该会话获取锁，会话已持有时增加一次持有计数
+// 嵌套函数传入外层持有的会话，各自释放一次
+func (o *ReentrantSuo) AcquireWithSession(ctx context.Context, sessionUUID string) (*Xin, error) {
+	var suo = o.suo
+	must.OK(sessionUUID)
+	var startTime = suo.clock.Now()
+	result, err := suo.eval(ctx, commandAcquireReentrant, []string{suo.key}, sessionUUID, strconv.FormatInt(suo.ttl.Milliseconds(), 10))
+	if err != nil {
+		suo.logger.ErrorLog("申请可重入锁报错", zap.String("k", suo.key), zap.String("v", sessionUUID), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	count, _ := result.(int64)
+	if count <= 0 {
+		suo.logger.DebugLog("锁已经被占用-申请不到-请等待释放", zap.String("k", suo.key), zap.String("v", sessionUUID))
+		return nil, nil
+	}
+	suo.logger.DebugLog("可重入锁已申请", zap.String("k", suo.key), zap.String("v", sessionUUID), zap.Int64("holds", count))
+	expireTime, optimisticExpire := suo.expiryOf(startTime, suo.clock.Now(), suo.ttl)
+	xin := &Xin{key: suo.key, sessionUUID: sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, acquiredAt: startTime}
+	return attachCloser(xin, suo.language, o.Release), nil
+}
+
+// Release gives one hold back, the lock frees up once the last hold is given back
+// Gives back false when the session no longer holds the lock
+//
+// Release 归还一次持有，最后一次持有归还后锁才会空闲
+// 会话已不再持有锁时返回 false
+func (o *ReentrantSuo) Release(ctx context.Context, xin *Xin) (bool, error) {
+	var suo = o.suo
+	must.Equals(xin.key, suo.key)
+	result, err := suo.eval(ctx, commandReleaseReentrant, []string{suo.key}, xin.sessionUUID)
+	if err != nil {
+		suo.logger.ErrorLog("释放可重入锁报错", zap.String("k", suo.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	left, _ := result.(int64)
+	xin.closer.markDone()
+	suo.logger.DebugLog("可重入锁已释放", zap.String("k", suo.key), zap.String("v", xin.sessionUUID), zap.Int64("holds", left))
+	return left >= 0, nil
+}
+
+// Extend refreshes the lease of the session keeping the hold count, nil once the session lost the lock
+// Extend 刷新会话的租期并保持持有计数，会话已失去锁时返回 nil
+func (o *ReentrantSuo) Extend(ctx context.Context, xin *Xin) (*Xin, error) {
+	var suo = o.suo
+	must.Equals(xin.key, suo.key)
+	var startTime = suo.clock.Now()
+	result, err := suo.eval(ctx, commandExtendReentrant, []string{suo.key}, xin.sessionUUID, strconv.FormatInt(suo.ttl.Milliseconds(), 10))
+	if err != nil {
+		suo.logger.ErrorLog("延期可重入锁报错", zap.String("k", suo.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	if extended, _ := result.(int64); extended != 1 {
+		return nil, nil
+	}
+	expireTime, optimisticExpire := suo.expiryOf(startTime, suo.clock.Now(), suo.ttl)
+	res := &Xin{key: suo.key, sessionUUID: xin.sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, acquiredAt: xin.acquiredAt, extensions: xin.extensions + 1}
+	xin.closer.follow(res)
+	return res, nil
+}
+
+// Holds gets back the hold count of the session, 0 when it does not hold the lock
+// Holds 返回该会话的持有计数，未持有锁时为 0
+func (o *ReentrantSuo) Holds(ctx context.Context, xin *Xin) (int64, error) {
+	count, err := o.suo.client().HGet(ctx, o.suo.key, xin.sessionUUID).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	} else if err != nil {
+		return 0, erero.Wro(err)
+	}
+	return count, nil
+}
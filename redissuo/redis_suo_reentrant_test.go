@@ -0,0 +1,61 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReentrantSuo validates nested holds of one session and the matching count of releases
+// TestReentrantSuo 验证同一会话的嵌套持有以及对应次数的释放
+func TestReentrantSuo(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewReentrantSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	outer, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, outer)
+
+	inner, err := suo.AcquireWithSession(ctx, outer.SessionUUID())
+	require.NoError(t, err)
+	require.NotNil(t, inner)
+
+	holds, err := suo.Holds(ctx, outer)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), holds)
+
+	other, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	inner, err = suo.Extend(ctx, inner)
+	require.NoError(t, err)
+	require.NotNil(t, inner)
+
+	success, err := suo.Release(ctx, inner)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	other, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	success, err = suo.Release(ctx, outer)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	success, err = suo.Release(ctx, outer)
+	require.NoError(t, err)
+	require.False(t, success)
+
+	other, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	success, err = suo.Release(ctx, other)
+	require.NoError(t, err)
+	require.True(t, success)
+}
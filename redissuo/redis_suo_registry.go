@@ -0,0 +1,112 @@
+package redissuo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: registry hash / ARGV: lock key, session
+	// Removes the entry just when it still points at the releasing session
+	// KEYS: 注册表哈希 / ARGV: 锁键、会话
+	// 仅当条目仍指向正在释放的会话时才删除
+	commandUnregister = `if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+    return redis.call("HDEL", KEYS[1], ARGV[1])
+end
+return 0`
+)
+
+// WithRegistry enables a registry hash listing the locks held through this manager's locks
+// Entries are written past each acquisition and removed on release, stale entries get pruned in List
+//
+// WithRegistry 启用注册表哈希，列出通过此管理器的锁持有的锁
+// 条目在每次获取后写入，释放时删除，过期条目在 List 中清理
+func (m *Manager) WithRegistry(registryKey string) *Manager {
+	m.registryKey = must.Nice(registryKey)
+	return m
+}
+
+// register records the held lock in the registry, problems are logged without failing the acquisition
+// register 在注册表中登记已持有的锁，错误仅记录日志不影响获取
+func (o *Suo) register(ctx context.Context, sessionUUID string) {
+	if o.registry == "" || o.dryRun {
+		return
+	}
+	if err := o.client().HSet(ctx, o.registry, o.key, sessionUUID).Err(); err != nil {
+		o.logger.ErrorLog("登记注册表报错", zap.String("k", o.key), zap.String("v", sessionUUID), zap.Error(err))
+	}
+}
+
+// unregister removes the registry entry of the released session
+// unregister 删除已释放会话的注册表条目
+func (o *Suo) unregister(ctx context.Context, sessionUUID string) {
+	if o.registry == "" || o.dryRun {
+		return
+	}
+	if err := o.client().Eval(ctx, commandUnregister, []string{o.registry}, []string{o.key, sessionUUID}).Err(); err != nil {
+		o.logger.ErrorLog("注销注册表报错", zap.String("k", o.key), zap.String("v", sessionUUID), zap.Error(err))
+	}
+}
+
+// List gets back held locks found in the registry carrying each of the given tags
+// Pass no tags to list every held lock, entries of locks no longer held get pruned
+// Results come back sorted through lock name
+//
+// List 返回注册表中带有给定全部标签的已持有锁
+// 不传标签时列出全部已持有锁，不再持有的锁条目会被清理
+// 结果按锁名排序返回
+func (m *Manager) List(ctx context.Context, tags map[string]string) ([]*LockInfo, error) {
+	must.OK(m.registryKey) // Registry must be enabled through WithRegistry // 必须通过 WithRegistry 启用注册表
+
+	entries, err := m.redisClient.HGetAll(ctx, m.registryKey).Result()
+	if err != nil {
+		m.logger.ErrorLog("读取注册表报错", zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pipe := m.redisClient.Pipeline()
+	cmds := make([]*redis.Cmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.Eval(ctx, commandInspectMeta, []string{key, companionKey(key, "meta")}))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		m.logger.ErrorLog("批量检查报错", zap.Int("size", len(keys)), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+
+	var infos []*LockInfo
+	var stale []string
+	for idx, cmd := range cmds {
+		info, err := parseLockInfo(keys[idx], cmd, m.codec)
+		if err != nil {
+			m.logger.ErrorLog("检查结果报错", zap.String("k", keys[idx]), zap.Error(err))
+			return nil, erero.Wro(err)
+		}
+		if !info.Held() {
+			stale = append(stale, keys[idx])
+			continue
+		}
+		if info.Metadata.MatchTags(tags) {
+			infos = append(infos, info)
+		}
+	}
+	if len(stale) > 0 {
+		// Stale entries come from crashed holders whose locks expired through TTL
+		// 过期条目来自崩溃的持有者，其锁已通过 TTL 过期
+		if err := m.redisClient.HDel(ctx, m.registryKey, stale...).Err(); err != nil {
+			m.logger.ErrorLog("清理注册表报错", zap.Int("size", len(stale)), zap.Error(err))
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,72 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_List validates listing held locks filtered through tags
+// Tests that released locks disappear and tag filters select matching locks
+//
+// TestManager_List 验证按标签过滤列出已持有的锁
+// 测试已释放的锁会消失且标签过滤器选出匹配的锁
+func TestManager_List(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient).WithRegistry(utils.NewUUID())
+
+	payment := manager.NewSuo(utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"team": "payment", "job": "settle"})
+	xin1, err := payment.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+
+	search := manager.NewSuo(utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"team": "search"})
+	xin2, err := search.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin2)
+
+	plain := manager.NewSuo(utils.NewUUID(), 5*time.Second)
+	xin3, err := plain.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin3)
+
+	xin1, err = payment.AcquireAgainExtendLock(ctx, xin1)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+
+	infos, err := manager.List(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, infos, 3)
+
+	infos, err = manager.List(ctx, map[string]string{"team": "payment"})
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, payment.Key(), infos[0].Key)
+	require.Equal(t, xin1.SessionUUID(), infos[0].Holder)
+	require.Equal(t, "settle", infos[0].Metadata.Tags["job"])
+
+	success, err := payment.Release(ctx, xin1)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	infos, err = manager.List(ctx, map[string]string{"team": "payment"})
+	require.NoError(t, err)
+	require.Empty(t, infos)
+
+	for _, pair := range []struct {
+		suo *redissuo.Suo
+		xin *redissuo.Xin
+	}{{search, xin2}, {plain, xin3}} {
+		success, err := pair.suo.Release(ctx, pair.xin)
+		require.NoError(t, err)
+		require.True(t, success)
+	}
+
+	infos, err = manager.List(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, infos)
+}
@@ -0,0 +1,58 @@
+package redissuo
+
+import (
+	"context"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// ReleaseAt keeps the session held through a watchdog until the given time and then releases it
+// Meant in workflows holding exclusivity across a cool-down window past the work, it blocks until the release
+// An ending context cuts the window short, the lock is still released and the context problem comes back
+// A lost lock during the window ends it with ErrLockLost, nothing is released then
+//
+// ReleaseAt 通过看门狗保持会话直到给定时间，然后释放
+// 适用于工作完成后仍需在冷却窗口内保持独占的流程，调用会阻塞直到释放
+// 上下文结束会提前结束窗口，锁仍会被释放并返回上下文错误
+// 窗口期间锁丢失时以 ErrLockLost 结束，此时不会释放任何内容
+func (o *Suo) ReleaseAt(ctx context.Context, xin *Xin, at time.Time) (bool, error) {
+	o.checkOwner(xin)
+	must.Equals(xin.key, o.key)
+	wait := at.Sub(o.clock.Now())
+	if wait <= 0 {
+		return o.Release(ctx, xin)
+	}
+	o.logger.DebugLog("延迟释放-保持到期", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Duration("wait", wait))
+
+	keepAlive := o.KeepAlive(context.WithoutCancel(ctx), xin, 0)
+	timer := o.clock.NewTimer(wait)
+	defer timer.Stop()
+	var cause error
+	select {
+	case <-timer.C():
+	case <-keepAlive.Done():
+	case <-ctx.Done():
+		cause = ctx.Err()
+	}
+	latest, err := keepAlive.Stop()
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	success, err := o.Release(context.WithoutCancel(ctx), latest)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	if cause != nil {
+		return success, erero.Wro(cause)
+	}
+	return success, nil
+}
+
+// ReleaseAfter keeps the session held through a watchdog through the cool-down duration and then releases it
+// ReleaseAfter 通过看门狗在冷却时长内保持会话，然后释放
+func (o *Suo) ReleaseAfter(ctx context.Context, xin *Xin, coolDown time.Duration) (bool, error) {
+	return o.ReleaseAt(ctx, xin, o.clock.Now().Add(coolDown))
+}
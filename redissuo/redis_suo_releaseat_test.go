@@ -0,0 +1,61 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ReleaseAfter validates the lock stays held across the cool-down window and goes away after it
+// TestSuo_ReleaseAfter 验证锁在冷却窗口内保持持有，窗口结束后释放
+func TestSuo_ReleaseAfter(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 150*time.Millisecond)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	// Another session tries inside the window
+	probe := make(chan *redissuo.Xin, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		other, _ := redissuo.NewSuo(caseRedisClient, suo.Key(), time.Second).Acquire(ctx)
+		probe <- other
+	}()
+
+	start := time.Now()
+	success, err := suo.ReleaseAfter(ctx, xin, 300*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	require.Nil(t, <-probe)
+
+	exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
+
+// TestSuo_ReleaseAt_Cancelled validates an ending context cuts the window short and still releases
+// TestSuo_ReleaseAt_Cancelled 验证上下文结束会提前结束窗口并仍然释放锁
+func TestSuo_ReleaseAt_Cancelled(t *testing.T) {
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	success, err := suo.ReleaseAt(ctx, xin, time.Now().Add(time.Minute))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, success)
+
+	exists, err := caseRedisClient.Exists(context.Background(), suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
@@ -0,0 +1,306 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: writer lock, readers, writer intent / ARGV: session, TTL milliseconds, renew flag
+	// Drops expired readers, then grants or renews the read lease of the session while no writer holds the lock
+	// A renewal keeps just a lease still present, so a lapsed reader never comes back past a writer
+	// New readers stay out while a writer intent is set, renewals go on so the present readers drain
+	// Scores are Redis TIME milliseconds, a skewed client clock neither evicts live readers nor outlives its lease
+	// KEYS: 写锁、读者集合、写者意向 / ARGV: 会话、TTL 毫秒数、续期标记
+	// 先清理过期读者，再在没有写者持锁时授予或续期该会话的读租期
+	// 续期只保留仍然存在的租期，使已失效的读者不会越过写者重新进入
+	// 设置了写者意向时新的读者无法进入，续期照常进行，使现有读者逐渐退出
+	// 分数是 Redis TIME 毫秒数，客户端时钟偏差既不会驱逐存活的读者，也不会使租期超出应有时长
+	commandAcquireRead = `redis.replicate_commands()
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", ms)
+if redis.call("EXISTS", KEYS[1]) == 1 then
+    return 0
+end
+if ARGV[3] ~= "1" and redis.call("EXISTS", KEYS[3]) == 1 then
+    return 0
+end
+if ARGV[3] == "1" and not redis.call("ZSCORE", KEYS[2], ARGV[1]) then
+    return 0
+end
+redis.call("ZADD", KEYS[2], ms + tonumber(ARGV[2]), ARGV[1])
+if redis.call("PTTL", KEYS[2]) < tonumber(ARGV[2]) then
+    redis.call("PEXPIRE", KEYS[2], ARGV[2])
+end
+return 1`
+
+	// KEYS: writer lock, readers, writer intent / ARGV: session, TTL milliseconds, intent flag
+	// Renews the lease of the holding writer, or else takes the lock just when it is free and no reader is left
+	// A fresh writer refused over readers sets the intent for one TTL, the writer getting in clears it
+	// Expired readers are told apart on Redis TIME, the same clock granting their leases
+	// KEYS: 写锁、读者集合、写者意向 / ARGV: 会话、TTL 毫秒数、意向标记
+	// 续期持锁写者的租期，否则仅当锁空闲且没有剩余读者时获取锁
+	// 因读者而被拒绝的新写者设置为期一个 TTL 的意向，进入的写者将其清除
+	// 过期读者依据 Redis TIME 判断，与授予其租期的时钟相同
+	commandAcquireWrite = `redis.replicate_commands()
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return 1
+end
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", ms)
+if redis.call("ZCARD", KEYS[2]) > 0 then
+    if ARGV[3] == "1" then
+        redis.call("SET", KEYS[3], ARGV[1], "PX", ARGV[2])
+    end
+    return 0
+end
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+    redis.call("DEL", KEYS[3])
+    return 1
+end
+return 0`
+)
+
+// RWSuo is a distributed read-write lock, many readers share it while a writer holds it alone
+// Readers are tracked per session in a companion sorted set, each with its own lease
+// The writer lock is the plain lock key, so Inspect and the manager see writers like any Suo holder
+// A writer refused over readers leaves an intent marker that keeps new readers out, so a steady stream of readers cannot starve writers
+// The marker lapses one TTL past the last refused attempt, so a writer giving up blocks readers no longer than that
+//
+// RWSuo 是分布式读写锁，多个读者可以共享，写者独占
+// 读者按会话记录在伴随有序集合中，各自拥有独立的租期
+// 写锁就是普通的锁键，因此 Inspect 和管理器看到的写者与普通 Suo 持有者相同
+// 因读者而被拒绝的写者留下意向标记，阻止新的读者进入，使持续不断的读者无法使写者饥饿
+// 该标记在最后一次被拒绝的尝试之后一个 TTL 失效，因此放弃的写者阻塞读者不会超过该时长
+type RWSuo struct {
+	suo *Suo // Lock of the writer, also owning the readers companion // 写者的锁，同时拥有读者伴随键
+}
+
+// NewRWSuo creates a read-write lock of the key, readers and writers both lease the TTL
+// NewRWSuo 创建该键的读写锁，读者和写者的租期都为该 TTL
+func NewRWSuo(rds redis.UniversalClient, key string, ttl time.Duration) *RWSuo {
+	return &RWSuo{suo: NewSuo(rds, key, ttl)}
+}
+
+// NewRWSuo creates a read-write lock bound to the manager, sharing the options of Manager.NewSuo
+// NewRWSuo 创建绑定到管理器的读写锁，共享 Manager.NewSuo 的选项
+func (m *Manager) NewRWSuo(key string, ttl time.Duration) *RWSuo {
+	return &RWSuo{suo: m.NewSuo(key, ttl)}
+}
+
+// WithLogger sets custom logger used in read-write lock operations
+// WithLogger 设置读写锁操作使用的自定义日志记录器
+func (o *RWSuo) WithLogger(logger logging.Logger) *RWSuo {
+	o.suo.WithLogger(logger)
+	return o
+}
+
+// WithClock sets the clock used in computing expiry and hold durations
+// WithClock 设置计算过期时间和持有时长使用的时钟
+func (o *RWSuo) WithClock(clock Clock) *RWSuo {
+	o.suo.WithClock(clock)
+	return o
+}
+
+// Key gets back the lock name
+// Key 返回锁名
+func (o *RWSuo) Key() string {
+	return o.suo.key
+}
+
+// readersKey gets back the companion sorted set holding the read leases of the read-write lock
+// readersKey 返回保存读写锁读租期的伴随有序集合
+func (o *Suo) readersKey() string {
+	return companionKey(o.key, "readers")
+}
+
+// writerIntentKey gets back the companion key a waiting writer sets to keep new readers out
+// writerIntentKey 返回等待中的写者设置的伴随键，用以阻止新的读者进入
+func (o *Suo) writerIntentKey() string {
+	return companionKey(o.key, "writer_intent")
+}
+
+// AcquireRead takes a read lease next to other readers, nil while a writer holds the lock or waits on it
+// AcquireRead 与其它读者一同获取读租期，写者持锁或等待期间返回 nil
+func (o *RWSuo) AcquireRead(ctx context.Context) (*Xin, error) {
+	if err := o.suo.admitFresh(ctx); err != nil {
+		return nil, erero.Wro(err)
+	}
+	xin, err := o.acquireRead(ctx, utils.NewUUID(), false)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return attachCloser(xin, o.suo.language, o.ReleaseRead), nil
+}
+
+// ExtendRead renews the read lease of the session, nil once the lease lapsed
+// ExtendRead 续期该会话的读租期，租期已失效时返回 nil
+func (o *RWSuo) ExtendRead(ctx context.Context, xin *Xin) (*Xin, error) {
+	must.Equals(xin.key, o.suo.key)
+	res, err := o.acquireRead(ctx, xin.sessionUUID, true)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	xin.closer.follow(res)
+	return res, nil
+}
+
+// acquireRead grants or renews the read lease of the session
+// acquireRead 授予或续期该会话的读租期
+func (o *RWSuo) acquireRead(ctx context.Context, sessionUUID string, renew bool) (*Xin, error) {
+	var suo = o.suo
+	var startTime = suo.clock.Now()
+	renewFlag := "0"
+	if renew {
+		renewFlag = "1"
+	}
+	args := []interface{}{
+		sessionUUID,
+		strconv.FormatInt(suo.ttl.Milliseconds(), 10),
+		renewFlag,
+	}
+	granted, err := o.grant(ctx, "acquire_read", commandAcquireRead, args)
+	if err != nil {
+		suo.logger.ErrorLog("申请读锁报错", zap.String("k", suo.key), zap.String("v", sessionUUID), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	if !granted {
+		suo.logger.DebugLog("写锁已占用-申请不到读锁", zap.String("k", suo.key), zap.String("v", sessionUUID))
+		return nil, nil
+	}
+	expireTime, optimisticExpire := suo.expiryOf(startTime, suo.clock.Now(), suo.ttl)
+	return &Xin{key: suo.key, sessionUUID: sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, acquiredAt: startTime}, nil
+}
+
+// grant runs a lease script on the lock key, the readers and the writer intent companions, true when the lease was granted
+// grant 在锁键、读者和写者意向伴随键上执行租期脚本，授予租期时返回 true
+func (o *RWSuo) grant(ctx context.Context, operation string, command string, args []interface{}) (bool, error) {
+	if o.suo.simulated(operation) {
+		return true, nil
+	}
+	result, err := o.suo.eval(ctx, command, []string{o.suo.key, o.suo.readersKey(), o.suo.writerIntentKey()}, args...)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	granted, _ := result.(int64)
+	return granted == 1, nil
+}
+
+// ReleaseRead gives the read lease back, false when it lapsed already
+// ReleaseRead 归还读租期，租期已失效时返回 false
+func (o *RWSuo) ReleaseRead(ctx context.Context, xin *Xin) (bool, error) {
+	must.Equals(xin.key, o.suo.key)
+	if o.suo.simulated("release_read") {
+		xin.closer.markDone()
+		return true, nil
+	}
+	count, err := o.suo.client().ZRem(ctx, o.suo.readersKey(), xin.sessionUUID).Result()
+	if err != nil {
+		o.suo.logger.ErrorLog("释放读锁报错", zap.String("k", o.suo.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	xin.closer.markDone()
+	return count == 1, nil
+}
+
+// AcquireWrite takes the lock alone, nil while another writer or any reader holds it
+// An attempt refused over readers sets the writer intent, new readers stay out while present readers finish, so retries get in
+//
+// AcquireWrite 独占获取锁，其它写者或任一读者持锁期间返回 nil
+// 因读者而被拒绝的尝试会设置写者意向，新的读者无法进入而现有读者继续完成，使重试能够进入
+func (o *RWSuo) AcquireWrite(ctx context.Context) (*Xin, error) {
+	if err := o.suo.admitFresh(ctx); err != nil {
+		return nil, erero.Wro(err)
+	}
+	xin, err := o.acquireWrite(ctx, utils.NewUUID(), true)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return attachCloser(xin, o.suo.language, o.ReleaseWrite), nil
+}
+
+// ExtendWrite renews the write lease of the session
+// A lapsed lease is taken again just when no reader got in meanwhile, nil otherwise
+//
+// ExtendWrite 续期该会话的写租期
+// 租期已失效时仅当期间没有读者进入才重新获取，否则返回 nil
+func (o *RWSuo) ExtendWrite(ctx context.Context, xin *Xin) (*Xin, error) {
+	must.Equals(xin.key, o.suo.key)
+	res, err := o.acquireWrite(ctx, xin.sessionUUID, false)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if res != nil {
+		res.acquiredAt = xin.acquiredAt
+		res.extensions = xin.extensions + 1
+		xin.closer.follow(res)
+	}
+	return res, nil
+}
+
+// acquireWrite takes or renews the write lease of the session, setting the writer intent when refused and intent is true
+// acquireWrite 获取或续期该会话的写租期，被拒绝且 intent 为 true 时设置写者意向
+func (o *RWSuo) acquireWrite(ctx context.Context, sessionUUID string, intent bool) (*Xin, error) {
+	var suo = o.suo
+	var startTime = suo.clock.Now()
+	intentFlag := "0"
+	if intent {
+		intentFlag = "1"
+	}
+	args := []interface{}{
+		sessionUUID,
+		strconv.FormatInt(suo.ttl.Milliseconds(), 10),
+		intentFlag,
+	}
+	granted, err := o.grant(ctx, "acquire_write", commandAcquireWrite, args)
+	if err != nil {
+		suo.logger.ErrorLog("申请写锁报错", zap.String("k", suo.key), zap.String("v", sessionUUID), zap.Error(err))
+		return nil, erero.Wro(err)
+	}
+	if !granted {
+		suo.logger.DebugLog("锁已被占用-申请不到写锁", zap.String("k", suo.key), zap.String("v", sessionUUID))
+		return nil, nil
+	}
+	expireTime, optimisticExpire := suo.expiryOf(startTime, suo.clock.Now(), suo.ttl)
+	return &Xin{key: suo.key, sessionUUID: sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, acquiredAt: startTime}, nil
+}
+
+// ReleaseWrite gives the write lock back, false when another session holds it
+// ReleaseWrite 归还写锁，被其它会话持有时返回 false
+func (o *RWSuo) ReleaseWrite(ctx context.Context, xin *Xin) (bool, error) {
+	must.Equals(xin.key, o.suo.key)
+	success, err := o.suo.release(ctx, xin.sessionUUID, false)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	xin.closer.markDone()
+	return success, nil
+}
+
+// Readers gets back the count of read leases held at present, as judged on the Redis clock
+// Readers 返回当前被持有的读租期数量，依据 Redis 时钟判断
+func (o *RWSuo) Readers(ctx context.Context) (int64, error) {
+	if o.suo.simulated("readers") {
+		return 0, nil
+	}
+	lower, err := o.suo.serverLowerBound(ctx)
+	if err != nil {
+		return 0, erero.Wro(err)
+	}
+	count, err := o.suo.client().ZCount(ctx, o.suo.readersKey(), lower, "+inf").Result()
+	if err != nil {
+		return 0, erero.Wro(err)
+	}
+	return count, nil
+}
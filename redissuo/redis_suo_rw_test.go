@@ -0,0 +1,181 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestRWSuo validates readers share the lock while a writer waits, and a writer keeps readers out
+// TestRWSuo 验证读者共享锁而写者等待，写者持锁时读者无法进入
+func TestRWSuo(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewRWSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	reader1, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader1)
+	reader2, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader2)
+
+	count, err := suo.Readers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	writer, err := suo.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.Nil(t, writer)
+
+	reader1, err = suo.ExtendRead(ctx, reader1)
+	require.NoError(t, err)
+	require.NotNil(t, reader1)
+
+	for _, reader := range []*redissuo.Xin{reader1, reader2} {
+		success, err := suo.ReleaseRead(ctx, reader)
+		require.NoError(t, err)
+		require.True(t, success)
+	}
+
+	writer, err = suo.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, writer)
+
+	reader, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.Nil(t, reader)
+
+	other, err := suo.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	writer, err = suo.ExtendWrite(ctx, writer)
+	require.NoError(t, err)
+	require.NotNil(t, writer)
+	require.Equal(t, 1, writer.Extensions())
+
+	success, err := suo.ReleaseWrite(ctx, writer)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	reader, err = suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	success, err = suo.ReleaseRead(ctx, reader)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestRWSuo_ExtendRead_Lapsed validates a lapsed reader is not brought back through the renewal
+// TestRWSuo_ExtendRead_Lapsed 验证已失效的读者不会通过续期重新进入
+func TestRWSuo_ExtendRead_Lapsed(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewRWSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	reader, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+
+	success, err := suo.ReleaseRead(ctx, reader)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	reader, err = suo.ExtendRead(ctx, reader)
+	require.NoError(t, err)
+	require.Nil(t, reader)
+}
+
+// TestRWSuo_ClockSkew validates a writer whose clock runs ahead does not evict live readers
+// TestRWSuo_ClockSkew 验证时钟超前的写者不会驱逐存活的读者
+func TestRWSuo_ClockSkew(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewRWSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	reader, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+
+	ahead := redissuo.NewRWSuo(caseRedisClient, suo.Key(), 5*time.Second).WithClock(&steppingClock{now: time.Now().Add(time.Hour)})
+	writer, err := ahead.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.Nil(t, writer)
+
+	count, err := ahead.Readers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+// TestRWSuo_WriterIntent validates a refused writer keeps new readers out while present readers renew and drain
+// TestRWSuo_WriterIntent 验证被拒绝的写者阻止新的读者进入，而现有读者可以续期并逐渐退出
+func TestRWSuo_WriterIntent(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewRWSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	reader, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+
+	writer, err := suo.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.Nil(t, writer)
+
+	late, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.Nil(t, late)
+
+	reader, err = suo.ExtendRead(ctx, reader)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	success, err := suo.ReleaseRead(ctx, reader)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	writer, err = suo.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, writer)
+	success, err = suo.ReleaseWrite(ctx, writer)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	// The writer getting in cleared the intent, so readers enter again
+	reader, err = suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	success, err = suo.ReleaseRead(ctx, reader)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestRWSuo_WriterIntent_Lapsed validates the intent of a writer giving up lapses after the TTL
+// TestRWSuo_WriterIntent_Lapsed 验证放弃的写者的意向在 TTL 之后失效
+func TestRWSuo_WriterIntent_Lapsed(t *testing.T) {
+	miniRedis := rese.P1(miniredis.Run())
+	t.Cleanup(miniRedis.Close)
+	redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+	t.Cleanup(func() { _ = redisClient.Close() })
+
+	ctx := context.Background()
+	suo := redissuo.NewRWSuo(redisClient, utils.NewUUID(), 5*time.Second)
+
+	reader, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+	writer, err := suo.AcquireWrite(ctx)
+	require.NoError(t, err)
+	require.Nil(t, writer)
+	late, err := suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.Nil(t, late)
+
+	miniRedis.FastForward(5 * time.Second)
+	reader, err = suo.AcquireRead(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, reader)
+}
@@ -0,0 +1,75 @@
+package redissuo
+
+import (
+	"encoding/json"
+
+	"github.com/yyle88/erero"
+)
+
+// EventSchemaVersion is the revision of the event JSON form emitted through this library
+// Fields only get added within one revision, a rename or a removal bumps it
+//
+// EventSchemaVersion 是本库发出的事件 JSON 形式的修订版本
+// 同一修订版本内只会新增字段，重命名或删除字段时递增版本
+const EventSchemaVersion = 1
+
+// EventSchema is the JSON Schema of one serialized Event, sinks post arrays of them
+// Durations are integer nanoseconds and times are RFC 3339, matching encoding/json of the Go types
+//
+// EventSchema 是单个序列化 Event 的 JSON Schema，接收端以数组形式发送
+// 时长为整数纳秒，时间为 RFC 3339 格式，与 Go 类型经 encoding/json 序列化的结果一致
+const EventSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "https://github.com/go-xlan/redis-go-suo/event/v1.json",
+  "title": "redis-go-suo lock event",
+  "type": "object",
+  "required": ["version", "kind", "key", "session", "time"],
+  "properties": {
+    "version": {"type": "integer", "const": 1},
+    "kind": {"type": "string", "enum": ["acquired", "extended", "released", "expiring_soon", "extend_throttled", "stolen", "slow_operation", "force_released"]},
+    "key": {"type": "string"},
+    "session": {"type": "string"},
+    "time": {"type": "string", "format": "date-time"},
+    "held_for": {"type": "integer", "minimum": 0},
+    "operation": {"type": "string"},
+    "latency": {"type": "integer", "minimum": 0},
+    "status": {"type": "string"},
+    "operator": {"type": "string"},
+    "forensic": {
+      "type": "object",
+      "required": ["operation", "session", "usurper", "usurper_pttl", "held_for"],
+      "properties": {
+        "operation": {"type": "string"},
+        "session": {"type": "string"},
+        "session_token": {"type": "integer"},
+        "usurper": {"type": "string"},
+        "usurper_token": {"type": "integer"},
+        "usurper_metadata": {"type": "object"},
+        "usurper_pttl": {"type": "integer"},
+        "held_for": {"type": "integer"},
+        "status": {"type": "string"}
+      }
+    }
+  }
+}`
+
+// DecodeEvents parses a batch of events as sinks post them, a JSON array of Event
+// Events ahead of versioning carry no version and read as version 1, newer revisions are rejected
+//
+// DecodeEvents 解析接收端发送的一批事件，即 Event 的 JSON 数组
+// 引入版本之前的事件不带版本，按版本 1 读取，更新的修订版本会被拒绝
+func DecodeEvents(data []byte) ([]*Event, error) {
+	var events []*Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, event := range events {
+		if event.Version == 0 {
+			event.Version = 1
+		}
+		if event.Version > EventSchemaVersion {
+			return nil, erero.Errorf("event schema version %d past supported %d", event.Version, EventSchemaVersion)
+		}
+	}
+	return events, nil
+}
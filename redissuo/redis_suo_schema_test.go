@@ -0,0 +1,78 @@
+package redissuo_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEventSchema validates emitted events carry the schema version and their JSON keys stay within the schema
+// TestEventSchema 验证发出的事件携带模式版本且其 JSON 键不超出模式范围
+func TestEventSchema(t *testing.T) {
+	var schema struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(redissuo.EventSchema), &schema))
+
+	var mutex sync.Mutex
+	var data []byte
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, events []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		var err error
+		data, err = json.Marshal(events)
+		return err
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16)
+
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithEvents(dispatcher)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	var records []map[string]any
+	require.NoError(t, json.Unmarshal(data, &records))
+	require.Len(t, records, 2)
+	for _, record := range records {
+		for _, name := range schema.Required {
+			require.Contains(t, record, name)
+		}
+		for name := range record {
+			require.Contains(t, schema.Properties, name)
+		}
+	}
+
+	events, err := redissuo.DecodeEvents(data)
+	require.NoError(t, err)
+	require.Equal(t, redissuo.EventSchemaVersion, events[0].Version)
+	require.Equal(t, redissuo.EventAcquired, events[0].Kind)
+	require.Equal(t, redissuo.EventReleased, events[1].Kind)
+}
+
+// TestDecodeEvents validates events without a version read as version 1 and newer revisions fail
+// TestDecodeEvents 验证不带版本的事件按版本 1 读取，更新的修订版本解析失败
+func TestDecodeEvents(t *testing.T) {
+	events, err := redissuo.DecodeEvents([]byte(`[{"kind":"acquired","key":"k","session":"s","time":"2024-01-01T00:00:00Z"}]`))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, 1, events[0].Version)
+
+	_, err = redissuo.DecodeEvents([]byte(`[{"version":99,"kind":"acquired","key":"k","session":"s","time":"2024-01-01T00:00:00Z"}]`))
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// LockScope collects acquired locks and releases them together
+// Locks are released in reverse sequence of addition, best-effort, with problems aggregated
+// Simplifies functions that take several locks depending on conditions
+//
+// LockScope 收集已获取的锁并统一释放
+// 锁按加入顺序的逆序释放，尽力而为并聚合错误
+// 简化根据条件获取多个锁的函数
+type LockScope struct {
+	mutex   sync.Mutex   // Protects entries // 保护条目
+	entries []scopeEntry // Held locks in sequence of addition // 按加入顺序排列的已持有锁
+}
+
+// scopeEntry pairs a lock with its acquired session
+// scopeEntry 将锁与其已获取的会话配对
+type scopeEntry struct {
+	suo *Suo
+	xin *Xin
+}
+
+// NewLockScope creates a blank scope
+// NewLockScope 创建空的作用域
+func NewLockScope() *LockScope {
+	return &LockScope{}
+}
+
+// Add puts an acquired session into the scope so Close releases it
+// Add 将已获取的会话放入作用域，由 Close 负责释放
+func (s *LockScope) Add(suo *Suo, xin *Xin) {
+	must.Nice(suo)
+	must.Nice(xin)
+	must.Equals(xin.key, suo.key)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, scopeEntry{suo: suo, xin: xin})
+}
+
+// Acquire attempts acquiring the lock and adds the session to the scope on success
+// Gives back nil when the lock is unavailable, same as Suo.Acquire
+//
+// Acquire 尝试获取锁，成功时将会话加入作用域
+// 锁不可用时返回 nil，与 Suo.Acquire 一致
+func (s *LockScope) Acquire(ctx context.Context, suo *Suo) (*Xin, error) {
+	xin, err := suo.Acquire(ctx)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if xin != nil {
+		s.Add(suo, xin)
+	}
+	return xin, nil
+}
+
+// Size gets back the count of sessions held in the scope
+// Size 返回作用域中持有的会话数量
+func (s *LockScope) Size() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.entries)
+}
+
+// Close releases each session in reverse sequence and empties the scope
+// Continues past failures and gives back the joined problems, nil when all got released
+// Calling Close again is a no-op since the scope is empty
+//
+// Close 按逆序释放每个会话并清空作用域
+// 遇到失败继续执行并返回合并后的错误，全部释放成功时返回 nil
+// 由于作用域已清空，再次调用 Close 不会执行任何操作
+func (s *LockScope) Close(ctx context.Context) error {
+	s.mutex.Lock()
+	entries := s.entries
+	s.entries = nil
+	s.mutex.Unlock()
+
+	var errs []error
+	for idx := len(entries) - 1; idx >= 0; idx-- {
+		entry := entries[idx]
+		success, err := entry.suo.Release(ctx, entry.xin)
+		if err != nil {
+			errs = append(errs, erero.WithMessagef(err, "release %s", entry.xin.key))
+		} else if !success {
+			errs = append(errs, erero.Errorf("release %s: lock owned through a different session", entry.xin.key))
+		}
+	}
+	return erero.Joins(errs)
+}
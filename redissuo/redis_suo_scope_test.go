@@ -0,0 +1,50 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockScope validates releasing collected locks together
+// Tests that Close releases each lock and reports the ones lost meanwhile
+//
+// TestLockScope 验证统一释放收集的锁
+// 测试 Close 释放每个锁并报告期间丢失的锁
+func TestLockScope(t *testing.T) {
+	ctx := context.Background()
+	scope := redissuo.NewLockScope()
+
+	suo1 := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin1, err := scope.Acquire(ctx, suo1)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+
+	suo2 := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin2, err := scope.Acquire(ctx, suo2)
+	require.NoError(t, err)
+	require.NotNil(t, xin2)
+	require.Equal(t, 2, scope.Size())
+
+	// Another session takes over suo2 so its release must be reported
+	require.NoError(t, caseRedisClient.Set(ctx, suo2.Key(), utils.NewUUID(), time.Minute).Err())
+
+	err = scope.Close(ctx)
+	require.Error(t, err)
+	t.Log(err)
+	require.Equal(t, 0, scope.Size())
+
+	xin, err := suo1.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin) // suo1 got released
+
+	require.NoError(t, scope.Close(ctx)) // Blank scope closes without problems
+
+	success, err := suo1.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,92 @@
+package redissuo
+
+// Names of the scripts exposed through Scripts
+// 通过 Scripts 公开的脚本名称
+const (
+	ScriptAcquire                = "acquire"                  // Classic acquisition // 经典获取
+	ScriptAcquireServerTime      = "acquire_server_time"      // Acquisition replying Redis TIME // 返回 Redis TIME 的获取
+	ScriptAcquireModern          = "acquire_modern"           // SET NX GET acquisition on Redis >= 7.0 // Redis >= 7.0 上的 SET NX GET 获取
+	ScriptAcquireStrict          = "acquire_strict"           // Classic acquisition in strict mode // 严格模式下的经典获取
+	ScriptAcquireGuarded         = "acquire_guarded"          // Classic acquisition behind guards // 带守卫的经典获取
+	ScriptRelease                = "release"                  // Release with ownership check // 带所有权检查的释放
+	ScriptInspect                = "inspect"                  // Holder, TTL and metadata lookup // 查询持有者、TTL 和元数据
+	ScriptUnregister             = "unregister"               // Registry removal // 注册表移除
+	ScriptEnqueue                = "enqueue"                  // Waiter queue join // 加入等待队列
+	ScriptQueueStatus            = "queue_status"             // Waiter queue position // 等待队列位置
+	ScriptRecordHold             = "record_hold"              // Hold duration record // 记录持有时长
+	ScriptHierarchyAcquireChild  = "hierarchy_acquire_child"  // Child lock acquisition // 子锁获取
+	ScriptHierarchyAcquireParent = "hierarchy_acquire_parent" // Parent lock acquisition // 父锁获取
+	ScriptHierarchyReleaseChild  = "hierarchy_release_child"  // Child lock release // 子锁释放
+	ScriptHierarchyReleaseParent = "hierarchy_release_parent" // Parent lock release // 父锁释放
+	ScriptFencedSet              = "fenced_set"               // Write guarded through a fencing token // 通过防护令牌保护的写入
+	ScriptExtendCheckpoint       = "extend_checkpoint"        // Extension storing a progress cursor // 保存进度游标的延期
+	ScriptCompleteRun            = "complete_run"             // Execution record write // 写入执行记录
+	ScriptAcquirePermit          = "acquire_permit"           // Semaphore permit grant // 授予信号量许可
+	ScriptCleanupCompanions      = "cleanup_companions"       // Companion key removal // 删除伴随键
+	ScriptShortenTTL             = "shorten_ttl"              // Lease shortening with ownership check // 带所有权检查的租期缩短
+	ScriptSetTemp                = "set_temp"                 // Lock-scoped temp key write // 写入锁作用域临时键
+	ScriptSyncTemps              = "sync_temps"               // Temp key TTL alignment with the lock // 将临时键的 TTL 与锁对齐
+	ScriptExtendRemaining        = "extend_remaining"         // Extension requiring a minimum remaining lease // 要求最小剩余租期的延期
+	ScriptAcquireRead            = "acquire_read"             // Read lease of the read-write lock // 读写锁的读租期
+	ScriptAcquireWrite           = "acquire_write"            // Write lease of the read-write lock // 读写锁的写租期
+	ScriptAcquireReentrant       = "acquire_reentrant"        // Counted hold of the reentrant lock // 可重入锁的计数持有
+	ScriptReleaseReentrant       = "release_reentrant"        // Counted release of the reentrant lock // 可重入锁的计数释放
+	ScriptExtendReentrant        = "extend_reentrant"         // Lease refresh of the reentrant lock // 可重入锁的租期刷新
+	ScriptEnqueueFair            = "enqueue_fair"             // Waiter queue entry with heartbeat // 带心跳的等待队列条目
+	ScriptQueueHead              = "queue_head"               // Fair queue head check with heartbeat // 带心跳的公平队列队首检查
+	ScriptAcquireFenced          = "acquire_fenced"           // Classic acquisition issuing a fencing token // 签发防护令牌的经典获取
+	ScriptForceRelease           = "force_release"            // Release regardless of the holder // 不论持有者的释放
+	ScriptAcquireMulti           = "acquire_multi"            // All-or-nothing acquisition of several locks // 多个锁的全有或全无获取
+	ScriptForceReleaseGetDel     = "force_release_getdel"     // GETDEL force release on Redis >= 6.2 // Redis >= 6.2 上的 GETDEL 强制释放
+	ScriptUpdateMeta             = "update_meta"              // Metadata rewrite with ownership check // 带所有权检查的元数据改写
+	ScriptUpdateMetaKeepTTL      = "update_meta_keepttl"      // SET KEEPTTL metadata rewrite on Redis >= 6.0 // Redis >= 6.0 上的 SET KEEPTTL 元数据改写
+	ScriptInspectSession         = "inspect_session"          // Lookup comparing the holder with a session // 将持有者与会话比较的查询
+)
+
+// Scripts gets back the Lua scripts run by the package, keyed by name
+// Composed variants are given in the exact form sent to Redis, letting script changes be verified independently
+//
+// Scripts 返回包中执行的 Lua 脚本，以名称为键
+// 组合变体以发送给 Redis 的确切形式给出，使脚本变更可以被独立验证
+func Scripts() map[string]string {
+	return map[string]string{
+		ScriptAcquire:                commandAcquire,
+		ScriptAcquireServerTime:      commandAcquireServerTime,
+		ScriptAcquireModern:          commandAcquireModern,
+		ScriptAcquireStrict:          commandStrictPrefix + commandAcquire,
+		ScriptAcquireGuarded:         commandGuardPrefix + commandAcquire,
+		ScriptRelease:                commandRelease,
+		ScriptInspect:                commandInspectMeta,
+		ScriptUnregister:             commandUnregister,
+		ScriptEnqueue:                commandEnqueue,
+		ScriptQueueStatus:            commandQueueStatus,
+		ScriptRecordHold:             commandRecordHold,
+		ScriptHierarchyAcquireChild:  commandAcquireChild,
+		ScriptHierarchyAcquireParent: commandAcquireParent,
+		ScriptHierarchyReleaseChild:  commandReleaseChild,
+		ScriptHierarchyReleaseParent: commandReleaseParent,
+		ScriptFencedSet:              commandFencedSet,
+		ScriptExtendCheckpoint:       commandExtendCheckpoint,
+		ScriptCompleteRun:            commandCompleteRun,
+		ScriptAcquirePermit:          commandAcquirePermit,
+		ScriptCleanupCompanions:      commandCleanupCompanions,
+		ScriptShortenTTL:             commandShortenTTL,
+		ScriptSetTemp:                commandSetTemp,
+		ScriptSyncTemps:              commandSyncTemps,
+		ScriptExtendRemaining:        commandExtendRemaining,
+		ScriptAcquireRead:            commandAcquireRead,
+		ScriptAcquireWrite:           commandAcquireWrite,
+		ScriptAcquireReentrant:       commandAcquireReentrant,
+		ScriptReleaseReentrant:       commandReleaseReentrant,
+		ScriptExtendReentrant:        commandExtendReentrant,
+		ScriptEnqueueFair:            commandEnqueueFair,
+		ScriptQueueHead:              commandQueueHead,
+		ScriptAcquireFenced:          commandFencingWrapperHead + commandAcquire + commandFencingWrapperTail,
+		ScriptForceRelease:           commandForceRelease,
+		ScriptAcquireMulti:           commandAcquireMulti,
+		ScriptForceReleaseGetDel:     commandForceReleaseGetDel,
+		ScriptUpdateMeta:             commandUpdateMeta,
+		ScriptUpdateMetaKeepTTL:      commandUpdateMetaKeepTTL,
+		ScriptInspectSession:         commandInspectSession,
+	}
+}
@@ -0,0 +1,124 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: permits / ARGV: session, limit, TTL milliseconds
+	// Drops expired permits, then grants or renews the permit of the session while below the limit
+	// Scores are Redis TIME milliseconds, with client clocks a client running ahead would evict live permits and over-admit
+	// KEYS: 许可集合 / ARGV: 会话、上限、TTL 毫秒数
+	// 先清理过期许可，再在未达上限时授予或续期该会话的许可
+	// 分数是 Redis TIME 毫秒数，若使用客户端时钟，时钟超前的客户端会驱逐存活的许可并超额授予
+	commandAcquirePermit = `redis.replicate_commands()
+local now = redis.call("TIME")
+local ms = tonumber(now[1]) * 1000 + math.floor(tonumber(now[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ms)
+if redis.call("ZSCORE", KEYS[1], ARGV[1]) or redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+    redis.call("ZADD", KEYS[1], ms + tonumber(ARGV[3]), ARGV[1])
+    redis.call("PEXPIRE", KEYS[1], ARGV[3])
+    return 1
+end
+return 0`
+)
+
+// permitsKey gets back the companion sorted set holding the permits of the lock name
+// permitsKey 返回保存该锁名许可的伴随有序集合
+func (o *Suo) permitsKey() string {
+	return companionKey(o.key, "permits")
+}
+
+// AcquirePermit takes one of up to limit permits shared fleet-wide under the lock name
+// Counts concurrent holders instead of excluding them, for work that tolerates N runs at once
+// Permits lapse after the TTL like the lock, the session gets back nil when all permits are taken
+// Passes the same pause, admission and maintenance checks as a fresh acquisition
+//
+// AcquirePermit 获取该锁名下整个集群共享的至多 limit 个许可之一
+// 统计并发持有者而不是互斥，适用于允许同时运行 N 个的工作
+// 许可与锁一样在 TTL 后失效，所有许可都被占用时返回 nil
+// 与新的获取一样经过暂停、准入和维护检查
+func (o *Suo) AcquirePermit(ctx context.Context, limit int) (*Xin, error) {
+	return o.AcquirePermitWithSession(ctx, utils.NewUUID(), limit)
+}
+
+// AcquirePermitWithSession takes a permit like AcquirePermit using the given session
+// A session holding a permit already renews it, so retries after a lost reply reuse the permit instead of taking another
+//
+// AcquirePermitWithSession 与 AcquirePermit 一样使用给定会话获取许可
+// 已持有许可的会话会续期该许可，因此回复丢失后的重试会复用该许可而不是再占一个
+func (o *Suo) AcquirePermitWithSession(ctx context.Context, sessionUUID string, limit int) (*Xin, error) {
+	must.OK(sessionUUID)
+	must.True(limit > 0)
+	if err := o.admitFresh(ctx); err != nil {
+		return nil, erero.Wro(err)
+	}
+	var startTime = o.clock.Now()
+	args := []interface{}{
+		sessionUUID,
+		strconv.Itoa(limit),
+		strconv.FormatInt(o.ttl.Milliseconds(), 10),
+	}
+	granted, err := o.grantPermit(ctx, args)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if !granted {
+		return nil, nil
+	}
+	expireTime, optimisticExpire := o.expiryOf(startTime, o.clock.Now(), o.ttl)
+	return &Xin{key: o.key, sessionUUID: sessionUUID, expire: expireTime, optimisticExpire: optimisticExpire, acquiredAt: startTime}, nil
+}
+
+// grantPermit runs the permit script, true when the session holds a permit afterwards
+// grantPermit 执行许可脚本，之后会话持有许可时返回 true
+func (o *Suo) grantPermit(ctx context.Context, args []interface{}) (bool, error) {
+	if o.simulated("acquire_permit") {
+		return true, nil
+	}
+	result, err := o.eval(ctx, commandAcquirePermit, []string{o.permitsKey()}, args...)
+	if err != nil {
+		o.logger.ErrorLog("申请许可报错", zap.String("k", o.key), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	granted, _ := result.(int64)
+	return granted == 1, nil
+}
+
+// ReleasePermit gives the permit back, false when it lapsed already
+// ReleasePermit 归还许可，许可已失效时返回 false
+func (o *Suo) ReleasePermit(ctx context.Context, xin *Xin) (bool, error) {
+	must.Equals(xin.key, o.key)
+	if o.simulated("release_permit") {
+		return true, nil
+	}
+	count, err := o.client().ZRem(ctx, o.permitsKey(), xin.sessionUUID).Result()
+	if err != nil {
+		o.logger.ErrorLog("归还许可报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	return count == 1, nil
+}
+
+// Permits gets back the count of permits held at present, as judged on the Redis clock
+// Permits 返回当前被持有的许可数量，依据 Redis 时钟判断
+func (o *Suo) Permits(ctx context.Context) (int64, error) {
+	if o.simulated("permits") {
+		return 0, nil
+	}
+	lower, err := o.serverLowerBound(ctx)
+	if err != nil {
+		return 0, erero.Wro(err)
+	}
+	count, err := o.client().ZCount(ctx, o.permitsKey(), lower, "+inf").Result()
+	if err != nil {
+		return 0, erero.Wro(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,65 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_AcquirePermit validates at most limit permits are granted and released permits become free again
+// TestSuo_AcquirePermit 验证至多授予 limit 个许可，归还的许可可再次获取
+func TestSuo_AcquirePermit(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	xin1, err := suo.AcquirePermit(ctx, 2)
+	require.NoError(t, err)
+	require.NotNil(t, xin1)
+	xin2, err := suo.AcquirePermit(ctx, 2)
+	require.NoError(t, err)
+	require.NotNil(t, xin2)
+
+	xin3, err := suo.AcquirePermit(ctx, 2)
+	require.NoError(t, err)
+	require.Nil(t, xin3)
+
+	count, err := suo.Permits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	success, err := suo.ReleasePermit(ctx, xin1)
+	require.NoError(t, err)
+	require.True(t, success)
+	success, err = suo.ReleasePermit(ctx, xin1)
+	require.NoError(t, err)
+	require.False(t, success)
+
+	xin3, err = suo.AcquirePermit(ctx, 2)
+	require.NoError(t, err)
+	require.NotNil(t, xin3)
+}
+
+// TestSuo_AcquirePermit_ClockSkew validates a client clock running ahead neither evicts live permits nor over-admits
+// TestSuo_AcquirePermit_ClockSkew 验证时钟超前的客户端既不会驱逐存活的许可，也不会超额授予
+func TestSuo_AcquirePermit_ClockSkew(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	suo := redissuo.NewSuo(caseRedisClient, key, 5*time.Second)
+	ahead := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithClock(&steppingClock{now: time.Now().Add(time.Hour)})
+
+	xin, err := suo.AcquirePermit(ctx, 1)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	other, err := ahead.AcquirePermit(ctx, 1)
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	count, err := ahead.Permits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
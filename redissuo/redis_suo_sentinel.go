@@ -0,0 +1,70 @@
+package redissuo
+
+import (
+	"context"
+)
+
+var (
+	// ErrNotOwner is returned in typed error mode when another session holds the lock the call works on
+	// ErrNotOwner 在类型化错误模式下，当调用操作的锁被其它会话持有时返回
+	ErrNotOwner = NewError(CodeNotOwner, LanguageEnglish, nil)
+	// ErrLockExpired is returned in typed error mode when the release found the lock gone, the hold outlived its lease
+	// ErrLockExpired 在类型化错误模式下，当释放时发现锁已不存在（持有超过了租期）时返回
+	ErrLockExpired = NewError(CodeLockExpired, LanguageEnglish, nil)
+)
+
+// WithTypedErrors makes the lock report outcomes through sentinel errors instead of a nil session or a false result
+// Acquire and AcquireLockWithSession fail with ErrLockHeld while another session holds the lock,
+// AcquireAgainExtendLock and ExtendFor fail with ErrNotOwner once the lock was lost,
+// Release fails with ErrNotOwner when another session holds the lock and with ErrLockExpired when the lock lapsed first
+// Callers then branch through errors.Is, and a missed nil check can no longer pass as a held lock
+//
+// WithTypedErrors 使锁通过哨兵错误而非空会话或 false 结果报告结果
+// 其它会话持有锁时，Acquire 和 AcquireLockWithSession 以 ErrLockHeld 失败，
+// 锁已丢失时，AcquireAgainExtendLock 和 ExtendFor 以 ErrNotOwner 失败，
+// 锁被其它会话持有时 Release 以 ErrNotOwner 失败，锁先行失效时以 ErrLockExpired 失败
+// 调用方据此通过 errors.Is 分支处理，遗漏的空值检查不会再被当作已持有锁
+func (o *Suo) WithTypedErrors(enable bool) *Suo {
+	o.typedErrors = enable
+	return o
+}
+
+// typedAcquire turns a missed acquisition into ErrLockHeld in typed error mode
+// typedAcquire 在类型化错误模式下将未获取到的结果转换为 ErrLockHeld
+func (o *Suo) typedAcquire(xin *Xin, err error) (*Xin, error) {
+	if o.typedErrors && err == nil && xin == nil {
+		return nil, o.newError(CodeLockHeld)
+	}
+	return xin, err
+}
+
+// typedExtend turns a lost extension into ErrNotOwner in typed error mode
+// typedExtend 在类型化错误模式下将丢失锁的延期转换为 ErrNotOwner
+func (o *Suo) typedExtend(res *Xin, err error) (*Xin, error) {
+	if o.typedErrors && err == nil && res == nil {
+		return nil, o.newError(CodeNotOwner)
+	}
+	return res, err
+}
+
+// typedRelease turns the not-owner and missing release statuses into ErrNotOwner and ErrLockExpired in typed error mode
+// typedRelease 在类型化错误模式下将非持有者和锁不存在的释放状态转换为 ErrNotOwner 和 ErrLockExpired
+func (o *Suo) typedRelease(status ReleaseStatus, success bool, err error) (bool, error) {
+	if !o.typedErrors || err != nil {
+		return success, err
+	}
+	switch status {
+	case ReleaseNotOwner:
+		return false, o.newError(CodeNotOwner)
+	case ReleaseMissing:
+		return false, o.newError(CodeLockExpired)
+	}
+	return success, nil
+}
+
+// releaseSession releases the session without typed errors, used where the plain outcome is needed
+// releaseSession 以不带类型化错误的方式释放会话，用于需要原始结果的场合
+func (o *Suo) releaseSession(ctx context.Context, xin *Xin) (bool, error) {
+	_, success, err := o.releaseHold(ctx, xin)
+	return success, err
+}
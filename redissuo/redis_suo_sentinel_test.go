@@ -0,0 +1,47 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithTypedErrors validates missed outcomes come back as sentinel errors
+// TestSuo_WithTypedErrors 验证未成功的结果以哨兵错误返回
+func TestSuo_WithTypedErrors(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTypedErrors(true)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	other, err := suo.Acquire(ctx)
+	require.ErrorIs(t, err, redissuo.ErrLockHeld)
+	require.Nil(t, other)
+
+	require.NoError(t, caseRedisClient.Set(ctx, suo.Key(), "usurper", time.Minute).Err())
+	res, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.ErrorIs(t, err, redissuo.ErrNotOwner)
+	require.Nil(t, res)
+	res, err = suo.ExtendFor(ctx, xin, time.Second)
+	require.ErrorIs(t, err, redissuo.ErrNotOwner)
+	require.Nil(t, res)
+
+	success, err := suo.Release(ctx, xin)
+	require.ErrorIs(t, err, redissuo.ErrNotOwner)
+	require.False(t, success)
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+
+	xin, err = suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+	success, err = suo.Release(ctx, xin)
+	require.ErrorIs(t, err, redissuo.ErrLockExpired)
+	require.False(t, success)
+}
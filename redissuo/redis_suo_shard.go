@@ -0,0 +1,20 @@
+package redissuo
+
+import (
+	"strconv"
+
+	"github.com/yyle88/must"
+)
+
+// Shard derives the lock guarding one shard of the job, named "<key>:shard:<index>"
+// The derived lock shares every setting of this lock, just the name differs
+//
+// Shard 派生保护作业某个分片的锁，名称为 "<key>:shard:<index>"
+// 派生锁共享该锁的全部设置，仅名称不同
+func (o *Suo) Shard(index int) *Suo {
+	must.True(index >= 0)
+	shard := *o
+	shard.key = o.key + ":shard:" + strconv.Itoa(index)
+	shard.setLogger(o.logger)
+	return &shard
+}
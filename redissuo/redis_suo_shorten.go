@@ -0,0 +1,64 @@
+package redissuo
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: lock, optional metadata companion / ARGV: session, ttl milliseconds
+	// Lowers the remaining TTL just when the session holds the lock, never raises it
+	// Gives back 1 when the lease is now at most the ttl, 0 when the session does not hold the lock
+	// KEYS: 锁、可选的元数据伴随键 / ARGV: 会话、TTL 毫秒数
+	// 仅当会话持有锁时降低剩余 TTL，从不提高
+	// 租期已不超过该 TTL 时返回 1，会话未持有锁时返回 0
+	commandShortenTTL = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+local ttl = tonumber(ARGV[2])
+local left = redis.call("PTTL", KEYS[1])
+if left < 0 or left > ttl then
+    redis.call("PEXPIRE", KEYS[1], ttl)
+    if KEYS[2] then
+        redis.call("PEXPIRE", KEYS[2], ttl)
+    end
+end
+return 1`
+)
+
+// ShortenTTL shrinks the remaining lease of the held session to at most the given duration
+// Meant in a holder about to crash or abort, so others take over without waiting out the full TTL
+// The session stays valid until the shortened lease lapses, while xin.Expire keeps the former estimate
+// Gives back false when the session no longer holds the lock
+//
+// ShortenTTL 将所持会话的剩余租期缩短到不超过给定时长
+// 适用于即将崩溃或中止的持有者，使其它方无需等待完整 TTL 即可接管
+// 会话在缩短后的租期到期前依然有效，而 xin.Expire 仍保留之前的估计值
+// 会话已不再持有锁时返回 false
+func (o *Suo) ShortenTTL(ctx context.Context, xin *Xin, ttl time.Duration) (bool, error) {
+	o.checkOwner(xin)
+	must.Equals(xin.key, o.key)
+	must.True(ttl > 0)
+	if o.dryRun {
+		return true, nil
+	}
+	keys := []string{o.key}
+	if o.hasMetadata() || xin.continues != nil {
+		keys = append(keys, o.metaKey())
+	}
+	result, err := o.eval(ctx, commandShortenTTL, keys, xin.sessionUUID, strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		o.logger.ErrorLog("缩短租期报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	status, err := ParseExtendStatus(result)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	return status == ExtendDone, nil
+}
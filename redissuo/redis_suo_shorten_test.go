@@ -0,0 +1,51 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_ShortenTTL validates the holder lowers its lease, never raises it, and others cannot shorten it
+// TestSuo_ShortenTTL 验证持有者能降低其租期但不会提高，且其它会话无法缩短
+func TestSuo_ShortenTTL(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTags(map[string]string{"job": "abort"})
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	ok, err := suo.ShortenTTL(ctx, xin, 100*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ok)
+	pttl, err := caseRedisClient.PTTL(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.LessOrEqual(t, pttl, 100*time.Millisecond)
+	pttl, err = caseRedisClient.PTTL(ctx, "{"+suo.Key()+"}:meta").Result()
+	require.NoError(t, err)
+	require.LessOrEqual(t, pttl, 100*time.Millisecond)
+
+	ok, err = suo.ShortenTTL(ctx, xin, time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	pttl, err = caseRedisClient.PTTL(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.LessOrEqual(t, pttl, 100*time.Millisecond)
+
+	foreign := redissuo.NewXin(suo.Key(), utils.NewUUID(), time.Now().Add(time.Second), time.Now())
+	ok, err = suo.ShortenTTL(ctx, foreign, time.Millisecond)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	ok, err = suo.ShortenTTL(ctx, xin, 100*time.Millisecond)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,27 @@
+package redissuo
+
+import (
+	"runtime"
+)
+
+// WithStackCapture enables storing the acquiring goroutine's stack in the lock metadata
+// The stack gets truncated to limit bytes, pass 0 to disable, intended in debug mode
+// Inspection then shows which code path holds a stuck lock
+//
+// WithStackCapture 启用在锁元数据中存储获取锁的 goroutine 堆栈
+// 堆栈被截断为 limit 字节，传 0 禁用，适用于调试模式
+// 检查时即可看到是哪条代码路径持有卡住的锁
+func (o *Suo) WithStackCapture(limit int) *Suo {
+	o.stackLimit = limit
+	return o
+}
+
+// captureStack gets back the current goroutine's stack truncated to the configured limit
+// captureStack 返回截断到配置上限的当前 goroutine 堆栈
+func (o *Suo) captureStack() string {
+	if o.stackLimit <= 0 {
+		return ""
+	}
+	buf := make([]byte, o.stackLimit)
+	return string(buf[:runtime.Stack(buf, false)])
+}
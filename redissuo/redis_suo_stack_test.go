@@ -0,0 +1,38 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithStackCapture validates the holder stack stored in metadata
+// Tests that inspection shows the acquiring test function within the size limit
+//
+// TestSuo_WithStackCapture 验证存储在元数据中的持有者堆栈
+// 测试检查结果在大小上限内显示获取锁的测试函数
+func TestSuo_WithStackCapture(t *testing.T) {
+	ctx := context.Background()
+	manager := redissuo.NewManager(caseRedisClient)
+	suo := manager.NewSuo(utils.NewUUID(), 5*time.Second).WithStackCapture(4096)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	infos, err := manager.InspectMany(ctx, suo.Key())
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.NotNil(t, infos[0].Metadata)
+	require.Contains(t, infos[0].Metadata.Stack, "TestSuo_WithStackCapture")
+	require.LessOrEqual(t, len(infos[0].Metadata.Stack), 4096)
+	t.Log(infos[0].Metadata.Stack)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
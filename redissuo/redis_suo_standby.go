@@ -0,0 +1,126 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// StandbyState is the phase of a warm standby
+// StandbyState 是热备的阶段
+type StandbyState string
+
+const (
+	StandbyIdle    StandbyState = "idle"    // Not started // 尚未启动
+	StandbyWaiting StandbyState = "waiting" // Queued, polling the lock // 已排队，正在轮询锁
+	StandbyHolding StandbyState = "holding" // Won the lock // 已赢得锁
+	StandbyStopped StandbyState = "stopped" // Gave up through the context // 因上下文结束而放弃
+)
+
+// StandbyStatus is a snapshot of a warm standby
+// StandbyStatus 是热备的快照
+type StandbyStatus struct {
+	State         StandbyState // Phase of the standby // 热备的阶段
+	Position      int64        // Waiters ahead at the last heartbeat // 最近一次心跳时前方的等待者数量
+	Heartbeats    int          // Heartbeats sent // 已发送的心跳次数
+	LastHeartbeat time.Time    // Time of the last heartbeat, zero before the first // 最近一次心跳的时间，首次之前为零值
+	Xin           *Xin         // Session won, nil unless holding // 赢得的会话，未持有时为 nil
+	Err           error        // Problem of the last heartbeat or poll, nil when fine // 最近一次心跳或轮询的错误，正常时为 nil
+}
+
+// Standby keeps a registered waiter warm, so on holder failure it wins the lock within one poll interval
+// Each poll is a heartbeat: it refreshes the queue entry and then attempts the lock with the waiter's session
+// Meant in leader-like usage where a hot spare should take over as soon as the lock frees up
+//
+// Standby 保持一个已登记的等待者处于热备状态，使持有者失效时在一个轮询间隔内赢得锁
+// 每次轮询即一次心跳：刷新队列条目，然后使用等待者的会话尝试获取锁
+// 适用于类似选主的场景，热备应在锁空闲后尽快接管
+type Standby struct {
+	suo      *Suo          // Lock being watched // 被监视的锁
+	interval time.Duration // Poll and heartbeat interval // 轮询和心跳间隔
+	mutex    sync.Mutex    // Protects status // 保护 status
+	status   StandbyStatus // Latest snapshot // 最新快照
+}
+
+// NewStandby creates a warm standby polling at the interval, requires WithWaitQueue
+// NewStandby 创建按该间隔轮询的热备，需要启用 WithWaitQueue
+func (o *Suo) NewStandby(interval time.Duration) *Standby {
+	must.True(o.waitQueue) // Requires WithWaitQueue // 需要启用 WithWaitQueue
+	return &Standby{suo: o, interval: must.Nice(interval), status: StandbyStatus{State: StandbyIdle}}
+}
+
+// Run registers the waiter and polls until the lock is won or the context ends
+// The waiter leaves the queue when the context ends, heartbeat problems are retried on the next poll
+//
+// Run 登记等待者并轮询，直到赢得锁或上下文结束
+// 上下文结束时等待者离开队列，心跳错误在下一次轮询时重试
+func (s *Standby) Run(ctx context.Context) (*Xin, error) {
+	o := s.suo
+	waiter := &Waiter{suo: o, sessionUUID: utils.NewUUID()}
+	s.update(func(status *StandbyStatus) { status.State = StandbyWaiting })
+	for {
+		if err := ctx.Err(); err != nil {
+			// The context is over, leave through a fresh context so the entry does not linger
+			// 上下文已结束，使用新的上下文离开队列，避免条目残留
+			_ = waiter.Leave(context.WithoutCancel(ctx))
+			s.update(func(status *StandbyStatus) { status.State = StandbyStopped })
+			return nil, erero.Wro(err)
+		}
+		xin, err := s.poll(ctx, waiter)
+		if err != nil {
+			o.logger.DebugLog("热备轮询报错", zap.String("k", o.key), zap.Error(err))
+		}
+		if xin != nil {
+			s.update(func(status *StandbyStatus) {
+				status.State = StandbyHolding
+				status.Xin = xin
+			})
+			return xin, nil
+		}
+		o.clock.Sleep(s.interval)
+	}
+}
+
+// poll sends one heartbeat and attempts the lock
+// poll 发送一次心跳并尝试获取锁
+func (s *Standby) poll(ctx context.Context, waiter *Waiter) (*Xin, error) {
+	position, err := s.suo.enqueue(ctx, waiter.sessionUUID)
+	if err != nil {
+		s.update(func(status *StandbyStatus) { status.Err = err })
+		return nil, erero.Wro(err)
+	}
+	now := s.suo.clock.Now()
+	s.update(func(status *StandbyStatus) {
+		status.Position = position
+		status.Heartbeats++
+		status.LastHeartbeat = now
+		status.Err = nil
+	})
+	xin, err := waiter.Acquire(ctx)
+	if err != nil {
+		s.update(func(status *StandbyStatus) { status.Err = err })
+		return nil, erero.Wro(err)
+	}
+	return xin, nil
+}
+
+// update changes the status under the mutex
+// update 在互斥锁保护下修改状态
+func (s *Standby) update(change func(status *StandbyStatus)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	change(&s.status)
+}
+
+// StandbyStatus gets back a snapshot of the standby, safe to call from other goroutines, e.g. health probes
+// StandbyStatus 返回热备的快照，可在其它 goroutine 中安全调用，例如健康探针
+func (s *Standby) StandbyStatus() StandbyStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status
+}
@@ -0,0 +1,86 @@
+package redissuo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStandby_Run validates the standby heartbeats while the lock is held and takes over once it frees up
+// TestStandby_Run 验证热备在锁被持有期间发送心跳，并在锁空闲后接管
+func TestStandby_Run(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithWaitQueue(true)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	standby := suo.NewStandby(10 * time.Millisecond)
+	require.Equal(t, redissuo.StandbyIdle, standby.StandbyStatus().State)
+
+	type outcome struct {
+		xin *redissuo.Xin
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		won, err := standby.Run(ctx)
+		done <- outcome{xin: won, err: err}
+	}()
+
+	require.Eventually(t, func() bool {
+		return standby.StandbyStatus().Heartbeats >= 3
+	}, time.Second, 5*time.Millisecond)
+	status := standby.StandbyStatus()
+	require.Equal(t, redissuo.StandbyWaiting, status.State)
+	require.Zero(t, status.Position)
+	require.Nil(t, status.Xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	res := <-done
+	require.NoError(t, res.err)
+	require.NotNil(t, res.xin)
+	status = standby.StandbyStatus()
+	require.Equal(t, redissuo.StandbyHolding, status.State)
+	require.Equal(t, res.xin, status.Xin)
+
+	success, err = suo.Release(ctx, res.xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestStandby_Run_Stopped validates the standby leaves the queue once the context ends
+// TestStandby_Run_Stopped 验证上下文结束后热备离开队列
+func TestStandby_Run_Stopped(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithWaitQueue(true)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	standby := suo.NewStandby(10 * time.Millisecond)
+	_, err = standby.Run(timeoutCtx)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, redissuo.StandbyStopped, standby.StandbyStatus().State)
+
+	waiter, err := suo.Enqueue(ctx)
+	require.NoError(t, err)
+	status, err := waiter.Status(ctx)
+	require.NoError(t, err)
+	require.Zero(t, status.Position)
+	require.NoError(t, waiter.Leave(ctx))
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,118 @@
+package redissuo
+
+import (
+	"strconv"
+
+	"github.com/yyle88/erero"
+)
+
+// ReleaseStatus is the reply code of the release script
+// ReleaseStatus 是释放脚本的回复码
+type ReleaseStatus int64
+
+const (
+	ReleaseVanished ReleaseStatus = 0 // Found through GET while DEL removed nothing, a rare edge case // GET 时存在但 DEL 未删除任何键，罕见的边缘情况
+	ReleaseDeleted  ReleaseStatus = 1 // Lock deleted // 锁已删除
+	ReleaseMissing  ReleaseStatus = 2 // Lock already gone, e.g. expired past a long hold // 锁已不存在，例如持有过久已过期
+	ReleaseNotOwner ReleaseStatus = 3 // Lock held through another session // 锁被其它会话持有
+)
+
+// releaseStatusNames maps the release status codes to their names
+// releaseStatusNames 将释放状态码映射为名称
+var releaseStatusNames = map[ReleaseStatus]string{
+	ReleaseVanished: "vanished",
+	ReleaseDeleted:  "deleted",
+	ReleaseMissing:  "missing",
+	ReleaseNotOwner: "not_owner",
+}
+
+// ParseReleaseStatus converts the reply of the release script into its status, failing on an unknown code
+// ParseReleaseStatus 将释放脚本的回复转换为状态，遇到未知回复码时失败
+func ParseReleaseStatus(reply interface{}) (ReleaseStatus, error) {
+	code, ok := reply.(int64)
+	if !ok {
+		return 0, erero.Errorf("release status: unexpected reply %v", reply)
+	}
+	if _, ok := releaseStatusNames[ReleaseStatus(code)]; !ok {
+		return 0, erero.Errorf("release status: unknown code %d", code)
+	}
+	return ReleaseStatus(code), nil
+}
+
+// Released reports whether the session no longer holds the lock past the release
+// Released 判断释放之后该会话是否已不再持有锁
+func (s ReleaseStatus) Released() bool {
+	return s != ReleaseNotOwner
+}
+
+// String gets back the name of the status, e.g. "not_owner"
+// String 返回状态名称，例如 "not_owner"
+func (s ReleaseStatus) String() string {
+	if name, ok := releaseStatusNames[s]; ok {
+		return name
+	}
+	return "release_status(" + strconv.FormatInt(int64(s), 10) + ")"
+}
+
+// ExtendStatus is the reply code of the scripts extending or touching a held session
+// Shared by the checkpoint, shorten, temp key and remaining-lease scripts
+//
+// ExtendStatus 是延期或操作所持会话的脚本的回复码
+// 由检查点、缩短租期、临时键和剩余租期脚本共用
+type ExtendStatus int64
+
+const (
+	ExtendTooLate  ExtendStatus = -1 // Too little of the lease remained // 剩余租期不足
+	ExtendNotOwner ExtendStatus = 0  // Session does not hold the lock // 会话未持有锁
+	ExtendDone     ExtendStatus = 1  // Lease extended or touched // 租期已延长或已操作
+)
+
+// extendStatusNames maps the extend status codes to their names
+// extendStatusNames 将延期状态码映射为名称
+var extendStatusNames = map[ExtendStatus]string{
+	ExtendTooLate:  "too_late",
+	ExtendNotOwner: "not_owner",
+	ExtendDone:     "done",
+}
+
+// ParseExtendStatus converts the reply of an extend script into its status, failing on an unknown code
+// ParseExtendStatus 将延期脚本的回复转换为状态，遇到未知回复码时失败
+func ParseExtendStatus(reply interface{}) (ExtendStatus, error) {
+	code, ok := reply.(int64)
+	if !ok {
+		return 0, erero.Errorf("extend status: unexpected reply %v", reply)
+	}
+	if _, ok := extendStatusNames[ExtendStatus(code)]; !ok {
+		return 0, erero.Errorf("extend status: unknown code %d", code)
+	}
+	return ExtendStatus(code), nil
+}
+
+// String gets back the name of the status, e.g. "too_late"
+// String 返回状态名称，例如 "too_late"
+func (s ExtendStatus) String() string {
+	if name, ok := extendStatusNames[s]; ok {
+		return name
+	}
+	return "extend_status(" + strconv.FormatInt(int64(s), 10) + ")"
+}
+
+// StatusError carries the script status behind a coded error, reach it through errors.As
+// StatusError 携带带错误码错误背后的脚本状态，可通过 errors.As 获取
+type StatusError struct {
+	Script string // Script name, one of the Script* names // 脚本名称，即某个 Script* 名称
+	Status string // Status name, e.g. "too_late" // 状态名称，例如 "too_late"
+	Code   int64  // Raw status code // 原始状态码
+}
+
+// Error renders the script and the status, e.g. "extend_remaining: too_late (-1)"
+// Error 输出脚本和状态，例如 "extend_remaining: too_late (-1)"
+func (e *StatusError) Error() string {
+	return e.Script + ": " + e.Status + " (" + strconv.FormatInt(e.Code, 10) + ")"
+}
+
+// extendStatusError wraps the extend status of the script as the cause of a coded error
+// extendStatusError 将脚本的延期状态包装为带错误码错误的原因
+func extendStatusError(script string, status ExtendStatus) *StatusError {
+	return &StatusError{Script: script, Status: status.String(), Code: int64(status)}
+}
@@ -0,0 +1,100 @@
+package redissuo_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseReleaseStatus validates the release codes parse into named statuses and unknown codes fail
+// TestParseReleaseStatus 验证释放回复码解析为具名状态，未知回复码解析失败
+func TestParseReleaseStatus(t *testing.T) {
+	status, err := redissuo.ParseReleaseStatus(int64(2))
+	require.NoError(t, err)
+	require.Equal(t, redissuo.ReleaseMissing, status)
+	require.Equal(t, "missing", status.String())
+	require.True(t, status.Released())
+	require.False(t, redissuo.ReleaseNotOwner.Released())
+
+	_, err = redissuo.ParseReleaseStatus(int64(9))
+	require.Error(t, err)
+	_, err = redissuo.ParseReleaseStatus("1")
+	require.Error(t, err)
+}
+
+// TestParseExtendStatus validates the extend codes parse into named statuses and unknown codes fail
+// TestParseExtendStatus 验证延期回复码解析为具名状态，未知回复码解析失败
+func TestParseExtendStatus(t *testing.T) {
+	status, err := redissuo.ParseExtendStatus(int64(-1))
+	require.NoError(t, err)
+	require.Equal(t, redissuo.ExtendTooLate, status)
+	require.Equal(t, "too_late", status.String())
+
+	_, err = redissuo.ParseExtendStatus(int64(5))
+	require.Error(t, err)
+}
+
+// TestSuo_ExtendIfRemaining_Status validates the refused extension carries the script status
+// TestSuo_ExtendIfRemaining_Status 验证被拒绝的延期携带脚本状态
+func TestSuo_ExtendIfRemaining_Status(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.PExpire(ctx, suo.Key(), 100*time.Millisecond).Err())
+
+	_, err = suo.ExtendIfRemaining(ctx, xin, 500*time.Millisecond)
+	require.ErrorIs(t, err, redissuo.ErrLockLost)
+	var statusErr *redissuo.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	require.Equal(t, redissuo.ScriptExtendRemaining, statusErr.Script)
+	require.Equal(t, "too_late", statusErr.Status)
+	require.Equal(t, int64(redissuo.ExtendTooLate), statusErr.Code)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_Release_EventStatus validates the released event names the release status
+// TestSuo_Release_EventStatus 验证释放事件给出释放状态名称
+func TestSuo_Release_EventStatus(t *testing.T) {
+	var mutex sync.Mutex
+	var events []*redissuo.Event
+	sink := redissuo.EventSinkFunc(func(ctx context.Context, batch []*redissuo.Event) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, batch...)
+		return nil
+	})
+	dispatcher := redissuo.NewEventDispatcher(sink, 16)
+
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithEvents(dispatcher)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, events, 2)
+	require.Equal(t, redissuo.EventReleased, events[1].Kind)
+	require.Equal(t, redissuo.ReleaseMissing.String(), events[1].Status)
+}
@@ -0,0 +1,45 @@
+package redissuo
+
+import (
+	"context"
+)
+
+// ErrAlreadyHeld is returned in strict mode when the session acquires a lock it already holds
+// ErrAlreadyHeld 在严格模式下会话获取其已持有的锁时返回
+var ErrAlreadyHeld = NewError(CodeAlreadyHeld, LanguageEnglish, nil)
+
+const (
+	// Runs ahead of plain acquisitions in strict mode, explicit extensions skip it
+	// 严格模式下在普通获取前执行，显式延期会跳过
+	commandStrictPrefix = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return "HELD"
+end
+`
+
+	// alreadyHeldMessage is the script reply marking a same-session acquisition
+	// alreadyHeldMessage 是表示同会话获取的脚本回复
+	alreadyHeldMessage = "HELD"
+)
+
+// WithStrict enables strict mode where acquiring a lock already held through the same session
+// fails with ErrAlreadyHeld instead of silently resetting the TTL
+// AcquireAgainExtendLock stays the way to extend, catching accidental double acquisitions
+//
+// WithStrict 启用严格模式，同一会话获取已持有的锁时
+// 以 ErrAlreadyHeld 失败而非静默重置 TTL
+// AcquireAgainExtendLock 仍是延期的方式，用于发现意外的重复获取
+func (o *Suo) WithStrict(enable bool) *Suo {
+	o.strict = enable
+	return o
+}
+
+// ReclaimLockWithSession acquires like AcquireLockWithSession, a lock held through the session already counting as acquired
+// Meant in retrying an attempt whose reply got lost after the server granted it, strict mode would refuse that retry
+// Pass just a session private to the caller, any holder sharing it would be taken over
+//
+// ReclaimLockWithSession 与 AcquireLockWithSession 一样获取锁，锁已被该会话持有时视为获取成功
+// 适用于重试在服务端授予后回复丢失的尝试，严格模式会拒绝这样的重试
+// 仅传入调用方私有的会话，共享该会话的持有者会被接管
+func (o *Suo) ReclaimLockWithSession(ctx context.Context, sessionUUID string) (*Xin, error) {
+	return o.typedAcquire(o.acquireLockWith(ctx, sessionUUID, &acquireRequest{ttl: o.freshTTL(), reclaim: true}))
+}
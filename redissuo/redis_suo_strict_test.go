@@ -0,0 +1,37 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_WithStrict validates same-session acquisition detection
+// Tests that plain re-acquisition fails while explicit extension works
+//
+// TestSuo_WithStrict 验证同会话获取检测
+// 测试普通的重复获取失败而显式延期正常
+func TestSuo_WithStrict(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithStrict(true)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	non, err := suo.AcquireLockWithSession(ctx, xin.SessionUUID())
+	require.ErrorIs(t, err, redissuo.ErrAlreadyHeld)
+	require.Nil(t, non)
+
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
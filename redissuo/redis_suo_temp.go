@@ -0,0 +1,139 @@
+package redissuo
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+const (
+	// KEYS: lock, temp index, temp key / ARGV: session, value
+	// Writes the temp key and lists it in the index just when the session holds the lock, both expiring with the lock
+	// KEYS: 锁、临时键索引、临时键 / ARGV: 会话、值
+	// 仅当会话持有锁时写入临时键并将其登记到索引中，两者与锁一同过期
+	commandSetTemp = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+    return 0
+end
+redis.call("SET", KEYS[3], ARGV[2], "PX", ttl)
+redis.call("SADD", KEYS[2], KEYS[3])
+redis.call("PEXPIRE", KEYS[2], ttl)
+return 1`
+
+	// KEYS: lock, temp index, temp keys / ARGV: session
+	// Aligns the TTL of the index and the temp keys with the lock just when the session holds it
+	// KEYS: 锁、临时键索引、临时键 / ARGV: 会话
+	// 仅当会话持有锁时，将索引和临时键的 TTL 与锁对齐
+	commandSyncTemps = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+    return 0
+end
+for i = 2, #KEYS do
+    redis.call("PEXPIRE", KEYS[i], ttl)
+end
+return 1`
+)
+
+// tempIndexKey gets back the companion key listing the temp keys of the session
+// tempIndexKey 返回登记该会话临时键的伴随键
+func (o *Suo) tempIndexKey(sessionUUID string) string {
+	return companionKey(o.key, "tmp:"+sessionUUID)
+}
+
+// TempKey gets back the name of a lock-scoped temp key of the session, sharing the lock's hash slot
+// TempKey 返回该会话的锁作用域临时键名称，与锁共享哈希槽
+func (o *Suo) TempKey(xin *Xin, name string) string {
+	must.Equals(xin.key, o.key)
+	return companionKey(o.key, "tmp:"+xin.sessionUUID+":"+must.Nice(name))
+}
+
+// SetTemp writes a lock-scoped temp value (scratch state, progress) expiring together with the lock
+// Extensions of the session carry the temp keys along and the release deletes them, so a finished or dead job leaks nothing
+// Call it on the latest session, gives back false when the session no longer holds the lock
+//
+// SetTemp 写入与锁一同过期的锁作用域临时值（暂存状态、进度）
+// 会话的延期会同步延长临时键，释放时删除它们，使结束或崩溃的任务不会遗留任何键
+// 请在最新的会话上调用，会话已不再持有锁时返回 false
+func (o *Suo) SetTemp(ctx context.Context, xin *Xin, name string, value string) (bool, error) {
+	o.checkOwner(xin)
+	keys := []string{o.key, o.tempIndexKey(xin.sessionUUID), o.TempKey(xin, name)}
+	result, err := o.eval(ctx, commandSetTemp, keys, xin.sessionUUID, value)
+	if err != nil {
+		o.logger.ErrorLog("写入临时键报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.String("name", name), zap.Error(err))
+		return false, erero.Wro(err)
+	}
+	status, err := ParseExtendStatus(result)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	if status == ExtendDone {
+		xin.temps = true
+		// Close works on a copy of the session, it has to see the temp keys too
+		// Close 使用会话的副本，它同样需要知道临时键的存在
+		xin.closer.follow(xin)
+	}
+	return status == ExtendDone, nil
+}
+
+// GetTemp reads a lock-scoped temp value of the session, blank when missing
+// GetTemp 读取该会话的锁作用域临时值，不存在时为空
+func (o *Suo) GetTemp(ctx context.Context, xin *Xin, name string) (string, error) {
+	value, err := o.client().Get(ctx, o.TempKey(xin, name)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	} else if err != nil {
+		return "", erero.Wro(err)
+	}
+	return value, nil
+}
+
+// tempKeys gets back the index and the temp keys of the session
+// tempKeys 返回该会话的索引和临时键
+func (o *Suo) tempKeys(ctx context.Context, sessionUUID string) ([]string, error) {
+	index := o.tempIndexKey(sessionUUID)
+	members, err := o.client().SMembers(ctx, index).Result()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return append([]string{index}, members...), nil
+}
+
+// syncTemps carries the temp keys of the session over to the extended lease, best-effort
+// syncTemps 将会话的临时键同步到延期后的租期，尽力而为
+func (o *Suo) syncTemps(ctx context.Context, xin *Xin) {
+	if !xin.temps || o.dryRun {
+		return
+	}
+	keys, err := o.tempKeys(ctx, xin.sessionUUID)
+	if err == nil {
+		_, err = o.eval(ctx, commandSyncTemps, append([]string{o.key}, keys...), xin.sessionUUID)
+	}
+	if err != nil {
+		o.logger.ErrorLog("同步临时键报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+	}
+}
+
+// dropTemps deletes the temp keys of the session on release, best-effort, leftovers expire with the former lease
+// dropTemps 在释放时删除会话的临时键，尽力而为，残留的键随原租期过期
+func (o *Suo) dropTemps(ctx context.Context, xin *Xin) {
+	if !xin.temps || o.dryRun {
+		return
+	}
+	keys, err := o.tempKeys(ctx, xin.sessionUUID)
+	if err == nil {
+		err = o.client().Del(ctx, keys...).Err()
+	}
+	if err != nil {
+		o.logger.ErrorLog("删除临时键报错", zap.String("k", o.key), zap.String("v", xin.sessionUUID), zap.Error(err))
+	}
+}
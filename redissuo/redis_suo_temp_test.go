@@ -0,0 +1,75 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_SetTemp validates temp keys follow the lock extensions and vanish on release
+// TestSuo_SetTemp 验证临时键跟随锁的延期，并在释放时删除
+func TestSuo_SetTemp(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	written, err := suo.SetTemp(ctx, xin, "progress", "42")
+	require.NoError(t, err)
+	require.True(t, written)
+
+	value, err := suo.GetTemp(ctx, xin, "progress")
+	require.NoError(t, err)
+	require.Equal(t, "42", value)
+
+	ttl, err := caseRedisClient.PTTL(ctx, suo.TempKey(xin, "progress")).Result()
+	require.NoError(t, err)
+	require.Greater(t, ttl, time.Duration(0))
+	require.LessOrEqual(t, ttl, time.Second)
+
+	xin, err = suo.ExtendFor(ctx, xin, 3*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	ttl, err = caseRedisClient.PTTL(ctx, suo.TempKey(xin, "progress")).Result()
+	require.NoError(t, err)
+	require.Greater(t, ttl, 3*time.Second)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	exists, err := caseRedisClient.Exists(ctx, suo.TempKey(xin, "progress")).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+
+	value, err = suo.GetTemp(ctx, xin, "progress")
+	require.NoError(t, err)
+	require.Empty(t, value)
+}
+
+// TestSuo_SetTemp_NotHolding validates no temp key is written once the session lost the lock
+// TestSuo_SetTemp_NotHolding 验证会话失去锁后不会写入临时键
+func TestSuo_SetTemp_NotHolding(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.NoError(t, caseRedisClient.Del(ctx, suo.Key()).Err())
+
+	written, err := suo.SetTemp(ctx, xin, "progress", "42")
+	require.NoError(t, err)
+	require.False(t, written)
+
+	exists, err := caseRedisClient.Exists(ctx, suo.TempKey(xin, "progress")).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
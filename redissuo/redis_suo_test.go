@@ -177,3 +177,53 @@ func TestSuo_AcquireAgainExtendLock(t *testing.T) {
 	require.Nil(t, err)
 	require.True(t, success)
 }
+
+// TestSuo_WithServerTime validates expiry anchored on the Redis server clock
+// Tests that the session records a server side expiry near TIME plus the TTL
+//
+// TestSuo_WithServerTime 验证锚定在 Redis 服务端时钟上的过期时间
+// 测试会话记录的服务端过期时间接近 TIME 加上 TTL
+func TestSuo_WithServerTime(t *testing.T) {
+	ctx := context.Background()
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithServerTime(true)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.False(t, xin.ServerExpire().IsZero())
+
+	t.Log(xin.ServerExpire(), xin.Expire())
+
+	require.WithinDuration(t, time.Now().Add(5*time.Second), xin.ServerExpire(), time.Second)
+
+	t.Run("SameLock", func(t *testing.T) {
+		non, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		require.Nil(t, non)
+	})
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestSuo_WithServerTime_Offset validates Expire follows the server expiry through the measured offset, free of the drift margin
+// TestSuo_WithServerTime_Offset 验证 Expire 通过测得的偏移跟随服务端过期时间，不扣除漂移余量
+func TestSuo_WithServerTime_Offset(t *testing.T) {
+	ctx := context.Background()
+	clock := &steppingClock{now: time.Now().Add(time.Hour), step: time.Millisecond}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithServerTime(true).WithDriftFactor(0.1).WithClock(clock)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	require.InDelta(t, float64(-time.Hour), float64(xin.ClockOffset()), float64(time.Second))
+	require.WithinDuration(t, xin.ServerExpire().Add(-xin.ClockOffset()), xin.Expire(), 10*time.Millisecond)
+	require.Equal(t, 5*time.Second, xin.Expire().Sub(xin.AcquiredAt()))
+	require.True(t, xin.OptimisticExpire().After(xin.Expire()))
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,76 @@
+package redissuo
+
+import (
+	"context"
+)
+
+// Names of the spans started around lock operations
+// 锁操作周围开启的 span 名称
+const (
+	SpanAcquire = "redissuo.acquire" // Acquisition attempt // 获取尝试
+	SpanExtend  = "redissuo.extend"  // Extension through the same session // 同一会话的延期
+	SpanRelease = "redissuo.release" // Release of the session // 会话的释放
+	SpanWait    = "redissuo.wait"    // Whole wait of a runner on the lock // 运行器对锁的整个等待
+	SpanRun     = "redissuo.run"     // Protected critical section of a runner // 运行器的受保护临界区
+)
+
+// Tracer starts spans around lock operations, e.g. an adapter over an OpenTelemetry tracer
+// The shape follows OpenTelemetry, so the adapter maps Start, SetAttribute and End onto it in a few lines
+// Lock waits then show up in distributed traces instead of as unexplained latency
+//
+// Tracer 在锁操作周围开启 span，例如基于 OpenTelemetry tracer 的适配器
+// 其形态与 OpenTelemetry 一致，适配器只需几行即可将 Start、SetAttribute 和 End 映射过去
+// 锁等待因此会出现在分布式追踪中，而不是表现为无法解释的延迟
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced operation, ended once with the problem of the operation, nil on success
+// Span 是一次被追踪的操作，以该操作的错误结束一次，成功时为 nil
+type Span interface {
+	SetAttribute(key string, value any)
+	End(err error)
+}
+
+// nopSpan is the span of locks without a tracer
+// nopSpan 是未设置 tracer 的锁所使用的 span
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(key string, value any) {}
+
+func (nopSpan) End(err error) {}
+
+// WithTracer sets the tracer starting spans around acquire, extend and release of this lock
+// WithTracer 设置在该锁的获取、延期和释放周围开启 span 的 tracer
+func (o *Suo) WithTracer(tracer Tracer) *Suo {
+	o.tracer = tracer
+	return o
+}
+
+// WithTracer sets the tracer of locks created through the manager
+// WithTracer 设置通过管理器创建的锁的 tracer
+func (m *Manager) WithTracer(tracer Tracer) *Manager {
+	m.tracer = tracer
+	return m
+}
+
+// Tracer gets back the tracer of the lock, nil when unset
+// Tracer 返回锁的 tracer，未设置时为 nil
+func (o *Suo) Tracer() Tracer {
+	return o.tracer
+}
+
+// StartSpan starts the named span carrying the lock name and the session, a no-op span without a tracer
+// Meant in runners wrapping lock operations, the lock itself starts the spans of acquire, extend and release
+//
+// StartSpan 开启携带锁名和会话的指定 span，未设置 tracer 时为空操作 span
+// 供包装锁操作的运行器使用，锁自身会开启获取、延期和释放的 span
+func (o *Suo) StartSpan(ctx context.Context, name string, sessionUUID string) (context.Context, Span) {
+	if o.tracer == nil {
+		return ctx, nopSpan{}
+	}
+	ctx, span := o.tracer.Start(ctx, name)
+	span.SetAttribute("lock.key", o.key)
+	span.SetAttribute("lock.session", sessionUUID)
+	return ctx, span
+}
@@ -0,0 +1,82 @@
+package redissuo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedSpan is one span kept through recordingTracer
+// recordedSpan 是 recordingTracer 记录的一个 span
+type recordedSpan struct {
+	name       string
+	attributes map[string]any
+	ended      bool
+	err        error
+}
+
+func (s *recordedSpan) SetAttribute(key string, value any) {
+	s.attributes[key] = value
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+// recordingTracer keeps the started spans in sequence
+// recordingTracer 按顺序记录开启的 span
+type recordingTracer struct {
+	mutex sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, redissuo.Span) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	span := &recordedSpan{name: name, attributes: map[string]any{}}
+	r.spans = append(r.spans, span)
+	return ctx, span
+}
+
+// TestSuo_WithTracer validates acquire, extend and release run inside spans noting their outcome
+// TestSuo_WithTracer 验证获取、延期和释放在记录其结果的 span 中执行
+func TestSuo_WithTracer(t *testing.T) {
+	ctx := context.Background()
+	tracer := &recordingTracer{}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTracer(tracer)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	other, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, other)
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	require.Len(t, tracer.spans, 4)
+	expected := []struct{ name, outcome string }{
+		{redissuo.SpanAcquire, "acquired"},
+		{redissuo.SpanAcquire, "busy"},
+		{redissuo.SpanExtend, "acquired"},
+		{redissuo.SpanRelease, "deleted"},
+	}
+	for idx, span := range tracer.spans {
+		require.Equal(t, expected[idx].name, span.name)
+		require.Equal(t, expected[idx].outcome, span.attributes["lock.outcome"])
+		require.Equal(t, suo.Key(), span.attributes["lock.key"])
+		require.True(t, span.ended)
+		require.NoError(t, span.err)
+	}
+	require.Equal(t, xin.SessionUUID(), tracer.spans[3].attributes["lock.session"])
+}
@@ -0,0 +1,198 @@
+package redissuo
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+const (
+	// commandAcquireModern relies on SET NX GET (Redis >= 7.0) to merge the GET and SET round trips
+	// The TTL is refreshed with PEXPIRE just when the same session already holds the lock
+	// commandAcquireModern 依赖 SET NX GET (Redis >= 7.0) 合并 GET 和 SET 两次调用
+	// 仅当同一会话已持有锁时才使用 PEXPIRE 刷新 TTL
+	commandAcquireModern = `local old = redis.call("SET", KEYS[1], ARGV[1], "NX", "GET", "PX", ARGV[2])
+if not old then
+    return "OK"
+elseif old == ARGV[1] then
+    redis.call("PEXPIRE", KEYS[1], ARGV[2])
+    return "OK"
+else
+    return false
+end`
+
+	// KEYS: lock, metadata companion / ARGV: session, metadata
+	// commandUpdateMetaKeepTTL rewrites the metadata through SET XX KEEPTTL (Redis >= 6.0), keeping the TTL it got at acquisition
+	// A missing companion is written with the remaining TTL of the lock instead
+	// commandUpdateMetaKeepTTL 通过 SET XX KEEPTTL (Redis >= 6.0) 改写元数据，保留其获取时得到的 TTL
+	// 伴随键不存在时改为以锁的剩余 TTL 写入
+	commandUpdateMetaKeepTTL = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+if redis.call("SET", KEYS[2], ARGV[2], "XX", "KEEPTTL") then
+    return 1
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+    return 0
+end
+redis.call("SET", KEYS[2], ARGV[2], "PX", ttl)
+return 1`
+
+	// KEYS: lock, metadata companion / ARGV: session, metadata
+	// commandUpdateMeta is the fallback reading the remaining TTL of the lock ahead of the write
+	// commandUpdateMeta 是回退脚本，写入前先读取锁的剩余 TTL
+	commandUpdateMeta = `if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+    return 0
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+    return 0
+end
+redis.call("SET", KEYS[2], ARGV[2], "PX", ttl)
+return 1`
+
+	// KEYS: lock, metadata / gets back the holder it deleted, false when the lock is free
+	// commandForceReleaseGetDel takes the holder and deletes the lock in one GETDEL (Redis >= 6.2)
+	// KEYS: 锁、元数据 / 返回被删除的持有者，锁空闲时返回 false
+	// commandForceReleaseGetDel 通过一次 GETDEL (Redis >= 6.2) 取出持有者并删除锁
+	commandForceReleaseGetDel = `local v = redis.call("GETDEL", KEYS[1])
+if not v then
+    return false
+end
+redis.call("DEL", KEYS[2])
+return v`
+)
+
+const (
+	// versionProbeTimeout bounds the INFO probe, which runs detached from the caller context
+	// versionProbeTimeout 限制 INFO 探测的时长，探测与调用方上下文分离执行
+	versionProbeTimeout = 2 * time.Second
+	// versionRetryInterval spaces probes past a failure, acquisitions use the fallback scripts meanwhile
+	// versionRetryInterval 是探测失败后再次探测的间隔，期间获取使用回退脚本
+	versionRetryInterval = 30 * time.Second
+)
+
+// versionProbe caches the Redis server version detected through INFO server
+// A successful probe is final, a failed probe falls back to the classic scripts and is tried again later
+//
+// versionProbe 缓存通过 INFO server 探测到的 Redis 服务端版本
+// 探测成功后不再重复，探测失败时回退到经典脚本并在稍后重试
+type versionProbe struct {
+	mutex   sync.Mutex // Protects the fields below // 保护以下字段
+	done    bool       // Set once a probe got an answer // 探测得到应答后置位
+	retryAt time.Time  // Earliest time of the next probe past a failure // 失败后下一次探测的最早时间
+	major   int
+	minor   int
+}
+
+// supports reports whether the detected version is at least major.minor
+// 判断探测到的版本是否不低于 major.minor
+func (v *versionProbe) supports(major, minor int) bool {
+	return v.major > major || (v.major == major && v.minor >= minor)
+}
+
+// acquireCommand selects the acquire script matching the options and the server version
+// Server time mode keeps the classic script, modern servers use the SET NX GET variant
+//
+// acquireCommand 根据选项和服务端版本选择获取脚本
+// 服务端时间模式保持经典脚本，新版本服务端使用 SET NX GET 变体
+func (o *Suo) acquireCommand(ctx context.Context) string {
+	if o.serverTime {
+		return commandAcquireServerTime
+	}
+	if o.versionAtLeast(ctx, 7, 0) {
+		return commandAcquireModern
+	}
+	return commandAcquire
+}
+
+// updateMetaCommand selects the metadata update script, SET KEEPTTL on Redis >= 6.0
+// updateMetaCommand 选择元数据更新脚本，Redis >= 6.0 上使用 SET KEEPTTL
+func (o *Suo) updateMetaCommand(ctx context.Context) string {
+	if o.versionAtLeast(ctx, 6, 0) {
+		return commandUpdateMetaKeepTTL
+	}
+	return commandUpdateMeta
+}
+
+// forceReleaseCommand selects the force release script, GETDEL on Redis >= 6.2
+// forceReleaseCommand 选择强制释放脚本，Redis >= 6.2 上使用 GETDEL
+func (o *Suo) forceReleaseCommand(ctx context.Context) string {
+	if o.versionAtLeast(ctx, 6, 2) {
+		return commandForceReleaseGetDel
+	}
+	return commandForceRelease
+}
+
+// versionAtLeast reports whether the Redis server is at least major.minor, probing it when not known yet
+// The probe runs detached from the caller context under its own timeout, so a cancelled caller never fixes the fallback
+//
+// versionAtLeast 判断 Redis 服务端版本是否不低于 major.minor，尚未得知时进行探测
+// 探测与调用方上下文分离并使用独立超时，因此被取消的调用方不会使回退脚本固定下来
+func (o *Suo) versionAtLeast(ctx context.Context, major, minor int) bool {
+	v := o.version
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if !v.done && !o.clock.Now().Before(v.retryAt) {
+		probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionProbeTimeout)
+		defer cancel()
+		if detectedMajor, detectedMinor, err := o.detectVersion(probeCtx); err != nil {
+			v.retryAt = o.clock.Now().Add(versionRetryInterval)
+		} else {
+			v.major, v.minor, v.done = detectedMajor, detectedMinor, true
+		}
+	}
+	return v.supports(major, minor)
+}
+
+// detectVersion reads redis_version from INFO server
+// Returns zero when the reply carries no version, selecting the fallback scripts
+//
+// detectVersion 从 INFO server 读取 redis_version
+// 回复中没有版本时返回零值，从而选择回退脚本
+func (o *Suo) detectVersion(ctx context.Context) (int, int, error) {
+	text, err := o.client().Info(ctx, "server").Result()
+	if err != nil {
+		o.logger.DebugLog("探测版本失败-使用兼容脚本", zap.String("k", o.key), zap.Error(err))
+		return 0, 0, erero.Wro(err)
+	}
+	major, minor := parseRedisVersion(text)
+	o.logger.DebugLog("探测版本", zap.String("k", o.key), zap.Int("major", major), zap.Int("minor", minor))
+	return major, minor, nil
+}
+
+// parseRedisVersion extracts major and minor numbers from INFO server output
+// Returns zero when the redis_version line is missing or malformed
+//
+// parseRedisVersion 从 INFO server 输出中提取主次版本号
+// 缺少 redis_version 行或格式错误时返回零值
+func parseRedisVersion(info string) (int, int) {
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "redis_version:")
+		if !ok {
+			continue
+		}
+		parts := strings.Split(value, ".")
+		if len(parts) < 2 {
+			return 0, 0
+		}
+		major, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, 0
+		}
+		minor, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return 0, 0
+		}
+		return major, minor
+	}
+	return 0, 0
+}
@@ -0,0 +1,194 @@
+package redissuo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// TestParseRedisVersion validates version parsing from INFO server output
+// Tests both well-formed and missing redis_version lines
+//
+// TestParseRedisVersion 验证从 INFO server 输出中解析版本
+// 测试格式正确和缺少 redis_version 行的情况
+func TestParseRedisVersion(t *testing.T) {
+	major, minor := parseRedisVersion("# Server\r\nredis_version:7.2.4\r\nredis_mode:standalone\r\n")
+	require.Equal(t, 7, major)
+	require.Equal(t, 2, minor)
+
+	major, minor = parseRedisVersion("# Clients\r\nconnected_clients:1\r\n")
+	require.Equal(t, 0, major)
+	require.Equal(t, 0, minor)
+
+	major, minor = parseRedisVersion("redis_version:x.y\r\n")
+	require.Equal(t, 0, major)
+	require.Equal(t, 0, minor)
+}
+
+// TestVersionProbe_Supports validates the version threshold comparison
+// 验证版本阈值比较
+func TestVersionProbe_Supports(t *testing.T) {
+	require.True(t, (&versionProbe{major: 7, minor: 0}).supports(7, 0))
+	require.True(t, (&versionProbe{major: 8, minor: 0}).supports(7, 2))
+	require.False(t, (&versionProbe{major: 6, minor: 2}).supports(7, 0))
+	require.False(t, (&versionProbe{}).supports(7, 0))
+}
+
+// TestCommandAcquireModern validates the SET NX GET script against a standalone Redis
+// Tests fresh acquisition, same session refresh, and rejection of a different session
+//
+// TestCommandAcquireModern 使用独立 Redis 验证 SET NX GET 脚本
+// 测试首次获取、同一会话刷新以及拒绝不同会话
+func TestCommandAcquireModern(t *testing.T) {
+	miniRedis := rese.P1(miniredis.Run())
+	defer miniRedis.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+	defer rese.F0(redisClient.Close)
+
+	ctx := context.Background()
+
+	res, err := redisClient.Eval(ctx, commandAcquireModern, []string{"k"}, []string{"a", "1000"}).Result()
+	require.NoError(t, err)
+	require.Equal(t, "OK", res)
+
+	res, err = redisClient.Eval(ctx, commandAcquireModern, []string{"k"}, []string{"a", "5000"}).Result()
+	require.NoError(t, err)
+	require.Equal(t, "OK", res)
+	require.Equal(t, 5*time.Second, miniRedis.TTL("k"))
+
+	_, err = redisClient.Eval(ctx, commandAcquireModern, []string{"k"}, []string{"b", "1000"}).Result()
+	require.ErrorIs(t, err, redis.Nil)
+}
+
+// TestCommandUpdateMeta validates both metadata update scripts keep the TTL and refuse other sessions
+// TestCommandUpdateMeta 验证两个元数据更新脚本都保留 TTL 并拒绝其它会话
+func TestCommandUpdateMeta(t *testing.T) {
+	miniRedis := rese.P1(miniredis.Run())
+	defer miniRedis.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+	defer rese.F0(redisClient.Close)
+
+	ctx := context.Background()
+	for _, command := range []string{commandUpdateMeta, commandUpdateMetaKeepTTL} {
+		miniRedis.FlushAll()
+		require.NoError(t, redisClient.Set(ctx, "k", "a", 5*time.Second).Err())
+
+		res, err := redisClient.Eval(ctx, command, []string{"k", "m"}, "b", "x").Int64()
+		require.NoError(t, err)
+		require.Equal(t, int64(0), res)
+		require.False(t, miniRedis.Exists("m"))
+
+		res, err = redisClient.Eval(ctx, command, []string{"k", "m"}, "a", "x").Int64()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res)
+		require.Equal(t, 5*time.Second, miniRedis.TTL("m"))
+
+		miniRedis.SetTTL("m", 3*time.Second)
+		res, err = redisClient.Eval(ctx, command, []string{"k", "m"}, "a", "y").Int64()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), res)
+		require.Equal(t, "y", rese.V1(miniRedis.Get("m")))
+		require.Positive(t, miniRedis.TTL("m"))
+	}
+}
+
+// TestCommandForceReleaseGetDel validates the GETDEL force release gives back the holder and drops the metadata
+// TestCommandForceReleaseGetDel 验证 GETDEL 强制释放返回持有者并删除元数据
+func TestCommandForceReleaseGetDel(t *testing.T) {
+	miniRedis := rese.P1(miniredis.Run())
+	defer miniRedis.Close()
+
+	redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+	defer rese.F0(redisClient.Close)
+
+	ctx := context.Background()
+	require.NoError(t, miniRedis.Set("k", "a"))
+	require.NoError(t, miniRedis.Set("m", "x"))
+
+	res, err := redisClient.Eval(ctx, commandForceReleaseGetDel, []string{"k", "m"}).Result()
+	require.NoError(t, err)
+	require.Equal(t, "a", res)
+	require.False(t, miniRedis.Exists("k"))
+	require.False(t, miniRedis.Exists("m"))
+
+	_, err = redisClient.Eval(ctx, commandForceReleaseGetDel, []string{"k", "m"}).Result()
+	require.ErrorIs(t, err, redis.Nil)
+}
+
+// probeClock reads a settable time, timers run on the wall clock
+// probeClock 读取可设置的时间，定时器使用系统时钟
+type probeClock struct {
+	systemClock
+	now time.Time
+}
+
+func (c *probeClock) Now() time.Time { return c.now }
+
+// infoHook answers INFO in place of the server, refusing the first calls
+// infoHook 代替服务端应答 INFO，拒绝最初的若干次调用
+type infoHook struct {
+	mutex  sync.Mutex
+	refuse int
+	calls  int
+}
+
+func (h *infoHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *infoHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() != "info" {
+			return next(ctx, cmd)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		h.calls++
+		if h.calls <= h.refuse {
+			return errors.New("info refused")
+		}
+		cmd.(*redis.StringCmd).SetVal("# Server\r\nredis_version:7.2.4\r\n")
+		return nil
+	}
+}
+
+func (h *infoHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// TestSuo_VersionProbe validates a failed probe is tried again past the interval, detached from a cancelled caller, and kept once it succeeds
+// TestSuo_VersionProbe 验证探测失败后在间隔之后重试，且与已取消的调用方分离，成功后保持结果
+func TestSuo_VersionProbe(t *testing.T) {
+	miniRedis := rese.P1(miniredis.Run())
+	defer miniRedis.Close()
+
+	hook := &infoHook{refuse: 1}
+	redisClient := redis.NewClient(&redis.Options{Addr: miniRedis.Addr()})
+	redisClient.AddHook(hook)
+	defer rese.F0(redisClient.Close)
+
+	clock := &probeClock{now: time.Now()}
+	suo := NewSuo(redisClient, "k", time.Second).WithClock(clock)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.Equal(t, commandAcquire, suo.acquireCommand(ctx))
+	require.Equal(t, commandAcquire, suo.acquireCommand(ctx))
+	require.Equal(t, 1, hook.calls)
+
+	clock.now = clock.now.Add(versionRetryInterval)
+	require.Equal(t, commandAcquireModern, suo.acquireCommand(ctx))
+	require.Equal(t, commandUpdateMetaKeepTTL, suo.updateMetaCommand(ctx))
+	require.Equal(t, commandForceReleaseGetDel, suo.forceReleaseCommand(ctx))
+	require.Equal(t, 2, hook.calls)
+}
@@ -0,0 +1,53 @@
+package redissuo
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// withinPollInterval caps the wait between two attempts of AcquireWithin
+// withinPollInterval 限制 AcquireWithin 两次尝试之间的等待时长
+const withinPollInterval = 50 * time.Millisecond
+
+// ErrWaitTimeout is returned when AcquireWithin could not obtain the lock within the max wait
+// ErrWaitTimeout 在 AcquireWithin 未能在最长等待时间内获取锁时返回
+var ErrWaitTimeout = NewError(CodeWaitTimeout, LanguageEnglish, nil)
+
+// AcquireWithin keeps trying to acquire the lock for at most maxWait, then gives up with ErrWaitTimeout
+// Suits request-scoped handlers that can afford a bounded wait, between the single-shot Acquire and the endless runner
+// Each attempt reuses one session, so an attempt whose reply got lost is taken back through the next one
+//
+// AcquireWithin 在最长 maxWait 时间内持续尝试获取锁，超时后以 ErrWaitTimeout 放弃
+// 适用于只能承受有限等待的请求级处理程序，介于单次的 Acquire 与无限等待的运行器之间
+// 各次尝试复用同一会话，回复丢失的尝试会通过下一次尝试重新拿回锁
+func (o *Suo) AcquireWithin(ctx context.Context, maxWait time.Duration) (*Xin, error) {
+	must.True(maxWait >= 0)
+	sessionUUID := utils.NewUUID()
+	deadline := o.clock.Now().Add(maxWait)
+	for {
+		xin, err := o.acquireSession(ctx, sessionUUID)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if xin != nil {
+			return xin, nil
+		}
+		remaining := deadline.Sub(o.clock.Now())
+		if remaining <= 0 {
+			o.logger.DebugLog("等待超时-放弃申请", zap.String("k", o.key), zap.Duration("max_wait", maxWait))
+			return nil, o.newError(CodeWaitTimeout)
+		}
+		timer := o.clock.NewTimer(min(withinPollInterval, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, erero.Wro(ctx.Err())
+		case <-timer.C():
+		}
+	}
+}
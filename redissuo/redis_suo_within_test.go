@@ -0,0 +1,40 @@
+package redissuo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuo_AcquireWithin validates the bounded wait gives up with ErrWaitTimeout and succeeds once the lock frees up in time
+// TestSuo_AcquireWithin 验证有限等待超时后以 ErrWaitTimeout 放弃，而锁及时释放时获取成功
+func TestSuo_AcquireWithin(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	start := time.Now()
+	busy, err := suo.AcquireWithin(ctx, 100*time.Millisecond)
+	require.ErrorIs(t, err, redissuo.ErrWaitTimeout)
+	require.Nil(t, busy)
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_, _ = suo.Release(ctx, xin)
+	}()
+	taken, err := suo.AcquireWithin(ctx, time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, taken)
+
+	success, err := suo.Release(ctx, taken)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,185 @@
+// Package redissuoelect: Leader election campaigning for a leadership key held through a Suo
+// Renews the term in the background, detects a lost term, and resigns gracefully when the campaign ends
+// OnElected runs with a context cancelled as the term ends, OnResigned follows once it returned
+//
+// redissuoelect: 通过 Suo 持有领导权键的选主竞选
+// 在后台续期任期，发现任期丢失，并在竞选结束时优雅卸任
+// OnElected 使用在任期结束时取消的上下文运行，返回之后调用 OnResigned
+package redissuoelect
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+	"go.uber.org/zap"
+)
+
+// Callbacks receive the leadership changes of one candidate
+// OnElected holds the leader work and should return soon once its context ends, nil callbacks are skipped
+//
+// Callbacks 接收单个候选者的领导权变化
+// OnElected 承载领导者的工作，其上下文结束后应尽快返回，为 nil 的回调会被跳过
+type Callbacks struct {
+	OnElected  func(ctx context.Context) // Term started, the context ends with the term // 任期开始，上下文随任期结束
+	OnResigned func()                    // Term over, through loss or resignation // 任期结束，因丢失或卸任
+}
+
+// Election campaigns for the leadership key of its Suo on behalf of one candidate
+// Election 代表单个候选者竞选其 Suo 的领导权键
+type Election struct {
+	suo       *redissuo.Suo  // Lock of the leadership key // 领导权键的锁
+	interval  time.Duration  // Gap between campaign attempts // 竞选尝试之间的间隔
+	renew     time.Duration  // Gap between renewals, 0 means a third of the TTL // 续期之间的间隔，0 表示 TTL 的三分之一
+	callbacks Callbacks      // Leadership change receivers // 领导权变化的接收方
+	logger    logging.Logger // Logger instance // 日志记录器实例
+	resign    chan struct{}  // Asks the leader to step down // 请求领导者卸任
+	mutex     sync.Mutex     // Protects xin // 保护 xin
+	xin       *redissuo.Xin  // Session of the current term, nil when not leading // 当前任期的会话，未领导时为 nil
+}
+
+// NewElection creates an election over the leadership key of the Suo, campaigning at the interval
+// NewElection 在 Suo 的领导权键上创建选举，按该间隔竞选
+func NewElection(suo *redissuo.Suo, interval time.Duration, callbacks Callbacks) *Election {
+	return &Election{
+		suo:       must.Nice(suo),
+		interval:  must.Nice(interval),
+		callbacks: callbacks,
+		logger:    logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+		resign:    make(chan struct{}, 1),
+	}
+}
+
+// WithRenewInterval sets the gap between term renewals, 0 picks a third of the TTL
+// WithRenewInterval 设置任期续期之间的间隔，0 表示使用 TTL 的三分之一
+func (e *Election) WithRenewInterval(renew time.Duration) *Election {
+	must.True(renew >= 0)
+	e.renew = renew
+	return e
+}
+
+// WithLogger sets custom logger used in reporting leadership changes
+// WithLogger 设置用于报告领导权变化的自定义日志记录器
+func (e *Election) WithLogger(logger logging.Logger) *Election {
+	e.logger = logger
+	return e
+}
+
+// Campaign runs for leadership until the context ends, serving each won term in turn
+// A lost term ends in OnResigned and the campaign goes on, the ending context resigns the term and releases the key
+//
+// Campaign 竞选领导权直到上下文结束，依次履行每个赢得的任期
+// 任期丢失时调用 OnResigned 并继续竞选，上下文结束时卸任并释放该键
+func (e *Election) Campaign(ctx context.Context) error {
+	o := e.suo
+	for {
+		xin, err := o.Acquire(ctx)
+		if err != nil && ctx.Err() == nil {
+			e.logger.DebugLog("竞选报错", zap.String("k", o.Key()), zap.Error(err))
+		}
+		if xin != nil {
+			e.serve(ctx, xin)
+		}
+		timer := o.Clock().NewTimer(e.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return erero.Wro(ctx.Err())
+		case <-timer.C():
+		}
+	}
+}
+
+// serve holds one term, renewing it until it is lost, resigned, or the context ends
+// serve 持有一个任期并持续续期，直到任期丢失、卸任或上下文结束
+func (e *Election) serve(ctx context.Context, xin *redissuo.Xin) {
+	o := e.suo
+	e.setXin(xin)
+	e.drainResign()
+	e.logger.DebugLog("当选领导者", zap.String("k", o.Key()), zap.String("v", xin.SessionUUID()))
+
+	termCtx, cancel := o.HoldContext(ctx, xin)
+	keepAlive := o.KeepAlive(ctx, xin, e.renew)
+	var wg sync.WaitGroup
+	if e.callbacks.OnElected != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.callbacks.OnElected(termCtx)
+		}()
+	}
+
+	select {
+	case <-keepAlive.Done():
+	case <-termCtx.Done():
+	case <-e.resign:
+	case <-ctx.Done():
+	}
+	latest, err := keepAlive.Stop()
+	if err != nil {
+		e.logger.ErrorLog("任期丢失", zap.String("k", o.Key()), zap.String("v", xin.SessionUUID()), zap.Error(err))
+	} else if _, err := o.Release(context.WithoutCancel(ctx), latest); err != nil {
+		e.logger.ErrorLog("卸任释放报错", zap.String("k", o.Key()), zap.String("v", xin.SessionUUID()), zap.Error(err))
+	}
+	cancel()
+	wg.Wait()
+	e.setXin(nil)
+	e.logger.DebugLog("已卸任", zap.String("k", o.Key()), zap.String("v", xin.SessionUUID()))
+	if e.callbacks.OnResigned != nil {
+		e.callbacks.OnResigned()
+	}
+}
+
+// Resign steps down from the current term, the campaign goes on and may win again later
+// Does nothing while not leading
+//
+// Resign 从当前任期卸任，竞选继续进行，之后可能再次当选
+// 未领导时不做任何事
+func (e *Election) Resign() {
+	if e.IsLeader() {
+		select {
+		case e.resign <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// drainResign drops a resignation asked ahead of the term
+// drainResign 丢弃任期开始之前的卸任请求
+func (e *Election) drainResign() {
+	select {
+	case <-e.resign:
+	default:
+	}
+}
+
+// setXin stores the session of the current term
+// setXin 保存当前任期的会话
+func (e *Election) setXin(xin *redissuo.Xin) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.xin = xin
+}
+
+// IsLeader reports whether the candidate holds a term at present, safe to call from other goroutines
+// IsLeader 判断候选者当前是否处于任期中，可在其它 goroutine 中安全调用
+func (e *Election) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.xin != nil
+}
+
+// Leader gets back the session of the leadership key as seen in Redis, blank when nobody leads
+// Leader 返回 Redis 中领导权键的会话，无人领导时为空
+func (e *Election) Leader(ctx context.Context) (string, error) {
+	holder, err := e.suo.Holder(ctx)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return holder, nil
+}
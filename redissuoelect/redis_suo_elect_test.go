@@ -0,0 +1,123 @@
+package redissuoelect_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuoelect"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+var caseRedisClient redis.UniversalClient
+
+func TestMain(m *testing.M) {
+	miniRedis := rese.P1(miniredis.Run())
+	defer miniRedis.Close()
+
+	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs: []string{miniRedis.Addr()},
+	})
+	must.Done(redisClient.Ping(context.Background()).Err())
+
+	caseRedisClient = redisClient
+
+	m.Run()
+}
+
+// newCandidate starts a campaign and gives back its election, elected and resigned signals and its stop function
+// newCandidate 开始竞选并返回其选举、当选和卸任信号以及停止函数
+func newCandidate(key string) (*redissuoelect.Election, chan struct{}, chan struct{}, func()) {
+	elected := make(chan struct{}, 10)
+	resigned := make(chan struct{}, 10)
+	suo := redissuo.NewSuo(caseRedisClient, key, time.Second)
+	election := redissuoelect.NewElection(suo, 10*time.Millisecond, redissuoelect.Callbacks{
+		OnElected: func(ctx context.Context) {
+			elected <- struct{}{}
+			<-ctx.Done()
+		},
+		OnResigned: func() {
+			resigned <- struct{}{}
+		},
+	}).WithRenewInterval(20 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = election.Campaign(ctx)
+	}()
+	return election, elected, resigned, func() {
+		cancel()
+		<-done
+	}
+}
+
+// TestElection validates a single leader at once and the handover once the leader stops campaigning
+// TestElection 验证同一时刻只有一个领导者，以及领导者停止竞选后的交接
+func TestElection(t *testing.T) {
+	key := utils.NewUUID()
+	first, firstElected, firstResigned, stopFirst := newCandidate(key)
+	<-firstElected
+	require.True(t, first.IsLeader())
+
+	second, secondElected, _, stopSecond := newCandidate(key)
+	defer stopSecond()
+	time.Sleep(50 * time.Millisecond)
+	require.False(t, second.IsLeader())
+
+	leader, err := first.Leader(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, leader)
+
+	stopFirst()
+	<-firstResigned
+	require.False(t, first.IsLeader())
+
+	select {
+	case <-secondElected:
+	case <-time.After(time.Second):
+		require.Fail(t, "second candidate not elected")
+	}
+	require.True(t, second.IsLeader())
+}
+
+// TestElection_Lost validates a stolen term ends in OnResigned and the campaign goes on
+// TestElection_Lost 验证被抢占的任期以 OnResigned 结束且竞选继续进行
+func TestElection_Lost(t *testing.T) {
+	key := utils.NewUUID()
+	election, elected, resigned, stop := newCandidate(key)
+	defer stop()
+	<-elected
+
+	// Another holder takes the key, the next renewal finds the term lost
+	require.NoError(t, caseRedisClient.Set(context.Background(), key, "usurper", time.Minute).Err())
+	<-resigned
+	require.False(t, election.IsLeader())
+
+	// The key frees up, the campaign wins it back
+	require.NoError(t, caseRedisClient.Del(context.Background(), key).Err())
+	select {
+	case <-elected:
+	case <-time.After(time.Second):
+		require.Fail(t, "candidate not elected again")
+	}
+}
+
+// TestElection_Resign validates a resigned leader releases the key
+// TestElection_Resign 验证卸任的领导者释放该键
+func TestElection_Resign(t *testing.T) {
+	key := utils.NewUUID()
+	election, elected, resigned, stop := newCandidate(key)
+	defer stop()
+	<-elected
+
+	election.Resign()
+	<-resigned
+	require.False(t, election.IsLeader())
+}
@@ -0,0 +1,246 @@
+// Package redissuoetcd: etcd backed implementation of the redissuo.Locker interface
+// Each session owns an etcd lease, the lock key is written through a create-if-absent transaction attached to the lease
+// The lease is read back from the stored key, so any Locker of the key, in any process, extends and releases the session
+// Talks to the etcd v3 JSON gateway over HTTP, so no etcd client dependency is pulled in
+//
+// redissuoetcd: 基于 etcd 的 redissuo.Locker 接口实现
+// 每个会话拥有一个 etcd 租约，锁键通过"不存在才创建"的事务写入并绑定到该租约
+// 租约从存储的键中读回，因此任意进程中该键的任意 Locker 都能延期和释放该会话
+// 通过 HTTP 访问 etcd v3 JSON 网关，因此不引入 etcd 客户端依赖
+package redissuoetcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/pkg/errors"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// Client calls the etcd v3 JSON gateway of one endpoint
+// Client 调用单个端点的 etcd v3 JSON 网关
+type Client struct {
+	endpoint   string       // Gateway address, e.g. http://127.0.0.1:2379 // 网关地址，例如 http://127.0.0.1:2379
+	httpClient *http.Client // HTTP client // HTTP 客户端
+}
+
+// NewClient creates a client of the gateway at the endpoint
+// NewClient 创建指向该端点网关的客户端
+func NewClient(endpoint string) *Client {
+	return &Client{
+		endpoint:   strings.TrimRight(must.Nice(endpoint), "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithHTTPClient sets the HTTP client, e.g. one carrying TLS settings
+// WithHTTPClient 设置 HTTP 客户端，例如携带 TLS 配置的客户端
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = must.Nice(httpClient)
+	return c
+}
+
+// NewLocker creates a locker of the key, the TTL rounds up to whole seconds as etcd leases count in seconds
+// NewLocker 创建指定键的锁，由于 etcd 租约以秒计，TTL 向上取整到整秒
+func (c *Client) NewLocker(key string, ttl time.Duration) *Locker {
+	return &Locker{
+		client: c,
+		key:    must.Nice(key),
+		ttl:    must.Nice(ttl),
+		clock:  redissuo.SystemClock(),
+	}
+}
+
+// call posts the request to the gateway path and decodes the reply
+// call 将请求发送到网关路径并解码回复
+func (c *Client) call(ctx context.Context, path string, request interface{}, reply interface{}) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return errors.WithMessage(err, "marshal request")
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return errors.WithMessage(err, "new request")
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	response, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return errors.WithMessagef(err, "post %s", path)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.Errorf("etcd %s status %d", path, response.StatusCode)
+	}
+	if err := json.NewDecoder(response.Body).Decode(reply); err != nil {
+		return errors.WithMessagef(err, "decode %s", path)
+	}
+	return nil
+}
+
+// encode gets back the base64 form the gateway expects of keys and values
+// encode 返回网关要求的键和值的 base64 形式
+func encode(text string) string {
+	return base64.StdEncoding.EncodeToString([]byte(text))
+}
+
+// Locker is a redissuo.Locker backed through etcd leases and transactions
+// Locker 是以 etcd 租约和事务为后端的 redissuo.Locker
+type Locker struct {
+	client *Client        // Gateway client // 网关客户端
+	key    string         // Lock name ID // 锁名标识符
+	ttl    time.Duration  // Lease of each acquisition // 每次获取的租期
+	clock  redissuo.Clock // Source of conservative expiry estimates // 保守过期时间估算的时间来源
+}
+
+var _ redissuo.Locker = (*Locker)(nil)
+
+// Key gets back the lock name ID
+// Key 返回锁名标识符
+func (l *Locker) Key() string {
+	return l.key
+}
+
+// leaseSeconds gets back the TTL rounded up to whole seconds
+// leaseSeconds 返回向上取整到整秒的 TTL
+func (l *Locker) leaseSeconds() int64 {
+	return int64((l.ttl + time.Second - 1) / time.Second)
+}
+
+// Acquire grants a lease and writes the key under it when absent, nil when held elsewhere
+// The lease is revoked again when the key is taken, so a refused attempt leaves nothing behind
+//
+// Acquire 授予租约并在键不存在时以该租约写入，被其它会话持有时返回 nil
+// 键已被占用时会撤销该租约，使被拒绝的尝试不留下任何残留
+func (l *Locker) Acquire(ctx context.Context) (*redissuo.Xin, error) {
+	startTime := l.clock.Now()
+	var grant struct {
+		ID string `json:"ID"`
+	}
+	if err := l.client.call(ctx, "/v3/lease/grant", map[string]interface{}{"TTL": l.leaseSeconds()}, &grant); err != nil {
+		return nil, erero.Wro(err)
+	}
+	session := utils.NewUUID()
+	var txn struct {
+		Succeeded bool `json:"succeeded"`
+	}
+	request := map[string]interface{}{
+		"compare": []map[string]interface{}{
+			{"key": encode(l.key), "result": "EQUAL", "target": "CREATE", "create_revision": "0"},
+		},
+		"success": []map[string]interface{}{
+			{"request_put": map[string]interface{}{"key": encode(l.key), "value": encode(session), "lease": grant.ID}},
+		},
+	}
+	if err := l.client.call(ctx, "/v3/kv/txn", request, &txn); err != nil {
+		l.revoke(ctx, grant.ID)
+		return nil, erero.Wro(err)
+	}
+	if !txn.Succeeded {
+		l.revoke(ctx, grant.ID)
+		return nil, nil
+	}
+	return redissuo.NewXin(l.key, session, startTime.Add(time.Duration(l.leaseSeconds())*time.Second), startTime), nil
+}
+
+// keyValue is one key of a gateway reply, the lease ID carried as a decimal string
+// keyValue 是网关回复中的一个键，租约 ID 以十进制字符串表示
+type keyValue struct {
+	Lease string `json:"lease"`
+}
+
+// txnReply is the gateway reply of a transaction, responses present just when it succeeded
+// txnReply 是网关对事务的回复，仅在事务成功时包含 responses
+type txnReply struct {
+	Succeeded bool `json:"succeeded"`
+	Responses []struct {
+		ResponseRange struct {
+			Kvs []keyValue `json:"kvs"`
+		} `json:"response_range"`
+		ResponseDeleteRange struct {
+			PrevKvs []keyValue `json:"prev_kvs"`
+		} `json:"response_delete_range"`
+	} `json:"responses"`
+}
+
+// ownedBy gets back the compare matching the key just while the session holds it
+// ownedBy 返回仅在会话持有该键时才匹配的比较条件
+func (l *Locker) ownedBy(session string) []map[string]interface{} {
+	return []map[string]interface{}{
+		{"key": encode(l.key), "result": "EQUAL", "target": "VALUE", "value": encode(session)},
+	}
+}
+
+// AcquireAgainExtendLock reads the lease off the key the session holds and renews it, nil when the session lost the key
+// AcquireAgainExtendLock 从会话持有的键上读取租约并续期，会话已失去该键时返回 nil
+func (l *Locker) AcquireAgainExtendLock(ctx context.Context, xin *redissuo.Xin) (*redissuo.Xin, error) {
+	must.Equals(xin.Key(), l.key)
+	startTime := l.clock.Now()
+	var txn txnReply
+	request := map[string]interface{}{
+		"compare": l.ownedBy(xin.SessionUUID()),
+		"success": []map[string]interface{}{
+			{"request_range": map[string]interface{}{"key": encode(l.key)}},
+		},
+	}
+	if err := l.client.call(ctx, "/v3/kv/txn", request, &txn); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if !txn.Succeeded || len(txn.Responses) == 0 || len(txn.Responses[0].ResponseRange.Kvs) == 0 {
+		return nil, nil
+	}
+	leaseID := txn.Responses[0].ResponseRange.Kvs[0].Lease
+	var keepAlive struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := l.client.call(ctx, "/v3/lease/keepalive", map[string]interface{}{"ID": leaseID}, &keepAlive); err != nil {
+		return nil, erero.Wro(err)
+	}
+	seconds, _ := strconv.ParseInt(keepAlive.Result.TTL, 10, 64)
+	if seconds <= 0 {
+		return nil, nil
+	}
+	return redissuo.NewExtendedXin(xin, startTime.Add(time.Duration(seconds)*time.Second)), nil
+}
+
+// Release deletes the key when the session still owns it, then revokes the lease the key was attached to
+// Release 在会话仍持有该键时删除它，然后撤销该键绑定的租约
+func (l *Locker) Release(ctx context.Context, xin *redissuo.Xin) (bool, error) {
+	must.Equals(xin.Key(), l.key)
+	var txn txnReply
+	request := map[string]interface{}{
+		"compare": l.ownedBy(xin.SessionUUID()),
+		"success": []map[string]interface{}{
+			{"request_delete_range": map[string]interface{}{"key": encode(l.key), "prev_kv": true}},
+		},
+	}
+	if err := l.client.call(ctx, "/v3/kv/txn", request, &txn); err != nil {
+		return false, erero.Wro(err)
+	}
+	if !txn.Succeeded {
+		return false, nil
+	}
+	if len(txn.Responses) > 0 {
+		for _, kv := range txn.Responses[0].ResponseDeleteRange.PrevKvs {
+			l.revoke(ctx, kv.Lease)
+		}
+	}
+	return true, nil
+}
+
+// revoke drops the lease best-effort, an unrevoked lease still lapses on its own
+// revoke 尽力撤销租约，未撤销的租约也会自行到期
+func (l *Locker) revoke(ctx context.Context, leaseID string) {
+	var reply struct{}
+	_ = l.client.call(ctx, "/v3/lease/revoke", map[string]interface{}{"ID": leaseID}, &reply)
+}
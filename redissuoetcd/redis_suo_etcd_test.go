@@ -0,0 +1,223 @@
+package redissuoetcd_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuoetcd"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcd emulates the gateway endpoints used through the locker, keys are kept base64 encoded
+// fakeEtcd 模拟锁所使用的网关端点，键以 base64 编码形式保存
+type fakeEtcd struct {
+	mutex  sync.Mutex
+	nextID int
+	leases map[string]bool
+	values map[string]string // Key to value // 键到值
+	owners map[string]string // Key to lease ID // 键到租约 ID
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var request map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var reply interface{}
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.leases[id] = true
+		reply = map[string]string{"ID": id, "TTL": "1"}
+	case "/v3/lease/keepalive":
+		var id string
+		_ = json.Unmarshal(request["ID"], &id)
+		ttl := "0"
+		if f.leases[id] {
+			ttl = "1"
+		}
+		reply = map[string]interface{}{"result": map[string]string{"ID": id, "TTL": ttl}}
+	case "/v3/lease/revoke":
+		var id string
+		_ = json.Unmarshal(request["ID"], &id)
+		f.expire(id)
+		reply = map[string]string{}
+	case "/v3/kv/txn":
+		var compares []map[string]string
+		var success []map[string]map[string]interface{}
+		_ = json.Unmarshal(request["compare"], &compares)
+		_ = json.Unmarshal(request["success"], &success)
+		compare := compares[0]
+		value, exists := f.values[compare["key"]]
+		succeeded := (compare["target"] == "CREATE" && !exists) || (compare["target"] == "VALUE" && exists && value == compare["value"])
+		var responses []map[string]interface{}
+		if succeeded {
+			for _, op := range success {
+				if put, ok := op["request_put"]; ok {
+					key := put["key"].(string)
+					f.values[key] = put["value"].(string)
+					f.owners[key] = put["lease"].(string)
+					responses = append(responses, map[string]interface{}{"response_put": map[string]string{}})
+				}
+				if get, ok := op["request_range"]; ok {
+					responses = append(responses, map[string]interface{}{"response_range": map[string]interface{}{"kvs": f.kvs(get["key"].(string))}})
+				}
+				if del, ok := op["request_delete_range"]; ok {
+					key := del["key"].(string)
+					result := map[string]interface{}{"deleted": strconv.Itoa(len(f.kvs(key)))}
+					if del["prev_kv"] == true {
+						result["prev_kvs"] = f.kvs(key)
+					}
+					delete(f.values, key)
+					delete(f.owners, key)
+					responses = append(responses, map[string]interface{}{"response_delete_range": result})
+				}
+			}
+		}
+		reply = map[string]interface{}{"succeeded": succeeded, "responses": responses}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(reply)
+}
+
+// kvs gets back the key in the gateway reply form, empty when absent
+// kvs 以网关回复形式返回该键，不存在时为空
+func (f *fakeEtcd) kvs(key string) []map[string]string {
+	value, exists := f.values[key]
+	if !exists {
+		return nil
+	}
+	return []map[string]string{{"key": key, "value": value, "lease": f.owners[key]}}
+}
+
+// expire drops the lease together with the keys attached to it
+// expire 删除租约及其绑定的键
+func (f *fakeEtcd) expire(id string) {
+	delete(f.leases, id)
+	for key, owner := range f.owners {
+		if owner == id {
+			delete(f.values, key)
+			delete(f.owners, key)
+		}
+	}
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{leases: map[string]bool{}, values: map[string]string{}, owners: map[string]string{}}
+}
+
+// TestLocker validates the locker excludes other sessions, extends through keepalive and releases
+// Set REDIS_SUO_ETCD_ENDPOINT to run the same steps against a real etcd gateway as well
+//
+// TestLocker 验证锁排斥其它会话，并能通过续约延期和释放
+// 设置 REDIS_SUO_ETCD_ENDPOINT 后也会针对真实的 etcd 网关运行相同步骤
+func TestLocker(t *testing.T) {
+	server := httptest.NewServer(newFakeEtcd())
+	defer server.Close()
+
+	endpoints := map[string]string{"fake": server.URL}
+	if endpoint := os.Getenv("REDIS_SUO_ETCD_ENDPOINT"); endpoint != "" {
+		endpoints["etcd"] = endpoint
+	}
+	for name, endpoint := range endpoints {
+		t.Run(name, func(t *testing.T) {
+			runLocker(t, endpoint)
+		})
+	}
+}
+
+// runLocker acquires through one client and extends and releases through a Locker of another, standing in another process
+// runLocker 通过一个客户端获取锁，再通过另一个客户端的 Locker 延期和释放，模拟另一个进程
+func runLocker(t *testing.T, endpoint string) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	locker := redissuoetcd.NewClient(endpoint).NewLocker(key, 500*time.Millisecond)
+	other := redissuoetcd.NewClient(endpoint).NewLocker(key, time.Second)
+
+	xin, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	busy, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, busy)
+
+	xin, err = other.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 1, xin.Extensions())
+
+	success, err := other.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	success, err = locker.Release(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, success)
+	lost, err := locker.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.Nil(t, lost)
+
+	taken, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, taken)
+	success, err = locker.Release(ctx, taken)
+	require.NoError(t, err)
+	require.True(t, success)
+}
+
+// TestLocker_Leases validates refused attempts and releases revoke their leases, and a lapsed lease reports the loss
+// TestLocker_Leases 验证被拒绝的尝试和释放会撤销租约，租约失效时延期报告丢失
+func TestLocker_Leases(t *testing.T) {
+	ctx := context.Background()
+	etcd := newFakeEtcd()
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	client := redissuoetcd.NewClient(server.URL)
+	locker := client.NewLocker("job", 500*time.Millisecond)
+	other := client.NewLocker("job", time.Second)
+
+	xin, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	busy, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, busy)
+
+	etcd.mutex.Lock()
+	require.Len(t, etcd.leases, 1)
+	etcd.mutex.Unlock()
+
+	success, err := other.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	etcd.mutex.Lock()
+	require.Empty(t, etcd.leases)
+	etcd.mutex.Unlock()
+
+	taken, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, taken)
+
+	// The lease lapses, the extension reports the loss
+	etcd.mutex.Lock()
+	etcd.expire("3")
+	etcd.mutex.Unlock()
+	lost, err := locker.AcquireAgainExtendLock(ctx, taken)
+	require.NoError(t, err)
+	require.Nil(t, lost)
+}
@@ -0,0 +1,175 @@
+// Package redissuopg: Postgres advisory lock implementation of the redissuo.Locker interface
+// Each held lock pins one pooled connection, the session-level advisory lock lives as long as that connection
+// Works through database/sql, the caller picks and registers the Postgres driver
+// redissuorun.LockerRun drives the runner on it, the same as on a Suo
+//
+// redissuopg: 基于 Postgres 咨询锁的 redissuo.Locker 接口实现
+// 每个被持有的锁固定占用一个连接池中的连接，会话级咨询锁与该连接同生命周期
+// 通过 database/sql 工作，由调用方选择并注册 Postgres 驱动
+// redissuorun.LockerRun 可在其上驱动运行器，与 Suo 相同
+package redissuopg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+const (
+	// TryLockSQL takes the advisory lock of the key without waiting, true when taken
+	// Keys map onto the lock space through hashtext, two keys sharing a hash contend with each other
+	//
+	// TryLockSQL 不等待地获取该键的咨询锁，获取成功时为 true
+	// 键通过 hashtext 映射到锁空间，哈希相同的两个键会相互争用
+	TryLockSQL = `SELECT pg_try_advisory_lock(hashtext($1))`
+	// UnlockSQL releases the advisory lock of the key held through the connection, true when it was held
+	// UnlockSQL 释放通过该连接持有的该键的咨询锁，持有时为 true
+	UnlockSQL = `SELECT pg_advisory_unlock(hashtext($1))`
+	// PingSQL checks the pinned connection is alive, the advisory lock stays held while it is
+	// PingSQL 检查固定的连接是否存活，连接存活期间咨询锁保持被持有
+	PingSQL = `SELECT 1`
+)
+
+// Store hands out advisory lockers over one database
+// Store 在一个数据库上创建咨询锁
+type Store struct {
+	db *sql.DB // Database handle // 数据库句柄
+}
+
+// NewStore creates a store of advisory lockers over the database
+// NewStore 在该数据库上创建咨询锁的存储
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: must.Nice(db)}
+}
+
+// NewLocker creates an advisory locker of the key
+// Advisory locks never lapse on their own, the TTL is the lease the sessions report and the runner budgets work through
+// A crashed process frees its locks once Postgres drops its connections
+//
+// NewLocker 创建该键的咨询锁
+// 咨询锁不会自行失效，TTL 是会话报告的租期，运行器据此安排工作时长
+// 进程崩溃后，Postgres 断开其连接时释放其锁
+func (s *Store) NewLocker(key string, ttl time.Duration) *Locker {
+	return &Locker{store: s, key: must.Nice(key), ttl: must.Nice(ttl), clock: redissuo.SystemClock(), conns: map[string]*sql.Conn{}}
+}
+
+// Locker is a redissuo.Locker backed through Postgres advisory locks
+// Sessions stay bound to the connection holding them, so extension and release work through the same Locker
+//
+// Locker 是以 Postgres 咨询锁为后端的 redissuo.Locker
+// 会话绑定在持有它的连接上，因此延期和释放需通过同一个 Locker 进行
+type Locker struct {
+	store *Store               // Backing database // 后端数据库
+	key   string               // Lock name ID // 锁名标识符
+	ttl   time.Duration        // Lease reported on the sessions // 会话上报告的租期
+	clock redissuo.Clock       // Source of conservative expiry estimates // 保守过期时间估算的时间来源
+	mutex sync.Mutex           // Protects conns // 保护 conns
+	conns map[string]*sql.Conn // Connections pinned through held sessions // 被持有的会话固定占用的连接
+}
+
+var _ redissuo.Locker = (*Locker)(nil)
+
+// Key gets back the lock name ID
+// Key 返回锁名标识符
+func (l *Locker) Key() string {
+	return l.key
+}
+
+// Acquire takes the advisory lock on a connection pinned to a fresh session, nil when held elsewhere
+// Acquire 在固定给新会话的连接上获取咨询锁，被其它会话持有时返回 nil
+func (l *Locker) Acquire(ctx context.Context) (*redissuo.Xin, error) {
+	startTime := l.clock.Now()
+	conn, err := l.store.db.Conn(ctx)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var taken bool
+	if err := conn.QueryRowContext(ctx, TryLockSQL, l.key).Scan(&taken); err != nil {
+		// The lock may have been taken before the reply got lost, the connection goes with it
+		// 锁可能在应答丢失之前已被获取，连接随之丢弃
+		discard(conn)
+		return nil, erero.Wro(err)
+	}
+	if !taken {
+		_ = conn.Close() // Nothing held, the connection goes back to the pool // 未持有任何锁，连接归还连接池
+		return nil, nil
+	}
+	session := utils.NewUUID()
+	l.mutex.Lock()
+	l.conns[session] = conn
+	l.mutex.Unlock()
+	return redissuo.NewXin(l.key, session, startTime.Add(l.ttl), startTime), nil
+}
+
+// AcquireAgainExtendLock renews the lease of the held session once its connection answers, nil when lost
+// AcquireAgainExtendLock 在会话的连接应答后续期该会话的租期，丢失时返回 nil
+func (l *Locker) AcquireAgainExtendLock(ctx context.Context, xin *redissuo.Xin) (*redissuo.Xin, error) {
+	must.Equals(xin.Key(), l.key)
+	conn := l.pinned(xin.SessionUUID())
+	if conn == nil {
+		return nil, nil
+	}
+	startTime := l.clock.Now()
+	var alive int
+	if err := conn.QueryRowContext(ctx, PingSQL).Scan(&alive); err != nil {
+		// A broken connection took the advisory lock with it
+		// 断开的连接带走了咨询锁
+		l.unpin(xin.SessionUUID())
+		discard(conn)
+		return nil, erero.Wro(err)
+	}
+	return redissuo.NewExtendedXin(xin, startTime.Add(l.ttl)), nil
+}
+
+// Release unlocks the session on its connection, false when the session is not held through this Locker
+// Release 在会话的连接上解锁，会话未通过该 Locker 持有时返回 false
+func (l *Locker) Release(ctx context.Context, xin *redissuo.Xin) (bool, error) {
+	must.Equals(xin.Key(), l.key)
+	conn := l.unpin(xin.SessionUUID())
+	if conn == nil {
+		return false, nil
+	}
+	var released bool
+	if err := conn.QueryRowContext(ctx, UnlockSQL, l.key).Scan(&released); err != nil {
+		// The lock must never ride back into the pool, closing the connection frees it
+		// 锁绝不能随连接回到连接池，关闭连接即可释放锁
+		discard(conn)
+		return false, erero.Wro(err)
+	}
+	if err := conn.Close(); err != nil {
+		return released, erero.Wro(err)
+	}
+	return released, nil
+}
+
+// pinned gets back the connection of the session, nil when not held through this Locker
+// pinned 返回会话的连接，未通过该 Locker 持有时为 nil
+func (l *Locker) pinned(session string) *sql.Conn {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.conns[session]
+}
+
+// unpin removes and gets back the connection of the session, nil when not held through this Locker
+// unpin 移除并返回会话的连接，未通过该 Locker 持有时为 nil
+func (l *Locker) unpin(session string) *sql.Conn {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	conn := l.conns[session]
+	delete(l.conns, session)
+	return conn
+}
+
+// discard closes the physical connection instead of handing it back to the pool, dropping any advisory lock on it
+// discard 关闭物理连接而不是将其归还连接池，从而释放其上的咨询锁
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
@@ -0,0 +1,162 @@
+package redissuopg_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuopg"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// statement is one query seen through the scripted driver
+// statement 是脚本化驱动看到的一条查询
+type statement struct {
+	conn  int
+	query string
+	args  []any
+}
+
+// scriptedServer answers each query with the next scripted reply and records it, keeping no lock semantics of its own
+// scriptedServer 以下一条预设应答回复每条查询并记录它，自身不包含任何锁语义
+type scriptedServer struct {
+	mutex      sync.Mutex
+	replies    []any // Scanned value of each reply, an error fails the query // 每条应答扫描出的值，为错误时查询失败
+	statements []statement
+	closed     map[int]bool
+	conns      int
+}
+
+func (s *scriptedServer) query(conn int, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	s.statements = append(s.statements, statement{conn: conn, query: query, args: values})
+	if len(s.replies) == 0 {
+		panic("unscripted query: " + query)
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	if err, ok := reply.(error); ok {
+		return nil, err
+	}
+	return &scriptedRows{value: reply}, nil
+}
+
+func (s *scriptedServer) Connect(ctx context.Context) (driver.Conn, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.conns++
+	return &scriptedConn{server: s, id: s.conns}, nil
+}
+
+func (s *scriptedServer) Driver() driver.Driver { return nil }
+
+type scriptedConn struct {
+	server *scriptedServer
+	id     int
+}
+
+func (c *scriptedConn) Prepare(query string) (driver.Stmt, error) { panic("prepare not supported") }
+func (c *scriptedConn) Begin() (driver.Tx, error)                 { panic("transactions not supported") }
+func (c *scriptedConn) Close() error {
+	c.server.mutex.Lock()
+	defer c.server.mutex.Unlock()
+	c.server.closed[c.id] = true
+	return nil
+}
+func (c *scriptedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.server.query(c.id, query, args)
+}
+
+type scriptedRows struct {
+	value any
+	done  bool
+}
+
+func (r *scriptedRows) Columns() []string { return []string{"value"} }
+func (r *scriptedRows) Close() error      { return nil }
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+func newScripted(replies ...any) (*scriptedServer, *sql.DB) {
+	server := &scriptedServer{replies: replies, closed: map[int]bool{}}
+	return server, sql.OpenDB(server)
+}
+
+// TestLocker validates the exact advisory lock statements and that extension and release run on the connection holding the lock
+// TestLocker 验证确切的咨询锁语句，以及延期和释放在持有锁的连接上执行
+func TestLocker(t *testing.T) {
+	ctx := context.Background()
+	server, db := newScripted(true, false, int64(1), true)
+	defer func() { _ = db.Close() }()
+
+	store := redissuopg.NewStore(db)
+	locker := store.NewLocker("job", time.Minute)
+	other := store.NewLocker("job", time.Minute)
+
+	xin, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	busy, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.Nil(t, busy)
+
+	xin, err = locker.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, 1, xin.Extensions())
+
+	// Another Locker does not hold the session, no statement goes out
+	lost, err := other.Release(ctx, xin)
+	require.NoError(t, err)
+	require.False(t, lost)
+
+	success, err := locker.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	require.Equal(t, []statement{
+		{conn: 1, query: "SELECT pg_try_advisory_lock(hashtext($1))", args: []any{"job"}},
+		{conn: 2, query: "SELECT pg_try_advisory_lock(hashtext($1))", args: []any{"job"}},
+		{conn: 1, query: "SELECT 1", args: []any{}},
+		{conn: 1, query: "SELECT pg_advisory_unlock(hashtext($1))", args: []any{"job"}},
+	}, server.statements)
+	require.Empty(t, server.closed)
+}
+
+// TestLocker_BrokenConnection validates a failed unlock discards the connection instead of pooling the held lock
+// TestLocker_BrokenConnection 验证解锁失败时丢弃连接，而不是将持有的锁放回连接池
+func TestLocker_BrokenConnection(t *testing.T) {
+	ctx := context.Background()
+	server, db := newScripted(true, errors.New("connection reset"))
+	defer func() { _ = db.Close() }()
+
+	locker := redissuopg.NewStore(db).NewLocker("job", time.Minute)
+	xin, err := locker.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	success, err := locker.Release(ctx, xin)
+	require.Error(t, err)
+	require.False(t, success)
+	require.True(t, server.closed[1])
+
+	extended, err := locker.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.Nil(t, extended)
+}
@@ -11,11 +11,14 @@ package redissuorun
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-xlan/redis-go-suo/internal/logging"
 	"github.com/go-xlan/redis-go-suo/internal/utils"
 	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/suoctx"
+	"github.com/pkg/errors"
 	"github.com/yyle88/erero"
 	"github.com/yyle88/must"
 	"github.com/yyle88/zaplog"
@@ -26,8 +29,24 @@ const (
 	// defaultReleaseTimeout defines the minimum timeout ensuring safe lock release operations
 	// defaultReleaseTimeout 定义最小超时时间确保安全的锁释放操作
 	defaultReleaseTimeout = 10 * time.Second
+	// minAttemptTimeout defines the floor of the per-attempt timeout, leaving short poll intervals room in a round trip
+	// minAttemptTimeout 定义单次尝试超时的下限，为较短的轮询间隔留出一次往返的时间
+	minAttemptTimeout = time.Second
 )
 
+// attemptTimeout derives the timeout of one acquisition attempt from the poll interval
+// A hung connection then costs one attempt, while the outer context still bounds the whole wait
+// An attempt timing out after Redis took the lock is safe, the next one reuses the session and gets it back
+// A permit granted that way stays taken until its TTL lapses
+//
+// attemptTimeout 根据轮询间隔推导单次获取尝试的超时时间
+// 这样一个挂起的连接只消耗一次尝试，而外层上下文仍然限制整个等待
+// 在 Redis 已获取锁之后才超时的尝试是安全的，下一次尝试复用同一会话并重新拿到锁
+// 以这种方式授予的许可会一直被占用，直到其 TTL 到期
+func attemptTimeout(sleep time.Duration) time.Duration {
+	return max(2*sleep, minAttemptTimeout)
+}
+
 // SuoLockRun executes a function within a distributed lock with automatic reattempt and cleanup
 // Handles lock acquisition reattempts, guaranteed lock release, and panic handling
 // Provides complete lifecycle management in distributed lock operations
@@ -49,6 +68,34 @@ func SuoLockRun(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Con
 // 支持自定义日志实现用于操作跟踪和调试
 // 为不同部署环境启用灵活的日志策略
 func SuoLockXqt(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Context) error, sleep time.Duration, logger logging.Logger) error {
+	return SuoLockRunWithConfig(ctx, suo, run, NewConfig(sleep).WithLogger(logger))
+}
+
+// SuoLockRunWithConfig executes a function within a distributed lock using the given config
+// Same lifecycle as SuoLockRun, with the optional behaviors enabled in the config
+//
+// SuoLockRunWithConfig 使用给定配置在分布式锁内执行函数
+// 生命周期与 SuoLockRun 相同，并启用配置中的可选行为
+func SuoLockRunWithConfig(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Context) error, config *Config) error {
+	if config.flight != "" {
+		// Concurrent calls of the same key and purpose in this process share one run
+		// 本进程中相同键和用途的并发调用共享一次运行
+		return processFlights.do(ctx, suo.Key(), config.flight, suo.ErrorLanguage(), func() error {
+			return lockRun(ctx, suo, run, config)
+		})
+	}
+	return lockRun(ctx, suo, run, config)
+}
+
+// lockRun acquires the lock, executes the function and releases the lock following the config
+// lockRun 按配置获取锁、执行函数并释放锁
+func lockRun(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Context) error, config *Config) error {
+	ctx, task := traceTask(ctx, suo.Key())
+	defer task.End()
+
+	var sleep = config.sleep
+	var logger = config.logger
+
 	// Generate unique session UUID to this lock execution
 	// 为此次锁执行生成唯一的会话 UUID
 	var sessionUUID = utils.NewUUID()
@@ -56,13 +103,101 @@ func SuoLockXqt(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Con
 	// Create message storage for lock session information
 	// 创建锁会话信息的消息容器
 	var message = &outputMessage{}
+	// Refuse to run while an earlier run of the key outlived its deadline and still runs in this process
+	// 本进程中该键的较早运行超过截止时间且仍在运行时拒绝运行
+	if processDetached.alive(suo.Key()) {
+		logger.ErrorLog("脱离执行未结束-拒绝运行", zap.String("k", suo.Key()))
+		return redissuo.NewError(redissuo.CodeDetachedRun, suo.ErrorLanguage(), nil)
+	}
+	run = guardDetached(suo.Key(), run, logger)
+	// Fail fast when too many goroutines of this process wait on the same key
+	// 当本进程中等待同一键的 goroutine 过多时快速失败
+	if !processWaiters.enter(suo.Key(), config.maxWaiters) {
+		logger.DebugLog("等待者过多-快速失败", zap.String("k", suo.Key()), zap.Int("max_waiters", config.maxWaiters))
+		return redissuo.NewError(redissuo.CodeTooManyWaiters, suo.ErrorLanguage(), nil)
+	}
 	// Retry lock acquisition until success or context cancellation
 	// 重试锁获取直到成功或上下文取消
-	if err := retryingAcquire(ctx, func(ctx context.Context) (bool, error) {
-		return acquireOnce(ctx, suo, sessionUUID, message)
-	}, sleep, logger); err != nil {
+	var waitStart = suo.Clock().Now()
+	var trace = config.newTrace(suo.Key(), waitStart)
+	var fairness = config.beginWait(suo.Key(), waitStart)
+	// Followers judge records against the one present at the start of the wait, both stamped on the Redis clock
+	// 跟随者依据等待开始时已存在的记录判断，两者都以 Redis 时钟标记
+	var baseline time.Time
+	if config.follower {
+		var err error
+		if baseline, err = followBaseline(ctx, suo, config.runID); err != nil {
+			processWaiters.leave(suo.Key(), config.maxWaiters)
+			return erero.Wro(err)
+		}
+	}
+	// Subscribe ahead of the first attempt, so a release racing with it still wakes the wait
+	// 在首次尝试之前订阅，使与其竞争的释放仍能唤醒等待
+	var wake <-chan struct{}
+	var sub *redissuo.ReleaseSubscription
+	if suo.ReleaseNotify() || suo.KeyspaceNotify() {
+		if sub, _ = suo.SubscribeRelease(ctx); sub != nil {
+			wake = sub.C()
+		}
+	}
+	// Fair locks hand the lock over in arrival sequence, so the wait goes through a queued waiter
+	// 公平锁按到达顺序交接锁，因此通过排队的等待者进行等待
+	var waiter *redissuo.Waiter
+	if suo.FairQueue() {
+		var err error
+		if waiter, err = suo.Enqueue(ctx); err != nil {
+			processWaiters.leave(suo.Key(), config.maxWaiters)
+			return erero.Wro(err)
+		}
+	}
+	// The wait span holds the acquire spans of each attempt
+	// 等待 span 包含每次尝试的获取 span
+	waitCtx, waitSpan := suo.StartSpan(ctx, redissuo.SpanWait, sessionUUID)
+	err := retryingAcquire(waitCtx, func(ctx context.Context) (bool, error) {
+		// Followers stop waiting once a holder recorded the outcome of the run
+		// 跟随者在持有者记录运行结果后停止等待
+		if config.follower {
+			if ok, err := followOnce(ctx, suo, config.runID, baseline, message); err != nil || ok {
+				return ok, err
+			}
+		}
+		var ok bool
+		var err error
+		if waiter != nil {
+			ok, err = acquireQueued(ctx, waiter, message)
+		} else {
+			ok, err = acquireOnce(ctx, suo, sessionUUID, message)
+		}
+		if err == nil && !ok {
+			fairness.busy(ctx, suo)
+		}
+		return ok, err
+	}, sleep, config.backoffOf(suo), config.retryHook(suo), suo.Clock(), logger, trace, config.newOutage(suo.Key()), wake)
+	if sub != nil {
+		_ = sub.Close() // The wait is over, the subscription goes with it // 等待结束，订阅随之关闭
+	}
+	if waiter != nil && message.xin == nil {
+		_ = waiter.Leave(context.WithoutCancel(ctx)) // Gave up waiting, the place goes to the next waiter // 放弃等待，位置让给下一个等待者
+	}
+	fairness.finish(suo.Clock().Now())
+	waitSpan.SetAttribute("lock.wait_ms", suo.Clock().Now().Sub(waitStart).Milliseconds())
+	switch {
+	case err != nil:
+		waitSpan.SetAttribute("lock.outcome", "error")
+	case message.followed != nil:
+		waitSpan.SetAttribute("lock.outcome", "followed")
+	default:
+		waitSpan.SetAttribute("lock.outcome", "acquired")
+	}
+	waitSpan.End(err)
+	err = config.finishTrace(trace, err)
+	processWaiters.leave(suo.Key(), config.maxWaiters)
+	if err != nil {
 		return erero.Wro(err) // Context issue occurred during acquisition // 获取过程中发生上下文错误
 	}
+	if message.followed != nil {
+		return followedOutcome(suo, message.followed, logger)
+	}
 
 	// Validate lock acquisition succeeded (guaranteed through retry logic)
 	// 验证锁获取成功（由重试逻辑保证）
@@ -71,18 +206,56 @@ func SuoLockXqt(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Con
 	// Ensure lock release regardless of business logic outcome
 	// 无论业务逻辑结果如何都确保释放锁
 	defer func() {
-		// Guaranteed lock cleanup with persistent retry
-		// 带持久重试的保证锁清理
+		// Guaranteed lock cleanup with persistent retry, counting attempts in the final statistics
+		// 带持久重试的保证锁清理，并在最终统计中计数尝试次数
+		var stats = &ReleaseStats{Key: suo.Key(), Session: message.xin.SessionUUID(), Extensions: message.xin.Extensions()}
 		retryingRelease(func() (bool, error) {
-			return releaseOnce(ctx, suo, message.xin, sleep)
-		}, sleep, logger)
+			stats.Attempts++
+			success, err := releaseOnce(ctx, suo, message.xin, sleep)
+			if err != nil {
+				stats.Err = err
+			}
+			return success, err
+		}, sleep, suo.Clock(), logger)
+		stats.HeldFor = suo.Clock().Now().Sub(message.xin.AcquiredAt())
+		config.runAfterRelease(stats)
 	}()
 
-	// Execute business logic within lock boundaries with timeout management
-	// Business must complete within remaining lock TTL duration
-	// 在锁边界内执行业务逻辑，带超时控制
-	// 业务必须在剩余锁 TTL 时间内完成
-	if err := execRun(ctx, run, time.Until(message.xin.Expire())); err != nil {
+	// The protected function reaches the lock and the session through suoctx
+	// 受保护函数通过 suoctx 获取锁和会话
+	run = sessionRun(suo, message.xin, run)
+	var erx error
+	var execute = func(ctx context.Context) error {
+		if config.runID != "" {
+			// Exactly-once runs check and write the execution record around the business logic
+			// 只执行一次的运行在业务逻辑前后检查并写入执行记录
+			return recordedRun(ctx, suo, message.xin, run, config, baseline)
+		}
+		// Execute business logic within lock boundaries with timeout management
+		// Business must complete within remaining lock TTL duration unless auto extension is on
+		// 在锁边界内执行业务逻辑，带超时控制
+		// 除非启用自动延期，业务必须在剩余锁 TTL 时间内完成
+		return config.runWithin(ctx, suo, message.xin, run)
+	}
+	// The run span covers the critical section, spans of the protected function nest under it
+	// 运行 span 覆盖临界区，受保护函数的 span 嵌套在其下
+	runCtx, runSpan := suo.StartSpan(ctx, redissuo.SpanRun, message.xin.SessionUUID())
+	if config.autoExtend {
+		// The watchdog keeps the lock while the run goes on
+		// 看门狗在运行期间保持锁
+		erx = extendedRun(runCtx, suo, message, config.extendInterval, execute)
+	} else {
+		erx = execute(runCtx)
+	}
+	if erx != nil {
+		runSpan.SetAttribute("lock.outcome", "error")
+	} else {
+		runSpan.SetAttribute("lock.outcome", "ok")
+	}
+	runSpan.End(erx)
+	// The barrier runs while the lock is still held, the deferred release comes after it
+	// 屏障在仍持有锁时运行，延迟的释放在其之后进行
+	if err := config.runBeforeRelease(ctx, suo, message.xin, erx); err != nil {
 		return erero.Wro(err)
 	}
 	return nil
@@ -96,7 +269,8 @@ func SuoLockXqt(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Con
 // 用于在获取和释放阶段之间传递锁会话信息
 // 确保整个执行生命周期中锁会话的一致性
 type outputMessage struct {
-	xin *redissuo.Xin // Acquired lock session // 已获取的锁会话
+	xin      *redissuo.Xin             // Acquired lock session // 已获取的锁会话
+	followed *redissuo.ExecutionRecord // Outcome recorded through the holder in follower mode // 跟随模式下由持有者记录的结果
 }
 
 // acquireOnce performs a single lock acquisition attempt with session UUID
@@ -112,6 +286,16 @@ func acquireOnce(ctx context.Context, suo *redissuo.Suo, sessionUUID string, out
 	// Attempt lock acquisition with predefined session UUID
 	// 使用预定义会话 UUID 尝试锁获取
 	xin, err := suo.AcquireLockWithSession(ctx, sessionUUID)
+	if errors.Is(err, redissuo.ErrAlreadyHeld) {
+		// An earlier attempt of this run got the lock but its reply got lost, the session is private to the run so reclaim it
+		// 本次运行的较早尝试已获得锁但回复丢失，该会话为本次运行私有，因此收回它
+		xin, err = suo.ReclaimLockWithSession(ctx, sessionUUID)
+	}
+	if errors.Is(err, redissuo.ErrLockHeld) {
+		// Typed error mode reports the held lock as a problem, the runner keeps waiting on it
+		// 类型化错误模式将锁被占用报告为错误，运行器继续等待
+		return false, nil
+	}
 	if err != nil {
 		return false, erero.Wro(err)
 	}
@@ -126,46 +310,116 @@ func acquireOnce(ctx context.Context, suo *redissuo.Suo, sessionUUID string, out
 	return false, nil
 }
 
+// acquireQueued performs a single lock acquisition attempt through the queued waiter of a fair lock
+// The waiter leaves the queue once it wins the lock
+//
+// acquireQueued 通过公平锁的排队等待者执行单次锁获取尝试
+// 等待者赢得锁后离开队列
+func acquireQueued(ctx context.Context, waiter *redissuo.Waiter, output *outputMessage) (bool, error) {
+	xin, err := waiter.Acquire(ctx)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	if xin != nil {
+		output.xin = xin
+		return true, nil
+	}
+	return false, nil
+}
+
 // retryingAcquire keeps attempting lock acquisition before success and context cancellation
 // Handles transient problems with growing backoff and context timeout detection
-// Returns nothing on completing acquisition, problems on context cancellation
+// The backoff gives the sleep after each missed attempt, duration bounds the time of each attempt
+// The retry hook sees each missed attempt ahead of the sleep, a problem from it ends the wait
+// Returns nothing on completing acquisition, an AcquireTimeoutError with the breakdown on context cancellation
 // Required achieving correct distributed lock coordination in high-contention scenarios
 //
 // retryingAcquire 持续重试锁获取直到成功或上下文取消
 // 使用指数退避和上下文超时检测处理瞬时错误
-// 成功获取时返回空值，上下文取消时返回错误
+// 退避给出每次未成功尝试后的休眠时长，duration 限定每次尝试的时长
+// 重试钩子在休眠之前看到每次未成功的尝试，其返回的错误会结束等待
+// 成功获取时返回空值，上下文取消时返回带明细的 AcquireTimeoutError
 // 对于高竞争场景中的可靠分布式锁协调至关重要
-func retryingAcquire(ctx context.Context, run func(ctx context.Context) (bool, error), duration time.Duration, logger logging.Logger) error {
+func retryingAcquire(ctx context.Context, run func(ctx context.Context) (bool, error), duration time.Duration, backoff func(attempt int) time.Duration, onRetry func(ctx context.Context, attempt int, err error) error, clock redissuo.Clock, logger logging.Logger, trace *AcquireTrace, outage *outageWatch, wake <-chan struct{}) error {
+	defer traceRegion(ctx, traceAcquireRegion)()
+	var startTime = clock.Now()
+	var breakdown = &AcquireTimeoutError{}
 	for {
 		// Check context cancellation and timeout
 		// 检查上下文取消或超时
 		if err := ctx.Err(); err != nil {
-			// Context problems prevent more Redis/database operations
-			// 上下文错误阻止进一步的 Redis/数据库操作
-			return erero.Wro(err)
+			// Context problems prevent more Redis/database operations, the breakdown goes with them
+			// 上下文错误阻止进一步的 Redis/数据库操作，并附带明细
+			breakdown.Err = err
+			breakdown.Waited = clock.Now().Sub(startTime)
+			return erero.Wro(breakdown)
 		}
 		// Attempt lock acquisition
 		// 尝试锁获取
-		success, err := run(ctx)
+		breakdown.Attempts++
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout(duration))
+		success, err := run(attemptCtx)
+		cancel()
+		if errors.Is(err, redissuo.ErrMaintenance) || errors.Is(err, redissuo.ErrPaused) || errors.Is(err, redissuo.ErrAdmissionDenied) || errors.Is(err, redissuo.ErrBudgetExhausted) {
+			// Maintenance freezes, fail-fast pauses, admission vetoes and fail-fast budgets are policy, fail fast instead of spinning through them
+			// 维护冻结、立即失败的暂停、准入否决和立即失败的预算属于策略，立即失败而不是空转
+			trace.add(clock.Now(), TraceFailed, 0, err)
+			return erero.Wro(err)
+		}
+		// The retry hook sees each missed attempt and may end the wait
+		// 重试钩子看到每次未成功的尝试，并可结束等待
+		if !success || err != nil {
+			if erh := callRetry(ctx, onRetry, breakdown.Attempts, err); erh != nil {
+				trace.add(clock.Now(), TraceFailed, 0, erh)
+				return erero.Wro(erh)
+			}
+		}
 		if err != nil {
-			// Log transient problems and reattempt following backoff
-			// 记录瞬时错误并在退避后重试
-			logger.DebugLog("wrong", zap.Error(err))
-			time.Sleep(duration)
+			// Log transient problems and reattempt following backoff, an outage pauses for its cool-down instead
+			// 记录瞬时错误并在退避后重试，故障期间改为按冷却时长暂停
+			sleep, quiet := outage.fail(clock.Now(), backoff(breakdown.Attempts-1), err)
+			if !quiet {
+				logger.DebugLog("wrong", zap.Error(err))
+			}
+			trace.add(clock.Now(), TraceFailed, sleep, err)
+			breakdown.Transient++
+			breakdown.Slept += sleep
+			clock.Sleep(sleep)
 			continue
 		}
+		outage.recover(clock.Now())
 		if success {
 			// Lock acquisition completed
 			// 锁成功获取
+			trace.add(clock.Now(), TraceAcquired, 0, nil)
 			return nil
 		}
 		// Lock unavailable, wait then reattempt
 		// 锁不可用，等待后重试
-		time.Sleep(duration)
+		sleep := backoff(breakdown.Attempts - 1)
+		trace.add(clock.Now(), TraceBusy, sleep, nil)
+		breakdown.Busy++
+		breakdown.Slept += sleep
+		pause(clock, sleep, wake)
 		continue
 	}
 }
 
+// pause waits the duration, cut short through a release notification when subscribed
+// pause 等待给定时长，订阅了释放通知时会因通知而提前结束
+func pause(clock redissuo.Clock, duration time.Duration, wake <-chan struct{}) {
+	if wake == nil {
+		clock.Sleep(duration)
+		return
+	}
+	timer := clock.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-wake:
+	case <-timer.C():
+	}
+}
+
 // releaseOnce performs a single lock release attempt with timeout protection
 // Creates safe context with minimum timeout ensuring release completion
 // Returns true on completing release, false if owned through a different session
@@ -184,6 +438,14 @@ func releaseOnce(ctx context.Context, suo *redissuo.Suo, xin *redissuo.Xin, slee
 	// Attempt lock release with session validation
 	// 尝试带会话验证的锁释放
 	success, err := suo.Release(ctx, xin)
+	if errors.Is(err, redissuo.ErrLockExpired) {
+		// Typed error mode reports the lapsed lock, it is gone all the same
+		// 类型化错误模式报告锁已失效，锁同样已不存在
+		return true, nil
+	}
+	if errors.Is(err, redissuo.ErrNotOwner) {
+		return false, nil
+	}
 	if err != nil {
 		return false, erero.Wro(err)
 	}
@@ -199,7 +461,7 @@ func releaseOnce(ctx context.Context, suo *redissuo.Suo, xin *redissuo.Xin, slee
 // 永不放弃锁清理以防止分布式系统中的资源泄漏
 // 使用持久重试逻辑处理瞬时错误和所有权变更
 // 对系统稳定性和防止死锁场景至关重要
-func retryingRelease(run func() (bool, error), duration time.Duration, logger logging.Logger) {
+func retryingRelease(run func() (bool, error), duration time.Duration, clock redissuo.Clock, logger logging.Logger) {
 	for {
 		// Attempt lock release
 		// 尝试锁释放
@@ -208,7 +470,7 @@ func retryingRelease(run func() (bool, error), duration time.Duration, logger lo
 			// Log problems and reattempt with backoff
 			// 记录错误并退避重试
 			logger.DebugLog("wrong", zap.Error(err))
-			time.Sleep(duration)
+			clock.Sleep(duration)
 			continue
 		}
 		if success {
@@ -218,7 +480,7 @@ func retryingRelease(run func() (bool, error), duration time.Duration, logger lo
 		}
 		// Release failed, wait then reattempt (persistent cleanup)
 		// 释放失败，等待后重试（持久清理）
-		time.Sleep(duration)
+		clock.Sleep(duration)
 		continue
 	}
 }
@@ -252,7 +514,7 @@ func safeCtx(ctx context.Context, duration time.Duration) (context.Context, cont
 // 基于剩余锁 TTL 创建超时上下文以进行安全执行
 // 委托给 safeRun 进行综合错误和 panic 处理
 // 确保业务逻辑在分布式锁边界内完成
-func execRun(ctx context.Context, run func(ctx context.Context) error, duration time.Duration) (err error) {
+func execRun(ctx context.Context, run func(ctx context.Context) error, duration time.Duration, language redissuo.Language) (err error) {
 	// Create timeout context based on remaining lock duration
 	// 基于剩余锁时长创建超时上下文
 	ctx, can := context.WithTimeout(ctx, duration)
@@ -260,7 +522,19 @@ func execRun(ctx context.Context, run func(ctx context.Context) error, duration
 
 	// Execute business logic with panic restore
 	// 执行带 panic 恢复的业务逻辑
-	return safeRun(ctx, run)
+	return safeRun(ctx, run, language)
+}
+
+// sessionRun wraps the run so its context carries the lock and the session, see package suoctx
+// The held section shows as an execution trace region
+//
+// sessionRun 包装运行，使其上下文携带锁和会话，参见 suoctx 包
+// 持有区段显示为执行追踪区域
+func sessionRun(suo *redissuo.Suo, xin *redissuo.Xin, run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		defer traceRegion(ctx, traceHeldRegion)()
+		return run(suoctx.WithSession(suoctx.WithSuo(ctx, suo), xin))
+	}
 }
 
 // safeRun executes function with comprehensive panic handling and problem conversion
@@ -272,20 +546,26 @@ func execRun(ctx context.Context, run func(ctx context.Context) error, duration
 // 捕获 panic 并将其转换为适当的错误类型以进行一致的错误处理
 // 返回函数的原始错误或转换的 panic 错误
 // 对于防止业务逻辑 panic 时的锁泄漏至关重要
-func safeRun(ctx context.Context, run func(ctx context.Context) error) (err error) {
+func safeRun(ctx context.Context, run func(ctx context.Context) error, language redissuo.Language) (err error) {
 	defer func() {
 		if rec := recover(); rec != nil {
-			// Convert panic to problem achieving consistent handling
-			// 将 panic 转换为错误以进行一致的错误处理
-			switch erx := rec.(type) {
-			case error:
-				err = erx
-			default:
-				err = erero.Errorf("错误(已从崩溃中恢复):%v", rec)
-			}
+			// Convert panic to coded problem achieving consistent handling
+			// 将 panic 转换为带错误码的错误以进行一致的错误处理
+			err = panicError(rec, language)
 		}
 	}()
 	// Execute business logic function
 	// 执行业务逻辑函数
 	return run(ctx)
 }
+
+// panicError converts a recovered panic into the coded problem of a crashed run
+// panicError 将恢复的 panic 转换为运行崩溃的带错误码的错误
+func panicError(rec any, language redissuo.Language) error {
+	switch erx := rec.(type) {
+	case error:
+		return redissuo.NewError(redissuo.CodePanicRecovered, language, erx)
+	default:
+		return redissuo.NewError(redissuo.CodePanicRecovered, language, fmt.Errorf("%v", rec))
+	}
+}
@@ -0,0 +1,43 @@
+package redissuorun
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReleaseStats is the final accounting of one run, handed over once the release completes
+// ReleaseStats 是单次运行的最终统计，在释放完成后交出
+type ReleaseStats struct {
+	Key        string        // Lock name ID // 锁名标识符
+	Session    string        // Session UUID of the hold // 持有的会话 UUID
+	HeldFor    time.Duration // Time from acquisition to the completed release // 从获取到释放完成的时长
+	Extensions int           // Extensions made across the hold // 持有期间的延期次数
+	Attempts   int           // Release attempts, more than 1 means problems got retried // 释放尝试次数，大于 1 表示有错误被重试
+	Err        error         // Last problem met while releasing, nil when none // 释放时遇到的最后一个错误，没有时为 nil
+}
+
+// WithAfterRelease registers a callback receiving the final statistics once the release completes
+// Attach business accounting here instead of wrapping the runner, panics in the callback are logged and dropped
+//
+// WithAfterRelease 注册在释放完成后接收最终统计的回调
+// 可在此附加业务统计而无需包装运行器，回调中的 panic 会被记录并丢弃
+func (c *Config) WithAfterRelease(callback func(stats *ReleaseStats)) *Config {
+	c.afterRelease = callback
+	return c
+}
+
+// runAfterRelease hands the statistics to the callback, a panic must not escape the deferred release
+// runAfterRelease 将统计交给回调，panic 不能逃逸出延迟的释放
+func (c *Config) runAfterRelease(stats *ReleaseStats) {
+	if c.afterRelease == nil {
+		return
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			c.logger.ErrorLog("释放后回调崩溃", zap.String("k", stats.Key), zap.String("panic", fmt.Sprint(rec)))
+		}
+	}()
+	c.afterRelease(stats)
+}
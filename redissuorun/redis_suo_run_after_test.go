@@ -0,0 +1,43 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_AfterRelease validates the callback receives the statistics once the lock is gone
+// TestSuoLockRunWithConfig_AfterRelease 验证回调在锁释放后接收统计
+func TestSuoLockRunWithConfig_AfterRelease(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	var session string
+	var stats *redissuorun.ReleaseStats
+	config := redissuorun.NewConfig(10 * time.Millisecond).WithAfterRelease(func(res *redissuorun.ReleaseStats) {
+		exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+		require.NoError(t, err)
+		require.Zero(t, exists)
+		stats = res
+		panic("callbacks must not break the runner")
+	})
+	err := redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		session, _ = caseRedisClient.Get(ctx, suo.Key()).Result()
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}, config)
+	require.NoError(t, err)
+
+	require.NotNil(t, stats)
+	require.Equal(t, suo.Key(), stats.Key)
+	require.Equal(t, session, stats.Session)
+	require.GreaterOrEqual(t, stats.HeldFor, 20*time.Millisecond)
+	require.Zero(t, stats.Extensions)
+	require.Equal(t, 1, stats.Attempts)
+	require.NoError(t, stats.Err)
+}
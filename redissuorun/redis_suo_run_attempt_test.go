@@ -0,0 +1,111 @@
+package redissuorun_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// hangHook hangs the first script until its context ends, like a stuck connection
+// hangHook 让第一个脚本一直挂起直到其上下文结束，模拟卡住的连接
+type hangHook struct {
+	hung atomic.Bool
+}
+
+func (h *hangHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *hangHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if (cmd.Name() == "eval" || cmd.Name() == "evalsha") && h.hung.CompareAndSwap(false, true) {
+			<-ctx.Done()
+			cmd.SetErr(ctx.Err())
+			return ctx.Err()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *hangHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// lostReplyHook lets the first script succeed on the server, then holds its reply until the context ends, like a reply lost after the grant
+// lostReplyHook 让第一个脚本在服务端成功执行，然后扣住其回复直到上下文结束，模拟授予之后丢失的回复
+type lostReplyHook struct {
+	lost atomic.Bool
+}
+
+func (h *lostReplyHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *lostReplyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := next(ctx, cmd); err != nil {
+			return err
+		}
+		if (cmd.Name() == "eval" || cmd.Name() == "evalsha") && h.lost.CompareAndSwap(false, true) {
+			<-ctx.Done()
+			cmd.SetErr(ctx.Err())
+			return ctx.Err()
+		}
+		return nil
+	}
+}
+
+func (h *lostReplyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// TestSuoLockRun_AttemptTimeout validates a hung attempt times out alone and the wait goes on
+// TestSuoLockRun_AttemptTimeout 验证挂起的尝试单独超时，等待继续进行
+func TestSuoLockRun_AttemptTimeout(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: caseRedisClient.(*redis.Client).Options().Addr,
+	})
+	defer func() { _ = redisClient.Close() }()
+	redisClient.AddHook(&hangHook{})
+
+	suo := redissuo.NewSuo(redisClient, utils.NewUUID(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var ran bool
+	err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ran)
+}
+
+// TestSuoLockRun_LostReplyStrict validates a strict run reclaims the lock its timed out attempt got, instead of spinning on its own orphan
+// TestSuoLockRun_LostReplyStrict 验证严格模式的运行收回超时尝试已获得的锁，而不是在自身遗留的锁上空转
+func TestSuoLockRun_LostReplyStrict(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: caseRedisClient.(*redis.Client).Options().Addr,
+	})
+	defer func() { _ = redisClient.Close() }()
+	redisClient.AddHook(&lostReplyHook{})
+
+	suo := redissuo.NewSuo(redisClient, utils.NewUUID(), 10*time.Second).WithStrict(true)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var ran bool
+	err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	exists, err := redisClient.Exists(context.Background(), suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
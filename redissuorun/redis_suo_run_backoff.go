@@ -0,0 +1,78 @@
+package redissuorun
+
+import (
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/must"
+)
+
+// defaultBackoffGrowth bounds the default backoff ceiling as a multiple of the sleep between attempts
+// defaultBackoffGrowth 以尝试间隔的倍数限定默认退避上限
+const defaultBackoffGrowth = 8
+
+// Backoff gives back the sleep ahead of the next attempt, attempt counts the missed attempts of the wait from 0
+// The random source comes from the lock, so seeded locks give repeatable waits
+//
+// Backoff 返回下次尝试前的休眠时长，attempt 从 0 开始计数本次等待中未成功的尝试
+// 随机源来自锁，因此设定种子的锁可得到可重复的等待
+type Backoff func(attempt int, random redissuo.Random) time.Duration
+
+// ExponentialBackoff doubles the ceiling each missed attempt from floor up to ceiling, sleeping a random span between floor and it
+// The full jitter spreads waiters contending on one key, so they do not retry in lockstep
+//
+// ExponentialBackoff 每次未成功的尝试将上限从 floor 翻倍直至 ceiling，并在 floor 与该上限之间随机休眠
+// 完全抖动使争用同一键的等待者分散开，不会同步重试
+func ExponentialBackoff(floor time.Duration, ceiling time.Duration) Backoff {
+	must.Nice(floor)
+	must.True(ceiling >= floor)
+	return func(attempt int, random redissuo.Random) time.Duration {
+		limit := floor
+		for step := 0; step < attempt && limit < ceiling; step++ {
+			limit *= 2
+		}
+		limit = min(limit, ceiling)
+		if limit == floor {
+			return floor
+		}
+		return floor + time.Duration(random.Int63n(int64(limit-floor)+1))
+	}
+}
+
+// ConstantBackoff sleeps the same span between each attempt, the way the runner waited before backoff existed
+// ConstantBackoff 每次尝试之间休眠相同时长，即引入退避之前运行器的等待方式
+func ConstantBackoff(sleep time.Duration) Backoff {
+	must.Nice(sleep)
+	return func(attempt int, random redissuo.Random) time.Duration {
+		return sleep
+	}
+}
+
+// WithBackoff sets the backoff between acquisition attempts
+// Unset, the backoff grows exponentially with full jitter from the sleep up to 8 times it
+//
+// WithBackoff 设置获取尝试之间的退避
+// 未设置时，退避以完全抖动从休眠时长指数增长至其 8 倍
+func (c *Config) WithBackoff(backoff Backoff) *Config {
+	must.True(backoff != nil)
+	c.backoff = backoff
+	return c
+}
+
+// backoffOf binds the backoff of the config to the random source of the lock
+// backoffOf 将配置的退避与锁的随机源绑定
+func (c *Config) backoffOf(suo *redissuo.Suo) func(attempt int) time.Duration {
+	return c.backoffWith(suo.Random())
+}
+
+// backoffWith binds the backoff of the config to the random source
+// backoffWith 将配置的退避与随机源绑定
+func (c *Config) backoffWith(random redissuo.Random) func(attempt int) time.Duration {
+	backoff := c.backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(c.sleep, defaultBackoffGrowth*c.sleep)
+	}
+	return func(attempt int) time.Duration {
+		return backoff(attempt, random)
+	}
+}
@@ -0,0 +1,68 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExponentialBackoff validates the sleep grows within the floor and the doubled ceiling and stops at the cap
+// TestExponentialBackoff 验证休眠时长在下限与翻倍上限之间增长并止于封顶值
+func TestExponentialBackoff(t *testing.T) {
+	backoff := redissuorun.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	random := redissuo.NewSeededRandom(1)
+
+	require.Equal(t, 10*time.Millisecond, backoff(0, random))
+	for attempt := 1; attempt < 10; attempt++ {
+		sleep := backoff(attempt, random)
+		require.GreaterOrEqual(t, sleep, 10*time.Millisecond)
+		require.LessOrEqual(t, sleep, min(10*time.Millisecond<<attempt, 50*time.Millisecond))
+	}
+
+	// The same seed gives the same sleeps
+	// 相同的种子给出相同的休眠时长
+	first, second := redissuo.NewSeededRandom(7), redissuo.NewSeededRandom(7)
+	for attempt := 0; attempt < 5; attempt++ {
+		require.Equal(t, backoff(attempt, first), backoff(attempt, second))
+	}
+}
+
+// TestSuoLockRunWithConfig_Backoff validates the busy sleeps of a wait follow the configured backoff
+// TestSuoLockRunWithConfig_Backoff 验证等待中的占用休眠遵循配置的退避
+func TestSuoLockRunWithConfig_Backoff(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	var attempts []int
+	config := redissuorun.NewConfig(5 * time.Millisecond).WithBackoff(func(attempt int, random redissuo.Random) time.Duration {
+		attempts = append(attempts, attempt)
+		return 20 * time.Millisecond
+	})
+	timeoutCtx, cancel := context.WithTimeout(ctx, 70*time.Millisecond)
+	defer cancel()
+	err = redissuorun.SuoLockRunWithConfig(timeoutCtx, suo, func(ctx context.Context) error {
+		return nil
+	}, config)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeoutErr *redissuorun.AcquireTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	require.Equal(t, time.Duration(timeoutErr.Busy)*20*time.Millisecond, timeoutErr.Slept)
+	require.Len(t, attempts, timeoutErr.Busy)
+	for idx, attempt := range attempts {
+		require.Equal(t, idx, attempt)
+	}
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,47 @@
+package redissuorun
+
+import (
+	"context"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// BeforeRelease runs past the protected function while the lock is still held
+// It receives the outcome of the run, e.g. to flush buffers or publish a completion marker just on success
+//
+// BeforeRelease 在受保护函数之后、仍持有锁时运行
+// 它接收运行结果，例如仅在成功时刷新缓冲区或发布完成标记
+type BeforeRelease func(ctx context.Context, xin *redissuo.Xin, erx error) error
+
+// WithBeforeRelease registers a barrier running after the run and ahead of the release
+// With abort set, a failing barrier turns a successful run into a failure, otherwise its problem just gets logged
+// A failed run keeps its own problem either way
+//
+// WithBeforeRelease 注册在运行之后、释放之前执行的屏障
+// 设置 abort 时，屏障失败会使成功的运行变为失败，否则仅记录其错误
+// 运行失败时无论如何都保留运行自身的错误
+func (c *Config) WithBeforeRelease(barrier BeforeRelease, abort bool) *Config {
+	c.beforeRelease = barrier
+	c.abortOnBarrier = abort
+	return c
+}
+
+// runBeforeRelease runs the barrier within the remaining lease and settles the outcome of the run
+// runBeforeRelease 在剩余租期内运行屏障并确定运行的最终结果
+func (c *Config) runBeforeRelease(ctx context.Context, suo *redissuo.Suo, xin *redissuo.Xin, erx error) error {
+	if c.beforeRelease == nil {
+		return erx
+	}
+	err := execRun(ctx, func(ctx context.Context) error {
+		return c.beforeRelease(ctx, xin, erx)
+	}, xin.Expire().Sub(suo.Clock().Now()), suo.ErrorLanguage())
+	if err != nil {
+		c.logger.ErrorLog("释放前屏障报错", zap.String("k", suo.Key()), zap.Bool("abort", c.abortOnBarrier), zap.Error(err))
+		if erx == nil && c.abortOnBarrier {
+			return erero.Wro(err)
+		}
+	}
+	return erx
+}
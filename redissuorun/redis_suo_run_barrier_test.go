@@ -0,0 +1,44 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_BeforeRelease validates the barrier runs while the lock is held and may abort the success
+// TestSuoLockRunWithConfig_BeforeRelease 验证屏障在持有锁时运行，并可以使成功结果失败
+func TestSuoLockRunWithConfig_BeforeRelease(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	flushErr := errors.New("flush failed")
+
+	var holders []string
+	barrier := func(ctx context.Context, xin *redissuo.Xin, erx error) error {
+		require.NoError(t, erx)
+		holder, err := caseRedisClient.Get(ctx, suo.Key()).Result()
+		require.NoError(t, err)
+		require.Equal(t, xin.SessionUUID(), holder)
+		holders = append(holders, holder)
+		return flushErr
+	}
+	run := func(ctx context.Context) error { return nil }
+
+	err := redissuorun.SuoLockRunWithConfig(ctx, suo, run, redissuorun.NewConfig(10*time.Millisecond).WithBeforeRelease(barrier, false))
+	require.NoError(t, err)
+
+	err = redissuorun.SuoLockRunWithConfig(ctx, suo, run, redissuorun.NewConfig(10*time.Millisecond).WithBeforeRelease(barrier, true))
+	require.ErrorIs(t, err, flushErr)
+	require.Len(t, holders, 2)
+
+	// The lock got released past the barrier
+	exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
@@ -0,0 +1,113 @@
+package redissuorun
+
+import (
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/must"
+	"github.com/yyle88/zaplog"
+)
+
+// Config holds the settings of SuoLockRunWithConfig
+// Created through NewConfig and adjusted through chained With* methods
+//
+// Config 保存 SuoLockRunWithConfig 的设置
+// 通过 NewConfig 创建并通过链式 With* 方法调整
+type Config struct {
+	sleep           time.Duration             // Wait between acquisition attempts // 获取尝试之间的等待时间
+	logger          logging.Logger            // Logger instance used in operations // 操作中使用的日志记录器实例
+	maxWaiters      int                       // Max goroutines waiting on one key in this process, 0 means unlimited // 本进程中等待同一键的最大 goroutine 数，0 表示不限制
+	style           *redissuo.LogStyle        // Field keys and message language of logs // 日志的字段键和消息语言
+	runID           string                    // Logical run ID of the execution record, blank when disabled // 执行记录的逻辑运行标识，为空时禁用
+	retention       time.Duration             // Retention of the execution record, 0 means forever // 执行记录的保留时长，0 表示永久
+	follower        bool                      // Wait on the holder's record instead of running again // 等待持有者的记录而非再次运行
+	resultHash      func() string             // Digest of the result written into the record, nil when not tracked // 写入记录的结果摘要，未跟踪时为空
+	traceLimit      int                       // Max attempts kept in the acquisition trace, 0 means disabled // 获取追踪中保留的最大尝试数，0 表示禁用
+	onTrace         func(trace *AcquireTrace) // Receives the trace of each wait, nil when unset // 接收每次等待的追踪记录，未设置时为空
+	beforeRelease   BeforeRelease             // Barrier ahead of the release, nil when unset // 释放之前的屏障，未设置时为空
+	abortOnBarrier  bool                      // Barrier failure fails a successful run // 屏障失败使成功的运行失败
+	afterRelease    func(stats *ReleaseStats) // Receives the final statistics, nil when unset // 接收最终统计，未设置时为空
+	fairness        *FairnessTracker          // Tracks per-key fairness, nil when disabled // 追踪逐键公平性，为空时禁用
+	autoExtend      bool                      // Extend the lock while the run goes on // 在运行期间延期锁
+	extendInterval  time.Duration             // Gap between auto extensions, 0 means a third of the TTL // 自动延期的间隔，0 表示 TTL 的三分之一
+	flight          string                    // Purpose shared by concurrent calls in this process, blank when disabled // 本进程中并发调用共享的用途，为空时禁用
+	outageThreshold int                       // Problems in a row pausing the polling, 0 means disabled // 暂停轮询的连续错误数，0 表示禁用
+	outageCoolDown  time.Duration             // Pause between attempts during an outage // 故障期间尝试之间的暂停时长
+	hooks           *redissuo.Hooks           // Lifecycle hooks of the runner, nil means the hooks of the lock // 运行器的生命周期钩子，为空时使用锁的钩子
+	backoff         Backoff                   // Sleep between acquisition attempts, nil means exponential with full jitter // 获取尝试之间的休眠，为空时为带完全抖动的指数退避
+}
+
+// NewConfig creates a config using the given sleep between acquisition attempts
+// NewConfig 使用给定的获取尝试间隔创建配置
+func NewConfig(sleep time.Duration) *Config {
+	return &Config{
+		sleep:  must.Nice(sleep),
+		logger: logging.NewZapLogger(zaplog.LOGS.Skip(1)),
+	}
+}
+
+// WithLogger sets custom logger used in lock operations
+// WithLogger 为锁操作设置自定义日志记录器
+func (c *Config) WithLogger(logger logging.Logger) *Config {
+	c.logger = c.style.Wrap(logger)
+	return c
+}
+
+// WithLogStyle sets field keys and message language of the runner logs
+// WithLogStyle 设置运行器日志的字段键和消息语言
+func (c *Config) WithLogStyle(style *redissuo.LogStyle) *Config {
+	c.style = style
+	c.logger = style.Wrap(c.logger)
+	return c
+}
+
+// WithMaxWaiters limits how many goroutines of this process may wait on the same key at once
+// Extra callers fail fast with ErrTooManyWaiters, preventing pileups when a lock gets stuck
+//
+// WithMaxWaiters 限制本进程中可同时等待同一键的 goroutine 数量
+// 超出的调用方以 ErrTooManyWaiters 快速失败，避免锁卡住时的 goroutine 堆积
+func (c *Config) WithMaxWaiters(maxWaiters int) *Config {
+	c.maxWaiters = maxWaiters
+	return c
+}
+
+// WithExecutionRecord makes the run exactly-once per run ID across holders
+// A run already recorded as succeeded gets skipped, otherwise the outcome is recorded before release
+// Retention bounds how long the record is kept, 0 keeps it forever
+//
+// WithExecutionRecord 使每个运行标识在各持有者之间只执行一次
+// 已记录为成功的运行会被跳过，否则在释放前记录运行结果
+// 保留时长限制记录的保存时间，0 表示永久保存
+func (c *Config) WithExecutionRecord(runID string, retention time.Duration) *Config {
+	c.runID = must.Nice(runID)
+	c.retention = retention
+	return c
+}
+
+// WithFollowers makes callers that miss the lock wait for the holder's execution record and return its outcome
+// N concurrent callers then see one execution, the holder's failure comes back as ErrFollowedRunFailed
+// Requires WithExecutionRecord, a follower still takes over when the lock frees up without a record
+//
+// WithFollowers 使未获取到锁的调用方等待持有者的执行记录并返回其结果
+// 这样 N 个并发调用方只会看到一次执行，持有者的失败以 ErrFollowedRunFailed 返回
+// 需要先设置 WithExecutionRecord，锁在没有记录的情况下空闲时跟随者仍会接管执行
+func (c *Config) WithFollowers(enable bool) *Config {
+	must.OK(c.runID) // Requires WithExecutionRecord // 需要启用 WithExecutionRecord
+	c.follower = enable
+	return c
+}
+
+// WithResultHash sets the digest of the result written into the execution record once the run succeeds
+// The function gets called past a successful run, so it may read what the run stored, e.g. through a captured variable
+// Requires WithExecutionRecord
+//
+// WithResultHash 设置运行成功后写入执行记录的结果摘要
+// 该函数在运行成功之后调用，因此可以读取运行保存的内容，例如通过捕获的变量
+// 需要先设置 WithExecutionRecord
+func (c *Config) WithResultHash(hash func() string) *Config {
+	must.OK(c.runID) // Requires WithExecutionRecord // 需要启用 WithExecutionRecord
+	must.True(hash != nil)
+	c.resultHash = hash
+	return c
+}
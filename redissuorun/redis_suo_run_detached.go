@@ -0,0 +1,116 @@
+package redissuorun
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"go.uber.org/zap"
+)
+
+// ErrDetachedRun is returned when a run of the key outlived its deadline in this process and has not finished yet
+// ErrDetachedRun 在本进程中该键的某次运行超过截止时间且尚未结束时返回
+var ErrDetachedRun = redissuo.NewError(redissuo.CodeDetachedRun, redissuo.LanguageEnglish, nil)
+
+const (
+	// detachedWarnInterval is the gap between warnings while a detached run lives
+	// detachedWarnInterval 是脱离运行存活期间告警之间的间隔
+	detachedWarnInterval = 10 * time.Second
+	// detachedGrace is the time a run gets past its deadline to return before it counts as detached
+	// detachedGrace 是运行超过截止时间后返回的宽限时长，超过后才视为脱离运行
+	detachedGrace = 100 * time.Millisecond
+)
+
+// processDetached tracks runs of this process that outlived their deadline
+// processDetached 跟踪本进程中超过截止时间的运行
+var processDetached = &detachedBoard{counts: map[string]int{}}
+
+// detachedBoard counts detached runs per key
+// detachedBoard 统计每个键的脱离运行数量
+type detachedBoard struct {
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+// alive reports whether a detached run of the key still lives
+// alive 返回该键是否仍有存活的脱离运行
+func (b *detachedBoard) alive(key string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.counts[key] > 0
+}
+
+// detach registers a run of the key that outlived its deadline
+// detach 登记该键的一次超过截止时间的运行
+func (b *detachedBoard) detach(key string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.counts[key]++
+}
+
+// finish unregisters a detached run once it returns
+// finish 在脱离运行返回后注销它
+func (b *detachedBoard) finish(key string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.counts[key]--; b.counts[key] <= 0 {
+		delete(b.counts, key)
+	}
+}
+
+// guardDetached wraps the run so it counts as detached once it keeps running past its deadline and the grace period
+// Go cannot stop a goroutine ignoring its context, past the deadline the lease may lapse and another holder may run
+// Warnings keep coming while it lives, and new runs of the key in this process get refused until it returns
+// A plain cancellation, e.g. the caller giving up, leaves the lease intact and never counts as detached
+//
+// guardDetached 包装运行，使其在超过截止时间和宽限时长后仍在运行时被视为脱离运行
+// Go 无法终止忽略上下文的 goroutine，超过截止时间后租期可能失效，其它持有者可能开始运行
+// 其存活期间持续告警，本进程中该键的新运行在其返回前均被拒绝
+// 普通的取消（例如调用方放弃）不影响租期，从不视为脱离运行
+func guardDetached(key string, run func(ctx context.Context) error, logger logging.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var done = make(chan struct{})
+		var watched = make(chan struct{})
+		go func() {
+			defer close(watched)
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+			}
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return
+			}
+			var grace = time.NewTimer(detachedGrace)
+			defer grace.Stop()
+			select {
+			case <-done:
+				return
+			case <-grace.C:
+			}
+			processDetached.detach(key)
+			defer processDetached.finish(key)
+			var start = time.Now()
+			logger.ErrorLog("运行超时-脱离执行", zap.String("k", key), zap.Error(ctx.Err()))
+			var ticker = time.NewTicker(detachedWarnInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					logger.ErrorLog("脱离执行-已结束", zap.String("k", key), zap.Duration("detached", time.Since(start)))
+					return
+				case <-ticker.C:
+					logger.ErrorLog("脱离执行-仍在运行", zap.String("k", key), zap.Duration("detached", time.Since(start)))
+				}
+			}
+		}()
+		defer func() {
+			close(done)
+			<-watched // Unregistered before the runner moves on // 在运行器继续之前完成注销
+		}()
+		return run(ctx)
+	}
+}
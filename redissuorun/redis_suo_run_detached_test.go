@@ -0,0 +1,80 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRun_Detached validates new runs of the key are refused while a run ignoring its deadline still lives
+// TestSuoLockRun_Detached 验证忽略截止时间的运行存活期间，该键的新运行被拒绝
+func TestSuoLockRun_Detached(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 50*time.Millisecond)
+
+	started := make(chan struct{})
+	finished := make(chan error, 1)
+	go func() {
+		finished <- redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+			close(started)
+			time.Sleep(400 * time.Millisecond) // Ignores the context on purpose
+			return nil
+		}, 10*time.Millisecond)
+	}()
+	<-started
+	time.Sleep(250 * time.Millisecond) // Past the deadline and the grace period of the first run
+
+	err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		t.Fatal("must not run while the detached run lives")
+		return nil
+	}, 10*time.Millisecond)
+	require.ErrorIs(t, err, redissuorun.ErrDetachedRun)
+
+	require.NoError(t, <-finished)
+
+	var ran bool
+	require.NoError(t, redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, 10*time.Millisecond))
+	require.True(t, ran)
+}
+
+// TestSuoLockRun_Detached_Cancelled validates a run outliving a plain cancellation does not count as detached
+// The lease stays intact, so new runs of the key wait on the lock instead of getting ErrDetachedRun
+//
+// TestSuoLockRun_Detached_Cancelled 验证在普通取消之后仍在运行的运行不被视为脱离运行
+// 租期保持完好，因此该键的新运行等待锁而不是得到 ErrDetachedRun
+func TestSuoLockRun_Detached_Cancelled(t *testing.T) {
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	finished := make(chan error, 1)
+	go func() {
+		finished <- redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+			close(started)
+			time.Sleep(400 * time.Millisecond) // Ignores the context on purpose
+			return nil
+		}, 10*time.Millisecond)
+	}()
+	<-started
+	cancel()
+	time.Sleep(250 * time.Millisecond) // Past the grace period of the first run
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	err := redissuorun.SuoLockRun(waitCtx, suo, func(ctx context.Context) error {
+		t.Fatal("must not run while the first run holds the lock")
+		return nil
+	}, 10*time.Millisecond)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, redissuorun.ErrDetachedRun)
+
+	<-finished
+}
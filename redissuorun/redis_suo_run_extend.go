@@ -0,0 +1,50 @@
+package redissuorun
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/must"
+)
+
+// SuoLockRunWithExtend executes a function within a distributed lock kept alive while the function runs
+// Unlike SuoLockRun the function is not bounded through the TTL, its context ends just once an extension fails
+//
+// SuoLockRunWithExtend 在函数运行期间持续续期的分布式锁内执行函数
+// 与 SuoLockRun 不同，函数不受 TTL 限制，其上下文仅在延期失败时结束
+func SuoLockRunWithExtend(ctx context.Context, suo *redissuo.Suo, run func(ctx context.Context) error, sleep time.Duration) error {
+	return SuoLockRunWithConfig(ctx, suo, run, NewConfig(sleep).WithAutoExtend(0))
+}
+
+// WithAutoExtend extends the lock at the interval while the run goes on, 0 picks a third of the TTL
+// The run context gets cancelled with redissuo.ErrLockLost as cause once an extension is refused or the lease lapses
+//
+// WithAutoExtend 在运行期间按该间隔延期锁，0 表示使用 TTL 的三分之一
+// 延期被拒绝或租期耗尽时，运行上下文以 redissuo.ErrLockLost 为原因被取消
+func (c *Config) WithAutoExtend(interval time.Duration) *Config {
+	must.True(interval >= 0)
+	c.autoExtend = true
+	c.extendInterval = interval
+	return c
+}
+
+// runWithin runs the business logic, bounded through the remaining lease unless the lock gets extended along the way
+// runWithin 执行业务逻辑，除非运行期间自动延期，否则受剩余租期限制
+func (c *Config) runWithin(ctx context.Context, suo *redissuo.Suo, xin *redissuo.Xin, run func(ctx context.Context) error) error {
+	if c.autoExtend {
+		return safeRun(ctx, run, suo.ErrorLanguage())
+	}
+	return execRun(ctx, run, xin.Expire().Sub(suo.Clock().Now()), suo.ErrorLanguage())
+}
+
+// extendedRun runs while a watchdog extends the session, storing the latest session into the message
+// extendedRun 在看门狗延期会话的同时运行，并将最新的会话存入消息
+func extendedRun(ctx context.Context, suo *redissuo.Suo, message *outputMessage, interval time.Duration, run func(ctx context.Context) error) error {
+	holdCtx, cancel := suo.HoldContext(ctx, message.xin)
+	defer cancel()
+	keepAlive := suo.KeepAlive(ctx, message.xin, interval)
+	erx := run(holdCtx)
+	message.xin, _ = keepAlive.Stop()
+	return erx
+}
@@ -0,0 +1,60 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithExtend validates a run longer than the TTL keeps the lock and its context
+// TestSuoLockRunWithExtend 验证运行时长超过 TTL 时仍保持锁及其上下文
+func TestSuoLockRunWithExtend(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 90*time.Millisecond)
+
+	var extensions int
+	config := redissuorun.NewConfig(10 * time.Millisecond).WithAutoExtend(0).WithAfterRelease(func(stats *redissuorun.ReleaseStats) {
+		extensions = stats.Extensions
+	})
+	err := redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		time.Sleep(250 * time.Millisecond)
+		require.NoError(t, ctx.Err())
+		holder, err := suo.Holder(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, holder)
+		return nil
+	}, config)
+	require.NoError(t, err)
+	require.Greater(t, extensions, 0)
+
+	exists, err := caseRedisClient.Exists(ctx, suo.Key()).Result()
+	require.NoError(t, err)
+	require.Zero(t, exists)
+}
+
+// TestSuoLockRunWithExtend_LockLost validates the run context ends with ErrLockLost once an extension is refused
+// TestSuoLockRunWithExtend_LockLost 验证延期被拒绝后运行上下文以 ErrLockLost 结束
+func TestSuoLockRunWithExtend_LockLost(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	config := redissuorun.NewConfig(10 * time.Millisecond).WithAutoExtend(20 * time.Millisecond)
+	err := redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		require.NoError(t, caseRedisClient.Set(ctx, suo.Key(), "usurper", 5*time.Second).Err())
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("the run context must end once the lock is lost")
+		}
+		require.ErrorIs(t, context.Cause(ctx), redissuo.ErrLockLost)
+		// The usurper finishes, letting the release go through
+		require.NoError(t, caseRedisClient.Del(context.Background(), suo.Key()).Err())
+		return context.Cause(ctx)
+	}, config)
+	require.ErrorIs(t, err, redissuo.ErrLockLost)
+}
@@ -0,0 +1,49 @@
+package redissuorun_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRun_FairQueue validates runners of a fair lock run in arrival sequence
+// TestSuoLockRun_FairQueue 验证公平锁的运行器按到达顺序运行
+func TestSuoLockRun_FairQueue(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithFairQueue(true)
+
+	// Hold the lock while the runners line up one by one
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	var mutex sync.Mutex
+	var sequence []int
+	var wg sync.WaitGroup
+	for idx := 0; idx < 3; idx++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+				mutex.Lock()
+				sequence = append(sequence, idx)
+				mutex.Unlock()
+				return nil
+			}, 5*time.Millisecond)
+			require.NoError(t, err)
+		}(idx)
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	wg.Wait()
+	require.Equal(t, []int{0, 1, 2}, sequence)
+}
@@ -0,0 +1,156 @@
+package redissuorun
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/must"
+)
+
+// FairnessStats are the fairness indicators of one key across the waits seen by the tracker
+// A high variance or any starvation hints the key deserves the fair queue
+//
+// FairnessStats 是追踪器观察到的单个键在各次等待中的公平性指标
+// 方差较大或出现饥饿时，提示该键应使用公平队列
+type FairnessStats struct {
+	Key          string        // Lock name ID // 锁名标识符
+	Waits        int           // Waits observed, acquired or given up // 观察到的等待次数，包括获取成功和放弃
+	MaxWait      time.Duration // Longest wait observed // 观察到的最长等待
+	MeanWait     time.Duration // Mean wait // 平均等待
+	WaitVariance float64       // Variance of the waits in seconds squared // 等待时长的方差，单位为秒的平方
+	MaxHandoffs  int           // Most handoffs one waiter sat through // 单个等待者经历的最多交接次数
+	Starvations  int           // Waits sitting through more handoffs than the starvation limit // 经历的交接次数超过饥饿上限的等待次数
+}
+
+// fairnessTally accumulates the waits of one key, the variance through Welford's method
+// fairnessTally 累计单个键的等待，方差使用 Welford 方法计算
+type fairnessTally struct {
+	stats FairnessStats
+	mean  float64 // Running mean in seconds // 以秒计的累计均值
+	m2    float64 // Running sum of squared deviations // 累计偏差平方和
+}
+
+// FairnessTracker tracks per-key fairness of the waits of the runner
+// A handoff is the lock changing holder while a waiter keeps missing it, read through one GET on each busy attempt
+//
+// FairnessTracker 追踪运行器等待的逐键公平性
+// 交接指等待者持续未获取到锁期间锁更换了持有者，在每次忙碌尝试时通过一次 GET 读取
+type FairnessTracker struct {
+	starvation int                       // Handoffs past which a wait counts as starved // 超过该交接次数的等待视为饥饿
+	mutex      sync.Mutex                // Protects tallies // 保护 tallies
+	tallies    map[string]*fairnessTally // Tally of each key // 每个键的统计
+}
+
+// NewFairnessTracker creates a tracker counting a wait as starved once it sits through more than the given handoffs
+// NewFairnessTracker 创建追踪器，等待经历的交接次数超过给定值时计为饥饿
+func NewFairnessTracker(starvation int) *FairnessTracker {
+	must.True(starvation >= 0)
+	return &FairnessTracker{starvation: starvation, tallies: map[string]*fairnessTally{}}
+}
+
+// Stats gets back the indicators of the key, zero values when no wait was seen
+// Stats 返回该键的指标，未观察到等待时为零值
+func (f *FairnessTracker) Stats(key string) FairnessStats {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if tally, ok := f.tallies[key]; ok {
+		return tally.stats
+	}
+	return FairnessStats{Key: key}
+}
+
+// Keys gets back the tracked keys in sorted sequence
+// Keys 按排序返回被追踪的键
+func (f *FairnessTracker) Keys() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	keys := make([]string, 0, len(f.tallies))
+	for key := range f.tallies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// observe folds one finished wait into the tally of the key
+// observe 将一次结束的等待计入该键的统计
+func (f *FairnessTracker) observe(key string, waited time.Duration, handoffs int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	tally, ok := f.tallies[key]
+	if !ok {
+		tally = &fairnessTally{stats: FairnessStats{Key: key}}
+		f.tallies[key] = tally
+	}
+	stats := &tally.stats
+	stats.Waits++
+	stats.MaxWait = max(stats.MaxWait, waited)
+	stats.MaxHandoffs = max(stats.MaxHandoffs, handoffs)
+	if handoffs > f.starvation {
+		stats.Starvations++
+	}
+	seconds := waited.Seconds()
+	delta := seconds - tally.mean
+	tally.mean += delta / float64(stats.Waits)
+	tally.m2 += delta * (seconds - tally.mean)
+	stats.MeanWait = time.Duration(math.Round(tally.mean * float64(time.Second)))
+	stats.WaitVariance = tally.m2 / float64(stats.Waits)
+}
+
+// fairnessWait follows the holders seen through one wait
+// fairnessWait 跟踪一次等待中看到的持有者
+type fairnessWait struct {
+	tracker  *FairnessTracker // Receives the finished wait // 接收结束的等待
+	key      string           // Lock name ID // 锁名标识符
+	start    time.Time        // Start of the wait // 等待开始时间
+	holder   string           // Holder seen last, blank before the first // 最近看到的持有者，首次之前为空
+	handoffs int              // Holder changes seen // 看到的持有者变更次数
+}
+
+// WithFairness tracks the fairness of each wait into the tracker
+// Costs one extra GET on each busy attempt, share one tracker across runs of the same keys
+//
+// WithFairness 将每次等待的公平性记录到追踪器中
+// 每次忙碌尝试多一次 GET，同一批键的多次运行应共享一个追踪器
+func (c *Config) WithFairness(tracker *FairnessTracker) *Config {
+	c.fairness = tracker
+	return c
+}
+
+// beginWait starts following one wait, nil when fairness is not tracked
+// beginWait 开始跟踪一次等待，未追踪公平性时返回 nil
+func (c *Config) beginWait(key string, start time.Time) *fairnessWait {
+	if c.fairness == nil {
+		return nil
+	}
+	return &fairnessWait{tracker: c.fairness, key: key, start: start}
+}
+
+// busy notes the present holder after a busy attempt, nil-safe
+// busy 在忙碌尝试之后记录当前持有者，对 nil 安全
+func (w *fairnessWait) busy(ctx context.Context, suo *redissuo.Suo) {
+	if w == nil {
+		return
+	}
+	holder, err := suo.Holder(ctx)
+	if err != nil || holder == "" {
+		return
+	}
+	if w.holder != "" && holder != w.holder {
+		w.handoffs++
+	}
+	w.holder = holder
+}
+
+// finish hands the wait to the tracker, nil-safe
+// finish 将该次等待交给追踪器，对 nil 安全
+func (w *fairnessWait) finish(now time.Time) {
+	if w == nil {
+		return
+	}
+	w.tracker.observe(w.key, now.Sub(w.start), w.handoffs)
+}
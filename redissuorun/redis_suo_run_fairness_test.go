@@ -0,0 +1,45 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_Fairness validates waits, handoffs and starvations reach the tracker
+// TestSuoLockRunWithConfig_Fairness 验证等待、交接和饥饿被记录到追踪器
+func TestSuoLockRunWithConfig_Fairness(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	// Other holders take turns while the runner waits
+	require.NoError(t, caseRedisClient.Set(ctx, suo.Key(), "holder-a", time.Second).Err())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = caseRedisClient.Set(ctx, suo.Key(), "holder-b", time.Second).Err()
+		time.Sleep(50 * time.Millisecond)
+		_ = caseRedisClient.Del(ctx, suo.Key()).Err()
+	}()
+
+	tracker := redissuorun.NewFairnessTracker(0)
+	config := redissuorun.NewConfig(10 * time.Millisecond).WithBackoff(redissuorun.ConstantBackoff(10 * time.Millisecond)).WithFairness(tracker)
+	run := func(ctx context.Context) error { return nil }
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, suo, run, config))
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, suo, run, config))
+
+	require.Equal(t, []string{suo.Key()}, tracker.Keys())
+	stats := tracker.Stats(suo.Key())
+	require.Equal(t, 2, stats.Waits)
+	require.Equal(t, 1, stats.MaxHandoffs)
+	require.Equal(t, 1, stats.Starvations)
+	require.GreaterOrEqual(t, stats.MaxWait, 100*time.Millisecond)
+	require.Less(t, stats.MeanWait, stats.MaxWait)
+	require.Positive(t, stats.WaitVariance)
+
+	require.Zero(t, tracker.Stats("unknown").Waits)
+}
@@ -0,0 +1,52 @@
+package redissuorun
+
+import (
+	"context"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+)
+
+// WithHooks sets the hooks the runner calls between attempts, in place of the hooks of the lock
+// Just OnRetry applies here, acquire, extend and release hooks run through the lock set with Suo.WithHooks
+//
+// WithHooks 设置运行器在两次尝试之间调用的钩子，取代锁的钩子
+// 此处只使用 OnRetry，获取、延期和释放钩子通过 Suo.WithHooks 设置在锁上运行
+func (c *Config) WithHooks(hooks *redissuo.Hooks) *Config {
+	c.hooks = hooks
+	return c
+}
+
+// retryHook binds the OnRetry hook of the config, or else of the lock, to the lock name, nil when neither sets one
+// retryHook 将配置的 OnRetry 钩子（否则为锁的钩子）绑定到锁名，两者都未设置时为 nil
+func (c *Config) retryHook(suo *redissuo.Suo) func(ctx context.Context, attempt int, err error) error {
+	hooks := c.hooks
+	if hooks == nil {
+		hooks = suo.Hooks()
+	}
+	return c.bindRetry(hooks, suo.Key())
+}
+
+// bindRetry binds the OnRetry hook of the hooks to the lock name, nil when unset
+// bindRetry 将钩子中的 OnRetry 绑定到锁名，未设置时为 nil
+func (c *Config) bindRetry(hooks *redissuo.Hooks, key string) func(ctx context.Context, attempt int, err error) error {
+	if hooks == nil || hooks.OnRetry == nil {
+		return nil
+	}
+	onRetry := hooks.OnRetry
+	return func(ctx context.Context, attempt int, err error) error {
+		return onRetry(ctx, key, attempt, err)
+	}
+}
+
+// callRetry runs the retry hook when set, a problem from it ends the wait
+// callRetry 在设置了重试钩子时运行它，其返回的错误会结束等待
+func callRetry(ctx context.Context, onRetry func(ctx context.Context, attempt int, err error) error, attempt int, err error) error {
+	if onRetry == nil {
+		return nil
+	}
+	if erh := onRetry(ctx, attempt, err); erh != nil {
+		return erero.Wro(erh)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_Hooks validates OnRetry sees each busy attempt and its problem ends the wait
+// TestSuoLockRunWithConfig_Hooks 验证 OnRetry 看到每次锁被占用的尝试，且其返回的错误结束等待
+func TestSuoLockRunWithConfig_Hooks(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	errGiveUp := errors.New("give up")
+	var attempts []int
+	config := redissuorun.NewConfig(5 * time.Millisecond).WithHooks(&redissuo.Hooks{
+		OnRetry: func(ctx context.Context, key string, attempt int, err error) error {
+			require.Equal(t, suo.Key(), key)
+			require.NoError(t, err)
+			attempts = append(attempts, attempt)
+			if attempt == 3 {
+				return errGiveUp
+			}
+			return nil
+		},
+	})
+	var ran bool
+	err = redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, config)
+	require.ErrorIs(t, err, errGiveUp)
+	require.False(t, ran)
+	require.Equal(t, []int{1, 2, 3}, attempts)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
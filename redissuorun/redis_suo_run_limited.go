@@ -0,0 +1,69 @@
+package redissuorun
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// RunLimited executes a function holding one of up to maxConcurrent permits shared fleet-wide under the lock name
+// Expresses "at most N at once" where strict mutual exclusion allows just one
+// Waits on a free permit with the retry, timeout and panic handling of SuoLockRun
+//
+// RunLimited 持有该锁名下整个集群共享的至多 maxConcurrent 个许可之一执行函数
+// 表达"同时至多 N 个"，而严格互斥只允许一个
+// 以 SuoLockRun 相同的重试、超时和 panic 处理等待空闲许可
+func RunLimited(ctx context.Context, suo *redissuo.Suo, maxConcurrent int, run func(ctx context.Context) error, sleep time.Duration) error {
+	return RunLimitedWithConfig(ctx, suo, maxConcurrent, run, NewConfig(sleep))
+}
+
+// RunLimitedWithConfig executes a function holding a permit using the given config
+// Execution records and followers apply to exclusive runs, they are not supported here
+//
+// RunLimitedWithConfig 使用给定配置持有许可执行函数
+// 执行记录和跟随者适用于互斥运行，此处不支持
+func RunLimitedWithConfig(ctx context.Context, suo *redissuo.Suo, maxConcurrent int, run func(ctx context.Context) error, config *Config) error {
+	must.True(maxConcurrent > 0)
+	must.Zero(config.runID)
+	ctx, task := traceTask(ctx, suo.Key())
+	defer task.End()
+	var sleep = config.sleep
+	var logger = config.logger
+
+	// One session across attempts, so an attempt whose reply got lost renews its permit on retry instead of orphaning it
+	// 各次尝试共用一个会话，使回复丢失的尝试在重试时续期其许可而不是遗留孤立许可
+	var sessionUUID = utils.NewUUID()
+	var xin *redissuo.Xin
+	var trace = config.newTrace(suo.Key(), suo.Clock().Now())
+	err := retryingAcquire(ctx, func(ctx context.Context) (bool, error) {
+		permit, err := suo.AcquirePermitWithSession(ctx, sessionUUID, maxConcurrent)
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		xin = permit
+		return permit != nil, nil
+	}, sleep, config.backoffOf(suo), config.retryHook(suo), suo.Clock(), logger, trace, config.newOutage(suo.Key()), nil)
+	if err := config.finishTrace(trace, err); err != nil {
+		return erero.Wro(err)
+	}
+
+	// A lapsed permit counts as given back, so the release does not spin on it
+	// 已失效的许可视为已归还，避免释放时空转
+	defer retryingRelease(func() (bool, error) {
+		ctx, can := safeCtx(ctx, max(sleep, defaultReleaseTimeout))
+		defer can()
+		if _, err := suo.ReleasePermit(ctx, xin); err != nil {
+			return false, erero.Wro(err)
+		}
+		return true, nil
+	}, sleep, suo.Clock(), logger)
+
+	if err := execRun(ctx, sessionRun(suo, xin, run), xin.Expire().Sub(suo.Clock().Now()), suo.ErrorLanguage()); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
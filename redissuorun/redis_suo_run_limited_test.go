@@ -0,0 +1,74 @@
+package redissuorun_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunLimited validates no more than maxConcurrent runs overlap while all of them complete
+// TestRunLimited 验证重叠运行的数量不超过 maxConcurrent 且全部运行都能完成
+func TestRunLimited(t *testing.T) {
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+	var running, peak, done atomic.Int64
+	var wg sync.WaitGroup
+	for idx := 0; idx < 8; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, redissuorun.RunLimited(context.Background(), suo, 3, func(ctx context.Context) error {
+				current := running.Add(1)
+				for {
+					old := peak.Load()
+					if current <= old || peak.CompareAndSwap(old, current) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				running.Add(-1)
+				done.Add(1)
+				return nil
+			}, 5*time.Millisecond))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(8), done.Load())
+	require.LessOrEqual(t, peak.Load(), int64(3))
+	require.Greater(t, peak.Load(), int64(1))
+}
+
+// TestRunLimited_LostReply validates an attempt whose permit was granted but whose reply got lost leaves no orphan permit
+// The retry renews the permit of the same session, so the single slot is not taken twice
+//
+// TestRunLimited_LostReply 验证许可已授予但回复丢失的尝试不会遗留孤立许可
+// 重试续期同一会话的许可，因此唯一的名额不会被占用两次
+func TestRunLimited_LostReply(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: caseRedisClient.(*redis.Client).Options().Addr,
+	})
+	defer func() { _ = redisClient.Close() }()
+	redisClient.AddHook(&lostReplyHook{})
+
+	suo := redissuo.NewSuo(redisClient, utils.NewUUID(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var ran bool
+	require.NoError(t, redissuorun.RunLimited(ctx, suo, 1, func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, 10*time.Millisecond))
+	require.True(t, ran)
+
+	count, err := suo.Permits(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,176 @@
+package redissuorun
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/suoctx"
+	"github.com/pkg/errors"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// LockerRun executes a function within a lock of any backend implementing redissuo.Locker
+// Lets the backend come from config, e.g. Manager.NewLocker, a Postgres or an etcd locker, without changes at the call site
+//
+// LockerRun 在任何实现 redissuo.Locker 的后端的锁内执行函数
+// 使后端可来自配置，例如 Manager.NewLocker、Postgres 或 etcd 锁，而无需修改调用点
+func LockerRun(ctx context.Context, locker redissuo.Locker, run func(ctx context.Context) error, sleep time.Duration) error {
+	return LockerRunWithConfig(ctx, locker, run, NewConfig(sleep))
+}
+
+// LockerRunWithConfig executes a function within a lock of any backend using the given config
+// A Suo goes through SuoLockRunWithConfig with each setting of the config
+// Other backends get the retries, backoff, retry hook, acquisition trace, outage pause, waiter limit,
+// auto extension and release statistics, the settings built on Redis features of the Suo do not apply to them
+//
+// LockerRunWithConfig 使用给定配置在任何后端的锁内执行函数
+// Suo 通过 SuoLockRunWithConfig 执行并使用配置的全部设置
+// 其它后端获得重试、退避、重试钩子、获取追踪、故障暂停、等待者限制、
+// 自动延期和释放统计，基于 Suo 的 Redis 功能的设置对其不生效
+func LockerRunWithConfig(ctx context.Context, locker redissuo.Locker, run func(ctx context.Context) error, config *Config) error {
+	must.Nice(locker)
+	if suo, ok := locker.(*redissuo.Suo); ok {
+		return SuoLockRunWithConfig(ctx, suo, run, config)
+	}
+	ctx, task := traceTask(ctx, locker.Key())
+	defer task.End()
+
+	var key = locker.Key()
+	var sleep = config.sleep
+	var logger = config.logger
+	var clock = redissuo.SystemClock()
+	// Backends outside this package report problems in the default language of locks
+	// 本包之外的后端以锁的默认语言报告错误
+	var language = redissuo.LanguageChinese
+
+	if !processWaiters.enter(key, config.maxWaiters) {
+		logger.DebugLog("等待者过多-快速失败", zap.String("k", key), zap.Int("max_waiters", config.maxWaiters))
+		return redissuo.NewError(redissuo.CodeTooManyWaiters, language, nil)
+	}
+	var xin *redissuo.Xin
+	var waitStart = clock.Now()
+	var trace = config.newTrace(key, waitStart)
+	err := retryingAcquire(ctx, func(ctx context.Context) (bool, error) {
+		res, err := locker.Acquire(ctx)
+		if errors.Is(err, redissuo.ErrLockHeld) {
+			return false, nil
+		}
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		if res == nil {
+			return false, nil
+		}
+		xin = res
+		return true, nil
+	}, sleep, config.backoffWith(redissuo.SystemRandom()), config.bindRetry(config.hooks, key), clock, logger, trace, config.newOutage(key), nil)
+	err = config.finishTrace(trace, err)
+	processWaiters.leave(key, config.maxWaiters)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	must.Nice(xin)
+
+	defer func() {
+		var stats = &ReleaseStats{Key: key, Session: xin.SessionUUID(), Extensions: xin.Extensions()}
+		retryingRelease(func() (bool, error) {
+			stats.Attempts++
+			success, err := releaseLocker(ctx, locker, xin, sleep, logger)
+			if err != nil {
+				stats.Err = err
+			}
+			return success, err
+		}, sleep, clock, logger)
+		stats.HeldFor = clock.Now().Sub(xin.AcquiredAt())
+		config.runAfterRelease(stats)
+	}()
+
+	run = func(run func(ctx context.Context) error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			defer traceRegion(ctx, traceHeldRegion)()
+			return run(suoctx.WithSession(ctx, xin))
+		}
+	}(run)
+	if !config.autoExtend {
+		return execRun(ctx, run, xin.Expire().Sub(clock.Now()), language)
+	}
+	return lockerExtendedRun(ctx, locker, &xin, config.extendInterval, clock, logger, run, language)
+}
+
+// releaseLocker performs a single release of the session through the locker with timeout protection
+// A session the backend no longer holds counts as released, backends outside this package cannot tell lost and lapsed apart
+//
+// releaseLocker 通过锁执行单次带超时保护的会话释放
+// 后端已不再持有的会话视为已释放，本包之外的后端无法区分锁丢失与锁失效
+func releaseLocker(ctx context.Context, locker redissuo.Locker, xin *redissuo.Xin, sleep time.Duration, logger logging.Logger) (bool, error) {
+	ctx, can := safeCtx(ctx, max(sleep, defaultReleaseTimeout))
+	defer can()
+
+	success, err := locker.Release(ctx, xin)
+	if err != nil && !errors.Is(err, redissuo.ErrLockExpired) && !errors.Is(err, redissuo.ErrNotOwner) {
+		return false, erero.Wro(err)
+	}
+	if !success || err != nil {
+		logger.DebugLog("释放时锁已丢失", zap.String("k", locker.Key()), zap.String("v", xin.SessionUUID()))
+	}
+	return true, nil
+}
+
+// lockerExtendedRun runs while a watchdog extends the session through the locker, storing the latest session into xin
+// The run context gets cancelled with redissuo.ErrLockLost as cause once an extension is refused
+// Lockers with a ShouldExtend policy skip the ticks it declines, checking again within half the remaining lease
+//
+// lockerExtendedRun 在看门狗通过锁延期会话的同时运行，并将最新的会话存入 xin
+// 延期被拒绝时，运行上下文以 redissuo.ErrLockLost 为原因被取消
+// 带有 ShouldExtend 策略的锁跳过其拒绝的间隔，并在剩余租期的一半之内再次检查
+func lockerExtendedRun(ctx context.Context, locker redissuo.Locker, xin **redissuo.Xin, interval time.Duration, clock redissuo.Clock, logger logging.Logger, run func(ctx context.Context) error, language redissuo.Language) error {
+	if interval == 0 {
+		interval = max((*xin).Expire().Sub(clock.Now())/3, time.Millisecond)
+	}
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	gate, _ := locker.(interface{ ShouldExtend(xin *redissuo.Xin) bool })
+	latest := make(chan *redissuo.Xin, 1)
+	latest <- *xin
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		timer := clock.NewTimer(interval)
+		defer timer.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-timer.C():
+			}
+			current := <-latest
+			if gate != nil && !gate.ShouldExtend(current) {
+				latest <- current
+				timer.Reset(min(interval, max(current.Expire().Sub(clock.Now())/2, time.Millisecond)))
+				continue
+			}
+			res, err := locker.AcquireAgainExtendLock(runCtx, current)
+			if err != nil && runCtx.Err() != nil {
+				latest <- current
+				return // Stopped during the extension // 在延期期间被停止
+			}
+			if err != nil || res == nil {
+				logger.ErrorLog("自动续期失败-锁已丢失", zap.String("k", locker.Key()), zap.String("v", current.SessionUUID()), zap.Error(err))
+				latest <- current
+				cancel(redissuo.ErrLockLost)
+				return
+			}
+			latest <- res
+			timer.Reset(interval)
+		}
+	}()
+	erx := safeRun(runCtx, run, language)
+	cancel(nil)
+	<-done
+	*xin = <-latest
+	return erx
+}
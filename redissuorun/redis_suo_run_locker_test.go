@@ -0,0 +1,77 @@
+package redissuorun_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/go-xlan/redis-go-suo/suoctx"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockerRun validates backends picked through the manager drive the runner, excluding each other and releasing after the run
+// TestLockerRun 验证通过管理器选择的后端可驱动运行器，相互排斥并在运行后释放
+func TestLockerRun(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+
+	for _, manager := range []*redissuo.Manager{
+		redissuo.NewManager(caseRedisClient),
+		redissuo.NewManager(caseRedisClient).WithMemoryStore(redissuo.NewMemoryStore()),
+	} {
+		locker := manager.NewLocker(key, 5*time.Second)
+		other := manager.NewLocker(key, 5*time.Second)
+
+		var ran atomic.Int32
+		require.NoError(t, redissuorun.LockerRun(ctx, locker, func(ctx context.Context) error {
+			xin, ok := suoctx.Session(ctx)
+			require.True(t, ok)
+			require.Equal(t, key, xin.Key())
+
+			busy, err := other.Acquire(ctx)
+			require.NoError(t, err)
+			require.Nil(t, busy)
+			ran.Add(1)
+			return nil
+		}, 10*time.Millisecond))
+		require.Equal(t, int32(1), ran.Load())
+
+		xin, err := other.Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, xin)
+		_, err = other.Release(ctx, xin)
+		require.NoError(t, err)
+	}
+}
+
+// TestLockerRunWithConfig_AutoExtend validates the watchdog keeps a memory lock past its TTL while the run goes on
+// TestLockerRunWithConfig_AutoExtend 验证看门狗在运行期间使内存锁的持有超过其 TTL
+func TestLockerRunWithConfig_AutoExtend(t *testing.T) {
+	ctx := context.Background()
+	store := redissuo.NewMemoryStore()
+	locker := store.NewLocker(utils.NewUUID(), 60*time.Millisecond)
+	other := store.NewLocker(locker.Key(), 60*time.Millisecond)
+
+	var stats *redissuorun.ReleaseStats
+	config := redissuorun.NewConfig(10 * time.Millisecond).WithAutoExtend(20 * time.Millisecond).WithAfterRelease(func(res *redissuorun.ReleaseStats) {
+		stats = res
+	})
+	require.NoError(t, redissuorun.LockerRunWithConfig(ctx, locker, func(ctx context.Context) error {
+		time.Sleep(150 * time.Millisecond)
+		require.NoError(t, ctx.Err())
+		busy, err := other.Acquire(ctx)
+		require.NoError(t, err)
+		require.Nil(t, busy)
+		return nil
+	}, config))
+	require.NotNil(t, stats)
+	require.Positive(t, stats.Extensions)
+
+	xin, err := other.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+}
@@ -0,0 +1,33 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRun_ReleaseNotify validates waiters wake up on release instead of sleeping out the poll interval
+// TestSuoLockRun_ReleaseNotify 验证等待者在释放时被唤醒，而不是睡满轮询间隔
+func TestSuoLockRun_ReleaseNotify(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithReleaseNotify()
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = suo.Release(ctx, xin)
+	}()
+
+	start := time.Now()
+	require.NoError(t, redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		return nil
+	}, 5*time.Second))
+	require.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,74 @@
+package redissuorun
+
+import (
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// WithOutagePause pauses polling through the cool-down once threshold attempts in a row hit Redis problems
+// Busy attempts do not count, just transient problems such as timeouts and refused connections
+// One aggregated warning marks the outage and one note its end, in place of a log line per attempt
+//
+// WithOutagePause 在连续 threshold 次尝试遇到 Redis 错误后，按冷却时长暂停轮询
+// 锁被占用的尝试不计入，只统计超时、连接被拒等瞬时错误
+// 以一条汇总警告标记故障、一条记录标记恢复，取代每次尝试一行日志
+func (c *Config) WithOutagePause(threshold int, coolDown time.Duration) *Config {
+	must.True(threshold > 0)
+	c.outageThreshold = threshold
+	c.outageCoolDown = must.Nice(coolDown)
+	return c
+}
+
+// outageWatch counts consecutive problems of one wait, nil when the outage pause is disabled
+// outageWatch 统计单次等待中的连续错误，未启用故障暂停时为空
+type outageWatch struct {
+	key       string         // Lock name ID // 锁名标识符
+	threshold int            // Problems in a row opening the outage // 开启故障的连续错误数
+	coolDown  time.Duration  // Pause between attempts during the outage // 故障期间尝试之间的暂停时长
+	logger    logging.Logger // Logger of the warnings // 警告的日志记录器
+	failures  int            // Problems in a row so far // 目前的连续错误数
+	since     time.Time      // Time of the first problem in the row // 连续错误中首次错误的时间
+}
+
+// newOutage creates the outage watch of one wait, nil when the outage pause is disabled
+// newOutage 创建单次等待的故障监视，未启用故障暂停时为空
+func (c *Config) newOutage(key string) *outageWatch {
+	if c.outageThreshold <= 0 {
+		return nil
+	}
+	return &outageWatch{key: key, threshold: c.outageThreshold, coolDown: c.outageCoolDown, logger: c.logger}
+}
+
+// fail counts the problem and gives back the sleep ahead of the next attempt, and whether its log line is folded in the warning
+// fail 统计该错误并返回下次尝试前的休眠时长，以及其日志是否已并入汇总警告
+func (o *outageWatch) fail(now time.Time, sleep time.Duration, err error) (time.Duration, bool) {
+	if o == nil {
+		return sleep, false
+	}
+	if o.failures == 0 {
+		o.since = now
+	}
+	o.failures++
+	if o.failures < o.threshold {
+		return sleep, false
+	}
+	if o.failures == o.threshold {
+		o.logger.ErrorLog("Redis连续报错-暂停轮询", zap.String("k", o.key), zap.Int("failures", o.failures), zap.Duration("cool_down", o.coolDown), zap.Error(err))
+	}
+	return max(sleep, o.coolDown), true
+}
+
+// recover closes the outage once an attempt gets through, noting its length and count of problems
+// recover 在尝试成功通过后结束故障，记录其时长和错误次数
+func (o *outageWatch) recover(now time.Time) {
+	if o == nil {
+		return
+	}
+	if o.failures >= o.threshold {
+		o.logger.DebugLog("Redis已恢复-继续轮询", zap.String("k", o.key), zap.Int("failures", o.failures), zap.Duration("outage", now.Sub(o.since)))
+	}
+	o.failures = 0
+}
@@ -0,0 +1,69 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// failHook fails the first scripts with a transient problem, like a Redis outage
+// failHook 让前几个脚本以瞬时错误失败，模拟 Redis 故障
+type failHook struct {
+	left atomic.Int32
+}
+
+func (h *failHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *failHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if (cmd.Name() == "eval" || cmd.Name() == "evalsha") && h.left.Add(-1) >= 0 {
+			err := errors.New("connection refused")
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *failHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// TestSuoLockRun_OutagePause validates polling pauses through the cool-down once problems come in a row
+// TestSuoLockRun_OutagePause 验证连续出错后轮询按冷却时长暂停
+func TestSuoLockRun_OutagePause(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: caseRedisClient.(*redis.Client).Options().Addr,
+	})
+	defer func() { _ = redisClient.Close() }()
+	hook := &failHook{}
+	hook.left.Store(4)
+	redisClient.AddHook(hook)
+
+	suo := redissuo.NewSuo(redisClient, utils.NewUUID(), 5*time.Second)
+	var trace *redissuorun.AcquireTrace
+	config := redissuorun.NewConfig(time.Millisecond).
+		WithOutagePause(2, 20*time.Millisecond).
+		WithAcquireTrace(10, func(res *redissuorun.AcquireTrace) { trace = res })
+
+	err := redissuorun.SuoLockRunWithConfig(context.Background(), suo, func(ctx context.Context) error {
+		return nil
+	}, config)
+	require.NoError(t, err)
+	require.NotNil(t, trace)
+	require.Len(t, trace.Entries, 5)
+
+	var backoffs []time.Duration
+	for _, entry := range trace.Entries {
+		backoffs = append(backoffs, entry.Backoff)
+	}
+	require.Equal(t, []time.Duration{time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond, 0}, backoffs)
+}
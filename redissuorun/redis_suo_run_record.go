@@ -0,0 +1,98 @@
+package redissuorun
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/logging"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+	"go.uber.org/zap"
+)
+
+// recordedRun skips the run when a previous holder completed it, otherwise runs it and records the outcome
+// The record gets written ahead of release, so a retry never misses a completed run
+// Followers give back a failure recorded since they started waiting, judged against the baseline on the Redis clock
+//
+// recordedRun 在先前持有者已完成运行时跳过，否则执行运行并记录结果
+// 记录在释放之前写入，使重试不会错过已完成的运行
+// 跟随者返回其开始等待之后记录的失败结果，依据 Redis 时钟上的基线判断
+func recordedRun(ctx context.Context, suo *redissuo.Suo, xin *redissuo.Xin, run func(ctx context.Context) error, config *Config, baseline time.Time) error {
+	var logger = config.logger
+	record, err := suo.ExecutionRecord(ctx, config.runID)
+	if err != nil {
+		logger.ErrorLog("读取执行记录报错", zap.String("k", suo.Key()), zap.String("run_id", config.runID), zap.Error(err))
+		return erero.Wro(err)
+	}
+	if record != nil && record.Status == redissuo.RunSucceeded {
+		logger.DebugLog("已由先前持有者完成-跳过", zap.String("k", suo.Key()), zap.String("run_id", config.runID), zap.Time("completed_at", record.CompletedAt))
+		return nil
+	}
+	// A follower that took the lock right past a failed holder shares that failure instead of running again
+	// 紧接着失败持有者获取到锁的跟随者共享该失败结果，而不是再次运行
+	if record != nil && config.follower && record.CompletedAt.After(baseline) {
+		return followedOutcome(suo, record, logger)
+	}
+
+	erx := config.runWithin(ctx, suo, xin, run)
+	status := redissuo.RunSucceeded
+	resultHash := ""
+	if erx != nil {
+		status = redissuo.RunFailed
+	} else if config.resultHash != nil {
+		resultHash = config.resultHash()
+	}
+	// A missing record only costs a repeated run, so problems here do not override the run outcome
+	// 缺失记录只会导致重复运行，因此这里的错误不会覆盖运行结果
+	ok, err := suo.CompleteRun(ctx, xin, config.runID, status, resultHash, config.retention)
+	if err == nil && !ok {
+		logger.ErrorLog("锁已丢失-未写入执行记录", zap.String("k", suo.Key()), zap.String("run_id", config.runID))
+	}
+	if erx != nil {
+		return erero.Wro(erx)
+	}
+	return nil
+}
+
+// followBaseline reads the completion time of the record present when the wait starts, zero when none
+// Both sides of the comparison then come from the Redis clock, so client skew cannot make a follower miss or repeat work
+//
+// followBaseline 读取等待开始时已存在的记录的完成时间，没有时为零值
+// 比较的双方都取自 Redis 时钟，因此客户端时钟偏差不会使跟随者错过或重复工作
+func followBaseline(ctx context.Context, suo *redissuo.Suo, runID string) (time.Time, error) {
+	record, err := suo.ExecutionRecord(ctx, runID)
+	if err != nil {
+		return time.Time{}, erero.Wro(err)
+	}
+	if record == nil {
+		return time.Time{}, nil
+	}
+	return record.CompletedAt, nil
+}
+
+// followOnce checks whether the holder recorded the outcome of the run, filling the message when it did
+// Failed records no later than the baseline belong to an earlier attempt and get ignored
+//
+// followOnce 检查持有者是否已记录运行结果，已记录时填充消息
+// 不晚于基线的失败记录属于更早的尝试，会被忽略
+func followOnce(ctx context.Context, suo *redissuo.Suo, runID string, baseline time.Time, output *outputMessage) (bool, error) {
+	record, err := suo.ExecutionRecord(ctx, runID)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	if record == nil || (record.Status != redissuo.RunSucceeded && !record.CompletedAt.After(baseline)) {
+		return false, nil
+	}
+	output.followed = record
+	return true, nil
+}
+
+// followedOutcome converts the recorded outcome into the result of the follower
+// followedOutcome 将记录的结果转换为跟随者的返回值
+func followedOutcome(suo *redissuo.Suo, record *redissuo.ExecutionRecord, logger logging.Logger) error {
+	logger.DebugLog("跟随持有者的运行结果", zap.String("k", suo.Key()), zap.String("run_id", record.RunID), zap.String("status", string(record.Status)))
+	if record.Status != redissuo.RunSucceeded {
+		return redissuo.NewError(redissuo.CodeFollowedRunFailed, suo.ErrorLanguage(), nil)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_ExecutionRecord validates a succeeded run gets skipped on retry while a failed one runs again
+// TestSuoLockRunWithConfig_ExecutionRecord 验证成功的运行在重试时被跳过，失败的运行会再次执行
+func TestSuoLockRunWithConfig_ExecutionRecord(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	config := redissuorun.NewConfig(10*time.Millisecond).WithExecutionRecord("batch-1", time.Minute)
+
+	var count int
+	failing := func(ctx context.Context) error {
+		count++
+		return errors.New("boom")
+	}
+	require.Error(t, redissuorun.SuoLockRunWithConfig(ctx, suo, failing, config))
+
+	succeeding := func(ctx context.Context) error {
+		count++
+		return nil
+	}
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, suo, succeeding, config))
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, suo, succeeding, config))
+	require.Equal(t, 2, count)
+
+	record, err := suo.ExecutionRecord(ctx, "batch-1")
+	require.NoError(t, err)
+	require.Equal(t, redissuo.RunSucceeded, record.Status)
+}
+
+// TestSuoLockRunWithConfig_Followers validates waiters return the holder's outcome instead of running again
+// TestSuoLockRunWithConfig_Followers 验证等待者返回持有者的结果而非再次运行
+func TestSuoLockRunWithConfig_Followers(t *testing.T) {
+	ctx := context.Background()
+	for _, failure := range []error{nil, errors.New("boom")} {
+		suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+		config := redissuorun.NewConfig(10*time.Millisecond).WithExecutionRecord("batch-1", time.Minute).WithFollowers(true)
+
+		var count atomic.Int64
+		run := func(ctx context.Context) error {
+			count.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return failure
+		}
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for idx := range errs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[idx] = redissuorun.SuoLockRunWithConfig(ctx, suo, run, config)
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, int64(1), count.Load())
+		for _, err := range errs {
+			if failure == nil {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		}
+	}
+}
+
+// TestSuoLockRunWithConfig_ResultHash validates the result hash of a succeeded run lands in the execution record
+// TestSuoLockRunWithConfig_ResultHash 验证成功运行的结果摘要写入执行记录
+func TestSuoLockRunWithConfig_ResultHash(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	var digest string
+	config := redissuorun.NewConfig(10*time.Millisecond).WithExecutionRecord("batch-1", time.Minute).WithResultHash(func() string {
+		return digest
+	})
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		digest = "sha256:abc"
+		return nil
+	}, config))
+
+	record, err := suo.ExecutionRecord(ctx, "batch-1")
+	require.NoError(t, err)
+	require.Equal(t, "sha256:abc", record.ResultHash)
+}
+
+// skewedClock is the wall clock shifted by an offset, a client whose clock runs ahead or behind
+// skewedClock 是偏移了一段时长的系统时钟，模拟时钟超前或落后的客户端
+type skewedClock struct {
+	redissuo.Clock
+	offset time.Duration
+}
+
+func (c *skewedClock) Now() time.Time {
+	return c.Clock.Now().Add(c.offset)
+}
+
+// TestSuoLockRunWithConfig_Followers_Skew validates followers judge records on the Redis clock, not their own
+// A follower behind the writer still reruns past a stale failure, one ahead still shares a fresh failure
+//
+// TestSuoLockRunWithConfig_Followers_Skew 验证跟随者依据 Redis 时钟而非自身时钟判断记录
+// 落后于写入者的跟随者仍会越过陈旧的失败而重新运行，超前的跟随者仍会共享新的失败
+func TestSuoLockRunWithConfig_Followers_Skew(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	suo := redissuo.NewSuo(caseRedisClient, key, 5*time.Second)
+	config := redissuorun.NewConfig(10*time.Millisecond).WithExecutionRecord("batch-1", time.Minute).WithFollowers(true)
+
+	boom := errors.New("boom")
+	require.ErrorIs(t, redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		return boom
+	}, config), boom)
+
+	behind := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithClock(&skewedClock{Clock: redissuo.SystemClock(), offset: -time.Hour})
+	var reran bool
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, behind, func(ctx context.Context) error {
+		reran = true
+		return nil
+	}, config))
+	require.True(t, reran)
+
+	config = redissuorun.NewConfig(10*time.Millisecond).WithExecutionRecord("batch-2", time.Minute).WithFollowers(true)
+	started := make(chan struct{})
+	finished := make(chan error, 1)
+	go func() {
+		finished <- redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			return boom
+		}, config)
+	}()
+	<-started
+
+	ahead := redissuo.NewSuo(caseRedisClient, key, 5*time.Second).WithClock(&skewedClock{Clock: redissuo.SystemClock(), offset: time.Hour})
+	err := redissuorun.SuoLockRunWithConfig(ctx, ahead, func(ctx context.Context) error {
+		t.Fatal("must share the failure of the holder instead of running again")
+		return nil
+	}, config)
+	require.Equal(t, redissuo.CodeFollowedRunFailed, redissuo.CodeOf(err))
+	require.ErrorIs(t, <-finished, boom)
+}
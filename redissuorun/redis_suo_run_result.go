@@ -0,0 +1,39 @@
+package redissuorun
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+)
+
+// SuoLockRunResult executes a function computing a value within a distributed lock, giving the value back
+// Same lifecycle as SuoLockRun, the value is the zero value when the run fails or never happens
+//
+// SuoLockRunResult 在分布式锁内执行计算值的函数，并返回该值
+// 生命周期与 SuoLockRun 相同，运行失败或未发生时返回零值
+func SuoLockRunResult[T any](ctx context.Context, suo *redissuo.Suo, run func(ctx context.Context) (T, error), sleep time.Duration) (T, error) {
+	return SuoLockRunResultWithConfig(ctx, suo, run, NewConfig(sleep))
+}
+
+// SuoLockRunResultWithConfig executes a function computing a value within a distributed lock using the given config
+// Followers and single flight callers that skip the run get the zero value next to the shared outcome
+//
+// SuoLockRunResultWithConfig 使用给定配置在分布式锁内执行计算值的函数
+// 跳过运行的跟随者和单飞调用方在共享结果之外得到零值
+func SuoLockRunResultWithConfig[T any](ctx context.Context, suo *redissuo.Suo, run func(ctx context.Context) (T, error), config *Config) (T, error) {
+	var result T
+	if err := SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		value, err := run(ctx)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		result = value
+		return nil
+	}, config); err != nil {
+		var zero T
+		return zero, erero.Wro(err)
+	}
+	return result, nil
+}
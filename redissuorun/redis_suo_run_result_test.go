@@ -0,0 +1,33 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunResult validates the value computed under the lock comes back, and the zero value on failure
+// TestSuoLockRunResult 验证在锁内计算的值被返回，失败时返回零值
+func TestSuoLockRunResult(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	value, err := redissuorun.SuoLockRunResult(ctx, suo, func(ctx context.Context) (int, error) {
+		return 42, nil
+	}, 10*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+
+	errWrong := errors.New("wrong")
+	text, err := redissuorun.SuoLockRunResult(ctx, suo, func(ctx context.Context) (string, error) {
+		return "partial", errWrong
+	}, 10*time.Millisecond)
+	require.ErrorIs(t, err, errWrong)
+	require.Empty(t, text)
+}
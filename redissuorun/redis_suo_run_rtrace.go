@@ -0,0 +1,29 @@
+package redissuorun
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+const (
+	traceTaskType      = "redissuo.lock"    // Task of one locked run // 一次加锁运行的任务
+	traceAcquireRegion = "redissuo.acquire" // Region of the acquisition wait // 获取等待的区域
+	traceHeldRegion    = "redissuo.held"    // Region of the held section // 持有区段的区域
+)
+
+// traceTask starts the execution trace task of one locked run, logging the lock name into it
+// go tool trace then shows lock waits and held sections next to goroutine scheduling, at no cost while tracing is off
+//
+// traceTask 开始一次加锁运行的执行追踪任务，并在其中记录锁名
+// go tool trace 因此可以在 goroutine 调度旁显示锁等待和持有区段，未开启追踪时没有开销
+func traceTask(ctx context.Context, key string) (context.Context, *trace.Task) {
+	ctx, task := trace.NewTask(ctx, traceTaskType)
+	trace.Log(ctx, "key", key)
+	return ctx, task
+}
+
+// traceRegion starts an execution trace region on the calling goroutine, call the result to end it there
+// traceRegion 在调用方 goroutine 上开始执行追踪区域，调用返回的函数在同一 goroutine 上结束它
+func traceRegion(ctx context.Context, regionType string) func() {
+	return trace.StartRegion(ctx, regionType).End
+}
@@ -0,0 +1,35 @@
+package redissuorun_test
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRun_RuntimeTrace validates the run shows its task and regions in the execution trace
+// TestSuoLockRun_RuntimeTrace 验证运行在执行追踪中显示其任务和区域
+func TestSuoLockRun_RuntimeTrace(t *testing.T) {
+	if trace.IsEnabled() {
+		t.Skip("execution tracing is on already")
+	}
+	var buffer bytes.Buffer
+	require.NoError(t, trace.Start(&buffer))
+
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	err := redissuorun.SuoLockRun(context.Background(), suo, func(ctx context.Context) error {
+		return nil
+	}, 10*time.Millisecond)
+	trace.Stop()
+	require.NoError(t, err)
+
+	for _, name := range []string{"redissuo.lock", "redissuo.acquire", "redissuo.held", suo.Key()} {
+		require.Contains(t, buffer.String(), name)
+	}
+}
@@ -0,0 +1,65 @@
+package redissuorun
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/pkg/errors"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+	"go.uber.org/zap"
+)
+
+// RunShards splits a job into shards 0..shards-1, each guarded through its own lock derived with Suo.Shard,
+// and runs all shards this instance wins at once, skipping shards held elsewhere without waiting
+// Each instance of a periodic job calls it, so adding instances spreads the shards across them
+// Gives back the shards run here, in ascending sequence, and the joined problems of their runs
+//
+// RunShards 将作业拆分为 0..shards-1 个分片，每个分片由 Suo.Shard 派生的锁保护，
+// 并发执行本实例抢到的全部分片，被其它实例持有的分片直接跳过而不等待
+// 周期作业的每个实例都调用它，增加实例即可将分片分摊到各实例上
+// 返回在本实例执行的分片（按升序）以及各次运行合并后的错误
+func RunShards(ctx context.Context, suo *redissuo.Suo, shards int, run func(ctx context.Context, shard int) error, sleep time.Duration) ([]int, error) {
+	must.True(shards > 0)
+	logger := NewConfig(sleep).logger
+	ctx, task := traceTask(ctx, suo.Key())
+	defer task.End()
+
+	var won []int
+	var errs = make([]error, shards)
+	var wg sync.WaitGroup
+	for idx := 0; idx < shards; idx++ {
+		shardSuo := suo.Shard(idx)
+		xin, err := shardSuo.Acquire(ctx)
+		if errors.Is(err, redissuo.ErrLockHeld) {
+			continue // Shard held through another instance in typed error mode // 类型化错误模式下分片被其它实例持有
+		}
+		if err != nil {
+			errs[idx] = erero.WithMessagef(err, "acquire shard %d", idx)
+			continue
+		}
+		if xin == nil {
+			continue // Shard held through another instance // 分片被其它实例持有
+		}
+		won = append(won, idx)
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			defer retryingRelease(func() (bool, error) {
+				return releaseOnce(ctx, shardSuo, xin, sleep)
+			}, sleep, shardSuo.Clock(), logger)
+
+			if err := execRun(ctx, sessionRun(shardSuo, xin, func(ctx context.Context) error {
+				return run(ctx, idx)
+			}), xin.Expire().Sub(shardSuo.Clock().Now()), shardSuo.ErrorLanguage()); err != nil {
+				logger.ErrorLog("分片运行报错", zap.String("k", shardSuo.Key()), zap.String("shard", strconv.Itoa(idx)), zap.Error(err))
+				errs[idx] = erero.WithMessagef(err, "run shard %d", idx)
+			}
+		}(idx)
+	}
+	wg.Wait()
+	return won, erero.Joins(errs)
+}
@@ -0,0 +1,77 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunShards validates the shards held through another instance are skipped and the rest run here
+// TestRunShards 验证被其它实例持有的分片被跳过，其余分片在本实例执行
+func TestRunShards(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second)
+
+	// Another instance holds shard 1
+	xin, err := suo.Shard(1).Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	var mutex sync.Mutex
+	var ran []int
+	errBoom := errors.New("boom")
+	won, err := redissuorun.RunShards(ctx, suo, 4, func(ctx context.Context, shard int) error {
+		mutex.Lock()
+		ran = append(ran, shard)
+		mutex.Unlock()
+		if shard == 3 {
+			return errBoom
+		}
+		return nil
+	}, 5*time.Millisecond)
+	require.ErrorIs(t, err, errBoom)
+	require.Equal(t, []int{0, 2, 3}, won)
+	require.ElementsMatch(t, []int{0, 2, 3}, ran)
+
+	// Shards got released past the runs
+	xin, err = suo.Shard(0).Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+}
+
+// TestRunShards_TypedErrors validates held shards are skipped in typed error mode as well
+// TestRunShards_TypedErrors 验证类型化错误模式下被持有的分片同样被跳过
+func TestRunShards_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), time.Second).WithTypedErrors(true)
+
+	xin, err := suo.Shard(0).Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	won, err := redissuorun.RunShards(ctx, suo, 2, func(ctx context.Context, shard int) error {
+		return nil
+	}, 5*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, won)
+
+	// The runner waits on the held lock and finishes once it frees up
+	// 运行器等待被持有的锁，并在其空闲后完成
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = suo.Shard(0).Release(ctx, xin)
+	}()
+	var ran bool
+	require.NoError(t, redissuorun.SuoLockRun(ctx, suo.Shard(0), func(ctx context.Context) error {
+		ran = true
+		return nil
+	}, 5*time.Millisecond))
+	require.True(t, ran)
+}
@@ -0,0 +1,87 @@
+package redissuorun
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// processFlights tracks the runs in flight of this process per key and purpose
+// processFlights 跟踪本进程中按键和用途划分的进行中运行
+var processFlights = &flightBoard{calls: map[flightKey]*flightCall{}}
+
+// flightKey identifies a shared run through the lock name and the purpose
+// flightKey 通过锁名和用途标识共享的运行
+type flightKey struct {
+	key     string // Lock name // 锁名
+	purpose string // Purpose of the run // 运行的用途
+}
+
+// flightCall is a run in flight that later callers join
+// flightCall 是后来的调用方加入的进行中运行
+type flightCall struct {
+	done chan struct{} // Closed once the run is over // 运行结束后关闭
+	err  error         // Outcome of the run, set ahead of closing done // 运行结果，在关闭 done 之前设置
+}
+
+// flightBoard collapses concurrent runs of the same key and purpose into one
+// flightBoard 将相同键和用途的并发运行合并为一次
+type flightBoard struct {
+	mutex sync.Mutex
+	calls map[flightKey]*flightCall
+}
+
+// do runs the function unless a run of the key and purpose is in flight, then waits on that run and shares its outcome
+// A joined caller whose context ends stops waiting with the context problem, the run goes on through its first caller
+// A panic reaches the joined callers as a coded panic problem and goes on panicking in the first caller
+//
+// do 执行函数，除非该键和用途的运行正在进行中，此时等待该运行并共享其结果
+// 加入的调用方上下文结束时以上下文错误停止等待，运行仍由首个调用方继续
+// panic 以带错误码的崩溃错误传给加入的调用方，并在首个调用方中继续 panic
+func (b *flightBoard) do(ctx context.Context, key string, purpose string, language redissuo.Language, run func() error) error {
+	id := flightKey{key: key, purpose: purpose}
+	b.mutex.Lock()
+	if call, ok := b.calls[id]; ok {
+		b.mutex.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return erero.Wro(ctx.Err())
+		}
+	}
+	call := &flightCall{done: make(chan struct{})}
+	b.calls[id] = call
+	b.mutex.Unlock()
+
+	defer func() {
+		rec := recover()
+		if rec != nil {
+			call.err = panicError(rec, language)
+		}
+		b.mutex.Lock()
+		delete(b.calls, id)
+		b.mutex.Unlock()
+		close(call.done)
+		if rec != nil {
+			panic(rec)
+		}
+	}()
+	call.err = run()
+	return call.err
+}
+
+// WithSingleflight collapses concurrent calls of the same key and purpose in this process into one run
+// Just the first caller acquires the lock and runs its function, the others wait and share its outcome
+// Meant in request-handler hot paths where many callers ask the same work at once, cutting redundant Redis traffic
+//
+// WithSingleflight 将本进程中相同键和用途的并发调用合并为一次运行
+// 仅首个调用方获取锁并执行其函数，其余调用方等待并共享其结果
+// 适用于大量调用方同时请求相同工作的请求处理热路径，减少多余的 Redis 流量
+func (c *Config) WithSingleflight(purpose string) *Config {
+	c.flight = must.Nice(purpose)
+	return c
+}
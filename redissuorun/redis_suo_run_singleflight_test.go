@@ -0,0 +1,104 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_Singleflight validates concurrent calls of the same purpose share one run and its outcome
+// TestSuoLockRunWithConfig_Singleflight 验证相同用途的并发调用共享一次运行及其结果
+func TestSuoLockRunWithConfig_Singleflight(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	// Hold the lock so every caller is in flight before the run starts
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	errShared := errors.New("shared")
+	var runs atomic.Int32
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for idx := range errs {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			errs[idx] = redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+				runs.Add(1)
+				return errShared
+			}, redissuorun.NewConfig(10*time.Millisecond).WithSingleflight("refresh"))
+		}(idx)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	wg.Wait()
+
+	require.Equal(t, int32(1), runs.Load())
+	for _, err := range errs {
+		require.ErrorIs(t, err, errShared)
+	}
+}
+
+// TestSuoLockRunWithConfig_Singleflight_Panic validates joined callers of a crashed run get a panic problem instead of success
+// The first caller keeps panicking, so the crash is not swallowed
+//
+// TestSuoLockRunWithConfig_Singleflight_Panic 验证崩溃运行的加入调用方得到崩溃错误而不是成功
+// 首个调用方继续 panic，崩溃不会被吞掉
+func TestSuoLockRunWithConfig_Singleflight_Panic(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	// Hold the lock so the first caller reaches the panicking backoff while the others join
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	var panics atomic.Int32
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for idx := range errs {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			defer func() {
+				if recover() != nil {
+					panics.Add(1)
+				}
+			}()
+			errs[idx] = redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+				return nil
+			}, redissuorun.NewConfig(10*time.Millisecond).WithSingleflight("crash").WithBackoff(func(attempt int, random redissuo.Random) time.Duration {
+				time.Sleep(100 * time.Millisecond)
+				panic("backoff crashed")
+			}))
+		}(idx)
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), panics.Load())
+	var joined int
+	for _, err := range errs {
+		if err != nil {
+			require.Equal(t, redissuo.CodePanicRecovered, redissuo.CodeOf(err))
+			joined++
+		}
+	}
+	require.Equal(t, 4, joined)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
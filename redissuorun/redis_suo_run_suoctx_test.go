@@ -0,0 +1,34 @@
+package redissuorun_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/go-xlan/redis-go-suo/suoctx"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRun_SessionContext validates the protected function finds the lock and the held session in its context
+// TestSuoLockRun_SessionContext 验证受保护函数可以在其上下文中找到锁和所持会话
+func TestSuoLockRun_SessionContext(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		xin, ok := suoctx.Session(ctx)
+		require.True(t, ok)
+		holder, err := suo.Holder(ctx)
+		require.NoError(t, err)
+		require.Equal(t, holder, xin.SessionUUID())
+
+		lock, ok := suoctx.Suo(ctx)
+		require.True(t, ok)
+		require.Same(t, suo, lock)
+		return nil
+	}, 10*time.Millisecond)
+	require.NoError(t, err)
+}
@@ -74,3 +74,19 @@ func TestSuoLockRun(t *testing.T) {
 	}
 	wg.Wait() // Wait while goroutines complete their tasks
 }
+
+// TestSuoLockRun_Maintenance validates the runner fails fast while a maintenance gate freezes the lock
+// TestSuoLockRun_Maintenance 验证维护开关冻结锁期间运行器立即失败
+func TestSuoLockRun_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	key := utils.NewUUID()
+	gate := redissuo.NewMaintenanceGate(caseRedisClient, utils.NewUUID())
+	require.NoError(t, gate.Freeze(ctx, "", "deploy"))
+
+	suo := redissuo.NewSuo(caseRedisClient, key, 50*time.Millisecond).WithMaintenanceGate(gate)
+	err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		t.Fatal("run must not start during maintenance")
+		return nil
+	}, time.Millisecond*20)
+	require.ErrorIs(t, err, redissuo.ErrMaintenance)
+}
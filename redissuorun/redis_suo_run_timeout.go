@@ -0,0 +1,34 @@
+package redissuorun
+
+import (
+	"strconv"
+	"time"
+)
+
+// AcquireTimeoutError is returned when the context ends the wait ahead of acquisition
+// The breakdown tells contention (mostly busy attempts) from Redis trouble (mostly failed attempts) at a glance
+//
+// AcquireTimeoutError 在上下文于获取锁之前结束等待时返回
+// 其中的明细可以一眼区分锁竞争（大多为忙碌尝试）和 Redis 故障（大多为失败尝试）
+type AcquireTimeoutError struct {
+	Err       error         // Context problem ending the wait // 结束等待的上下文错误
+	Attempts  int           // Acquisition attempts made // 已进行的获取尝试次数
+	Busy      int           // Attempts finding the lock held elsewhere // 发现锁被其它会话持有的尝试次数
+	Transient int           // Attempts hitting a problem // 遇到错误的尝试次数
+	Slept     time.Duration // Whole backoff slept between attempts // 尝试之间累计的退避休眠时长
+	Waited    time.Duration // Whole wait duration // 整个等待时长
+}
+
+// Error renders the problem with the breakdown, e.g. "context deadline exceeded: 12 attempts (10 busy, 2 errors), slept 1.1s in 1.2s"
+// Error 输出错误及明细，例如 "context deadline exceeded: 12 attempts (10 busy, 2 errors), slept 1.1s in 1.2s"
+func (e *AcquireTimeoutError) Error() string {
+	return e.Err.Error() + ": " + strconv.Itoa(e.Attempts) + " attempts (" +
+		strconv.Itoa(e.Busy) + " busy, " + strconv.Itoa(e.Transient) + " errors), slept " +
+		e.Slept.String() + " in " + e.Waited.String()
+}
+
+// Unwrap gets back the context problem so errors.Is(err, context.DeadlineExceeded) keeps working
+// Unwrap 返回上下文错误，使 errors.Is(err, context.DeadlineExceeded) 依然有效
+func (e *AcquireTimeoutError) Unwrap() error {
+	return e.Err
+}
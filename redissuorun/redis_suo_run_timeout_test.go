@@ -0,0 +1,45 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRun_AcquireTimeoutError validates a timed out wait reports the breakdown of its attempts
+// TestSuoLockRun_AcquireTimeoutError 验证超时的等待会报告其尝试的明细
+func TestSuoLockRun_AcquireTimeoutError(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	err = redissuorun.SuoLockRun(timeoutCtx, suo, func(ctx context.Context) error {
+		return nil
+	}, 10*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var timeoutErr *redissuorun.AcquireTimeoutError
+	require.True(t, errors.As(err, &timeoutErr))
+	require.Positive(t, timeoutErr.Busy)
+	require.Zero(t, timeoutErr.Transient)
+	require.Equal(t, timeoutErr.Busy, timeoutErr.Attempts)
+	// The default backoff sleeps between the sleep and 8 times it
+	require.GreaterOrEqual(t, timeoutErr.Slept, time.Duration(timeoutErr.Busy)*10*time.Millisecond)
+	require.LessOrEqual(t, timeoutErr.Slept, time.Duration(timeoutErr.Busy)*80*time.Millisecond)
+	require.GreaterOrEqual(t, timeoutErr.Waited, timeoutErr.Slept)
+	require.Contains(t, err.Error(), "busy, 0 errors")
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
@@ -0,0 +1,132 @@
+package redissuorun
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TraceResult is the outcome of one acquisition attempt in the trace
+// TraceResult 是追踪记录中单次获取尝试的结果
+type TraceResult string
+
+const (
+	TraceAcquired TraceResult = "acquired" // Lock acquired // 已获取锁
+	TraceBusy     TraceResult = "busy"     // Lock held elsewhere // 锁被其它会话持有
+	TraceFailed   TraceResult = "error"    // Attempt hit a problem // 尝试遇到错误
+)
+
+// TraceEntry is one acquisition attempt of the wait
+// TraceEntry 是等待过程中的一次获取尝试
+type TraceEntry struct {
+	Offset  time.Duration // Time since the wait started // 距等待开始的时间
+	Result  TraceResult   // Outcome of the attempt // 尝试结果
+	Backoff time.Duration // Sleep chosen past the attempt, 0 on the last one // 尝试之后选择的休眠时长，最后一次为 0
+	Err     string        // Problem text on a failed attempt // 失败尝试的错误文本
+}
+
+// AcquireTrace is the bounded timeline of one wait on the lock
+// Keeps the latest entries up to the limit, counting the ones dropped in front of them
+//
+// AcquireTrace 是单次等锁过程的有限时间线
+// 保留不超过上限的最新条目，并统计被丢弃的早期条目数量
+type AcquireTrace struct {
+	Key     string        // Lock name ID // 锁名标识符
+	Entries []TraceEntry  // Latest attempts in sequence // 按顺序排列的最新尝试
+	Dropped int           // Attempts dropped past the limit // 超出上限被丢弃的尝试数
+	Waited  time.Duration // Whole wait duration // 整个等待时长
+	limit   int           // Max entries kept // 保留条目的上限
+	start   time.Time     // Start of the wait // 等待开始时间
+}
+
+// add appends the attempt, nil-safe so untraced runs skip it
+// add 追加一次尝试，对 nil 安全，使未开启追踪的运行直接跳过
+func (t *AcquireTrace) add(now time.Time, result TraceResult, backoff time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	entry := TraceEntry{Offset: now.Sub(t.start), Result: result, Backoff: backoff}
+	if err != nil {
+		entry.Err = err.Error()
+	}
+	if len(t.Entries) == t.limit {
+		t.Entries = t.Entries[1:]
+		t.Dropped++
+	}
+	t.Entries = append(t.Entries, entry)
+	t.Waited = entry.Offset
+}
+
+// String renders the trace on one line, e.g. "k waited 1.2s in 3 attempts (+0 dropped): 0s busy sleep 500ms, ..."
+// String 将追踪记录输出为一行，例如 "k waited 1.2s in 3 attempts (+0 dropped): 0s busy sleep 500ms, ..."
+func (t *AcquireTrace) String() string {
+	var builder strings.Builder
+	builder.WriteString(t.Key + " waited " + t.Waited.String() + " in " + strconv.Itoa(len(t.Entries)+t.Dropped) + " attempts (+" + strconv.Itoa(t.Dropped) + " dropped):")
+	for idx, entry := range t.Entries {
+		if idx > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(" " + entry.Offset.String() + " " + string(entry.Result))
+		if entry.Err != "" {
+			builder.WriteString(" [" + entry.Err + "]")
+		}
+		if entry.Backoff > 0 {
+			builder.WriteString(" sleep " + entry.Backoff.String())
+		}
+	}
+	return builder.String()
+}
+
+// TraceError carries the acquisition trace of a wait that gave up
+// TraceError 携带放弃等待时的获取追踪记录
+type TraceError struct {
+	Err   error         // Problem ending the wait // 结束等待的错误
+	Trace *AcquireTrace // Timeline of the wait // 等待的时间线
+}
+
+// Error renders the problem followed through the trace
+// Error 输出错误及其后的追踪记录
+func (e *TraceError) Error() string {
+	return e.Err.Error() + ": " + e.Trace.String()
+}
+
+// Unwrap gets back the problem so errors.Is and errors.As reach it
+// Unwrap 返回错误，使 errors.Is 和 errors.As 可以访问它
+func (e *TraceError) Unwrap() error {
+	return e.Err
+}
+
+// WithAcquireTrace records the acquisition timeline of each run, keeping the latest limit attempts
+// A wait that gives up returns a TraceError, the handler receives the trace of each wait and may be nil
+//
+// WithAcquireTrace 记录每次运行的获取时间线，保留最新的 limit 次尝试
+// 放弃等待时返回 TraceError，处理函数接收每次等待的追踪记录且可以为空
+func (c *Config) WithAcquireTrace(limit int, handler func(trace *AcquireTrace)) *Config {
+	c.traceLimit = limit
+	c.onTrace = handler
+	return c
+}
+
+// newTrace starts the trace of one wait, nil when tracing is off
+// newTrace 开始一次等待的追踪，未开启追踪时返回 nil
+func (c *Config) newTrace(key string, start time.Time) *AcquireTrace {
+	if c.traceLimit <= 0 {
+		return nil
+	}
+	return &AcquireTrace{Key: key, limit: c.traceLimit, start: start}
+}
+
+// finishTrace hands the trace to the handler and attaches it to the problem ending the wait
+// finishTrace 将追踪记录交给处理函数，并附加到结束等待的错误上
+func (c *Config) finishTrace(trace *AcquireTrace, err error) error {
+	if trace == nil {
+		return err
+	}
+	if c.onTrace != nil {
+		c.onTrace(trace)
+	}
+	if err != nil {
+		return &TraceError{Err: err, Trace: trace}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package redissuorun_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_AcquireTrace validates a wait that gives up returns the bounded timeline of its attempts
+// TestSuoLockRunWithConfig_AcquireTrace 验证放弃等待时返回其尝试的有限时间线
+func TestSuoLockRunWithConfig_AcquireTrace(t *testing.T) {
+	ctx := context.Background()
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	var traces []*redissuorun.AcquireTrace
+	config := redissuorun.NewConfig(10*time.Millisecond).WithBackoff(redissuorun.ConstantBackoff(10*time.Millisecond)).WithAcquireTrace(3, func(trace *redissuorun.AcquireTrace) {
+		traces = append(traces, trace)
+	})
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	err = redissuorun.SuoLockRunWithConfig(timeoutCtx, suo, func(ctx context.Context) error {
+		return nil
+	}, config)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var traceErr *redissuorun.TraceError
+	require.True(t, errors.As(err, &traceErr))
+	require.Len(t, traceErr.Trace.Entries, 3)
+	require.Positive(t, traceErr.Trace.Dropped)
+	require.Equal(t, redissuorun.TraceBusy, traceErr.Trace.Entries[2].Result)
+	require.Contains(t, err.Error(), "busy sleep 10ms")
+	require.Len(t, traces, 1)
+
+	success, err := suo.Release(ctx, xin)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.NoError(t, redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		return nil
+	}, config))
+	require.Len(t, traces, 2)
+	require.Equal(t, redissuorun.TraceAcquired, traces[1].Entries[0].Result)
+}
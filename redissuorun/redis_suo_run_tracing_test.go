@@ -0,0 +1,78 @@
+package redissuorun_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// spanParentKey carries the name of the enclosing span in the context
+// spanParentKey 在上下文中携带外层 span 的名称
+type spanParentKey struct{}
+
+// nestedSpan is one span kept through nestingTracer
+// nestedSpan 是 nestingTracer 记录的一个 span
+type nestedSpan struct {
+	name       string
+	parent     string
+	attributes map[string]any
+	ended      bool
+}
+
+func (s *nestedSpan) SetAttribute(key string, value any) {
+	s.attributes[key] = value
+}
+
+func (s *nestedSpan) End(err error) {
+	s.ended = true
+}
+
+// nestingTracer keeps the started spans with the names of their parents
+// nestingTracer 记录开启的 span 及其父 span 的名称
+type nestingTracer struct {
+	mutex sync.Mutex
+	spans []*nestedSpan
+}
+
+func (r *nestingTracer) Start(ctx context.Context, name string) (context.Context, redissuo.Span) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	parent, _ := ctx.Value(spanParentKey{}).(string)
+	span := &nestedSpan{name: name, parent: parent, attributes: map[string]any{}}
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, spanParentKey{}, name), span
+}
+
+// TestSuoLockRun_Tracer validates the wait and the critical section get spans, with acquisitions nested in the wait
+// TestSuoLockRun_Tracer 验证等待和临界区都有 span，且获取嵌套在等待之中
+func TestSuoLockRun_Tracer(t *testing.T) {
+	ctx := context.Background()
+	tracer := &nestingTracer{}
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second).WithTracer(tracer)
+
+	var parent string
+	require.NoError(t, redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		parent, _ = ctx.Value(spanParentKey{}).(string)
+		return nil
+	}, 10*time.Millisecond))
+	require.Equal(t, redissuo.SpanRun, parent)
+
+	tracer.mutex.Lock()
+	defer tracer.mutex.Unlock()
+	names := map[string]*nestedSpan{}
+	for _, span := range tracer.spans {
+		require.True(t, span.ended)
+		names[span.name] = span
+	}
+	require.Equal(t, redissuo.SpanWait, names[redissuo.SpanAcquire].parent)
+	require.Equal(t, "acquired", names[redissuo.SpanWait].attributes["lock.outcome"])
+	require.Contains(t, names[redissuo.SpanWait].attributes, "lock.wait_ms")
+	require.Equal(t, "ok", names[redissuo.SpanRun].attributes["lock.outcome"])
+	require.Equal(t, "deleted", names[redissuo.SpanRelease].attributes["lock.outcome"])
+}
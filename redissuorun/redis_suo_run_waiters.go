@@ -0,0 +1,50 @@
+package redissuorun
+
+import (
+	"sync"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+)
+
+// ErrTooManyWaiters is returned when the per-process waiter limit of a key is reached
+// ErrTooManyWaiters 在达到某个键的进程内等待者上限时返回
+var ErrTooManyWaiters = redissuo.NewError(redissuo.CodeTooManyWaiters, redissuo.LanguageEnglish, nil)
+
+// processWaiters counts goroutines of this process waiting on each key
+// processWaiters 统计本进程中等待各个键的 goroutine 数量
+var processWaiters = &waiterBoard{counts: map[string]int{}}
+
+// waiterBoard tracks waiter counts per key
+// waiterBoard 跟踪每个键的等待者数量
+type waiterBoard struct {
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+// enter registers a waiter, false when the limit is reached, limit 0 means unlimited
+// enter 登记一个等待者，达到上限时返回 false，上限为 0 表示不限制
+func (b *waiterBoard) enter(key string, limit int) bool {
+	if limit <= 0 {
+		return true
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.counts[key] >= limit {
+		return false
+	}
+	b.counts[key]++
+	return true
+}
+
+// leave unregisters a waiter admitted through enter using the same limit
+// leave 注销通过 enter 以相同上限登记的等待者
+func (b *waiterBoard) leave(key string, limit int) {
+	if limit <= 0 {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.counts[key]--; b.counts[key] <= 0 {
+		delete(b.counts, key)
+	}
+}
@@ -0,0 +1,57 @@
+package redissuorun_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuoLockRunWithConfig_MaxWaiters validates the per-process waiter limit
+// Tests that waiters past the limit fail fast while the lock is stuck
+//
+// TestSuoLockRunWithConfig_MaxWaiters 验证进程内等待者上限
+// 测试锁卡住时超出上限的等待者快速失败
+func TestSuoLockRunWithConfig_MaxWaiters(t *testing.T) {
+	suo := redissuo.NewSuo(caseRedisClient, utils.NewUUID(), 5*time.Second)
+
+	// Hold the lock so every runner has to wait
+	xin, err := suo.Acquire(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+
+	config := redissuorun.NewConfig(10 * time.Millisecond).WithMaxWaiters(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < 2; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+				return nil
+			}, config)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond) // Let the two waiters register
+
+	err = redissuorun.SuoLockRunWithConfig(ctx, suo, func(ctx context.Context) error {
+		return nil
+	}, config)
+	require.ErrorIs(t, err, redissuorun.ErrTooManyWaiters)
+
+	wg.Wait()
+
+	success, err := suo.Release(context.Background(), xin)
+	require.NoError(t, err)
+	require.True(t, success)
+}
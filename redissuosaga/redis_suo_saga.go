@@ -0,0 +1,156 @@
+// Package redissuosaga: Saga coordinator running ordered steps each under its own named lock
+// Persists step status in Redis so a rerun skips finished steps, and compensates finished steps on failure
+// Builds on redissuorun, so acquisition retries, release guarantees and panic handling stay the same
+//
+// redissuosaga: 在各自命名锁下执行有序步骤的 Saga 协调器
+// 在 Redis 中持久化步骤状态，使重新运行时跳过已完成的步骤，并在失败时补偿已完成的步骤
+// 基于 redissuorun 构建，因此获取重试、释放保证和 panic 恢复保持一致
+package redissuosaga
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// StepStatus is the persisted state of one step
+// StepStatus 是单个步骤的持久化状态
+type StepStatus string
+
+const (
+	StepPending            StepStatus = ""                    // Not run yet // 尚未运行
+	StepDone               StepStatus = "done"                // Run succeeded // 运行成功
+	StepFailed             StepStatus = "failed"              // Run failed // 运行失败
+	StepCompensated        StepStatus = "compensated"         // Undone through compensation // 已通过补偿撤销
+	StepCompensationFailed StepStatus = "compensation_failed" // Compensation failed, needs a human // 补偿失败，需要人工处理
+)
+
+// Step is one unit of the saga, Compensate undoes Run and may be nil when nothing needs undoing
+// Step 是 Saga 的一个单元，Compensate 用于撤销 Run，无需撤销时可以为 nil
+type Step struct {
+	Name       string                          // Step name, unique in the saga // 步骤名称，在 Saga 中唯一
+	Run        func(ctx context.Context) error // Forward action // 正向操作
+	Compensate func(ctx context.Context) error // Undo action // 撤销操作
+}
+
+// Saga runs its steps in sequence, each under the lock "<name>:<step>"
+// Step status lives in the hash "<name>:steps", so reruns after a crash resume past finished steps
+//
+// Saga 按顺序执行步骤，每个步骤在锁 "<name>:<step>" 下运行
+// 步骤状态保存在哈希 "<name>:steps" 中，使崩溃后的重新运行从已完成步骤之后继续
+type Saga struct {
+	redisClient redis.UniversalClient // Redis client connection // Redis 客户端连接
+	name        string                // Saga name ID // Saga 名称标识符
+	ttl         time.Duration         // TTL of each step lock // 每个步骤锁的 TTL
+	config      *redissuorun.Config   // Runner settings of each step // 每个步骤的运行器设置
+	steps       []*Step               // Steps in sequence // 按顺序排列的步骤
+}
+
+// NewSaga creates a saga whose step locks use the given TTL and runner config
+// NewSaga 创建 Saga，其步骤锁使用给定的 TTL 和运行器配置
+func NewSaga(rds redis.UniversalClient, name string, ttl time.Duration, config *redissuorun.Config) *Saga {
+	return &Saga{
+		redisClient: must.Nice(rds),
+		name:        must.Nice(name),
+		ttl:         must.Nice(ttl),
+		config:      must.Nice(config),
+	}
+}
+
+// AddStep appends a step, compensate may be nil
+// AddStep 追加一个步骤，compensate 可以为 nil
+func (s *Saga) AddStep(name string, run func(ctx context.Context) error, compensate func(ctx context.Context) error) *Saga {
+	s.steps = append(s.steps, &Step{Name: must.Nice(name), Run: run, Compensate: compensate})
+	return s
+}
+
+// statusKey gets back the hash holding the status of each step
+// statusKey 返回保存各步骤状态的哈希
+func (s *Saga) statusKey() string {
+	return s.name + ":steps"
+}
+
+// Execute runs the steps in sequence, skipping the ones already done
+// On a failure the done steps get compensated in reverse sequence, and the joined problems come back
+//
+// Execute 按顺序运行步骤，跳过已完成的步骤
+// 失败时按逆序补偿已完成的步骤，并返回合并后的错误
+func (s *Saga) Execute(ctx context.Context) error {
+	statuses, err := s.Status(ctx)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	for idx, step := range s.steps {
+		if statuses[step.Name] == StepDone {
+			continue
+		}
+		if err := s.runStep(ctx, step, step.Run, StepDone, StepFailed); err != nil {
+			errs := []error{erero.WithMessagef(err, "step %s", step.Name)}
+			errs = append(errs, s.compensate(ctx, idx)...)
+			return erero.Joins(errs)
+		}
+	}
+	return nil
+}
+
+// compensate undoes the steps ahead of the failed one in reverse sequence
+// compensate 按逆序撤销失败步骤之前的步骤
+func (s *Saga) compensate(ctx context.Context, failed int) []error {
+	var errs []error
+	for idx := failed - 1; idx >= 0; idx-- {
+		step := s.steps[idx]
+		if step.Compensate == nil {
+			continue
+		}
+		if err := s.runStep(ctx, step, step.Compensate, StepCompensated, StepCompensationFailed); err != nil {
+			errs = append(errs, erero.WithMessagef(err, "compensate %s", step.Name))
+		}
+	}
+	return errs
+}
+
+// runStep runs the action under the lock of the step and persists the resulting status
+// runStep 在步骤的锁下执行操作并持久化结果状态
+func (s *Saga) runStep(ctx context.Context, step *Step, action func(ctx context.Context) error, success StepStatus, failure StepStatus) error {
+	suo := redissuo.NewSuo(s.redisClient, s.name+":"+step.Name, s.ttl)
+	erx := redissuorun.SuoLockRunWithConfig(ctx, suo, action, s.config)
+	status := success
+	if erx != nil {
+		status = failure
+	}
+	if err := s.redisClient.HSet(ctx, s.statusKey(), step.Name, string(status)).Err(); err != nil {
+		return erero.Joins([]error{erx, erero.Wro(err)})
+	}
+	if erx != nil {
+		return erero.Wro(erx)
+	}
+	return nil
+}
+
+// Status gets back the persisted status of each step, steps not run yet are missing
+// Status 返回各步骤的持久化状态，尚未运行的步骤不在其中
+func (s *Saga) Status(ctx context.Context) (map[string]StepStatus, error) {
+	fields, err := s.redisClient.HGetAll(ctx, s.statusKey()).Result()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	statuses := make(map[string]StepStatus, len(fields))
+	for name, value := range fields {
+		statuses[name] = StepStatus(value)
+	}
+	return statuses, nil
+}
+
+// Reset removes the persisted status, letting the saga run from the first step again
+// Reset 删除持久化状态，使 Saga 从第一步重新运行
+func (s *Saga) Reset(ctx context.Context) error {
+	if err := s.redisClient.Del(ctx, s.statusKey()).Err(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package redissuosaga_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/internal/utils"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/go-xlan/redis-go-suo/redissuosaga"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+var caseRedisClient redis.UniversalClient
+
+func TestMain(m *testing.M) {
+	miniRedis := rese.P1(miniredis.Run())
+	defer miniRedis.Close()
+
+	redisClient := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs: []string{miniRedis.Addr()},
+	})
+	must.Done(redisClient.Ping(context.Background()).Err())
+
+	caseRedisClient = redisClient
+
+	m.Run()
+}
+
+// TestSaga_Execute validates a failed step compensates the done steps and a rerun skips steps already done
+// TestSaga_Execute 验证失败的步骤会补偿已完成的步骤，重新运行时跳过已完成的步骤
+func TestSaga_Execute(t *testing.T) {
+	ctx := context.Background()
+	var trace []string
+	var fail = true
+	step := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			trace = append(trace, name)
+			if name == "ship" && fail {
+				return errors.New("boom")
+			}
+			return nil
+		}
+	}
+
+	saga := redissuosaga.NewSaga(caseRedisClient, utils.NewUUID(), 5*time.Second, redissuorun.NewConfig(10*time.Millisecond)).
+		AddStep("reserve", step("reserve"), step("unreserve")).
+		AddStep("charge", step("charge"), step("refund")).
+		AddStep("ship", step("ship"), nil)
+
+	require.Error(t, saga.Execute(ctx))
+	require.Equal(t, []string{"reserve", "charge", "ship", "refund", "unreserve"}, trace)
+
+	statuses, err := saga.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, redissuosaga.StepCompensated, statuses["reserve"])
+	require.Equal(t, redissuosaga.StepFailed, statuses["ship"])
+
+	// The rerun finishes, then a later rerun skips the done steps
+	fail = false
+	trace = nil
+	require.NoError(t, saga.Execute(ctx))
+	require.Equal(t, []string{"reserve", "charge", "ship"}, trace)
+
+	trace = nil
+	require.NoError(t, saga.Execute(ctx))
+	require.Empty(t, trace)
+
+	require.NoError(t, saga.Reset(ctx))
+	statuses, err = saga.Status(ctx)
+	require.NoError(t, err)
+	require.Empty(t, statuses)
+}
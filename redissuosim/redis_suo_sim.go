@@ -0,0 +1,217 @@
+// Package redissuosim: Deterministic simulation harness running locks on virtual time
+// Drives miniredis TTLs and the lock clock together, so expiry and extension scenarios finish in milliseconds
+// Sleeps advance the virtual clock at once, making sequential scenarios reproducible run after run
+//
+// redissuosim: 在虚拟时间上运行锁的确定性模拟工具
+// 同时驱动 miniredis 的 TTL 和锁的时钟，使过期和延期场景在毫秒内完成
+// 休眠会立即推进虚拟时钟，使顺序场景每次运行结果一致
+package redissuosim
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// Simulation bundles a miniredis server, a client bound to it, and a virtual clock
+// Create locks through NewSuo so they share the virtual clock
+//
+// Simulation 组合 miniredis 服务、与之绑定的客户端以及虚拟时钟
+// 通过 NewSuo 创建锁使其共享虚拟时钟
+type Simulation struct {
+	miniRedis   *miniredis.Miniredis  // In-memory Redis server // 内存 Redis 服务
+	redisClient redis.UniversalClient // Client bound to the server // 与服务绑定的客户端
+	clock       *VirtualClock         // Shared virtual clock // 共享的虚拟时钟
+}
+
+// NewSimulation starts a miniredis server with its clock pinned at the given start time
+// NewSimulation 启动 miniredis 服务，并将其时钟固定在给定的开始时间
+func NewSimulation(start time.Time) (*Simulation, error) {
+	miniRedis, err := miniredis.Run()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	miniRedis.SetTime(start)
+	return &Simulation{
+		miniRedis:   miniRedis,
+		redisClient: redis.NewClient(&redis.Options{Addr: miniRedis.Addr()}),
+		clock:       &VirtualClock{now: start, miniRedis: miniRedis},
+	}, nil
+}
+
+// Client gets back the Redis client bound to the simulated server
+// Client 返回与模拟服务绑定的 Redis 客户端
+func (s *Simulation) Client() redis.UniversalClient {
+	return s.redisClient
+}
+
+// Clock gets back the virtual clock
+// Clock 返回虚拟时钟
+func (s *Simulation) Clock() *VirtualClock {
+	return s.clock
+}
+
+// Miniredis gets back the server, letting scenarios inject faults or inspect keys
+// Miniredis 返回服务，便于场景注入故障或检查键
+func (s *Simulation) Miniredis() *miniredis.Miniredis {
+	return s.miniRedis
+}
+
+// NewSuo creates a lock on the simulated server using the virtual clock
+// NewSuo 在模拟服务上创建使用虚拟时钟的锁
+func (s *Simulation) NewSuo(key string, ttl time.Duration) *redissuo.Suo {
+	return redissuo.NewSuo(s.redisClient, key, ttl).WithClock(s.clock)
+}
+
+// Advance moves virtual time forward, expiring keys whose TTL runs out
+// Advance 推进虚拟时间，使 TTL 耗尽的键过期
+func (s *Simulation) Advance(duration time.Duration) {
+	s.clock.Advance(duration)
+}
+
+// Close shuts down the client and the server
+// Close 关闭客户端和服务
+func (s *Simulation) Close() {
+	must.Done(s.redisClient.Close())
+	s.miniRedis.Close()
+}
+
+// VirtualClock is a redissuo.Clock whose time only moves when advanced
+// Sleep advances the clock at once instead of blocking, timers fire once the clock gets advanced past their deadline
+//
+// VirtualClock 是一个仅在推进时才移动时间的 redissuo.Clock
+// Sleep 会立即推进时钟而不是阻塞，定时器在时钟被推进越过其截止时间时触发
+type VirtualClock struct {
+	mutex     sync.Mutex           // Guards now, the server time and the timers // 保护当前时间、服务时间和定时器
+	now       time.Time            // Virtual time at present // 当前的虚拟时间
+	miniRedis *miniredis.Miniredis // Server kept in step // 保持同步的服务
+	timers    []*virtualTimer      // Pending timers // 待触发的定时器
+}
+
+// Now gets back the virtual time
+// Now 返回虚拟时间
+func (c *VirtualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Sleep advances the virtual time by the given duration
+// Sleep 将虚拟时间推进给定时长
+func (c *VirtualClock) Sleep(duration time.Duration) {
+	c.Advance(duration)
+}
+
+// Advance moves the virtual time and the server clock and TTLs forward together
+// Advance 同时推进虚拟时间、服务时钟和 TTL
+func (c *VirtualClock) Advance(duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	c.now = c.now.Add(duration)
+	c.miniRedis.SetTime(c.now)
+	c.miniRedis.FastForward(duration)
+	due := c.takeDue()
+	c.mutex.Unlock()
+	for _, timer := range due {
+		timer.fire()
+	}
+}
+
+// NewTimer creates a timer sending the virtual time on its channel once the clock passes the deadline
+// NewTimer 创建在时钟越过截止时间时于其通道上发送虚拟时间的定时器
+func (c *VirtualClock) NewTimer(duration time.Duration) redissuo.Timer {
+	timer := &virtualTimer{clock: c, channel: make(chan time.Time, 1)}
+	timer.Reset(duration)
+	return timer
+}
+
+// AfterFunc creates a timer calling run on its own goroutine once the clock passes the deadline
+// AfterFunc 创建在时钟越过截止时间时于独立 goroutine 中调用 run 的定时器
+func (c *VirtualClock) AfterFunc(duration time.Duration, run func()) redissuo.Timer {
+	timer := &virtualTimer{clock: c, run: run}
+	timer.Reset(duration)
+	return timer
+}
+
+// takeDue removes the timers whose deadline has come, earliest first, the mutex must be held
+// takeDue 移除截止时间已到的定时器（最早的在前），调用时必须持有互斥锁
+func (c *VirtualClock) takeDue() []*virtualTimer {
+	var due, pending []*virtualTimer
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			due = append(due, timer)
+		}
+	}
+	c.timers = pending
+	sort.SliceStable(due, func(i, j int) bool { return due[i].deadline.Before(due[j].deadline) })
+	return due
+}
+
+// remove drops a pending timer, reporting whether it was pending, the mutex must be held
+// remove 移除待触发的定时器并报告其是否处于待触发状态，调用时必须持有互斥锁
+func (c *VirtualClock) remove(timer *virtualTimer) bool {
+	for idx, pending := range c.timers {
+		if pending == timer {
+			c.timers = append(c.timers[:idx], c.timers[idx+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// virtualTimer is a redissuo.Timer driven through a VirtualClock
+// virtualTimer 是由 VirtualClock 驱动的 redissuo.Timer
+type virtualTimer struct {
+	clock    *VirtualClock  // Clock driving the timer // 驱动定时器的时钟
+	deadline time.Time      // Virtual time the timer fires at // 定时器触发的虚拟时间
+	channel  chan time.Time // Receives the deadline, nil in AfterFunc timers // 接收截止时间，AfterFunc 定时器中为 nil
+	run      func()         // Called on firing, nil in NewTimer timers // 触发时调用，NewTimer 定时器中为 nil
+}
+
+func (t *virtualTimer) C() <-chan time.Time {
+	return t.channel
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *virtualTimer) Reset(duration time.Duration) bool {
+	c := t.clock
+	c.mutex.Lock()
+	pending := c.remove(t)
+	t.deadline = c.now.Add(duration)
+	if duration > 0 {
+		c.timers = append(c.timers, t)
+		c.mutex.Unlock()
+		return pending
+	}
+	c.mutex.Unlock()
+	t.fire()
+	return pending
+}
+
+// fire delivers the deadline, a full channel keeps the earlier value as time.Timer does
+// fire 投递截止时间，通道已满时与 time.Timer 一样保留先前的值
+func (t *virtualTimer) fire() {
+	if t.run != nil {
+		go t.run()
+		return
+	}
+	select {
+	case t.channel <- t.deadline:
+	default:
+	}
+}
@@ -0,0 +1,92 @@
+package redissuosim
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/yyle88/erero"
+)
+
+// ScriptCase is one golden case of a Lua script: a pre-state, a call, and the expected outcome
+// Keys named in Strings, TTLs, Keys and After are cleared ahead of and past the case, nothing else is touched
+//
+// ScriptCase 是 Lua 脚本的一个黄金用例：前置状态、一次调用以及期望结果
+// 在 Strings、TTLs、Keys 和 After 中出现的键会在用例前后清除，其它键不受影响
+type ScriptCase struct {
+	Name    string                   // Case name used in failures // 失败时使用的用例名称
+	Strings map[string]string        // String keys set ahead of the call // 调用前设置的字符串键
+	TTLs    map[string]time.Duration // TTLs applied to pre-state keys // 应用到前置状态键的 TTL
+	Keys    []string                 // KEYS of the call // 调用的 KEYS
+	Args    []interface{}            // ARGV of the call // 调用的 ARGV
+	Want    interface{}              // Expected reply, nil when the script replies nil // 期望的回复，脚本回复 nil 时为 nil
+	After   map[string]string        // Expected string keys past the call, blank means absent // 调用后期望的字符串键，为空表示不存在
+}
+
+// RunScriptCase executes one case against the client and reports the first mismatch
+// Works against miniredis and real Redis alike
+//
+// RunScriptCase 针对客户端执行一个用例并报告第一个不一致
+// 对 miniredis 和真实 Redis 同样适用
+func RunScriptCase(ctx context.Context, redisClient redis.UniversalClient, script string, scriptCase *ScriptCase) error {
+	if keys := scriptCase.touchedKeys(); len(keys) > 0 {
+		if err := redisClient.Del(ctx, keys...).Err(); err != nil {
+			return erero.Wro(err)
+		}
+		defer redisClient.Del(context.Background(), keys...)
+	}
+
+	for key, value := range scriptCase.Strings {
+		if err := redisClient.Set(ctx, key, value, scriptCase.TTLs[key]).Err(); err != nil {
+			return erero.Wro(err)
+		}
+	}
+
+	reply, err := redisClient.Eval(ctx, script, scriptCase.Keys, scriptCase.Args...).Result()
+	if errors.Is(err, redis.Nil) {
+		reply = nil
+	} else if err != nil {
+		return errors.WithMessagef(err, "case %s: eval", scriptCase.Name)
+	}
+	if !reflect.DeepEqual(reply, scriptCase.Want) {
+		return errors.Errorf("case %s: reply %#v, want %#v", scriptCase.Name, reply, scriptCase.Want)
+	}
+
+	for key, want := range scriptCase.After {
+		value, err := redisClient.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			value = ""
+		} else if err != nil {
+			return errors.WithMessagef(err, "case %s: get %s", scriptCase.Name, key)
+		}
+		if value != want {
+			return errors.Errorf("case %s: key %s is %q, want %q", scriptCase.Name, key, value, want)
+		}
+	}
+	return nil
+}
+
+// touchedKeys lists each key the case reads or writes
+// touchedKeys 列出用例读写的每个键
+func (c *ScriptCase) touchedKeys() []string {
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range c.Keys {
+		add(key)
+	}
+	for key := range c.Strings {
+		add(key)
+	}
+	for key := range c.After {
+		add(key)
+	}
+	return keys
+}
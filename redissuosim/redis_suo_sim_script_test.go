@@ -0,0 +1,80 @@
+package redissuosim_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuosim"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+// caseScriptMatrix holds golden cases of the core scripts across lock pre-states
+// caseScriptMatrix 保存核心脚本在各种锁前置状态下的黄金用例
+var caseScriptMatrix = map[string][]*redissuosim.ScriptCase{
+	redissuo.ScriptAcquire: {
+		{Name: "free", Keys: []string{"k"}, Args: []interface{}{"s1", 1000}, Want: "OK", After: map[string]string{"k": "s1"}},
+		{Name: "same-session", Strings: map[string]string{"k": "s1"}, Keys: []string{"k"}, Args: []interface{}{"s1", 1000}, Want: "OK", After: map[string]string{"k": "s1"}},
+		{Name: "other-session", Strings: map[string]string{"k": "s2"}, Keys: []string{"k"}, Args: []interface{}{"s1", 1000}, Want: nil, After: map[string]string{"k": "s2"}},
+	},
+	redissuo.ScriptAcquireStrict: {
+		{Name: "free", Keys: []string{"k"}, Args: []interface{}{"s1", 1000}, Want: "OK", After: map[string]string{"k": "s1"}},
+		{Name: "same-session", Strings: map[string]string{"k": "s1"}, Keys: []string{"k"}, Args: []interface{}{"s1", 1000}, Want: "HELD"},
+		{Name: "other-session", Strings: map[string]string{"k": "s2"}, Keys: []string{"k"}, Args: []interface{}{"s1", 1000}, Want: nil},
+	},
+	redissuo.ScriptAcquireGuarded: {
+		{Name: "guard-passes", Keys: []string{"k", "g"}, Args: []interface{}{"s1", 1000, 1, "absent", ""}, Want: "OK", After: map[string]string{"k": "s1"}},
+		{Name: "guard-rejects", Strings: map[string]string{"g": "on"}, Keys: []string{"k", "g"}, Args: []interface{}{"s1", 1000, 1, "absent", ""}, Want: "GUARD", After: map[string]string{"k": ""}},
+	},
+	redissuo.ScriptRelease: {
+		{Name: "free", Keys: []string{"k"}, Args: []interface{}{"s1"}, Want: int64(2)},
+		{Name: "own", Strings: map[string]string{"k": "s1"}, TTLs: map[string]time.Duration{"k": time.Minute}, Keys: []string{"k"}, Args: []interface{}{"s1"}, Want: int64(1), After: map[string]string{"k": ""}},
+		{Name: "other-session", Strings: map[string]string{"k": "s2"}, Keys: []string{"k"}, Args: []interface{}{"s1"}, Want: int64(3), After: map[string]string{"k": "s2"}},
+	},
+	redissuo.ScriptFencedSet: {
+		{Name: "unfenced", Keys: []string{"v", "f"}, Args: []interface{}{"a", 3}, Want: int64(1), After: map[string]string{"v": "a", "f": "3"}},
+		{Name: "newer-token", Strings: map[string]string{"v": "a", "f": "3"}, Keys: []string{"v", "f"}, Args: []interface{}{"b", 4}, Want: int64(1), After: map[string]string{"v": "b", "f": "4"}},
+		{Name: "stale-token", Strings: map[string]string{"v": "a", "f": "3"}, Keys: []string{"v", "f"}, Args: []interface{}{"b", 2}, Want: int64(0), After: map[string]string{"v": "a", "f": "3"}},
+	},
+}
+
+// TestScripts validates every script in the matrix is exposed
+// TestScripts 验证矩阵中的每个脚本都已公开
+func TestScripts(t *testing.T) {
+	scripts := redissuo.Scripts()
+	for name := range caseScriptMatrix {
+		require.NotEmpty(t, scripts[name], name)
+	}
+}
+
+// TestRunScriptCase runs the golden matrix against miniredis
+// Set REDIS_SUO_SCRIPT_ADDR to run the same matrix against a real Redis as well
+//
+// TestRunScriptCase 针对 miniredis 运行黄金矩阵
+// 设置 REDIS_SUO_SCRIPT_ADDR 后也会针对真实 Redis 运行同一矩阵
+func TestRunScriptCase(t *testing.T) {
+	sim := rese.P1(redissuosim.NewSimulation(caseStartTime))
+	defer sim.Close()
+
+	clients := map[string]redis.UniversalClient{"miniredis": sim.Client()}
+	if addr := os.Getenv("REDIS_SUO_SCRIPT_ADDR"); addr != "" {
+		realClient := redis.NewClient(&redis.Options{Addr: addr})
+		defer func() { _ = realClient.Close() }()
+		clients["redis"] = realClient
+	}
+
+	scripts := redissuo.Scripts()
+	for clientName, redisClient := range clients {
+		for name, cases := range caseScriptMatrix {
+			for _, scriptCase := range cases {
+				t.Run(clientName+"/"+name+"/"+scriptCase.Name, func(t *testing.T) {
+					require.NoError(t, redissuosim.RunScriptCase(context.Background(), redisClient, scripts[name], scriptCase))
+				})
+			}
+		}
+	}
+}
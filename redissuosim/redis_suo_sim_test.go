@@ -0,0 +1,116 @@
+package redissuosim_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/redissuorun"
+	"github.com/go-xlan/redis-go-suo/redissuosim"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+)
+
+var caseStartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// TestSimulation_ExtensionRace validates extension ahead of expiry and takeover past it
+// Tests that an hour of lock activity runs on virtual time with exact expiry values
+//
+// TestSimulation_ExtensionRace 验证过期前延期以及过期后被接管
+// 测试一小时的锁活动在虚拟时间上运行且过期时间精确
+func TestSimulation_ExtensionRace(t *testing.T) {
+	ctx := context.Background()
+	sim := rese.P1(redissuosim.NewSimulation(caseStartTime))
+	defer sim.Close()
+
+	suo := sim.NewSuo("sim-extension", time.Minute).WithServerTime(true)
+
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, caseStartTime.Add(time.Minute), xin.Expire())
+	require.True(t, caseStartTime.Add(time.Minute).Equal(xin.ServerExpire()))
+
+	sim.Advance(59 * time.Second)
+	xin, err = suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	require.Equal(t, caseStartTime.Add(119*time.Second), xin.Expire())
+
+	sim.Advance(time.Hour)
+	other, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+
+	// The first session lost the lock while it was away
+	extended, err := suo.AcquireAgainExtendLock(ctx, xin)
+	require.NoError(t, err)
+	require.Nil(t, extended)
+}
+
+// TestSimulation_ExpiryDuringRun validates a run outliving its lease
+// Tests that a waiter gets the lock once the lease runs out in the middle of the run
+//
+// TestSimulation_ExpiryDuringRun 验证运行时间超过租期的情况
+// 测试租期在运行中途耗尽后等待者可以获得锁
+func TestSimulation_ExpiryDuringRun(t *testing.T) {
+	ctx := context.Background()
+	sim := rese.P1(redissuosim.NewSimulation(caseStartTime))
+	defer sim.Close()
+
+	suo := sim.NewSuo("sim-expiry", 10*time.Second)
+
+	err := redissuorun.SuoLockRun(ctx, suo, func(ctx context.Context) error {
+		sim.Clock().Sleep(30 * time.Second)
+
+		other, err := suo.Acquire(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, other)
+		return nil
+	}, time.Second)
+	require.NoError(t, err)
+	// Release keeps retrying until the other session's 10s lease runs out too
+	require.Equal(t, caseStartTime.Add(40*time.Second), sim.Clock().Now())
+}
+
+// TestSimulation_TimersFollowVirtualTime validates hold contexts and expiry warnings fire on virtual time
+// Tests that nothing fires ahead of the advance and both fire once the clock passes the lease
+//
+// TestSimulation_TimersFollowVirtualTime 验证持有上下文和过期警告按虚拟时间触发
+// 测试推进之前不会触发，时钟越过租期后两者都会触发
+func TestSimulation_TimersFollowVirtualTime(t *testing.T) {
+	ctx := context.Background()
+	sim := rese.P1(redissuosim.NewSimulation(caseStartTime))
+	defer sim.Close()
+
+	suo := sim.NewSuo("sim-timers", time.Minute).WithExpiringSoon(10*time.Second, nil)
+	xin, err := suo.Acquire(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, xin)
+	holdCtx, cancel := suo.HoldContext(ctx, xin)
+	defer cancel()
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, holdCtx.Err())
+	select {
+	case <-xin.ExpiringSoon():
+		t.Fatal("warning fired ahead of virtual time")
+	default:
+	}
+
+	sim.Advance(55 * time.Second)
+	require.Eventually(t, func() bool {
+		select {
+		case <-xin.ExpiringSoon():
+			return true
+		default:
+			return false
+		}
+	}, time.Second, time.Millisecond)
+	require.NoError(t, holdCtx.Err())
+
+	sim.Advance(5 * time.Second)
+	require.Eventually(t, func() bool { return holdCtx.Err() != nil }, time.Second, time.Millisecond)
+	require.ErrorIs(t, context.Cause(holdCtx), redissuo.ErrLockLost)
+}
@@ -0,0 +1,49 @@
+// Package suoctx: Typed context keys carrying the current lock session through deep call stacks
+// Code inside the protected function reaches the session (fencing token, extension, logging) without threading Xin through every signature
+// The redissuorun runners put the lock and the session into the context handed to the protected function
+//
+// suoctx: 在深层调用栈中携带当前锁会话的类型化上下文键
+// 受保护函数内部的代码无需在每个函数签名中传递 Xin 即可获取会话（防护令牌、延期、日志）
+// redissuorun 运行器会将锁和会话放入传给受保护函数的上下文中
+package suoctx
+
+import (
+	"context"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/yyle88/must"
+)
+
+// sessionKey is the context key of the lock session
+// sessionKey 是锁会话的上下文键
+type sessionKey struct{}
+
+// suoKey is the context key of the lock
+// suoKey 是锁的上下文键
+type suoKey struct{}
+
+// WithSession gets back a context carrying the lock session
+// WithSession 返回携带锁会话的上下文
+func WithSession(ctx context.Context, xin *redissuo.Xin) context.Context {
+	return context.WithValue(ctx, sessionKey{}, must.Nice(xin))
+}
+
+// Session gets back the lock session carried in the context, false when none
+// Session 返回上下文中携带的锁会话，没有时返回 false
+func Session(ctx context.Context) (*redissuo.Xin, bool) {
+	xin, ok := ctx.Value(sessionKey{}).(*redissuo.Xin)
+	return xin, ok
+}
+
+// WithSuo gets back a context carrying the lock, letting deep code extend the session
+// WithSuo 返回携带锁的上下文，使深层代码可以延期会话
+func WithSuo(ctx context.Context, suo *redissuo.Suo) context.Context {
+	return context.WithValue(ctx, suoKey{}, must.Nice(suo))
+}
+
+// Suo gets back the lock carried in the context, false when none
+// Suo 返回上下文中携带的锁，没有时返回 false
+func Suo(ctx context.Context) (*redissuo.Suo, bool) {
+	suo, ok := ctx.Value(suoKey{}).(*redissuo.Suo)
+	return suo, ok
+}
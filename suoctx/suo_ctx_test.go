@@ -0,0 +1,34 @@
+package suoctx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/redis-go-suo/redissuo"
+	"github.com/go-xlan/redis-go-suo/suoctx"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSession validates the session and the lock round-trip through the context
+// TestSession 验证会话和锁可以通过上下文存取
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+	_, ok := suoctx.Session(ctx)
+	require.False(t, ok)
+	_, ok = suoctx.Suo(ctx)
+	require.False(t, ok)
+
+	now := time.Now()
+	xin := redissuo.NewXin("job", "session", now.Add(time.Second), now)
+	suo := redissuo.NewSuo(redis.NewClient(&redis.Options{}), "job", time.Second)
+	ctx = suoctx.WithSuo(suoctx.WithSession(ctx, xin), suo)
+
+	res, ok := suoctx.Session(ctx)
+	require.True(t, ok)
+	require.Same(t, xin, res)
+	lock, ok := suoctx.Suo(ctx)
+	require.True(t, ok)
+	require.Same(t, suo, lock)
+}